@@ -0,0 +1,31 @@
+// Package api holds the OpenAPI description of the REST API. The spec is
+// embedded, so the binary serves and validates against the same document
+// that lives in the repository.
+package api
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+// Load parses the embedded spec and checks that it is a valid OpenAPI 3
+// document.
+func Load(ctx context.Context) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't load openapi spec: %w", err)
+	}
+
+	if err = doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	return doc, nil
+}
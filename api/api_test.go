@@ -0,0 +1,20 @@
+package api
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load(context.Background())
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if doc.Paths.Find("/api/posts/") == nil {
+		t.Error("expected /api/posts/ in the spec")
+		return
+	}
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/archive"
+	"github.com/akrovv/redditclone/internal/config"
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/metrics"
+	"github.com/akrovv/redditclone/internal/seed"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/pkg/logger"
+)
+
+const ctlUsage = `usage: redditclone ctl [-o table|json] [-as admin] <command> [args]
+
+  user create <username>          creates a user, the password is read from stdin
+  user reset-password <username>  sets the password read from stdin
+  user ban <username>             deactivates the user and revokes the sessions
+  user unban <username>           activates the user again
+  session list <username>         lists the open sessions of the user
+  session revoke <token>          revokes one session
+  session revoke-all <username>   revokes every session of the user
+  post delete <id> [reason]       soft-deletes the post on behalf of -as
+  post restore <id>               restores a soft-deleted post
+  post recompute [id]             recounts the score of the post, or of every post
+  reindex                         rebuilds the indexes of Postgres and Mongo
+  export [file]                   writes users, posts, comments and votes to an NDJSON
+                                  archive, to stdout without a file
+  import [file]                   restores an archive, from stdin without a file; a
+                                  second import of the same archive changes nothing
+  seed [-seed n] [-users n] [-posts n] [-comments n] [-months n] [-password p]
+       [-now YYYY-MM-DD]          fills the storages with made up users, posts,
+                                  comments and votes leading up to -now, today by
+                                  default; the same seed and -now give the same
+                                  data, a second run adds nothing
+`
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type ctlUserService interface {
+	Save(ctx context.Context, dto *service.SaveUser) (*domain.User, error)
+	ResetPassword(ctx context.Context, dto *service.ResetPassword) error
+	SetActive(ctx context.Context, dto *service.SetUserActive) error
+}
+
+type ctlSessionService interface {
+	List(ctx context.Context, dto *service.ListSessions) ([]*domain.SessionInfo, error)
+	Revoke(ctx context.Context, dto *service.RevokeSession) error
+	RevokeAll(ctx context.Context, dto *service.RevokeSessions) (int, error)
+}
+
+type ctlPostService interface {
+	Delete(ctx context.Context, dto *service.DeletePost) error
+	Restore(ctx context.Context, dto *service.RestorePost) error
+	RecomputeScores(ctx context.Context, dto *service.RecomputeScores) (int64, error)
+}
+
+type ctlArchiveService interface {
+	Export(ctx context.Context, dto *service.ExportArchive) (*archive.Counts, error)
+	Import(ctx context.Context, dto *service.ImportArchive) (*service.ImportResult, error)
+}
+
+// ctl runs the admin commands through the same services the API uses, so
+// the validation and the moderation log apply to them as well.
+type ctl struct {
+	users    ctlUserService
+	sessions ctlSessionService
+	posts    ctlPostService
+	archive  ctlArchiveService
+	st       *storages
+
+	actor  *domain.User
+	output string
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+// outcome is what a command that changes something reports.
+type outcome struct {
+	Action string `json:"action"`
+	Target string `json:"target"`
+	Count  *int64 `json:"count,omitempty"`
+}
+
+// runCtl is the ctl subcommand. It needs the databases the server uses,
+// the memory storage lives only as long as the process.
+func runCtl(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("ctl", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), ctlUsage) }
+	output := flags.String("o", outputTable, "output format: table or json")
+	actor := flags.String("as", "admin", "username the moderation log records")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case flags.NArg() == 0:
+		flags.Usage()
+		return errors.New("expected a command")
+	case *output != outputTable && *output != outputJSON:
+		return fmt.Errorf("unknown -o %q, expected %s or %s", *output, outputTable, outputJSON)
+	case cfg.Storage == storageMemory:
+		return errors.New("STORAGE=memory keeps nothing to administer")
+	}
+
+	st, err := newStorages(cfg, metrics.New())
+
+	if err != nil {
+		return err
+	}
+	defer st.close(context.Background())
+
+	c := newCtl(cfg, st, *actor, *output)
+
+	l, err := logger.New(cfg.LogLevel)
+
+	if err != nil {
+		return err
+	}
+
+	// The services log what doesn't fail the command, e.g. a lost mod log
+	// entry, to stderr
+	ctx, stop := signal.NotifyContext(logger.NewContext(context.Background(), l), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return c.run(ctx, flags.Args())
+}
+
+// newCtl builds the services over st the way the server does.
+func newCtl(cfg *config.Config, st *storages, actor, output string) *ctl {
+	policy := service.ContentPolicy{
+		RestoreWindow:      cfg.RestoreWindow,
+		ArchiveAfterMonths: cfg.ArchiveAfterMonths,
+	}
+
+	automodService := service.NewAutomodService(st.automod, st.posts, st.users, st.reports, st.modLog)
+
+	return &ctl{
+		users:    service.NewUserService(st.users, st.modLog),
+		sessions: service.NewSessionService(st.sessions),
+		posts:    service.NewPostService(st.posts, st.modLog, policy, automodService),
+		archive:  service.NewArchiveService(st.users, st.posts),
+		st:       st,
+		actor:    &domain.User{Username: actor, ID: actor, Role: domain.RoleAdmin},
+		output:   output,
+		stdin:    os.Stdin,
+		stdout:   os.Stdout,
+	}
+}
+
+func (c *ctl) run(ctx context.Context, args []string) error {
+	command := strings.Join(args[:min(2, len(args))], " ")
+
+	switch {
+	case command == "user create" && len(args) == 3:
+		return c.createUser(ctx, args[2])
+	case command == "user reset-password" && len(args) == 3:
+		return c.resetPassword(ctx, args[2])
+	case command == "user ban" && len(args) == 3:
+		return c.setActive(ctx, args[2], false)
+	case command == "user unban" && len(args) == 3:
+		return c.setActive(ctx, args[2], true)
+	case command == "session list" && len(args) == 3:
+		return c.listSessions(ctx, args[2])
+	case command == "session revoke" && len(args) == 3:
+		return c.revokeSession(ctx, args[2])
+	case command == "session revoke-all" && len(args) == 3:
+		return c.revokeSessions(ctx, args[2])
+	case command == "post delete" && len(args) >= 3:
+		return c.deletePost(ctx, args[2], strings.Join(args[3:], " "))
+	case command == "post restore" && len(args) == 3:
+		return c.restorePost(ctx, args[2])
+	case command == "post recompute" && len(args) <= 3:
+		postID := ""
+
+		if len(args) == 3 {
+			postID = args[2]
+		}
+
+		return c.recompute(ctx, postID)
+	case args[0] == "reindex" && len(args) == 1:
+		return c.reindex(ctx)
+	case args[0] == "export" && len(args) <= 2:
+		return c.export(ctx, args[1:])
+	case args[0] == "import" && len(args) <= 2:
+		return c.restore(ctx, args[1:])
+	case args[0] == "seed":
+		return c.seed(ctx, args[1:])
+	}
+
+	return fmt.Errorf("unknown command %q, see redditclone ctl -h", strings.Join(args, " "))
+}
+
+func (c *ctl) createUser(ctx context.Context, username string) error {
+	password, err := c.readPassword()
+
+	if err != nil {
+		return err
+	}
+
+	user, err := c.users.Save(ctx, &service.SaveUser{Username: username, Password: password})
+
+	if err != nil {
+		return err
+	}
+
+	user.Password = ""
+
+	return c.print(user, []string{"ID", "USERNAME", "ROLE"}, [][]string{{user.ID, user.Username, user.Role}})
+}
+
+func (c *ctl) resetPassword(ctx context.Context, username string) error {
+	password, err := c.readPassword()
+
+	if err != nil {
+		return err
+	}
+
+	if err = c.users.ResetPassword(ctx, &service.ResetPassword{Username: username, Password: password}); err != nil {
+		return err
+	}
+
+	return c.printOutcome(&outcome{Action: "reset-password", Target: username})
+}
+
+// setActive revokes the sessions of a banned user too, otherwise they stay
+// logged in until the sessions expire.
+func (c *ctl) setActive(ctx context.Context, username string, active bool) error {
+	if err := c.users.SetActive(ctx, &service.SetUserActive{User: c.actor, Username: username, Active: active}); err != nil {
+		return err
+	}
+
+	if active {
+		return c.printOutcome(&outcome{Action: "unban", Target: username})
+	}
+
+	revoked, err := c.sessions.RevokeAll(ctx, &service.RevokeSessions{Username: username})
+
+	if err != nil {
+		return fmt.Errorf("user is banned, but the sessions are not revoked: %w", err)
+	}
+
+	return c.printOutcome(&outcome{Action: "ban", Target: username, Count: count(int64(revoked))})
+}
+
+func (c *ctl) listSessions(ctx context.Context, username string) error {
+	sessions, err := c.sessions.List(ctx, &service.ListSessions{Username: username})
+
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(sessions))
+
+	for _, s := range sessions {
+		rows = append(rows, []string{s.ID, s.User.Username, s.Expires.Format(time.RFC3339)})
+	}
+
+	return c.print(sessions, []string{"TOKEN", "USERNAME", "EXPIRES"}, rows)
+}
+
+func (c *ctl) revokeSession(ctx context.Context, key string) error {
+	if err := c.sessions.Revoke(ctx, &service.RevokeSession{Key: key}); err != nil {
+		return err
+	}
+
+	return c.printOutcome(&outcome{Action: "revoke", Target: key})
+}
+
+func (c *ctl) revokeSessions(ctx context.Context, username string) error {
+	revoked, err := c.sessions.RevokeAll(ctx, &service.RevokeSessions{Username: username})
+
+	if err != nil {
+		return err
+	}
+
+	return c.printOutcome(&outcome{Action: "revoke-all", Target: username, Count: count(int64(revoked))})
+}
+
+func (c *ctl) deletePost(ctx context.Context, postID, reason string) error {
+	if err := c.posts.Delete(ctx, &service.DeletePost{User: c.actor, PostID: postID, Reason: reason}); err != nil {
+		return err
+	}
+
+	return c.printOutcome(&outcome{Action: "delete", Target: postID})
+}
+
+func (c *ctl) restorePost(ctx context.Context, postID string) error {
+	if err := c.posts.Restore(ctx, &service.RestorePost{User: c.actor, PostID: postID}); err != nil {
+		return err
+	}
+
+	return c.printOutcome(&outcome{Action: "restore", Target: postID})
+}
+
+func (c *ctl) recompute(ctx context.Context, postID string) error {
+	changed, err := c.posts.RecomputeScores(ctx, &service.RecomputeScores{PostID: postID})
+
+	if err != nil {
+		return err
+	}
+
+	target := postID
+
+	if target == "" {
+		target = "all posts"
+	}
+
+	return c.printOutcome(&outcome{Action: "recompute", Target: target, Count: count(changed)})
+}
+
+func (c *ctl) reindex(ctx context.Context) error {
+	outcomes := make([]*outcome, 0, len(c.st.migrations))
+
+	for _, r := range c.st.migrations {
+		if err := r.Reindex(ctx); err != nil {
+			return err
+		}
+
+		outcomes = append(outcomes, &outcome{Action: "reindex", Target: r.Name()})
+	}
+
+	return c.printOutcomes(outcomes...)
+}
+
+// export writes the summary to stderr when the archive goes to stdout.
+func (c *ctl) export(ctx context.Context, args []string) (err error) {
+	out := c.stdout
+
+	if len(args) == 1 {
+		f, createErr := os.Create(args[0])
+
+		if createErr != nil {
+			return createErr
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}()
+
+		out = f
+	} else {
+		c.stdout = os.Stderr
+	}
+
+	counts, err := c.archive.Export(ctx, &service.ExportArchive{Writer: out})
+
+	if err != nil {
+		return err
+	}
+
+	return c.print(counts, []string{"USERS", "POSTS", "COMMENTS", "VOTES"}, [][]string{{
+		strconv.FormatInt(counts.Users, 10), strconv.FormatInt(counts.Posts, 10),
+		strconv.FormatInt(counts.Comments, 10), strconv.FormatInt(counts.Votes, 10),
+	}})
+}
+
+// restore is the import command, import is a keyword.
+func (c *ctl) restore(ctx context.Context, args []string) error {
+	in := c.stdin
+
+	if len(args) == 1 {
+		f, err := os.Open(args[0])
+
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	result, err := c.archive.Import(ctx, &service.ImportArchive{Reader: in})
+
+	// What was imported before the error stays, a second run picks up
+	// where this one stopped.
+	if result != nil {
+		if printErr := c.print(result, []string{"USERS", "SKIPPED", "PASSWORD RESETS", "POSTS", "SKIPPED", "REMAPPED"}, [][]string{{
+			strconv.Itoa(result.Users), strconv.Itoa(result.UsersSkipped), strconv.Itoa(result.PasswordResets),
+			strconv.Itoa(result.Posts), strconv.Itoa(result.PostsSkipped), strconv.Itoa(result.PostsRemapped),
+		}}); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+
+	return err
+}
+
+func (c *ctl) seed(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	opts := seed.Options{}
+	flags.Int64Var(&opts.Seed, "seed", 1, "seed of the random data")
+	flags.IntVar(&opts.Users, "users", 100, "number of users")
+	flags.IntVar(&opts.Posts, "posts", 500, "number of posts")
+	flags.IntVar(&opts.Comments, "comments", 5, "mean number of comments of a post")
+	flags.IntVar(&opts.Months, "months", 6, "how many months back the posts go")
+	flags.StringVar(&opts.Password, "password", "password", "password of every user")
+
+	// The posts lead up to the start of a day, not to the moment of the
+	// run, so a second run on the same day finds them all.
+	opts.Now = time.Now().UTC().Truncate(24 * time.Hour)
+	flags.Func("now", "day the posts lead up to, YYYY-MM-DD", func(value string) error {
+		day, err := time.Parse(time.DateOnly, value)
+		opts.Now = day
+
+		return err
+	})
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments %q", strings.Join(flags.Args(), " "))
+	}
+
+	result, err := seed.Run(ctx, c.st.users, c.st.posts, c.st.comments, opts)
+
+	if result != nil {
+		if printErr := c.print(result, []string{"USERS", "SKIPPED", "POSTS", "SKIPPED", "COMMENTS", "VOTES"}, [][]string{{
+			strconv.Itoa(result.Users), strconv.Itoa(result.UsersSkipped), strconv.Itoa(result.Posts),
+			strconv.Itoa(result.PostsSkipped), strconv.Itoa(result.Comments), strconv.Itoa(result.Votes),
+		}}); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+
+	return err
+}
+
+// readPassword reads the password from the first line of stdin, so it
+// stays out of the shell history and the process list.
+func (c *ctl) readPassword() (string, error) {
+	line, err := bufio.NewReader(c.stdin).ReadString('\n')
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("can't read the password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (c *ctl) printOutcome(o *outcome) error {
+	if c.output == outputJSON {
+		return c.print(o, nil, nil)
+	}
+
+	return c.printOutcomes(o)
+}
+
+func (c *ctl) printOutcomes(outcomes ...*outcome) error {
+	rows := make([][]string, 0, len(outcomes))
+
+	for _, o := range outcomes {
+		n := ""
+
+		if o.Count != nil {
+			n = strconv.FormatInt(*o.Count, 10)
+		}
+
+		rows = append(rows, []string{o.Action, o.Target, n})
+	}
+
+	return c.print(outcomes, []string{"ACTION", "TARGET", "COUNT"}, rows)
+}
+
+// print writes v as JSON, or the rows as a table under the header.
+func (c *ctl) print(v any, header []string, rows [][]string) error {
+	if c.output == outputJSON {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+
+	return w.Flush()
+}
+
+func count(n int64) *int64 {
+	return &n
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/config"
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestCtl(t *testing.T) {
+	ctx := context.Background()
+	st := newMemoryStorages(config.Default())
+	c := newCtl(config.Default(), st, "root", outputTable)
+	out := &bytes.Buffer{}
+	c.stdout = out
+
+	post, err := st.posts.Save(ctx, &domain.Post{
+		Type:     domain.PostTypeText,
+		Title:    "title",
+		Text:     "text",
+		Category: "music",
+		Author:   &domain.Profile{Username: "akro", ID: "akro-id"},
+		Votes:    []*domain.Vote{{User: "akro-id", Vote: 1}, {User: "other-id", Vote: 1}},
+		Comments: []*domain.Comment{},
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	session, err := st.sessions.Create(ctx, "akro-id", "akro", domain.RoleMember)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	other, err := st.sessions.Create(ctx, "other-id", "other", domain.RoleMember)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "archive.ndjson")
+
+	// The commands run one after another on the same storages
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		stdin    string
+		json     bool
+		output   string
+		contains []string
+		err      string
+	}{
+		{
+			name:  "create user",
+			args:  []string{"user", "create", "akro"},
+			stdin: "password\n",
+			output: "ID                        USERNAME  ROLE\n" +
+				"18890d6a9ce0cdbb5a8ce0c1  akro      member\n",
+		},
+		{
+			name:  "create taken user",
+			args:  []string{"user", "create", "akro"},
+			stdin: "password\n",
+			err:   "already exists",
+		},
+		{
+			name:  "reset password",
+			args:  []string{"user", "reset-password", "akro"},
+			stdin: "new password\n",
+			output: "ACTION          TARGET  COUNT\n" +
+				"reset-password  akro    \n",
+		},
+		{
+			name:  "reset password of unknown user",
+			args:  []string{"user", "reset-password", "unknown"},
+			stdin: "password\n",
+			err:   "not found",
+		},
+		{
+			name:     "list sessions",
+			args:     []string{"session", "list", "akro"},
+			contains: []string{"TOKEN", session.ID + "  akro"},
+		},
+		{
+			name: "revoke session",
+			args: []string{"session", "revoke", other.ID},
+			json: true,
+			output: "{\n" +
+				"  \"action\": \"revoke\",\n" +
+				"  \"target\": \"" + other.ID + "\"\n" +
+				"}\n",
+		},
+		{
+			name: "ban",
+			args: []string{"user", "ban", "akro"},
+			output: "ACTION  TARGET  COUNT\n" +
+				"ban     akro    1\n",
+		},
+		{
+			name: "ban unknown user",
+			args: []string{"user", "ban", "unknown"},
+			err:  "not found",
+		},
+		{
+			name: "revoke revoked session",
+			args: []string{"session", "revoke", session.ID},
+			err:  "not found",
+		},
+		{
+			name: "unban",
+			args: []string{"user", "unban", "akro"},
+			json: true,
+			output: "{\n" +
+				"  \"action\": \"unban\",\n" +
+				"  \"target\": \"akro\"\n" +
+				"}\n",
+		},
+		{
+			name: "revoke all sessions",
+			args: []string{"session", "revoke-all", "akro"},
+			output: "ACTION      TARGET  COUNT\n" +
+				"revoke-all  akro    0\n",
+		},
+		{
+			name: "delete post",
+			args: []string{"post", "delete", post.ID, "off", "topic"},
+			output: "ACTION  TARGET                                COUNT\n" +
+				"delete  " + post.ID + "  \n",
+		},
+		{
+			name: "restore post",
+			args: []string{"post", "restore", post.ID},
+			output: "ACTION   TARGET                                COUNT\n" +
+				"restore  " + post.ID + "  \n",
+		},
+		{
+			name: "restore unknown post",
+			args: []string{"post", "restore", "unknown"},
+			err:  "not found",
+		},
+		{
+			name: "recompute post",
+			args: []string{"post", "recompute", post.ID},
+			output: "ACTION     TARGET                                COUNT\n" +
+				"recompute  " + post.ID + "  1\n",
+		},
+		{
+			name: "recompute all posts",
+			args: []string{"post", "recompute"},
+			json: true,
+			output: "{\n" +
+				"  \"action\": \"recompute\",\n" +
+				"  \"target\": \"all posts\",\n" +
+				"  \"count\": 0\n" +
+				"}\n",
+		},
+		{
+			name:   "reindex without migrations",
+			args:   []string{"reindex"},
+			output: "ACTION  TARGET  COUNT\n",
+		},
+		{
+			name: "export",
+			args: []string{"export", archive},
+			output: "USERS  POSTS  COMMENTS  VOTES\n" +
+				"1      1      0         2\n",
+		},
+		{
+			name: "import of the same data",
+			args: []string{"import", archive},
+			output: "USERS  SKIPPED  PASSWORD RESETS  POSTS  SKIPPED  REMAPPED\n" +
+				"0      1        0                0      1        0\n",
+		},
+		{
+			name: "seed",
+			args: []string{"seed", "-users", "3", "-posts", "4", "-comments", "2", "-now", "2024-03-01"},
+			output: "USERS  SKIPPED  POSTS  SKIPPED  COMMENTS  VOTES\n" +
+				"3      0        4      0        6         9\n",
+		},
+		{
+			name: "seed again on the same day",
+			args: []string{"seed", "-users", "3", "-posts", "4", "-comments", "2", "-now", "2024-03-01"},
+			output: "USERS  SKIPPED  POSTS  SKIPPED  COMMENTS  VOTES\n" +
+				"0      3        0      4        0         0\n",
+		},
+		{
+			name: "seed with a bad day",
+			args: []string{"seed", "-now", "yesterday"},
+			err:  "-now",
+		},
+		{
+			name: "unknown command",
+			args: []string{"user", "delete", "akro"},
+			err:  "unknown command",
+		},
+		{
+			name: "missing argument",
+			args: []string{"user", "ban"},
+			err:  "unknown command",
+		},
+	} {
+		out.Reset()
+		c.stdin = strings.NewReader(tc.stdin)
+		c.output = outputTable
+
+		if tc.json {
+			c.output = outputJSON
+		}
+
+		err := c.run(ctx, tc.args)
+
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: expected %q error, got: %v", tc.name, tc.err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+			continue
+		}
+
+		for _, s := range tc.contains {
+			if !strings.Contains(out.String(), s) {
+				t.Errorf("%s: expected %q in output:\n%s", tc.name, s, out)
+			}
+		}
+
+		if tc.contains == nil && out.String() != tc.output {
+			t.Errorf("%s: expected output:\n%s\ngot:\n%s", tc.name, tc.output, out)
+		}
+	}
+
+	// The ban and the unban are in the mod log on behalf of -as
+	entries, err := st.modLog.Get(ctx, &domain.ModLogFilter{Actor: "root", TargetID: "akro"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	actions := make([]string, 0, len(entries))
+
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+
+	if strings.Join(actions, " ") != domain.ModActionUnbanUser+" "+domain.ModActionBanUser {
+		t.Errorf("expected unban and ban in the mod log, got: %v", actions)
+	}
+}
@@ -2,154 +2,369 @@ package main
 
 import (
 	"context"
-	"database/sql"
-	"fmt"
+	"crypto/tls"
+	"errors"
+	"flag"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
-	"github.com/akrovv/redditclone/internal/adapters/mongodb"
-	"github.com/akrovv/redditclone/internal/adapters/pgsqldb"
-	"github.com/akrovv/redditclone/internal/adapters/redisdb"
+	"github.com/akrovv/redditclone/api"
 	"github.com/akrovv/redditclone/internal/config"
 	"github.com/akrovv/redditclone/internal/controllers/rest"
 	"github.com/akrovv/redditclone/internal/controllers/rest/middleware"
+	"github.com/akrovv/redditclone/internal/metrics"
 	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/internal/tracing"
+	"github.com/akrovv/redditclone/internal/worker"
+	"github.com/akrovv/redditclone/pkg/certreload"
 	"github.com/akrovv/redditclone/pkg/logger"
 	"github.com/casbin/casbin"
-	"github.com/redis/go-redis/v9"
-
-	"github.com/gorilla/mux"
-	_ "github.com/lib/pq"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-const (
-	path     = "."
-	filename = ".env"
-)
+const filename = ".env"
 
 func main() {
-	cfg, err := config.New(filename, path)
+	cfg, args, err := config.Load(filename, os.Args[1:])
+
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 
-	ctxRedis := context.Background()
-	dsnRedis := fmt.Sprintf("%s:%s", cfg.RedisHost, cfg.RedisPort)
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			err = runMigrate(cfg, args[1:])
+		case "ctl":
+			err = runCtl(cfg, args[1:])
+		case "config":
+			err = cfg.Write(os.Stdout)
+		default:
+			log.Fatalf("unknown command %q, expected migrate, ctl or config", args[0])
+		}
+
+		if err != nil {
+			log.Fatal(err)
+		}
 
-	client := redis.NewClient(&redis.Options{
-		Addr: dsnRedis,
-		DB:   0,
-	})
-
-	err = client.Ping(ctxRedis).Err()
-
-	if err != nil {
-		log.Fatal("redis", err)
 		return
 	}
-	defer client.Close()
 
-	ctxMongo := context.Background()
-	dsnMongo := fmt.Sprintf("mongodb://%s", cfg.MongoHost)
-	mongoClient, err := mongo.Connect(ctxMongo, options.Client().ApplyURI(dsnMongo))
+	m := metrics.New()
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "redditclone",
+		Exporter:    cfg.TraceExporter,
+		Endpoint:    cfg.TraceEndpoint,
+		Insecure:    cfg.TraceInsecure,
+	})
 
 	if err != nil {
-		log.Fatal("mongo", err)
+		log.Fatal(err)
 		return
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s", cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.SSLMode)
-	db, err := sql.Open("postgres", dsn)
+	st, err := newStorages(cfg, m)
 
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 
-	db.SetMaxOpenConns(10)
-
-	err = db.Ping()
-
+	e, err := casbin.NewEnforcerSafe("basic_model.conf", "basic_policy.csv")
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 
-	e, err := casbin.NewEnforcerSafe("basic_model.conf", "basic_policy.csv")
+	l, err := logger.New(cfg.LogLevel)
+
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 
-	l := logger.NewLogger()
+	policy := service.ContentPolicy{
+		RestoreWindow:      cfg.RestoreWindow,
+		ArchiveAfterMonths: cfg.ArchiveAfterMonths,
+	}
 
-	var (
-		commentDB = mongodb.NewCommentStorage(ctxMongo, mongoClient)
-		postDB    = mongodb.NewPostStorage(ctxMongo, mongoClient)
-		userDB    = pgsqldb.NewUserStorage(db)
-		sessionDB = redisdb.NewSessionStorage(ctxRedis, client)
-	)
+	automodService := service.NewAutomodService(st.automod, st.posts, st.users, st.reports, st.modLog)
 
 	var (
-		commentService = service.NewCommentService(commentDB)
-		postService    = service.NewPostService(postDB)
-		userService    = service.NewUserService(userDB)
-		sessionService = service.NewSessionService(sessionDB)
+		commentService = service.NewCommentService(st.comments, st.posts, st.modLog, policy, automodService)
+		postService    = service.NewPostService(st.posts, st.modLog, policy, automodService)
+		userService    = service.NewUserService(st.users, st.modLog)
+		sessionService = service.NewSessionService(st.sessions)
+		reportService  = service.NewReportService(st.reports, st.posts, st.comments, st.modLog)
+		modLogService  = service.NewModLogService(st.modLog)
 	)
 
 	rootHandler := rest.NewRootHandler(l)
 	userHandler := rest.NewUserHandler(l, userService, sessionService)
 	postHandler := rest.NewPostHandler(l, postService, commentService, sessionService)
+	moderationHandler := rest.NewModerationHandler(l, reportService, modLogService, postService)
+	automodHandler := rest.NewAutomodHandler(l, automodService)
+	v2Handler := rest.NewV2Handler(l, userService, sessionService, postService, commentService, reportService, modLogService)
+	healthHandler := rest.NewHealthHandler(l, cfg.HealthTimeout, st.checks)
 
-	router := mux.NewRouter()
+	spec, err := api.Load(context.Background())
 
-	// Static
-	staticHandler := http.StripPrefix("/static/", http.FileServer(http.Dir("front")))
-	router.PathPrefix("/static").Handler(staticHandler)
-	router.Handle("/static/", staticHandler)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// Main
-	router.HandleFunc("/", rootHandler.Main)
+	openAPIHandler, err := rest.NewOpenAPIHandler(l, spec)
 
-	// API
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// User
-	router.HandleFunc("/signup", rootHandler.Main)
-	router.HandleFunc("/login", rootHandler.Main)
-	router.HandleFunc("/u/{USER_LOGIN}", rootHandler.Main)
+	routes := rest.APIRoutes(&rest.Handlers{
+		Users:      userHandler,
+		Posts:      postHandler,
+		Moderation: moderationHandler,
+		Automod:    automodHandler,
+		OpenAPI:    openAPIHandler,
+		V2:         v2Handler,
+	})
 
-	router.HandleFunc("/api/register", userHandler.Register).Methods("POST")
-	router.HandleFunc("/api/login", userHandler.Login).Methods("POST")
+	for _, name := range []string{"login", "v2CreateSession"} {
+		routes.Wrap(name, func(next http.HandlerFunc) http.HandlerFunc {
+			return middleware.CountLogins(next, m)
+		})
+	}
 
-	// Posts
-	router.HandleFunc("/createpost", rootHandler.Main)
-	router.HandleFunc("/a/{CATEGORY_NAME}", rootHandler.Main)
-	router.HandleFunc("/a/{CATEGORY_NAME}/{POST_ID}", rootHandler.Main)
+	// The routes that log in hand out the session cookie as well.
+	if cfg.AuthCookie {
+		for _, name := range []string{"login", "register", "v2CreateSession"} {
+			routes.Wrap(name, func(next http.HandlerFunc) http.HandlerFunc {
+				return middleware.SessionCookies(next, []byte(cfg.CSRFKey), cfg.SessionTTL)
+			})
+		}
+	}
 
-	router.HandleFunc("/api/posts/", postHandler.ShowPosts).Methods("GET")
-	router.HandleFunc("/api/posts", postHandler.AddPost).Methods("POST")
-	router.HandleFunc("/api/posts/{CATEGORY_NAME}", postHandler.ShowPostsByFilter).Methods("GET")
-	router.HandleFunc("/api/post/{POST_ID}", postHandler.PostDetail).Methods("GET")
-	router.HandleFunc("/api/post/{POST_ID}", postHandler.AddComment).Methods("POST")
-	router.HandleFunc("/api/post/{POST_ID}/{COMMENT_ID}", postHandler.DeleteComment).Methods("DELETE")
-	router.HandleFunc("/api/post/{POST_ID}/upvote", postHandler.PostVote).Methods("GET")
-	router.HandleFunc("/api/post/{POST_ID}/downvote", postHandler.PostVote).Methods("GET")
-	router.HandleFunc("/api/post/{POST_ID}/unvote", postHandler.PostVote).Methods("GET")
-	router.HandleFunc("/api/post/{POST_ID}", postHandler.DeletePost).Methods("DELETE")
-	router.HandleFunc("/api/user/{USER_LOGIN}", postHandler.GetUserPosts).Methods("GET")
+	router := rest.NewRouter(rootHandler, routes)
 
-	siteMux := middleware.Logger(router, l)
+	siteMux := middleware.OpenAPI(router, router, spec, l, cfg.OpenAPIValidateResponses)
 	siteMux = middleware.Permissions(siteMux, l, e)
 	siteMux = middleware.Auth(siteMux, sessionService)
 
-	log.Println("Server is starting on :8080")
-	errListen := http.ListenAndServe(":8080", siteMux)
+	if cfg.AuthCookie {
+		siteMux = middleware.CookieAuth(siteMux, []byte(cfg.CSRFKey))
+	}
+
+	siteMux = middleware.Metrics(siteMux, router, m)
+	siteMux = middleware.Logger(siteMux, router, l)
+	siteMux = middleware.RequestID(siteMux)
+	siteMux = middleware.Tracing(siteMux, router)
+	siteMux = middleware.LegacyAPI(siteMux)
+
+	if cfg.TLS() && cfg.HSTSMaxAge > 0 {
+		siteMux = middleware.HSTS(siteMux, cfg.HSTSMaxAge, cfg.HSTSIncludeSubdomains)
+	}
+
+	page, err := os.ReadFile(rest.IndexPage)
 
-	if errListen != nil {
+	if err != nil {
+		log.Fatal(err)
 		return
 	}
+
+	siteMux = middleware.SecurityHeaders(siteMux, middleware.SecurityPolicy{
+		ContentSecurityPolicy: middleware.AllowInlineScripts(cfg.ContentSecurityPolicy, page),
+		FrameOptions:          cfg.FrameOptions,
+		ReferrerPolicy:        cfg.ReferrerPolicy,
+	})
+
+	// CORS comes first, a preflight carries no session and is not in the
+	// OpenAPI spec.
+	siteMux = middleware.CORS(siteMux, middleware.CORSPolicy{
+		Origins:     cfg.CORSOrigins,
+		Credentials: cfg.AuthCookie,
+		MaxAge:      cfg.CORSMaxAge,
+	})
+
+	// Probes and metrics are served outside of the middleware chain, so they
+	// need neither a session nor a casbin policy.
+	rootMux := http.NewServeMux()
+	rootMux.HandleFunc("/healthz", healthHandler.Live)
+	rootMux.HandleFunc("/readyz", healthHandler.Ready)
+	rootMux.Handle("/metrics", m.Handler())
+	rootMux.Handle("/", siteMux)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{
+		Addr:         net.JoinHostPort(cfg.ServerHost, strconv.Itoa(cfg.ServerPort)),
+		Handler:      rootMux,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+
+	var certs *certreload.Reloader
+
+	if cfg.TLS() {
+		if certs, err = certreload.New(cfg.TLSCertFile, cfg.TLSKeyFile); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		server.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+
+	errListen := make(chan error, 2)
+
+	go func() {
+		if certs == nil {
+			log.Printf("Server is starting on %s", server.Addr)
+			errListen <- server.ListenAndServe()
+			return
+		}
+
+		// The certificate comes from GetCertificate, ServeTLS adds h2 to
+		// the protocols, so HTTP/2 needs nothing else.
+		log.Printf("Server is starting on %s with TLS", server.Addr)
+		errListen <- server.ListenAndServeTLS("", "")
+	}()
+
+	var redirect *http.Server
+
+	if cfg.TLSRedirectPort != 0 {
+		redirect = &http.Server{
+			Addr:         net.JoinHostPort(cfg.ServerHost, strconv.Itoa(cfg.TLSRedirectPort)),
+			Handler:      middleware.RedirectHTTPS(cfg.ServerPort),
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			IdleTimeout:  cfg.IdleTimeout,
+		}
+
+		go func() {
+			log.Printf("Redirect to HTTPS is starting on %s", redirect.Addr)
+			errListen <- redirect.ListenAndServe()
+		}()
+	}
+
+	// The probes are served while the schema is migrated, /readyz reports
+	// migrating until it is done. The workers wait for it too.
+	var errMigrate error
+
+	if cfg.MigrateOnStart {
+		healthHandler.SetReady(false, "migrating")
+
+		if errMigrate = migrateOnStart(ctx, st.migrations); errMigrate != nil {
+			log.Println("migrate:", errMigrate)
+			stop()
+		}
+	}
+
+	workers := &worker.Group{}
+
+	workers.Go(ctx, l, "purge", cfg.PurgeInterval, func(ctx context.Context) error {
+		posts, err := postService.Purge(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		comments, err := commentService.Purge(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		logger.WithTrace(ctx, l).Infof("purged %d posts and %d comments", posts, comments)
+		return nil
+	})
+
+	workers.Go(ctx, l, "archive", cfg.ArchiveInterval, func(ctx context.Context) error {
+		archived, err := postService.Archive(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		logger.WithTrace(ctx, l).Infof("archived %d posts", archived)
+		return nil
+	})
+
+	if certs != nil {
+		workers.Go(ctx, l, "certreload", cfg.TLSReloadInterval, func(ctx context.Context) error {
+			reloaded, err := certs.Reload()
+
+			if err != nil {
+				return err
+			}
+
+			if reloaded {
+				logger.WithTrace(ctx, l).Infof("reloaded the TLS certificate, it expires at %s", certs.NotAfter())
+			}
+
+			return nil
+		})
+	}
+
+	if ctx.Err() == nil {
+		healthHandler.SetReady(true, "")
+	}
+
+	select {
+	case err = <-errListen:
+		log.Println("server stopped:", err)
+	case <-ctx.Done():
+		log.Println("shutting down")
+	}
+
+	stop()
+
+	// Readiness fails first so the orchestrator stops routing new traffic
+	// here before the listener goes away.
+	healthHandler.SetReady(false, "draining")
+	time.Sleep(cfg.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	// In-flight requests drain first, then the workers finish their current
+	// run, and only then the storages they use are closed.
+	if err = server.Shutdown(shutdownCtx); err != nil {
+		log.Println("server shutdown:", err)
+	}
+
+	if redirect != nil {
+		if err = redirect.Shutdown(shutdownCtx); err != nil {
+			log.Println("redirect shutdown:", err)
+		}
+	}
+
+	if !workers.Wait(shutdownCtx) {
+		log.Println("workers did not stop in time")
+	}
+
+	st.close(shutdownCtx)
+
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		log.Println("tracing shutdown:", err)
+	}
+
+	log.Println("server stopped")
+
+	if errMigrate != nil {
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/akrovv/redditclone/internal/config"
+	"github.com/akrovv/redditclone/internal/metrics"
+	"github.com/akrovv/redditclone/internal/migrate"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const migrateUsage = `usage: redditclone migrate [-db all|postgres|mongo] [-steps n] up|down|status
+
+  up      applies the pending migrations
+  down    reverts the last -steps applied migrations
+  status  lists the migrations and whether they are applied
+`
+
+// runMigrate is the migrate subcommand. It connects only to Postgres and,
+// unless CONTENT_STORAGE=postgres leaves nothing there, to Mongo. Redis has
+// no schema.
+func runMigrate(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprint(flags.Output(), migrateUsage) }
+	dbName := flags.String("db", "all", "database to migrate: all, postgres or mongo")
+	steps := flags.Int("steps", 1, "number of migrations down reverts")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	command := flags.Arg(0)
+
+	switch {
+	case flags.NArg() != 1, command != "up" && command != "down" && command != "status":
+		flags.Usage()
+		return errors.New("expected one command: up, down or status")
+	case *dbName != "all" && *dbName != "postgres" && *dbName != "mongo":
+		return fmt.Errorf("unknown -db %q, expected all, postgres or mongo", *dbName)
+	case *steps < 1:
+		return errors.New("-steps must be at least 1")
+	case cfg.Storage == storageMemory:
+		return errors.New("STORAGE=memory has no schema to migrate")
+	case cfg.ContentStorage == contentPostgres && *dbName == "mongo":
+		return errors.New("CONTENT_STORAGE=postgres keeps nothing in Mongo")
+	}
+
+	var mongoDB *mongo.Database
+
+	if cfg.ContentStorage != contentPostgres {
+		mongoClient, err := connectMongo(cfg, metrics.New())
+
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := mongoClient.Disconnect(context.Background()); err != nil {
+				log.Println("mongo disconnect:", err)
+			}
+		}()
+
+		mongoDB = mongoClient.Database(cfg.MongoDatabase)
+	}
+
+	db, err := connectPostgres(cfg)
+
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	runners, err := newMigrations(db, mongoDB)
+
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	for _, r := range runners {
+		if *dbName != "all" && *dbName != r.Name() {
+			continue
+		}
+
+		switch command {
+		case "up":
+			done, err := r.Up(ctx)
+			printMigrations(r, "applied", done)
+
+			if err != nil {
+				return err
+			}
+
+		case "down":
+			done, err := r.Down(ctx, *steps)
+			printMigrations(r, "reverted", done)
+
+			if err != nil {
+				return err
+			}
+
+		case "status":
+			statuses, err := r.Status(ctx)
+
+			if err != nil {
+				return err
+			}
+
+			for _, s := range statuses {
+				state := "pending"
+
+				if s.Applied {
+					state = "applied"
+				}
+
+				fmt.Fprintf(os.Stdout, "%s\t%s\t%s\n", r.Name(), s.Migration, state)
+			}
+		}
+	}
+
+	return nil
+}
+
+func printMigrations(r *migrate.Runner, what string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(os.Stdout, "%s: nothing %s\n", r.Name(), what)
+	}
+
+	for _, m := range migrations {
+		fmt.Fprintf(os.Stdout, "%s: %s %s\n", r.Name(), what, m)
+	}
+}
+
+// migrateOnStart applies the pending migrations of every database before
+// the server reports ready. The runners wait for each other, so replicas
+// that start together migrate once.
+func migrateOnStart(ctx context.Context, runners []*migrate.Runner) error {
+	for _, r := range runners {
+		done, err := r.Up(ctx)
+
+		for _, m := range done {
+			log.Printf("%s: applied migration %s", r.Name(), m)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
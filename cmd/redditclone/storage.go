@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+
+	"github.com/XSAM/otelsql"
+	"github.com/akrovv/redditclone/deploy/migrations"
+	"github.com/akrovv/redditclone/internal/adapters/memory"
+	"github.com/akrovv/redditclone/internal/adapters/mongodb"
+	"github.com/akrovv/redditclone/internal/adapters/pgsqldb"
+	"github.com/akrovv/redditclone/internal/adapters/redisdb"
+	"github.com/akrovv/redditclone/internal/config"
+	"github.com/akrovv/redditclone/internal/controllers/rest"
+	"github.com/akrovv/redditclone/internal/metrics"
+	"github.com/akrovv/redditclone/internal/migrate"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/redis/go-redis/extra/redisotel/v9"
+	"github.com/redis/go-redis/v9"
+
+	_ "github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const (
+	storageDB     = "db"
+	storageMemory = "memory"
+
+	contentMongo    = "mongo"
+	contentPostgres = "postgres"
+)
+
+// storages are the adapters the services run on, the checks of the
+// dependencies behind them for /readyz, the migrations of their schemas,
+// and close, which releases the connections once the server and the
+// workers are done.
+type storages struct {
+	posts    service.PostStorage
+	comments service.CommentStorage
+	users    service.UserStorage
+	sessions service.SessionStorage
+	reports  service.ReportStorage
+	modLog   service.ModLogStorage
+	automod  service.AutomodStorage
+
+	checks     map[string]rest.DependencyCheck
+	migrations []*migrate.Runner
+	close      func(ctx context.Context)
+}
+
+// newStorages opens the storages the STORAGE option selects and wraps them
+// in the metrics decorators.
+func newStorages(cfg *config.Config, m *metrics.Metrics) (*storages, error) {
+	var (
+		s   *storages
+		err error
+	)
+
+	switch cfg.Storage {
+	case storageDB:
+		s, err = newDBStorages(cfg, m)
+	case storageMemory:
+		s = newMemoryStorages(cfg)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE %q, expected %s or %s", cfg.Storage, storageDB, storageMemory)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.posts = metrics.NewPostStorage(s.posts, m)
+	s.comments = metrics.NewCommentStorage(s.comments, m)
+	s.users = metrics.NewUserStorage(s.users, m)
+	s.sessions = metrics.NewSessionStorage(s.sessions, m)
+	s.reports = metrics.NewReportStorage(s.reports, m)
+	s.modLog = metrics.NewModLogStorage(s.modLog, m)
+	s.automod = metrics.NewAutomodStorage(s.automod, m)
+
+	return s, nil
+}
+
+// newMemoryStorages keeps everything in the process, there is nothing to
+// check, migrate or close.
+func newMemoryStorages(cfg *config.Config) *storages {
+	db := memory.New()
+
+	return &storages{
+		posts:    memory.NewPostStorage(db),
+		comments: memory.NewCommentStorage(db),
+		users:    memory.NewUserStorage(db),
+		sessions: memory.NewSessionStorage(db, cfg.SessionTTL),
+		reports:  memory.NewReportStorage(db),
+		modLog:   memory.NewModLogStorage(db),
+		automod:  memory.NewAutomodStorage(db),
+		checks:   map[string]rest.DependencyCheck{},
+		close:    func(context.Context) {},
+	}
+}
+
+// newDBStorages connects to Postgres for users, Redis for sessions and, by
+// CONTENT_STORAGE, to Mongo or Postgres for the content and the moderation.
+func newDBStorages(cfg *config.Config, m *metrics.Metrics) (*storages, error) {
+	switch cfg.ContentStorage {
+	case contentMongo, contentPostgres:
+	default:
+		return nil, fmt.Errorf("unknown CONTENT_STORAGE %q, expected %s or %s", cfg.ContentStorage, contentMongo, contentPostgres)
+	}
+
+	ctxRedis := context.Background()
+	dsnRedis := net.JoinHostPort(cfg.RedisHost, strconv.Itoa(cfg.RedisPort))
+
+	client := redis.NewClient(&redis.Options{
+		Addr: dsnRedis,
+		DB:   0,
+	})
+
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	if err := client.Ping(ctxRedis).Err(); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	db, err := connectPostgres(cfg)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.RegisterPostgresPool(db, "usersdb"); err != nil {
+		return nil, err
+	}
+
+	if err = m.RegisterRedisPool(client); err != nil {
+		return nil, err
+	}
+
+	s := &storages{
+		users:    pgsqldb.NewUserStorage(db, cfg.DBTimeout),
+		sessions: redisdb.NewSessionStorage(client, cfg.RedisTimeout, cfg.SessionTTL),
+		checks: map[string]rest.DependencyCheck{
+			"postgres": db.PingContext,
+			"redis": func(ctx context.Context) error {
+				return client.Ping(ctx).Err()
+			},
+		},
+	}
+
+	closeContent, err := s.openContent(cfg, m, db)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.close = func(ctx context.Context) {
+		closeContent(ctx)
+
+		if err := db.Close(); err != nil {
+			log.Println("postgres close:", err)
+		}
+
+		if err := client.Close(); err != nil {
+			log.Println("redis close:", err)
+		}
+	}
+
+	return s, nil
+}
+
+// openContent opens the storages of the content and the moderation in the
+// database CONTENT_STORAGE selects and adds its check and migrations. Mongo
+// is connected to only if it keeps them, the returned func disconnects.
+func (s *storages) openContent(cfg *config.Config, m *metrics.Metrics, db *sql.DB) (func(ctx context.Context), error) {
+	if cfg.ContentStorage == contentPostgres {
+		s.posts = pgsqldb.NewPostStorage(db, cfg.DBTimeout)
+		s.comments = pgsqldb.NewCommentStorage(db, cfg.DBTimeout)
+		s.reports = pgsqldb.NewReportStorage(db, cfg.DBTimeout)
+		s.modLog = pgsqldb.NewModLogStorage(db, cfg.DBTimeout)
+		s.automod = pgsqldb.NewAutomodStorage(db, cfg.DBTimeout)
+
+		runners, err := newMigrations(db, nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.migrations = runners
+
+		return func(context.Context) {}, nil
+	}
+
+	mongoClient, err := connectMongo(cfg, m)
+
+	if err != nil {
+		return nil, err
+	}
+
+	mongoDB := mongoClient.Database(cfg.MongoDatabase)
+
+	s.posts = mongodb.NewPostStorage(mongoDB, cfg.MongoTimeout)
+	s.comments = mongodb.NewCommentStorage(mongoDB, cfg.MongoTimeout)
+	s.reports = mongodb.NewReportStorage(mongoDB, cfg.MongoTimeout)
+	s.modLog = mongodb.NewModLogStorage(mongoDB, cfg.MongoTimeout)
+	s.automod = mongodb.NewAutomodStorage(mongoDB, cfg.MongoTimeout)
+	s.checks["mongo"] = func(ctx context.Context) error {
+		return mongoClient.Ping(ctx, readpref.Primary())
+	}
+
+	runners, err := newMigrations(db, mongoDB)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.migrations = runners
+
+	return func(ctx context.Context) {
+		if err := mongoClient.Disconnect(ctx); err != nil {
+			log.Println("mongo disconnect:", err)
+		}
+	}, nil
+}
+
+func connectMongo(cfg *config.Config, m *metrics.Metrics) (*mongo.Client, error) {
+	dsnMongo := fmt.Sprintf("mongodb://%s", cfg.MongoHost)
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI(dsnMongo).SetPoolMonitor(m.MongoPoolMonitor()).SetMonitor(otelmongo.NewMonitor()))
+
+	if err != nil {
+		return nil, fmt.Errorf("mongo: %w", err)
+	}
+
+	return mongoClient, nil
+}
+
+func connectPostgres(cfg *config.Config) (*sql.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s", cfg.DBHost, cfg.DBPort, cfg.DBUser, string(cfg.DBPassword), cfg.DBName, cfg.SSLMode)
+	db, err := otelsql.Open("postgres", dsn, otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+
+	if err = db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// newMigrations are the schema migrations of Postgres and of the database
+// of Mongo, in the order they run. Without mongoDB there are only the ones
+// of Postgres.
+func newMigrations(db *sql.DB, mongoDB *mongo.Database) ([]*migrate.Runner, error) {
+	pg, err := migrate.NewPostgres(db, migrations.FS)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if mongoDB == nil {
+		return []*migrate.Runner{pg}, nil
+	}
+
+	return []*migrate.Runner{pg, migrate.NewMongo(mongoDB)}, nil
+}
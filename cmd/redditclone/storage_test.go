@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/config"
+	"github.com/akrovv/redditclone/internal/controllers/rest"
+	"github.com/akrovv/redditclone/internal/metrics"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func runnerNames(s *storages) string {
+	names := ""
+
+	for _, r := range s.migrations {
+		names += r.Name() + ","
+	}
+
+	return names
+}
+
+func TestOpenContent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+	}
+	defer db.Close()
+
+	// CONTENT_STORAGE=postgres keeps everything in Postgres and needs no Mongo
+	cfg := config.Default()
+	cfg.ContentStorage, cfg.MongoHost, cfg.MongoDatabase = contentPostgres, "", ""
+	s := &storages{checks: map[string]rest.DependencyCheck{}}
+	closeContent, err := s.openContent(cfg, metrics.New(), db)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	closeContent(context.Background())
+
+	for _, storage := range []any{s.posts, s.comments, s.reports, s.modLog, s.automod} {
+		if typ := fmt.Sprintf("%T", storage); !strings.HasPrefix(typ, "*pgsqldb.") {
+			t.Errorf("expected a pgsqldb storage, got: %s", typ)
+		}
+	}
+
+	if _, ok := s.checks["mongo"]; ok || runnerNames(s) != "postgres," {
+		t.Errorf("expected no mongo check and migrations, got: %v, %s", s.checks, runnerNames(s))
+	}
+
+	// The storages don't query anything until they are used
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// CONTENT_STORAGE=mongo, the client connects lazily
+	cfg = config.Default()
+	s = &storages{checks: map[string]rest.DependencyCheck{}}
+	closeContent, err = s.openContent(cfg, metrics.New(), db)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	closeContent(context.Background())
+
+	for _, storage := range []any{s.posts, s.comments, s.reports, s.modLog, s.automod} {
+		if typ := fmt.Sprintf("%T", storage); !strings.HasPrefix(typ, "*mongodb.") {
+			t.Errorf("expected a mongodb storage, got: %s", typ)
+		}
+	}
+
+	if _, ok := s.checks["mongo"]; !ok || runnerNames(s) != "postgres,mongo," {
+		t.Errorf("expected the mongo check and migrations, got: %v, %s", s.checks, runnerNames(s))
+	}
+}
@@ -2,5 +2,8 @@ CREATE TABLE users (
     user_id SERIAL PRIMARY KEY,
     username varchar(255) NOT NULL,
     password varchar(255) NOT NULL,
-    is_active boolean NOT NULL
-);
\ No newline at end of file
+    is_active boolean NOT NULL,
+    role varchar(32) NOT NULL DEFAULT 'member',
+    created timestamptz NOT NULL DEFAULT now()
+);
+CREATE UNIQUE INDEX users_username_key ON users (username);
@@ -0,0 +1,4 @@
+-- The table itself and its role and created columns may come from init.sql
+-- and hold the accounts, the binary reads them. Only the index is dropped,
+-- the up migration creates it again.
+DROP INDEX IF EXISTS users_username_key;
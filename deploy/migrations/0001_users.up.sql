@@ -0,0 +1,16 @@
+-- The same table as deploy/init.sql, which docker-compose runs on a fresh
+-- volume. IF NOT EXISTS keeps the migration a no-op there.
+CREATE TABLE IF NOT EXISTS users (
+    user_id SERIAL PRIMARY KEY,
+    username varchar(255) NOT NULL,
+    password varchar(255) NOT NULL,
+    is_active boolean NOT NULL,
+    role varchar(32) NOT NULL DEFAULT 'member',
+    created timestamptz NOT NULL DEFAULT now()
+);
+-- A volume made by an older init.sql has the table without the later
+-- columns and without the index. The index fails on duplicate usernames,
+-- those have to be merged by hand first.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role varchar(32) NOT NULL DEFAULT 'member';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS created timestamptz NOT NULL DEFAULT now();
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_key ON users (username);
@@ -0,0 +1,57 @@
+-- Posts, comments and votes for CONTENT_STORAGE=postgres. The author and
+-- the moderator who deleted a post are stored as they were at that moment,
+-- like in the posts collection of Mongo.
+CREATE TABLE posts (
+    seq BIGSERIAL UNIQUE,
+    id varchar(64) PRIMARY KEY,
+    type varchar(16) NOT NULL,
+    title varchar(255) NOT NULL,
+    url text NOT NULL DEFAULT '',
+    text text NOT NULL DEFAULT '',
+    category varchar(64) NOT NULL,
+    flair varchar(64) NOT NULL DEFAULT '',
+    author_id varchar(64) NOT NULL,
+    author_username varchar(255) NOT NULL,
+    score integer NOT NULL DEFAULT 0,
+    views integer NOT NULL DEFAULT 0,
+    upvote_percentage integer NOT NULL DEFAULT 0,
+    removed boolean NOT NULL DEFAULT false,
+    locked boolean NOT NULL DEFAULT false,
+    stickied boolean NOT NULL DEFAULT false,
+    archived boolean NOT NULL DEFAULT false,
+    created timestamptz NOT NULL,
+    deleted_at timestamptz,
+    deleted_by_id varchar(64),
+    deleted_by_username varchar(255)
+);
+
+CREATE INDEX posts_category_idx ON posts (category);
+CREATE INDEX posts_author_username_idx ON posts (author_username);
+CREATE INDEX posts_score_idx ON posts (score DESC);
+CREATE INDEX posts_created_idx ON posts (created DESC);
+CREATE INDEX posts_deleted_at_idx ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE comments (
+    seq BIGSERIAL UNIQUE,
+    id varchar(64) PRIMARY KEY,
+    post_id varchar(64) NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    author_id varchar(64) NOT NULL,
+    author_username varchar(255) NOT NULL,
+    body text NOT NULL,
+    removed boolean NOT NULL DEFAULT false,
+    created timestamptz NOT NULL,
+    deleted_at timestamptz,
+    deleted_by_id varchar(64),
+    deleted_by_username varchar(255)
+);
+
+CREATE INDEX comments_post_id_idx ON comments (post_id, seq);
+CREATE INDEX comments_deleted_at_idx ON comments (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE votes (
+    post_id varchar(64) NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+    user_id varchar(64) NOT NULL,
+    vote smallint NOT NULL CHECK (vote IN (-1, 1)),
+    seq BIGSERIAL,
+    PRIMARY KEY (post_id, user_id)
+);
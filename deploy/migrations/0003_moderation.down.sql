@@ -0,0 +1,3 @@
+DROP TABLE automod_rules;
+DROP TABLE mod_log;
+DROP TABLE reports;
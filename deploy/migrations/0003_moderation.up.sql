@@ -0,0 +1,49 @@
+-- Reports, the mod log and the automod rules for CONTENT_STORAGE=postgres,
+-- like the collections of the same names in Mongo. They reference posts and
+-- comments by ID only: the mod log and the resolved reports outlive a purge.
+CREATE TABLE reports (
+    seq BIGSERIAL UNIQUE,
+    id varchar(64) PRIMARY KEY,
+    target_type varchar(16) NOT NULL,
+    post_id varchar(64) NOT NULL,
+    comment_id varchar(64) NOT NULL DEFAULT '',
+    category varchar(64) NOT NULL,
+    reason text NOT NULL,
+    reporter_id varchar(64) NOT NULL,
+    reporter_username varchar(255) NOT NULL,
+    status varchar(16) NOT NULL,
+    created timestamptz NOT NULL,
+    resolved timestamptz,
+    resolved_by_id varchar(64),
+    resolved_by_username varchar(255)
+);
+
+CREATE INDEX reports_open_category_idx ON reports (category, created) WHERE status = 'open';
+CREATE INDEX reports_open_target_idx ON reports (post_id, comment_id) WHERE status = 'open';
+
+CREATE TABLE mod_log (
+    seq BIGSERIAL UNIQUE,
+    id varchar(64) PRIMARY KEY,
+    actor_id varchar(64) NOT NULL,
+    actor_username varchar(255) NOT NULL,
+    action varchar(32) NOT NULL,
+    target_type varchar(16) NOT NULL,
+    target_id varchar(64) NOT NULL,
+    category varchar(64) NOT NULL DEFAULT '',
+    reason text NOT NULL DEFAULT '',
+    created timestamptz NOT NULL,
+    before jsonb,
+    after jsonb
+);
+
+CREATE INDEX mod_log_created_idx ON mod_log (created DESC);
+CREATE INDEX mod_log_category_idx ON mod_log (category, created DESC);
+CREATE INDEX mod_log_target_id_idx ON mod_log (target_id);
+
+CREATE TABLE automod_rules (
+    category varchar(64) PRIMARY KEY,
+    source text NOT NULL,
+    updated timestamptz NOT NULL,
+    updated_by_id varchar(64),
+    updated_by_username varchar(255)
+);
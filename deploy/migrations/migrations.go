@@ -0,0 +1,13 @@
+// Package migrations holds the versioned Postgres migrations. The files are
+// embedded, so the migrate subcommand of the binary applies the same schema
+// that lives in the repository.
+//
+// A migration is a pair of files, NNNN_name.up.sql and NNNN_name.down.sql.
+// The files of the directory above are run by docker-entrypoint on a fresh
+// volume, these ones only by the migrate subcommand.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
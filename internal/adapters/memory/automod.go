@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+type automodStorage struct {
+	db *DB
+}
+
+func NewAutomodStorage(db *DB) *automodStorage {
+	return &automodStorage{db: db}
+}
+
+// Get returns nil without an error when the category has no rules yet.
+func (a automodStorage) Get(ctx context.Context, category string) (*domain.AutomodRules, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	a.db.automod.RLock()
+	defer a.db.automod.RUnlock()
+
+	rules, ok := a.db.automod.byCategory[category]
+
+	if !ok {
+		return nil, nil
+	}
+
+	return cloneAutomodRules(rules), nil
+}
+
+func (a automodStorage) Save(ctx context.Context, rules *domain.AutomodRules) (*domain.AutomodRules, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	rules.Updated = a.db.now()
+
+	a.db.automod.Lock()
+	defer a.db.automod.Unlock()
+
+	a.db.automod.byCategory[rules.Category] = cloneAutomodRules(rules)
+
+	return rules, nil
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestAutomod(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAutomodStorage(New())
+
+	// No rules yet
+	rules, err := repo.Get(ctx, "music")
+
+	if err != nil || rules != nil {
+		t.Errorf("expected nil, got: %+v, %v", rules, err)
+		return
+	}
+
+	// Save replaces the rules
+	for _, source := range []string{"rules: []", "rules: [{}]"} {
+		if _, err = repo.Save(ctx, &domain.AutomodRules{Category: "music", Source: source}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+	}
+
+	rules, err = repo.Get(ctx, "music")
+
+	if err != nil || rules.Source != "rules: [{}]" || rules.Updated.IsZero() {
+		t.Errorf("expected the last rules, got: %+v, %v", rules, err)
+		return
+	}
+}
@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type commentStorage struct {
+	db *DB
+}
+
+func NewCommentStorage(db *DB) *commentStorage {
+	return &commentStorage{db: db}
+}
+
+func (c commentStorage) Add(ctx context.Context, author *domain.Profile, body, postID string) (*domain.Comment, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	t := c.db.now()
+	dataForID := strings.Trim(body+author.Username+author.ID+t.String(), " ")
+	comment := &domain.Comment{ID: generator.GenerateNewID(dataForID), Author: cloneProfile(author), Body: body, Created: t}
+
+	c.db.posts.Lock()
+	defer c.db.posts.Unlock()
+
+	post := c.db.findPost(postID)
+
+	if post == nil {
+		return nil, domain.NotFound("post not found")
+	}
+
+	post.Comments = append(post.Comments, comment)
+
+	return cloneComment(comment), nil
+}
+
+func (c commentStorage) GetDeleted(ctx context.Context, postID, commentID string) (*domain.Comment, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	c.db.posts.RLock()
+	defer c.db.posts.RUnlock()
+
+	comment := c.db.findComment(postID, commentID)
+
+	if comment == nil {
+		return nil, domain.NotFound("comment not found")
+	}
+
+	if comment.DeletedAt == nil {
+		return nil, domain.NotFound("deleted comment not found")
+	}
+
+	return cloneComment(comment), nil
+}
+
+func (c commentStorage) Delete(ctx context.Context, postID, commentID string, deletedBy *domain.Profile) error {
+	return c.update(ctx, postID, commentID, func(comment *domain.Comment) bool {
+		if comment.DeletedAt != nil {
+			return false
+		}
+
+		now := c.db.now()
+		comment.DeletedAt = &now
+		comment.DeletedBy = cloneProfile(deletedBy)
+
+		return true
+	})
+}
+
+func (c commentStorage) Restore(ctx context.Context, postID, commentID string) error {
+	return c.update(ctx, postID, commentID, func(comment *domain.Comment) bool {
+		if comment.DeletedAt == nil {
+			return false
+		}
+
+		comment.DeletedAt = nil
+		comment.DeletedBy = nil
+
+		return true
+	})
+}
+
+func (c commentStorage) SetRemoved(ctx context.Context, postID, commentID string, removed bool) error {
+	return c.update(ctx, postID, commentID, func(comment *domain.Comment) bool {
+		comment.Removed = removed
+		return true
+	})
+}
+
+// update changes the comment, change reports whether the comment was in the
+// state the change applies to.
+func (c commentStorage) update(ctx context.Context, postID, commentID string, change func(comment *domain.Comment) bool) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	c.db.posts.Lock()
+	defer c.db.posts.Unlock()
+
+	comment := c.db.findComment(postID, commentID)
+
+	if comment == nil || !change(comment) {
+		return domain.NotFound("comment not found")
+	}
+
+	return nil
+}
+
+// Purge hard-deletes comments that were soft-deleted before the given moment
+// and reports how many posts were touched.
+func (c commentStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	if err := alive(ctx); err != nil {
+		return 0, err
+	}
+
+	c.db.posts.Lock()
+	defer c.db.posts.Unlock()
+
+	var touched int64
+
+	for _, post := range c.db.posts.list {
+		kept := make([]*domain.Comment, 0, len(post.Comments))
+
+		for _, comment := range post.Comments {
+			if comment.DeletedAt == nil || !comment.DeletedAt.Before(deletedBefore) {
+				kept = append(kept, comment)
+			}
+		}
+
+		if len(kept) != len(post.Comments) {
+			post.Comments = kept
+			touched++
+		}
+	}
+
+	return touched, nil
+}
+
+func (c commentStorage) Import(ctx context.Context, postID string, comment *domain.Comment) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	c.db.posts.Lock()
+	defer c.db.posts.Unlock()
+
+	post := c.db.findPost(postID)
+
+	if post == nil {
+		return domain.NotFound("post not found")
+	}
+
+	if c.db.findComment(postID, comment.ID) != nil {
+		return domain.AlreadyExists("comment %s already exists", comment.ID)
+	}
+
+	post.Comments = append(post.Comments, cloneComment(comment))
+
+	return nil
+}
+
+// findComment returns the stored comment, deleted or not. The caller holds
+// the posts lock.
+func (db *DB) findComment(postID, commentID string) *domain.Comment {
+	post := db.findPost(postID)
+
+	if post == nil {
+		return nil
+	}
+
+	for _, comment := range post.Comments {
+		if comment.ID == commentID {
+			return comment
+		}
+	}
+
+	return nil
+}
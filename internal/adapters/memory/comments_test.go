@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestComments(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	posts := NewPostStorage(db)
+	repo := NewCommentStorage(db)
+	author := &domain.Profile{Username: "akro", ID: "1"}
+	post, _ := posts.Save(ctx, newTestPost("title", "music", "akro"))
+
+	// Add
+	comment, err := repo.Add(ctx, author, "body", post.ID)
+
+	if err != nil || comment.ID == "" {
+		t.Errorf("expected comment with id, got: %+v, %v", comment, err)
+		return
+	}
+
+	if _, err = repo.Add(ctx, author, "body", "unknown"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// Removed comments are hidden
+	if err = repo.SetRemoved(ctx, post.ID, comment.ID, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	got, _ := posts.GetOne(ctx, post.ID)
+
+	if len(got.Comments) != 0 {
+		t.Errorf("expected removed comment to be hidden, got: %d", len(got.Comments))
+		return
+	}
+
+	if err = repo.SetRemoved(ctx, post.ID, comment.ID, false); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	// Delete
+	deletedAt := time.Now()
+	db.now = func() time.Time { return deletedAt }
+
+	if _, err = repo.GetDeleted(ctx, post.ID, comment.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found before delete, got: %v", err)
+		return
+	}
+
+	if err = repo.Delete(ctx, post.ID, comment.ID, author); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if err = repo.Delete(ctx, post.ID, comment.ID, author); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found on second delete, got: %v", err)
+		return
+	}
+
+	deleted, err := repo.GetDeleted(ctx, post.ID, comment.ID)
+
+	if err != nil || deleted.DeletedBy.Username != "akro" {
+		t.Errorf("expected deleted comment, got: %+v, %v", deleted, err)
+		return
+	}
+
+	// Restore
+	if err = repo.Restore(ctx, post.ID, comment.ID); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	got, _ = posts.GetOne(ctx, post.ID)
+
+	if len(got.Comments) != 1 {
+		t.Errorf("expected restored comment, got: %d", len(got.Comments))
+		return
+	}
+
+	// Purge
+	if err = repo.Delete(ctx, post.ID, comment.ID, author); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if touched, _ := repo.Purge(ctx, deletedAt.Add(time.Second)); touched != 1 {
+		t.Errorf("expected 1 touched post, got: %d", touched)
+		return
+	}
+
+	if _, err = repo.GetDeleted(ctx, post.ID, comment.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found after purge, got: %v", err)
+		return
+	}
+}
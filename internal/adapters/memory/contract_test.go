@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/internal/service/storagetest"
+)
+
+func TestPostStorageContract(t *testing.T) {
+	storagetest.TestPostStorage(t, func(t *testing.T, clock *storagetest.Clock) service.PostStorage {
+		return NewPostStorage(NewWithClock(clock.Now))
+	})
+}
+
+func TestCommentStorageContract(t *testing.T) {
+	storagetest.TestCommentStorage(t, func(t *testing.T, clock *storagetest.Clock) (service.PostStorage, service.CommentStorage) {
+		db := NewWithClock(clock.Now)
+		return NewPostStorage(db), NewCommentStorage(db)
+	})
+}
+
+func TestUserStorageContract(t *testing.T) {
+	storagetest.TestUserStorage(t, func(t *testing.T, clock *storagetest.Clock) service.UserStorage {
+		return NewUserStorage(NewWithClock(clock.Now))
+	})
+}
+
+func TestSessionStorageContract(t *testing.T) {
+	storagetest.TestSessionStorage(t, func(t *testing.T, clock *storagetest.Clock) service.SessionStorage {
+		return NewSessionStorage(NewWithClock(clock.Now), sessionTTL)
+	}, sessionTTL)
+}
@@ -0,0 +1,157 @@
+// Package memory keeps the data of every storage in the process memory, so
+// the server and the end-to-end tests run without Postgres, Mongo and Redis.
+// It follows the semantics of the database adapters: the same errors, sort
+// orders, soft deletes and session TTL. Nothing survives a restart.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// DB is the shared state of the memory storages, what the client is for
+// the database adapters. Posts and comments live in one table, like in the
+// posts collection of Mongo.
+type DB struct {
+	posts struct {
+		sync.RWMutex
+		// list keeps the insertion order, the sorts are stable over it.
+		list []*domain.Post
+	}
+
+	users struct {
+		sync.RWMutex
+		byName map[string]*user
+	}
+
+	sessions struct {
+		sync.Mutex
+		byKey  map[string]*session
+		secret []byte
+	}
+
+	reports struct {
+		sync.RWMutex
+		list []*domain.Report
+	}
+
+	modLog struct {
+		sync.RWMutex
+		list []*domain.ModLogEntry
+	}
+
+	automod struct {
+		sync.RWMutex
+		byCategory map[string]*domain.AutomodRules
+	}
+
+	now func() time.Time
+}
+
+func New() *DB {
+	return NewWithClock(time.Now)
+}
+
+// NewWithClock is New with another time source, the tests move it forward
+// instead of sleeping.
+func NewWithClock(now func() time.Time) *DB {
+	db := &DB{now: now}
+	db.users.byName = make(map[string]*user)
+	db.sessions.byKey = make(map[string]*session)
+	db.sessions.secret = newSecret()
+	db.automod.byCategory = make(map[string]*domain.AutomodRules)
+
+	return db
+}
+
+// alive fails the call when the request is already gone, like a database
+// driver does.
+func alive(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// The storages hand out copies, so neither the caller nor another goroutine
+// can change what is stored behind the lock.
+
+func cloneProfile(p *domain.Profile) *domain.Profile {
+	if p == nil {
+		return nil
+	}
+
+	c := *p
+
+	return &c
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	c := *t
+
+	return &c
+}
+
+func cloneComment(comment *domain.Comment) *domain.Comment {
+	c := *comment
+	c.Author = cloneProfile(comment.Author)
+	c.DeletedAt = cloneTime(comment.DeletedAt)
+	c.DeletedBy = cloneProfile(comment.DeletedBy)
+
+	return &c
+}
+
+func clonePost(post *domain.Post) *domain.Post {
+	c := *post
+	c.Author = cloneProfile(post.Author)
+	c.DeletedAt = cloneTime(post.DeletedAt)
+	c.DeletedBy = cloneProfile(post.DeletedBy)
+
+	if post.Votes != nil {
+		c.Votes = make([]*domain.Vote, len(post.Votes))
+
+		for i, vote := range post.Votes {
+			v := *vote
+			c.Votes[i] = &v
+		}
+	}
+
+	if post.Comments != nil {
+		c.Comments = make([]*domain.Comment, len(post.Comments))
+
+		for i, comment := range post.Comments {
+			c.Comments[i] = cloneComment(comment)
+		}
+	}
+
+	return &c
+}
+
+func cloneReport(report *domain.Report) *domain.Report {
+	c := *report
+	c.Reporter = cloneProfile(report.Reporter)
+	c.ResolvedBy = cloneProfile(report.ResolvedBy)
+	c.Resolved = cloneTime(report.Resolved)
+
+	return &c
+}
+
+func cloneModLogEntry(entry *domain.ModLogEntry) *domain.ModLogEntry {
+	c := *entry
+	c.Actor = cloneProfile(entry.Actor)
+	c.Before = append([]byte(nil), entry.Before...)
+	c.After = append([]byte(nil), entry.After...)
+
+	return &c
+}
+
+func cloneAutomodRules(rules *domain.AutomodRules) *domain.AutomodRules {
+	c := *rules
+	c.UpdatedBy = cloneProfile(rules.UpdatedBy)
+
+	return &c
+}
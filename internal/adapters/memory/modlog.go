@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type modLogStorage struct {
+	db *DB
+}
+
+func NewModLogStorage(db *DB) *modLogStorage {
+	return &modLogStorage{db: db}
+}
+
+func (m modLogStorage) Save(ctx context.Context, entry *domain.ModLogEntry) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	entry.Created = m.db.now()
+	dataForID := strings.Trim(entry.Action+entry.TargetID+entry.Actor.ID+entry.Created.String(), " ")
+	entry.ID = generator.GenerateNewID(dataForID)
+
+	m.db.modLog.Lock()
+	defer m.db.modLog.Unlock()
+
+	m.db.modLog.list = append(m.db.modLog.list, cloneModLogEntry(entry))
+
+	return nil
+}
+
+// Get returns the matching entries newest first. A zero limit means no
+// limit, like in Mongo.
+func (m modLogStorage) Get(ctx context.Context, filter *domain.ModLogFilter) ([]*domain.ModLogEntry, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	m.db.modLog.RLock()
+	defer m.db.modLog.RUnlock()
+
+	entries := []*domain.ModLogEntry{}
+
+	// The newest entries are at the end, walking back keeps the sort stable
+	// for entries saved at the same moment.
+	for i := len(m.db.modLog.list) - 1; i >= 0; i-- {
+		entry := m.db.modLog.list[i]
+
+		if matchModLog(entry, filter) {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Created.After(entries[j].Created) })
+
+	from := min(filter.Offset, len(entries))
+	to := len(entries)
+
+	if filter.Limit > 0 {
+		to = min(from+filter.Limit, to)
+	}
+
+	page := make([]*domain.ModLogEntry, 0, to-from)
+
+	for _, entry := range entries[from:to] {
+		page = append(page, cloneModLogEntry(entry))
+	}
+
+	return page, nil
+}
+
+func matchModLog(entry *domain.ModLogEntry, filter *domain.ModLogFilter) bool {
+	switch {
+	case filter.Category != "" && entry.Category != filter.Category:
+		return false
+	case filter.Action != "" && entry.Action != filter.Action:
+		return false
+	case filter.Actor != "" && (entry.Actor == nil || entry.Actor.Username != filter.Actor):
+		return false
+	case filter.TargetID != "" && entry.TargetID != filter.TargetID:
+		return false
+	}
+
+	return true
+}
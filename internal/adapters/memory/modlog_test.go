@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestModLog(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	repo := NewModLogStorage(db)
+	actor := &domain.Profile{Username: "mod", ID: "1"}
+	now := time.Now()
+
+	for i, action := range []string{domain.ModActionLockPost, domain.ModActionStickyPost, domain.ModActionLockPost} {
+		db.now = func() time.Time { return now.Add(time.Duration(i) * time.Minute) }
+
+		if err := repo.Save(ctx, &domain.ModLogEntry{Actor: actor, Action: action, TargetID: "post", Category: "music"}); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+	}
+
+	// Newest first, filtered and paged
+	entries, err := repo.Get(ctx, &domain.ModLogFilter{Action: domain.ModActionLockPost})
+
+	if err != nil || len(entries) != 2 || !entries[0].Created.After(entries[1].Created) {
+		t.Errorf("expected 2 entries, newest first, got: %d, %v", len(entries), err)
+		return
+	}
+
+	entries, _ = repo.Get(ctx, &domain.ModLogFilter{Actor: "mod", Offset: 1, Limit: 1})
+
+	if len(entries) != 1 || entries[0].Action != domain.ModActionStickyPost {
+		t.Errorf("expected the sticky entry, got: %+v", entries)
+		return
+	}
+
+	entries, _ = repo.Get(ctx, &domain.ModLogFilter{Category: "news"})
+
+	if entries == nil || len(entries) != 0 {
+		t.Errorf("expected empty entries, got: %+v", entries)
+		return
+	}
+}
@@ -0,0 +1,445 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type postStorage struct {
+	db *DB
+}
+
+func NewPostStorage(db *DB) *postStorage {
+	return &postStorage{db: db}
+}
+
+// postFields are the fields GetBy can filter on, by their names in Mongo.
+var postFields = map[string]func(post *domain.Post) string{
+	"id":       func(post *domain.Post) string { return post.ID },
+	"type":     func(post *domain.Post) string { return post.Type },
+	"category": func(post *domain.Post) string { return post.Category },
+	"author.username": func(post *domain.Post) string {
+		if post.Author == nil {
+			return ""
+		}
+
+		return post.Author.Username
+	},
+}
+
+// postSorts compare two posts by a sort field, descending.
+var postSorts = map[string]func(a, b *domain.Post) bool{
+	"score":   func(a, b *domain.Post) bool { return a.Score > b.Score },
+	"views":   func(a, b *domain.Post) bool { return a.Views > b.Views },
+	"created": func(a, b *domain.Post) bool { return a.Created.After(b.Created) },
+}
+
+func (p postStorage) Save(ctx context.Context, post *domain.Post) (*domain.Post, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	dataForID := strings.Trim(post.Title+post.Author.Username+post.Category, " ")
+	post.ID = generator.GenerateNewID(dataForID)
+	post.Created = p.db.now()
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	p.db.posts.list = append(p.db.posts.list, clonePost(post))
+
+	return post, nil
+}
+
+func (p postStorage) GetOne(ctx context.Context, id string) (*domain.Post, error) {
+	return p.getOne(ctx, id, false)
+}
+
+func (p postStorage) GetDeleted(ctx context.Context, id string) (*domain.Post, error) {
+	return p.getOne(ctx, id, true)
+}
+
+func (p postStorage) getOne(ctx context.Context, id string, deleted bool) (*domain.Post, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	p.db.posts.RLock()
+	defer p.db.posts.RUnlock()
+
+	post := p.db.findPost(id)
+
+	if post == nil || (post.DeletedAt != nil) != deleted {
+		return nil, domain.NotFound("post not found")
+	}
+
+	return visible(post), nil
+}
+
+func (p postStorage) Get(ctx context.Context) ([]*domain.Post, error) {
+	return p.list(ctx, func(*domain.Post) bool { return true }, "score")
+}
+
+func (p postStorage) GetBy(ctx context.Context, category, data, sortField string) ([]*domain.Post, error) {
+	field, ok := postFields[category]
+
+	if !ok {
+		return nil, fmt.Errorf("can't filter posts by %s", category)
+	}
+
+	return p.list(ctx, func(post *domain.Post) bool { return field(post) == data }, sortField)
+}
+
+func (p postStorage) GetStickied(ctx context.Context, category string) ([]*domain.Post, error) {
+	return p.list(ctx, func(post *domain.Post) bool { return post.Category == category && post.Stickied }, "created")
+}
+
+// list returns the posts that match and were neither removed nor deleted,
+// sorted by the field descending.
+func (p postStorage) list(ctx context.Context, match func(post *domain.Post) bool, sortField string) ([]*domain.Post, error) {
+	less, ok := postSorts[sortField]
+
+	if !ok {
+		return nil, fmt.Errorf("can't sort posts by %s", sortField)
+	}
+
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	p.db.posts.RLock()
+	defer p.db.posts.RUnlock()
+
+	posts := []*domain.Post{}
+
+	for _, post := range p.db.posts.list {
+		if !post.Removed && post.DeletedAt == nil && match(post) {
+			posts = append(posts, visible(post))
+		}
+	}
+
+	sort.SliceStable(posts, func(i, j int) bool { return less(posts[i], posts[j]) })
+
+	return posts, nil
+}
+
+func (p postStorage) UpdateMetrics(ctx context.Context, postID string, inc int8, authorID string) error {
+	if inc < -1 || inc > 1 {
+		return domain.Validation("unknown vote %d", inc)
+	}
+
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	post := p.db.findPost(postID)
+
+	if post == nil {
+		return domain.NotFound("post not found")
+	}
+
+	var current *domain.Vote
+
+	for _, vote := range post.Votes {
+		if vote.User == authorID {
+			current = vote
+			break
+		}
+	}
+
+	switch {
+	case inc == 0 && current == nil:
+		return domain.Conflict("post score was not changed")
+
+	case inc == 0:
+		votes := make([]*domain.Vote, 0, len(post.Votes)-1)
+
+		for _, vote := range post.Votes {
+			if vote != current {
+				votes = append(votes, vote)
+			}
+		}
+
+		post.Votes = votes
+
+	case current == nil:
+		post.Votes = append(post.Votes, &domain.Vote{User: authorID, Vote: int(inc)})
+
+	case current.Vote == int(inc):
+		return domain.Conflict("vote is already counted")
+
+	default:
+		current.Vote = int(inc)
+	}
+
+	updateScorePercent(post)
+
+	return nil
+}
+
+// updateScorePercent counts the score and the share of upvotes from the
+// votes, the same way the Mongo aggregation does.
+func updateScorePercent(post *domain.Post) {
+	score, positive := 0, 0
+
+	for _, vote := range post.Votes {
+		score += vote.Vote
+
+		if vote.Vote > 0 {
+			positive++
+		}
+	}
+
+	var percent = 0
+
+	if len(post.Votes) != 0 {
+		percent = int((float32(positive) / float32(len(post.Votes))) * 100.0)
+	}
+
+	post.Score = score
+	post.UpvotePercentage = uint(percent)
+}
+
+func (p postStorage) RecomputeScores(ctx context.Context, postID string) (int64, error) {
+	if err := alive(ctx); err != nil {
+		return 0, err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	posts := p.db.posts.list
+
+	if postID != "" {
+		post := p.db.findPost(postID)
+
+		if post == nil {
+			return 0, domain.NotFound("post not found")
+		}
+
+		posts = []*domain.Post{post}
+	}
+
+	var changed int64
+
+	for _, post := range posts {
+		score, percent := post.Score, post.UpvotePercentage
+		updateScorePercent(post)
+
+		if post.Score != score || post.UpvotePercentage != percent {
+			changed++
+		}
+	}
+
+	return changed, nil
+}
+
+// Walk copies the posts first, fn runs without the lock.
+func (p postStorage) Walk(ctx context.Context, fn func(post *domain.Post) error) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	p.db.posts.RLock()
+	posts := make([]*domain.Post, 0, len(p.db.posts.list))
+
+	for _, post := range p.db.posts.list {
+		posts = append(posts, clonePost(post))
+	}
+	p.db.posts.RUnlock()
+
+	for _, post := range posts {
+		if err := alive(ctx); err != nil {
+			return err
+		}
+
+		if err := fn(post); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p postStorage) Import(ctx context.Context, post *domain.Post) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	if p.db.findPost(post.ID) != nil {
+		return domain.AlreadyExists("post %s already exists", post.ID)
+	}
+
+	p.db.posts.list = append(p.db.posts.list, clonePost(post))
+
+	return nil
+}
+
+func (p postStorage) IncrViews(ctx context.Context, postID string) error {
+	return p.update(ctx, postID, func(post *domain.Post) { post.Views++ })
+}
+
+func (p postStorage) SetRemoved(ctx context.Context, postID string, removed bool) error {
+	return p.update(ctx, postID, func(post *domain.Post) { post.Removed = removed })
+}
+
+func (p postStorage) SetLocked(ctx context.Context, postID string, locked bool) error {
+	return p.update(ctx, postID, func(post *domain.Post) { post.Locked = locked })
+}
+
+func (p postStorage) SetStickied(ctx context.Context, postID string, stickied bool) error {
+	return p.update(ctx, postID, func(post *domain.Post) { post.Stickied = stickied })
+}
+
+func (p postStorage) update(ctx context.Context, postID string, change func(post *domain.Post)) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	post := p.db.findPost(postID)
+
+	if post == nil {
+		return domain.NotFound("post not found")
+	}
+
+	change(post)
+
+	return nil
+}
+
+func (p postStorage) Delete(ctx context.Context, postID string, deletedBy *domain.Profile) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	post := p.db.findPost(postID)
+
+	if post == nil || post.DeletedAt != nil {
+		return domain.NotFound("post not found")
+	}
+
+	now := p.db.now()
+	post.DeletedAt = &now
+	post.DeletedBy = cloneProfile(deletedBy)
+
+	return nil
+}
+
+func (p postStorage) Restore(ctx context.Context, postID string) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	post := p.db.findPost(postID)
+
+	if post == nil || post.DeletedAt == nil {
+		return domain.NotFound("post not found")
+	}
+
+	post.DeletedAt = nil
+	post.DeletedBy = nil
+
+	return nil
+}
+
+// Purge hard-deletes posts that were soft-deleted before the given moment.
+func (p postStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	if err := alive(ctx); err != nil {
+		return 0, err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	kept := p.db.posts.list[:0]
+	var purged int64
+
+	for _, post := range p.db.posts.list {
+		if post.DeletedAt != nil && post.DeletedAt.Before(deletedBefore) {
+			purged++
+			continue
+		}
+
+		kept = append(kept, post)
+	}
+
+	clear(p.db.posts.list[len(kept):])
+	p.db.posts.list = kept
+
+	return purged, nil
+}
+
+// Archive marks every post created before the given moment as read-only.
+func (p postStorage) Archive(ctx context.Context, createdBefore time.Time) (int64, error) {
+	if err := alive(ctx); err != nil {
+		return 0, err
+	}
+
+	p.db.posts.Lock()
+	defer p.db.posts.Unlock()
+
+	var archived int64
+
+	for _, post := range p.db.posts.list {
+		if post.Created.Before(createdBefore) && !post.Archived {
+			post.Archived = true
+			archived++
+		}
+	}
+
+	return archived, nil
+}
+
+// findPost returns the stored post, deleted or not. The caller holds the
+// lock.
+func (db *DB) findPost(id string) *domain.Post {
+	for _, post := range db.posts.list {
+		if post.ID == id {
+			return post
+		}
+	}
+
+	return nil
+}
+
+// visible copies the post without the removed and deleted comments.
+func visible(post *domain.Post) *domain.Post {
+	c := clonePost(post)
+
+	if c.Comments == nil {
+		return c
+	}
+
+	comments := make([]*domain.Comment, 0, len(c.Comments))
+
+	for _, comment := range c.Comments {
+		if !comment.Removed && comment.DeletedAt == nil {
+			comments = append(comments, comment)
+		}
+	}
+
+	c.Comments = comments
+
+	return c
+}
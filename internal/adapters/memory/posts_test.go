@@ -0,0 +1,337 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func newTestPost(title, category, username string) *domain.Post {
+	return &domain.Post{
+		Score:            1,
+		Views:            1,
+		Type:             domain.PostTypeText,
+		Title:            title,
+		Author:           &domain.Profile{Username: username, ID: username},
+		Category:         category,
+		Text:             "text",
+		Votes:            []*domain.Vote{{User: username, Vote: 1}},
+		Comments:         []*domain.Comment{},
+		UpvotePercentage: 100,
+	}
+}
+
+func TestPostSave(t *testing.T) {
+	ctx := context.Background()
+	repo := NewPostStorage(New())
+	post := newTestPost("first", "music", "akro")
+
+	// OK
+	saved, err := repo.Save(ctx, post)
+
+	if err != nil || saved.ID == "" || saved.Created.IsZero() {
+		t.Errorf("expected saved post with id and created, got: %+v, %v", saved, err)
+		return
+	}
+
+	// The stored post is a copy
+	saved.Title = "changed"
+	got, err := repo.GetOne(ctx, saved.ID)
+
+	if err != nil || got.Title != "first" {
+		t.Errorf("expected stored title first, got: %+v, %v", got, err)
+		return
+	}
+
+	got.Votes[0].Vote = -1
+	got, _ = repo.GetOne(ctx, saved.ID)
+
+	if got.Votes[0].Vote != 1 {
+		t.Errorf("expected stored vote to stay 1, got: %d", got.Votes[0].Vote)
+		return
+	}
+
+	// Canceled context
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err = repo.Save(canceled, newTestPost("second", "music", "akro")); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+		return
+	}
+}
+
+func TestPostGetSorted(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	repo := NewPostStorage(db)
+	now := time.Now()
+
+	for i, title := range []string{"low", "high", "removed", "deleted", "news"} {
+		db.now = func() time.Time { return now.Add(time.Duration(i) * time.Minute) }
+		category := "music"
+
+		if title == "news" {
+			category = "news"
+		}
+
+		post, err := repo.Save(ctx, newTestPost(title, category, "akro"))
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+
+		switch title {
+		case "high":
+			err = repo.UpdateMetrics(ctx, post.ID, 1, "other")
+		case "removed":
+			err = repo.SetRemoved(ctx, post.ID, true)
+		case "deleted":
+			err = repo.Delete(ctx, post.ID, post.Author)
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+	}
+
+	// Get sorts by score, equal scores keep the insertion order
+	posts, err := repo.Get(ctx)
+
+	if err != nil || titles(posts) != "high,low,news" {
+		t.Errorf("expected high,low,news, got: %s, %v", titles(posts), err)
+		return
+	}
+
+	// GetBy sorts by the given field
+	posts, err = repo.GetBy(ctx, "author.username", "akro", "created")
+
+	if err != nil || titles(posts) != "news,high,low" {
+		t.Errorf("expected news,high,low, got: %s, %v", titles(posts), err)
+		return
+	}
+
+	posts, err = repo.GetBy(ctx, "category", "music", "score")
+
+	if err != nil || titles(posts) != "high,low" {
+		t.Errorf("expected high,low, got: %s, %v", titles(posts), err)
+		return
+	}
+
+	// Unknown field
+	if _, err = repo.GetBy(ctx, "password", "akro", "score"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+}
+
+func titles(posts []*domain.Post) string {
+	s := ""
+
+	for i, post := range posts {
+		if i > 0 {
+			s += ","
+		}
+
+		s += post.Title
+	}
+
+	return s
+}
+
+func TestPostUpdateMetrics(t *testing.T) {
+	ctx := context.Background()
+	repo := NewPostStorage(New())
+	post, _ := repo.Save(ctx, newTestPost("title", "music", "akro"))
+
+	// Downvote by another user
+	if err := repo.UpdateMetrics(ctx, post.ID, -1, "other"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	got, _ := repo.GetOne(ctx, post.ID)
+
+	if got.Score != 0 || got.UpvotePercentage != 50 {
+		t.Errorf("expected score 0 and 50%%, got: %d and %d%%", got.Score, got.UpvotePercentage)
+		return
+	}
+
+	// Same vote again
+	if err := repo.UpdateMetrics(ctx, post.ID, -1, "other"); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("expected conflict, got: %v", err)
+		return
+	}
+
+	// Unvote
+	if err := repo.UpdateMetrics(ctx, post.ID, 0, "other"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	got, _ = repo.GetOne(ctx, post.ID)
+
+	if got.Score != 1 || got.UpvotePercentage != 100 || len(got.Votes) != 1 {
+		t.Errorf("expected score 1, 100%% and one vote, got: %+v", got)
+		return
+	}
+
+	// Unknown vote
+	if err := repo.UpdateMetrics(ctx, post.ID, 2, "other"); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected validation error, got: %v", err)
+		return
+	}
+
+	// Unknown post
+	if err := repo.UpdateMetrics(ctx, "unknown", 1, "other"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+}
+
+func TestPostConcurrentVotes(t *testing.T) {
+	ctx := context.Background()
+	repo := NewPostStorage(New())
+	post, _ := repo.Save(ctx, newTestPost("title", "music", "akro"))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			user := string(rune('a'+i%26)) + string(rune('a'+i/26))
+
+			if err := repo.UpdateMetrics(ctx, post.ID, 1, user); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if err := repo.IncrViews(ctx, post.ID); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if _, err := repo.Get(ctx); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	got, _ := repo.GetOne(ctx, post.ID)
+
+	if got.Score != 51 || got.Views != 51 {
+		t.Errorf("expected score and views 51, got: %d and %d", got.Score, got.Views)
+		return
+	}
+}
+
+func TestPostDeleteRestorePurge(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	repo := NewPostStorage(db)
+	post, _ := repo.Save(ctx, newTestPost("title", "music", "akro"))
+	deletedAt := time.Now()
+	db.now = func() time.Time { return deletedAt }
+
+	// Delete
+	if err := repo.Delete(ctx, post.ID, post.Author); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if _, err := repo.GetOne(ctx, post.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	if err := repo.Delete(ctx, post.ID, post.Author); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found on second delete, got: %v", err)
+		return
+	}
+
+	deleted, err := repo.GetDeleted(ctx, post.ID)
+
+	if err != nil || deleted.DeletedBy.Username != "akro" {
+		t.Errorf("expected deleted post, got: %+v, %v", deleted, err)
+		return
+	}
+
+	// Restore
+	if err = repo.Restore(ctx, post.ID); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if err = repo.Restore(ctx, post.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found on second restore, got: %v", err)
+		return
+	}
+
+	// Purge only takes posts deleted before the moment
+	if err = repo.Delete(ctx, post.ID, post.Author); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if purged, _ := repo.Purge(ctx, deletedAt); purged != 0 {
+		t.Errorf("expected 0 purged, got: %d", purged)
+		return
+	}
+
+	if purged, _ := repo.Purge(ctx, deletedAt.Add(time.Second)); purged != 1 {
+		t.Errorf("expected 1 purged, got: %d", purged)
+		return
+	}
+
+	if _, err = repo.GetDeleted(ctx, post.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found after purge, got: %v", err)
+		return
+	}
+}
+
+func TestPostStickiedArchive(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	repo := NewPostStorage(db)
+	created := time.Now().AddDate(-1, 0, 0)
+	db.now = func() time.Time { return created }
+	post, _ := repo.Save(ctx, newTestPost("title", "music", "akro"))
+
+	// Sticky
+	if err := repo.SetStickied(ctx, post.ID, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	posts, err := repo.GetStickied(ctx, "music")
+
+	if err != nil || len(posts) != 1 {
+		t.Errorf("expected one stickied post, got: %d, %v", len(posts), err)
+		return
+	}
+
+	if err = repo.SetLocked(ctx, "unknown", true); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// Archive once
+	if archived, _ := repo.Archive(ctx, time.Now()); archived != 1 {
+		t.Errorf("expected 1 archived, got: %d", archived)
+		return
+	}
+
+	if archived, _ := repo.Archive(ctx, time.Now()); archived != 0 {
+		t.Errorf("expected 0 archived, got: %d", archived)
+		return
+	}
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type reportStorage struct {
+	db *DB
+}
+
+func NewReportStorage(db *DB) *reportStorage {
+	return &reportStorage{db: db}
+}
+
+func (r reportStorage) Save(ctx context.Context, report *domain.Report) (*domain.Report, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	report.Created = r.db.now()
+	dataForID := strings.Trim(report.PostID+report.CommentID+report.Reporter.ID+report.Created.String(), " ")
+	report.ID = generator.GenerateNewID(dataForID)
+	report.Status = domain.ReportStatusOpen
+
+	r.db.reports.Lock()
+	defer r.db.reports.Unlock()
+
+	r.db.reports.list = append(r.db.reports.list, cloneReport(report))
+
+	return report, nil
+}
+
+func (r reportStorage) GetOne(ctx context.Context, id string) (*domain.Report, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	r.db.reports.RLock()
+	defer r.db.reports.RUnlock()
+
+	for _, report := range r.db.reports.list {
+		if report.ID == id {
+			return cloneReport(report), nil
+		}
+	}
+
+	return nil, domain.NotFound("report not found")
+}
+
+// GetOpen returns the open reports of the category, oldest first.
+func (r reportStorage) GetOpen(ctx context.Context, category string) ([]*domain.Report, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	r.db.reports.RLock()
+	defer r.db.reports.RUnlock()
+
+	reports := []*domain.Report{}
+
+	for _, report := range r.db.reports.list {
+		if report.Category == category && report.Status == domain.ReportStatusOpen {
+			reports = append(reports, cloneReport(report))
+		}
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].Created.Before(reports[j].Created) })
+
+	return reports, nil
+}
+
+// Resolve closes every open report filed against the same post or comment,
+// so a moderator deals with a piece of content once regardless of how many
+// members reported it.
+func (r reportStorage) Resolve(ctx context.Context, postID, commentID, status string, moderator *domain.Profile) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	r.db.reports.Lock()
+	defer r.db.reports.Unlock()
+
+	now := r.db.now()
+	resolved := 0
+
+	for _, report := range r.db.reports.list {
+		if report.PostID != postID || report.CommentID != commentID || report.Status != domain.ReportStatusOpen {
+			continue
+		}
+
+		resolvedAt := now
+		report.Status = status
+		report.ResolvedBy = cloneProfile(moderator)
+		report.Resolved = &resolvedAt
+		resolved++
+	}
+
+	if resolved == 0 {
+		return domain.NotFound("no open reports for the content")
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestReports(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	repo := NewReportStorage(db)
+	reporter := &domain.Profile{Username: "akro", ID: "1"}
+	now := time.Now()
+
+	var first *domain.Report
+
+	for i, postID := range []string{"1", "1", "2"} {
+		db.now = func() time.Time { return now.Add(time.Duration(-i) * time.Minute) }
+		report, err := repo.Save(ctx, &domain.Report{TargetType: domain.ReportTargetPost, PostID: postID, Category: "music", Reporter: reporter})
+
+		if err != nil || report.Status != domain.ReportStatusOpen {
+			t.Errorf("expected open report, got: %+v, %v", report, err)
+			return
+		}
+
+		if i == 0 {
+			first = report
+		}
+	}
+
+	// GetOpen is oldest first
+	reports, err := repo.GetOpen(ctx, "music")
+
+	if err != nil || len(reports) != 3 || reports[0].PostID != "2" {
+		t.Errorf("expected 3 reports, oldest first, got: %d, %v", len(reports), err)
+		return
+	}
+
+	// Resolve closes every report on the content
+	if err = repo.Resolve(ctx, "1", "", domain.ReportStatusApproved, reporter); err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	if reports, _ = repo.GetOpen(ctx, "music"); len(reports) != 1 {
+		t.Errorf("expected 1 open report, got: %d", len(reports))
+		return
+	}
+
+	if err = repo.Resolve(ctx, "1", "", domain.ReportStatusApproved, reporter); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	report, err := repo.GetOne(ctx, first.ID)
+
+	if err != nil || report.Status != domain.ReportStatusApproved || report.Resolved == nil {
+		t.Errorf("expected approved report, got: %+v, %v", report, err)
+		return
+	}
+}
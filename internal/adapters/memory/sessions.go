@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/dgrijalva/jwt-go"
+)
+
+type session struct {
+	user    domain.User
+	expires time.Time
+}
+
+type sessionStorage struct {
+	db *DB
+	// ttl is how long a session lives, the same as in Redis.
+	ttl time.Duration
+}
+
+func NewSessionStorage(db *DB, ttl time.Duration) *sessionStorage {
+	return &sessionStorage{db: db, ttl: ttl}
+}
+
+// Create issues the same kind of token as the Redis storage, the frontend
+// reads the user from it. It is signed with a key of this DB, so tokens
+// don't outlive the process.
+func (s sessionStorage) Create(ctx context.Context, ID, username, role string) (*domain.Session, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	userWithoutPassword := &domain.User{
+		Username: username,
+		ID:       ID,
+		Role:     role,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user": userWithoutPassword,
+	})
+
+	s.db.sessions.Lock()
+	defer s.db.sessions.Unlock()
+
+	tokenString, err := token.SignedString(s.db.sessions.secret)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.db.now()
+	s.expire(now)
+	s.db.sessions.byKey[tokenString] = &session{user: *userWithoutPassword, expires: now.Add(s.ttl)}
+
+	return &domain.Session{ID: tokenString, User: userWithoutPassword}, nil
+}
+
+func (s sessionStorage) Get(ctx context.Context, key string) (*domain.User, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	s.db.sessions.Lock()
+	defer s.db.sessions.Unlock()
+
+	sess, ok := s.db.sessions.byKey[key]
+
+	if !ok || !s.db.now().Before(sess.expires) {
+		delete(s.db.sessions.byKey, key)
+		return nil, domain.NotFound("session not found")
+	}
+
+	user := sess.user
+
+	return &user, nil
+}
+
+// List returns the live sessions of the user, the ones that expire first
+// come first.
+func (s sessionStorage) List(ctx context.Context, username string) ([]*domain.SessionInfo, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	s.db.sessions.Lock()
+	defer s.db.sessions.Unlock()
+
+	s.expire(s.db.now())
+	sessions := []*domain.SessionInfo{}
+
+	for key, sess := range s.db.sessions.byKey {
+		if sess.user.Username == username {
+			user := sess.user
+			sessions = append(sessions, &domain.SessionInfo{ID: key, User: &user, Expires: sess.expires})
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Expires.Before(sessions[j].Expires) })
+
+	return sessions, nil
+}
+
+func (s sessionStorage) Delete(ctx context.Context, key string) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	s.db.sessions.Lock()
+	defer s.db.sessions.Unlock()
+
+	sess, ok := s.db.sessions.byKey[key]
+	delete(s.db.sessions.byKey, key)
+
+	if !ok || !s.db.now().Before(sess.expires) {
+		return domain.NotFound("session not found")
+	}
+
+	return nil
+}
+
+// expire drops the sessions that are over, Redis does it on its own. The
+// caller holds the lock.
+func (s sessionStorage) expire(now time.Time) {
+	for key, sess := range s.db.sessions.byKey {
+		if !now.Before(sess.expires) {
+			delete(s.db.sessions.byKey, key)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+const sessionTTL = time.Hour * 9
+
+func TestSessions(t *testing.T) {
+	ctx := context.Background()
+	db := New()
+	repo := NewSessionStorage(db, sessionTTL)
+	now := time.Now()
+	db.now = func() time.Time { return now }
+
+	// Create
+	sess, err := repo.Create(ctx, "1", "akro", domain.RoleMember)
+
+	if err != nil || sess.ID == "" {
+		t.Errorf("expected session, got: %+v, %v", sess, err)
+		return
+	}
+
+	// Get
+	user, err := repo.Get(ctx, sess.ID)
+
+	if err != nil || user.Username != "akro" || user.Role != domain.RoleMember {
+		t.Errorf("expected user, got: %+v, %v", user, err)
+		return
+	}
+
+	if _, err = repo.Get(ctx, "unknown"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// Expired
+	now = now.Add(sessionTTL)
+
+	if _, err = repo.Get(ctx, sess.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found after the ttl, got: %v", err)
+		return
+	}
+
+	// Another DB doesn't accept the token
+	if _, err = NewSessionStorage(New(), sessionTTL).Get(ctx, sess.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found in another db, got: %v", err)
+		return
+	}
+}
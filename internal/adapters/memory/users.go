@@ -0,0 +1,242 @@
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type user struct {
+	id       string
+	role     string
+	salt     []byte
+	password []byte
+	isActive bool
+	created  time.Time
+}
+
+type userStorage struct {
+	db *DB
+}
+
+func NewUserStorage(db *DB) *userStorage {
+	return &userStorage{db: db}
+}
+
+func (s userStorage) Get(ctx context.Context, username, password string) (*domain.User, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	s.db.users.RLock()
+	u, ok := s.db.users.byName[username]
+	s.db.users.RUnlock()
+
+	if !ok || !u.isActive || subtle.ConstantTimeCompare(u.password, hashPassword(u.salt, password)) != 1 {
+		return nil, domain.NotFound("user not found")
+	}
+
+	return &domain.User{Username: username, Password: password, ID: u.id, Role: u.role}, nil
+}
+
+func (s userStorage) Save(ctx context.Context, username, password string) (*domain.User, error) {
+	if err := alive(ctx); err != nil {
+		return nil, err
+	}
+
+	u := &user{
+		id:       generator.GenerateNewIDByMD(username),
+		role:     domain.RoleMember,
+		salt:     newSecret(),
+		isActive: true,
+		created:  s.db.now(),
+	}
+	u.password = hashPassword(u.salt, password)
+
+	s.db.users.Lock()
+	defer s.db.users.Unlock()
+
+	if _, ok := s.db.users.byName[username]; ok {
+		return nil, domain.AlreadyExists("user %s already exists", username)
+	}
+
+	s.db.users.byName[username] = u
+
+	return &domain.User{Username: username, Password: password, ID: u.id, Role: u.role}, nil
+}
+
+// GetCreated returns when the user registered. Automod uses it for account
+// age checks.
+func (s userStorage) GetCreated(ctx context.Context, username string) (time.Time, error) {
+	if err := alive(ctx); err != nil {
+		return time.Time{}, err
+	}
+
+	s.db.users.RLock()
+	defer s.db.users.RUnlock()
+
+	u, ok := s.db.users.byName[username]
+
+	if !ok {
+		return time.Time{}, domain.NotFound("user not found")
+	}
+
+	return u.created, nil
+}
+
+func (s userStorage) SetPassword(ctx context.Context, username, password string) error {
+	return s.update(ctx, username, func(u *user) {
+		u.salt = newSecret()
+		u.password = hashPassword(u.salt, password)
+	})
+}
+
+func (s userStorage) SetActive(ctx context.Context, username string, active bool) error {
+	return s.update(ctx, username, func(u *user) { u.isActive = active })
+}
+
+// passwordScheme names the hashes of hashPassword in the user records, the
+// hash is the hex salt and the hex sum separated by a colon.
+const passwordScheme = "sha256-salted"
+
+func (s userStorage) PasswordScheme() string {
+	return passwordScheme
+}
+
+func (s userStorage) Walk(ctx context.Context, fn func(user *domain.UserRecord) error) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	s.db.users.RLock()
+	records := make([]*domain.UserRecord, 0, len(s.db.users.byName))
+
+	for username, u := range s.db.users.byName {
+		records = append(records, &domain.UserRecord{
+			Username: username,
+			ID:       u.id,
+			Role:     u.role,
+			Active:   u.isActive,
+			Created:  u.created,
+			Password: &domain.PasswordHash{
+				Scheme: passwordScheme,
+				Hash:   hex.EncodeToString(u.salt) + ":" + hex.EncodeToString(u.password),
+			},
+		})
+	}
+	s.db.users.RUnlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		if !records[i].Created.Equal(records[j].Created) {
+			return records[i].Created.Before(records[j].Created)
+		}
+
+		return records[i].Username < records[j].Username
+	})
+
+	for _, record := range records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s userStorage) Import(ctx context.Context, record *domain.UserRecord) (string, bool, error) {
+	if err := alive(ctx); err != nil {
+		return "", false, err
+	}
+
+	u := &user{
+		id:       generator.GenerateNewIDByMD(record.Username),
+		role:     record.Role,
+		isActive: record.Active,
+		created:  record.Created,
+	}
+
+	if salt, password, ok := parsePasswordHash(record.Password); ok {
+		u.salt, u.password = salt, password
+	} else {
+		// Nobody knows a password for a random hash, until it is reset.
+		u.salt, u.password = newSecret(), newSecret()
+	}
+
+	s.db.users.Lock()
+	defer s.db.users.Unlock()
+
+	if existing, ok := s.db.users.byName[record.Username]; ok {
+		return existing.id, false, nil
+	}
+
+	s.db.users.byName[record.Username] = u
+
+	return u.id, true, nil
+}
+
+func parsePasswordHash(password *domain.PasswordHash) (salt, sum []byte, ok bool) {
+	if password == nil || password.Scheme != passwordScheme {
+		return nil, nil, false
+	}
+
+	saltHex, sumHex, found := strings.Cut(password.Hash, ":")
+
+	if !found {
+		return nil, nil, false
+	}
+
+	salt, errSalt := hex.DecodeString(saltHex)
+	sum, errSum := hex.DecodeString(sumHex)
+
+	if errSalt != nil || errSum != nil || len(sum) != sha256.Size {
+		return nil, nil, false
+	}
+
+	return salt, sum, true
+}
+
+func (s userStorage) update(ctx context.Context, username string, change func(u *user)) error {
+	if err := alive(ctx); err != nil {
+		return err
+	}
+
+	s.db.users.Lock()
+	defer s.db.users.Unlock()
+
+	u, ok := s.db.users.byName[username]
+
+	if !ok {
+		return domain.NotFound("user not found")
+	}
+
+	change(u)
+
+	return nil
+}
+
+// hashPassword keeps the passwords out of the memory as they were typed, a
+// dump of a dev server still shouldn't leak them.
+func hashPassword(salt []byte, password string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(password))
+
+	return h.Sum(nil)
+}
+
+func newSecret() []byte {
+	secret := make([]byte, 32)
+
+	// crypto/rand doesn't fail on the supported platforms.
+	_, _ = rand.Read(secret)
+
+	return secret
+}
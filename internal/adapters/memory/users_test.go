@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+func TestUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserStorage(New())
+
+	// Save
+	user, err := repo.Save(ctx, "akro", "akroakroakro")
+
+	if err != nil || user.ID != generator.GenerateNewIDByMD("akro") || user.Role != domain.RoleMember {
+		t.Errorf("expected member with id, got: %+v, %v", user, err)
+		return
+	}
+
+	if _, err = repo.Save(ctx, "akro", "other"); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("expected already exists, got: %v", err)
+		return
+	}
+
+	// Get
+	got, err := repo.Get(ctx, "akro", "akroakroakro")
+
+	if err != nil || got.ID != user.ID {
+		t.Errorf("expected user, got: %+v, %v", got, err)
+		return
+	}
+
+	if _, err = repo.Get(ctx, "akro", "wrong"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found for a bad password, got: %v", err)
+		return
+	}
+
+	// GetCreated
+	if created, err := repo.GetCreated(ctx, "akro"); err != nil || created.IsZero() {
+		t.Errorf("expected created, got: %v, %v", created, err)
+		return
+	}
+
+	if _, err = repo.GetCreated(ctx, "unknown"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+}
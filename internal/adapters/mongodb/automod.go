@@ -0,0 +1,58 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type automodStorage struct {
+	DB      *mongo.Collection
+	timeout time.Duration
+}
+
+func NewAutomodStorage(db *mongo.Database, timeout time.Duration) *automodStorage {
+	collection := db.Collection("automod")
+	return &automodStorage{DB: collection, timeout: timeout}
+}
+
+// Get returns nil without an error when the category has no rules yet.
+func (a automodStorage) Get(ctx context.Context, category string) (*domain.AutomodRules, error) {
+	ctx, cancel := deadline.Bound(ctx, a.timeout)
+	defer cancel()
+
+	rules := &domain.AutomodRules{}
+	err := a.DB.FindOne(ctx, bson.M{"category": category}).Decode(rules)
+
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read data in *AutomodRules{}: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (a automodStorage) Save(ctx context.Context, rules *domain.AutomodRules) (*domain.AutomodRules, error) {
+	ctx, cancel := deadline.Bound(ctx, a.timeout)
+	defer cancel()
+
+	rules.Updated = time.Now()
+
+	_, err := a.DB.ReplaceOne(ctx, bson.M{"category": rules.Category}, rules, options.Replace().SetUpsert(true))
+
+	if err != nil {
+		return nil, fmt.Errorf("can't save automod rules into db: %w", err)
+	}
+
+	return rules, nil
+}
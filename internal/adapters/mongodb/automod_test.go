@@ -0,0 +1,104 @@
+package mongodb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestAutomodGet(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Get", func(mt *mtest.T) {
+		repo := NewAutomodStorage(mt.DB, time.Second)
+		expectRules := &domain.AutomodRules{Category: "music", Source: "rules: []"}
+
+		// OK
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, "post.automod", mtest.FirstBatch, bson.D{
+			{Key: "category", Value: "music"},
+			{Key: "source", Value: "rules: []"},
+		}))
+
+		rules, err := repo.Get(ctx, "music")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if !reflect.DeepEqual(expectRules, rules) {
+			t.Errorf("expected: %v, got: %v", expectRules, rules)
+			return
+		}
+
+		// No rules
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "post.automod", mtest.FirstBatch))
+
+		rules, err = repo.Get(ctx, "music")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if rules != nil {
+			t.Errorf("expected nil, got %v", rules)
+			return
+		}
+
+		// Find error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.Get(ctx, "music")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestAutomodSave(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Save", func(mt *mtest.T) {
+		repo := NewAutomodStorage(mt.DB, time.Second)
+		rules := &domain.AutomodRules{
+			Category:  "music",
+			Source:    "rules: []",
+			UpdatedBy: &domain.Profile{Username: "mod", ID: "1"},
+		}
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		saved, err := repo.Save(ctx, rules)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if saved.Updated.IsZero() {
+			t.Errorf("expected updated to be set, got %v", saved)
+			return
+		}
+
+		// Replace error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.Save(ctx, rules)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
@@ -2,29 +2,33 @@ package mongodb
 
 import (
 	"context"
-	"errors"
 	"strings"
 
 	"time"
 
 	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
 	"github.com/akrovv/redditclone/pkg/generator"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type commentStorage struct {
-	ctx context.Context
-	DB  *mongo.Collection
+	DB      *mongo.Collection
+	timeout time.Duration
+	now     func() time.Time
 }
 
-func NewCommentStorage(ctx context.Context, db *mongo.Client) *commentStorage {
-	collection := db.Database("post").Collection("posts")
-	return &commentStorage{ctx: ctx, DB: collection}
+func NewCommentStorage(db *mongo.Database, timeout time.Duration) *commentStorage {
+	collection := db.Collection("posts")
+	return &commentStorage{DB: collection, timeout: timeout, now: now}
 }
 
-func (c commentStorage) Add(author *domain.Profile, body, postID string) error {
-	t := time.Now()
+func (c commentStorage) Add(ctx context.Context, author *domain.Profile, body, postID string) (*domain.Comment, error) {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	t := c.now()
 	dataForID := strings.Trim(body+author.Username+author.ID+t.String(), " ")
 	id := generator.GenerateNewID(dataForID)
 
@@ -36,29 +40,137 @@ func (c commentStorage) Add(author *domain.Profile, body, postID string) error {
 	}
 
 	postFilter := bson.M{"id": postID}
-	result, err := c.DB.UpdateOne(c.ctx, postFilter, bson.M{"$push": bson.M{"comments": newComment}})
+	result, err := c.DB.UpdateOne(ctx, postFilter, bson.M{"$push": bson.M{"comments": newComment}})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result.ModifiedCount == 0 {
+		return nil, domain.NotFound("post not found")
+	}
+
+	return &domain.Comment{ID: id, Author: author, Body: body, Created: t}, nil
+}
+
+func (c commentStorage) GetDeleted(ctx context.Context, postID, commentID string) (*domain.Comment, error) {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	post := &domain.Post{}
+	err := c.DB.FindOne(ctx, bson.M{"id": postID, "comments.id": commentID}).Decode(post)
+
+	if err != nil {
+		return nil, findError(err, "comment")
+	}
+
+	for _, comment := range post.Comments {
+		if comment.ID == commentID && comment.DeletedAt != nil {
+			return comment, nil
+		}
+	}
+
+	return nil, domain.NotFound("deleted comment not found")
+}
+
+func (c commentStorage) Delete(ctx context.Context, postID, commentID string, deletedBy *domain.Profile) error {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.M{"id": postID, "comments": bson.M{"$elemMatch": bson.M{"id": commentID, "deletedAt": nil}}}
+	update := bson.M{"$set": bson.M{"comments.$.deletedAt": c.now(), "comments.$.deletedBy": deletedBy}}
+	result, err := c.DB.UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
 	}
 
 	if result.ModifiedCount == 0 {
-		return errors.New("affected 0 rows")
+		return domain.NotFound("comment not found")
 	}
 
 	return nil
 }
 
-func (c commentStorage) Delete(postID, commentID string) error {
-	filter := bson.M{"id": postID}
-	result, err := c.DB.UpdateOne(c.ctx, filter, bson.M{"$pull": bson.M{"comments": bson.M{"id": commentID}}})
+func (c commentStorage) Restore(ctx context.Context, postID, commentID string) error {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.M{"id": postID, "comments": bson.M{"$elemMatch": bson.M{"id": commentID, "deletedAt": bson.M{"$ne": nil}}}}
+	update := bson.M{"$unset": bson.M{"comments.$.deletedAt": "", "comments.$.deletedBy": ""}}
+	result, err := c.DB.UpdateOne(ctx, filter, update)
 
 	if err != nil {
 		return err
 	}
 
 	if result.ModifiedCount == 0 {
-		return errors.New("affected 0 rows")
+		return domain.NotFound("comment not found")
+	}
+
+	return nil
+}
+
+// Purge hard-deletes comments that were soft-deleted before the given moment
+// and reports how many posts were touched.
+func (c commentStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	expired := bson.M{"deletedAt": bson.M{"$lt": deletedBefore}}
+	result, err := c.DB.UpdateMany(ctx, bson.M{"comments": bson.M{"$elemMatch": expired}}, bson.M{"$pull": bson.M{"comments": expired}})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Import pushes the comment only onto a post that has none with the ID, in
+// one operation, so two imports don't both push it.
+func (c commentStorage) Import(ctx context.Context, postID string, comment *domain.Comment) error {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.M{"id": postID, "comments.id": bson.M{"$ne": comment.ID}}
+	result, err := c.DB.UpdateOne(ctx, filter, bson.M{"$push": bson.M{"comments": comment}})
+
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	// Nothing was pushed, either the post or the comment is missing
+	n, err := c.DB.CountDocuments(ctx, bson.M{"id": postID})
+
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return domain.NotFound("post not found")
+	}
+
+	return domain.AlreadyExists("comment %s already exists", comment.ID)
+}
+
+func (c commentStorage) SetRemoved(ctx context.Context, postID, commentID string, removed bool) error {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	filter := bson.M{"id": postID, "comments.id": commentID}
+	result, err := c.DB.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"comments.$.removed": removed}})
+
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.NotFound("comment not found")
 	}
 
 	return nil
@@ -2,7 +2,9 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/akrovv/redditclone/internal/domain"
 	"go.mongodb.org/mongo-driver/bson"
@@ -20,7 +22,7 @@ func TestAddComment(t *testing.T) {
 	id := "1"
 
 	mt.Run("Add", func(mt *mtest.T) {
-		repo := NewCommentStorage(ctx, mt.Client)
+		repo := NewCommentStorage(mt.DB, time.Second)
 
 		// OK
 		mt.AddMockResponses(mtest.CreateSuccessResponse(
@@ -29,20 +31,25 @@ func TestAddComment(t *testing.T) {
 				{Key: "nModified", Value: 1},
 			}...))
 
-		err := repo.Add(author, body, id)
+		comment, err := repo.Add(ctx, author, body, id)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
 			return
 		}
 
+		if comment.ID == "" || comment.Body != body || comment.Author != author {
+			t.Errorf("expected new comment, got %v", comment)
+			return
+		}
+
 		// Zero rows affected
 		mt.AddMockResponses(mtest.CreateSuccessResponse(
 			bson.D{
 				{Key: "ok", Value: 1},
 			}...))
 
-		err = repo.Add(author, body, id)
+		_, err = repo.Add(ctx, author, body, id)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -56,7 +63,7 @@ func TestAddComment(t *testing.T) {
 			Message: "some error",
 		}))
 
-		err = repo.Add(author, body, id)
+		_, err = repo.Add(ctx, author, body, id)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -65,14 +72,36 @@ func TestAddComment(t *testing.T) {
 	})
 }
 
+func TestCommentTimeout(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("Timeout", func(mt *mtest.T) {
+		repo := NewCommentStorage(mt.DB, time.Nanosecond)
+
+		// The answer is there, but the operation runs out of time first
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.D{
+				{Key: "ok", Value: 1},
+				{Key: "nModified", Value: 1},
+			}...))
+
+		_, err := repo.Add(context.Background(), &domain.Profile{Username: "akro"}, "good review", "1")
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected deadline exceeded, got %v", err)
+		}
+	})
+}
+
 func TestDeleteComment(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	ctx := context.Background()
 	postID := "1"
 	commentID := "1"
+	deletedBy := &domain.Profile{Username: "akro", ID: "1"}
 
 	mt.Run("Delete", func(mt *mtest.T) {
-		repo := NewCommentStorage(ctx, mt.Client)
+		repo := NewCommentStorage(mt.DB, time.Second)
 
 		// OK
 		mt.AddMockResponses(mtest.CreateSuccessResponse(
@@ -81,7 +110,7 @@ func TestDeleteComment(t *testing.T) {
 				{Key: "nModified", Value: 1},
 			}...))
 
-		err := repo.Delete(postID, commentID)
+		err := repo.Delete(ctx, postID, commentID, deletedBy)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -94,7 +123,7 @@ func TestDeleteComment(t *testing.T) {
 				{Key: "ok", Value: 1},
 			}...))
 
-		err = repo.Delete(postID, commentID)
+		err = repo.Delete(ctx, postID, commentID, deletedBy)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -108,7 +137,60 @@ func TestDeleteComment(t *testing.T) {
 			Message: "some error",
 		}))
 
-		err = repo.Delete(postID, commentID)
+		err = repo.Delete(ctx, postID, commentID, deletedBy)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestSetRemovedComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+	postID := "1"
+	commentID := "1"
+
+	mt.Run("SetRemoved", func(mt *mtest.T) {
+		repo := NewCommentStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.D{
+				{Key: "ok", Value: 1},
+				{Key: "n", Value: 1},
+				{Key: "nModified", Value: 1},
+			}...))
+
+		err := repo.SetRemoved(ctx, postID, commentID, true)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// Comment not found
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.D{
+				{Key: "ok", Value: 1},
+			}...))
+
+		err = repo.SetRemoved(ctx, postID, commentID, true)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+
+		// Update error
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    11000,
+			Message: "some error",
+		}))
+
+		err = repo.SetRemoved(ctx, postID, commentID, true)
 
 		if err == nil {
 			t.Error("expected error, got nil")
@@ -116,3 +198,178 @@ func TestDeleteComment(t *testing.T) {
 		}
 	})
 }
+
+func TestRestoreComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+	postID := "1"
+	commentID := "1"
+
+	mt.Run("Restore", func(mt *mtest.T) {
+		repo := NewCommentStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.D{
+				{Key: "ok", Value: 1},
+				{Key: "nModified", Value: 1},
+			}...))
+
+		err := repo.Restore(ctx, postID, commentID)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// Nothing to restore
+		mt.AddMockResponses(mtest.CreateSuccessResponse(
+			bson.D{
+				{Key: "ok", Value: 1},
+			}...))
+
+		err = repo.Restore(ctx, postID, commentID)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestGetDeletedComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+	deletedAt := time.Date(2024, 2, 17, 12, 0, 0, 0, time.UTC)
+
+	mt.Run("GetDeleted", func(mt *mtest.T) {
+		repo := NewCommentStorage(mt.DB, time.Second)
+
+		post := func() bson.D {
+			return mtest.CreateCursorResponse(1, "post.posts", mtest.FirstBatch, bson.D{
+				{Key: "id", Value: "1"},
+				{Key: "comments", Value: bson.A{
+					bson.D{{Key: "id", Value: "1"}, {Key: "body", Value: "alive"}},
+					bson.D{{Key: "id", Value: "2"}, {Key: "body", Value: "gone"}, {Key: "deletedAt", Value: deletedAt}},
+				}},
+			})
+		}
+
+		// OK
+		mt.AddMockResponses(post())
+
+		comment, err := repo.GetDeleted(ctx, "1", "2")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if comment.ID != "2" || comment.DeletedAt == nil || !comment.DeletedAt.Equal(deletedAt) {
+			t.Errorf("expected deleted comment 2, got %v", comment)
+			return
+		}
+
+		// Comment is not deleted
+		mt.AddMockResponses(post())
+
+		_, err = repo.GetDeleted(ctx, "1", "1")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+
+		// Find error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.GetDeleted(ctx, "1", "2")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestPurgeComments(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Purge", func(mt *mtest.T) {
+		repo := NewCommentStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}})
+
+		n, err := repo.Purge(ctx, time.Now())
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if n != 2 {
+			t.Errorf("expected 2, got %d", n)
+			return
+		}
+
+		// Update error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.Purge(ctx, time.Now())
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestImportComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+	comment := &domain.Comment{ID: "1", Author: &domain.Profile{Username: "akro"}, Body: "good review", Created: time.Now()}
+
+	mt.Run("Import", func(mt *mtest.T) {
+		repo := NewCommentStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		if err := repo.Import(ctx, "1", comment); err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// The post has the comment already
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}},
+			mtest.CreateCursorResponse(0, "post.posts", mtest.FirstBatch, bson.D{{Key: "n", Value: 1}}),
+		)
+
+		if err := repo.Import(ctx, "1", comment); !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("expected already exists, got %v", err)
+			return
+		}
+
+		// Post not found
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}},
+			mtest.CreateCursorResponse(0, "post.posts", mtest.FirstBatch),
+		)
+
+		if err := repo.Import(ctx, "1", comment); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected not found, got %v", err)
+			return
+		}
+
+		// Update error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		if err := repo.Import(ctx, "1", comment); err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/migrate"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/internal/service/storagetest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// testDB connects to the server of MONGO_TEST_URI and migrates a database of
+// its own, which is dropped after the test. Without it the contract tests are
+// skipped, they need a real Mongo.
+func testDB(t *testing.T) *mongo.Database {
+	uri := os.Getenv("MONGO_TEST_URI")
+
+	if uri == "" {
+		t.Skip("MONGO_TEST_URI is not set")
+	}
+
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+
+	if err != nil {
+		t.Fatalf("can't connect: %s", err)
+	}
+
+	db := client.Database(fmt.Sprintf("redditclone_test_%d", time.Now().UnixNano()))
+
+	t.Cleanup(func() {
+		_ = db.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+
+	if _, err = migrate.NewMongo(db).Up(ctx); err != nil {
+		t.Fatalf("can't migrate: %s", err)
+	}
+
+	return db
+}
+
+func clearPosts(t *testing.T, db *mongo.Database) {
+	if _, err := db.Collection("posts").DeleteMany(context.Background(), bson.M{}); err != nil {
+		t.Fatalf("can't clear posts: %s", err)
+	}
+}
+
+func TestPostStorageContract(t *testing.T) {
+	db := testDB(t)
+
+	storagetest.TestPostStorage(t, func(t *testing.T, clock *storagetest.Clock) service.PostStorage {
+		clearPosts(t, db)
+
+		posts := NewPostStorage(db, 0)
+		posts.now = clock.Now
+
+		return posts
+	})
+}
+
+func TestCommentStorageContract(t *testing.T) {
+	db := testDB(t)
+
+	storagetest.TestCommentStorage(t, func(t *testing.T, clock *storagetest.Clock) (service.PostStorage, service.CommentStorage) {
+		clearPosts(t, db)
+
+		posts := NewPostStorage(db, 0)
+		posts.now = clock.Now
+		comments := NewCommentStorage(db, 0)
+		comments.now = clock.Now
+
+		return posts, comments
+	})
+}
@@ -0,0 +1,19 @@
+package mongodb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// findError turns a missing document into a domain not found error and
+// keeps any other driver error behind a readable message.
+func findError(err error, what string) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.NotFound("%s not found", what)
+	}
+
+	return fmt.Errorf("can't read %s: %w", what, err)
+}
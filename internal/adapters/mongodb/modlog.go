@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+	"github.com/akrovv/redditclone/pkg/generator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type modLogStorage struct {
+	DB      *mongo.Collection
+	timeout time.Duration
+}
+
+func NewModLogStorage(db *mongo.Database, timeout time.Duration) *modLogStorage {
+	collection := db.Collection("modlog")
+	return &modLogStorage{DB: collection, timeout: timeout}
+}
+
+func (m modLogStorage) Save(ctx context.Context, entry *domain.ModLogEntry) error {
+	ctx, cancel := deadline.Bound(ctx, m.timeout)
+	defer cancel()
+
+	entry.Created = time.Now()
+	dataForID := strings.Trim(entry.Action+entry.TargetID+entry.Actor.ID+entry.Created.String(), " ")
+	entry.ID = generator.GenerateNewID(dataForID)
+
+	_, err := m.DB.InsertOne(ctx, entry)
+
+	if err != nil {
+		return fmt.Errorf("can't insert mod log entry into db: %w", err)
+	}
+
+	return nil
+}
+
+func (m modLogStorage) Get(ctx context.Context, filter *domain.ModLogFilter) ([]*domain.ModLogEntry, error) {
+	ctx, cancel := deadline.Bound(ctx, m.timeout)
+	defer cancel()
+
+	entries := []*domain.ModLogEntry{}
+	query := bson.M{}
+
+	if filter.Category != "" {
+		query["category"] = filter.Category
+	}
+
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+
+	if filter.Actor != "" {
+		query["actor.username"] = filter.Actor
+	}
+
+	if filter.TargetID != "" {
+		query["targetId"] = filter.TargetID
+	}
+
+	options := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64(filter.Offset)).
+		SetLimit(int64(filter.Limit))
+
+	c, err := m.DB.Find(ctx, query, options)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get mod log from db: %w", err)
+	}
+
+	err = c.All(ctx, &entries)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read mod log in []*ModLogEntry{}: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,102 @@
+package mongodb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestModLogSave(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Save", func(mt *mtest.T) {
+		repo := NewModLogStorage(mt.DB, time.Second)
+		entry := &domain.ModLogEntry{
+			Actor:      &domain.Profile{Username: "mod", ID: "1"},
+			Action:     domain.ModActionDeletePost,
+			TargetType: domain.ReportTargetPost,
+			TargetID:   "1",
+			Before:     json.RawMessage(`{"id":"1"}`),
+		}
+
+		// OK
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.D{{Key: "ok", Value: 1}}...))
+
+		err := repo.Save(ctx, entry)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if entry.ID == "" || entry.Created.IsZero() {
+			t.Errorf("expected id and created to be set, got %v", entry)
+			return
+		}
+
+		// Insert error
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+
+		err = repo.Save(ctx, entry)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestModLogGet(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Get", func(mt *mtest.T) {
+		repo := NewModLogStorage(mt.DB, time.Second)
+		filter := &domain.ModLogFilter{Category: "music", Action: domain.ModActionRemovePost, Limit: 25}
+		expectEntries := []*domain.ModLogEntry{
+			{ID: "1", Action: domain.ModActionRemovePost, Category: "music", TargetID: "1"},
+		}
+
+		find := mtest.CreateCursorResponse(1, "post.modlog", mtest.FirstBatch, bson.D{
+			{Key: "id", Value: "1"},
+			{Key: "action", Value: domain.ModActionRemovePost},
+			{Key: "category", Value: "music"},
+			{Key: "targetId", Value: "1"},
+		})
+		killCursors := mtest.CreateCursorResponse(0, "post.modlog", mtest.NextBatch)
+		mt.AddMockResponses(find, killCursors)
+
+		entries, err := repo.Get(ctx, filter)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if !reflect.DeepEqual(expectEntries, entries) {
+			t.Errorf("results not match, want %v, have %v", expectEntries, entries)
+			return
+		}
+
+		// Find error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.Get(ctx, filter)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
@@ -3,10 +3,12 @@ package mongodb
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
 	"github.com/akrovv/redditclone/pkg/generator"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,19 +17,29 @@ import (
 )
 
 type postStorage struct {
-	DB  *mongo.Collection
-	ctx context.Context
+	DB      *mongo.Collection
+	timeout time.Duration
+	now     func() time.Time
 }
 
-func NewPostStorage(ctx context.Context, db *mongo.Client) *postStorage {
-	collection := db.Database("post").Collection("posts")
-	return &postStorage{DB: collection, ctx: ctx}
+func NewPostStorage(db *mongo.Database, timeout time.Duration) *postStorage {
+	collection := db.Collection("posts")
+	return &postStorage{DB: collection, timeout: timeout, now: now}
 }
 
-func (p postStorage) Save(post *domain.Post) (*domain.Post, error) {
+// now is the time the storages write, cut to the precision of BSON dates,
+// so a post reads back with the same created it was saved with.
+func now() time.Time {
+	return time.Now().Truncate(time.Millisecond)
+}
+
+func (p postStorage) Save(ctx context.Context, post *domain.Post) (*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
 	dataForID := strings.Trim(post.Title+post.Author.Username+post.Category, " ")
 	post.ID = generator.GenerateNewID(dataForID)
-	post.Created = time.Now()
+	post.Created = p.now()
 
 	newPost := bson.M{
 		"_id":              primitive.NewObjectID(),
@@ -44,88 +56,128 @@ func (p postStorage) Save(post *domain.Post) (*domain.Post, error) {
 		"comments":         post.Comments,
 		"created":          post.Created,
 		"upvotePercentage": post.UpvotePercentage,
+		"removed":          post.Removed,
+		"flair":            post.Flair,
+	}
+
+	_, err := p.DB.InsertOne(ctx, newPost)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't insert post into db: %w", err)
 	}
 
-	_, err := p.DB.InsertOne(p.ctx, newPost)
+	return post, nil
+}
+
+func (p postStorage) GetOne(ctx context.Context, id string) (*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	post := &domain.Post{}
+	err := p.DB.FindOne(ctx, bson.M{"id": id, "deletedAt": nil}).Decode(post)
 
 	if err != nil {
-		return nil, errors.New("can't insert into db")
+		return nil, findError(err, "post")
 	}
 
+	post.Comments = visibleComments(post.Comments)
+
 	return post, nil
 }
 
-func (p postStorage) GetOne(id string) (*domain.Post, error) {
+func (p postStorage) GetDeleted(ctx context.Context, id string) (*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
 	post := &domain.Post{}
-	err := p.DB.FindOne(p.ctx, bson.M{"id": id}).Decode(post)
+	err := p.DB.FindOne(ctx, bson.M{"id": id, "deletedAt": bson.M{"$ne": nil}}).Decode(post)
 
 	if err != nil {
-		return nil, errors.New("can't read data in *Post{}")
+		return nil, findError(err, "post")
 	}
 
+	post.Comments = visibleComments(post.Comments)
+
 	return post, nil
 }
 
-func (p postStorage) Get() ([]*domain.Post, error) {
+func (p postStorage) Get(ctx context.Context) ([]*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
 	posts := []*domain.Post{}
 
 	options := options.Find().SetSort(bson.D{{Key: "score", Value: -1}})
-	c, err := p.DB.Find(p.ctx, bson.M{}, options)
+	c, err := p.DB.Find(ctx, visiblePosts(bson.M{}), options)
 
 	if err != nil {
-		return nil, errors.New("can't get posts from db")
+		return nil, fmt.Errorf("can't get posts from db: %w", err)
 	}
 
-	err = c.All(p.ctx, &posts)
+	err = c.All(ctx, &posts)
 
 	if err != nil {
-		return nil, errors.New("can't read posts in []*Post{}")
+		return nil, fmt.Errorf("can't read posts in []*Post{}: %w", err)
+	}
+
+	for _, post := range posts {
+		post.Comments = visibleComments(post.Comments)
 	}
 
 	return posts, nil
 }
 
-func (p postStorage) GetBy(category, data, sortField string) ([]*domain.Post, error) {
+func (p postStorage) GetBy(ctx context.Context, category, data, sortField string) ([]*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
 	posts := []*domain.Post{}
 	options := options.Find().SetSort(bson.D{{Key: sortField, Value: -1}})
-	c, err := p.DB.Find(p.ctx, bson.M{category: data}, options)
+	c, err := p.DB.Find(ctx, visiblePosts(bson.M{category: data}), options)
 
 	if err != nil {
-		return nil, errors.New("can't get posts from db")
+		return nil, fmt.Errorf("can't get posts from db: %w", err)
 	}
 
-	err = c.All(p.ctx, &posts)
+	err = c.All(ctx, &posts)
 
 	if err != nil {
-		return nil, errors.New("can't read posts in []*Post{}")
+		return nil, fmt.Errorf("can't read posts in []*Post{}: %w", err)
+	}
+
+	for _, post := range posts {
+		post.Comments = visibleComments(post.Comments)
 	}
 
 	return posts, nil
 }
 
-func (p postStorage) UpdateMetrics(postID string, inc int8, authorID string) error {
+func (p postStorage) UpdateMetrics(ctx context.Context, postID string, inc int8, authorID string) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
 	postFilter := bson.M{"id": postID}
 
 	switch {
 	case inc == 0:
-		_, err := p.DB.UpdateOne(p.ctx, postFilter, bson.M{"$pull": bson.M{"votes": bson.M{"user": authorID}}})
+		_, err := p.DB.UpdateOne(ctx, postFilter, bson.M{"$pull": bson.M{"votes": bson.M{"user": authorID}}})
 
 		if err != nil {
 			return err
 		}
 
 	case inc == 1 || inc == -1:
-		err := p.setVote(authorID, postID, inc)
+		err := p.setVote(ctx, authorID, postID, inc)
 
 		if err != nil {
 			return err
 		}
 
 	default:
-		return errors.New("unknown inc")
+		return domain.Validation("unknown vote %d", inc)
 	}
 
-	err := p.updateScorePercent(postID)
+	err := p.updateScorePercent(ctx, postID)
 
 	if err != nil {
 		return err
@@ -134,7 +186,7 @@ func (p postStorage) UpdateMetrics(postID string, inc int8, authorID string) err
 	return nil
 }
 
-func (p postStorage) updateScorePercent(postID string) error {
+func (p postStorage) updateScorePercent(ctx context.Context, postID string) error {
 	pipeline := bson.A{
 		bson.D{{Key: "$match", Value: bson.D{{Key: "id", Value: postID}}}},
 		bson.D{{Key: "$project", Value: bson.D{
@@ -148,10 +200,10 @@ func (p postStorage) updateScorePercent(postID string) error {
 		}},
 		}}
 
-	cursor, err := p.DB.Aggregate(p.ctx, pipeline)
+	cursor, err := p.DB.Aggregate(ctx, pipeline)
 
 	if err != nil {
-		return errors.New("can't aggregate func")
+		return fmt.Errorf("can't aggregate score: %w", err)
 	}
 
 	var result struct {
@@ -160,9 +212,9 @@ func (p postStorage) updateScorePercent(postID string) error {
 		PositiveVoteCount int `bson:"positiveVoteCount"`
 	}
 
-	if cursor.Next(p.ctx) {
+	if cursor.Next(ctx) {
 		if err := cursor.Decode(&result); err != nil {
-			return errors.New("can't decode into struct")
+			return fmt.Errorf("can't decode score: %w", err)
 		}
 
 		var percent = 0
@@ -180,68 +232,393 @@ func (p postStorage) updateScorePercent(postID string) error {
 
 		filter := bson.D{{Key: "id", Value: postID}}
 
-		updateResult, err := p.DB.UpdateOne(p.ctx, filter, update)
+		updateResult, err := p.DB.UpdateOne(ctx, filter, update)
 
 		if err != nil {
 			return err
 		} else if updateResult.ModifiedCount == 0 {
-			return errors.New("affected 0 rows")
+			return domain.Conflict("post score was not changed")
 		}
 	} else {
-		return errors.New("no matching documents")
+		return domain.NotFound("post not found")
+	}
+
+	return nil
+}
+
+// RecomputeScores runs updateScorePercent for the post or for every post, one
+// post at a time, each within the timeout.
+func (p postStorage) RecomputeScores(ctx context.Context, postID string) (int64, error) {
+	ids := []string{postID}
+
+	if postID == "" {
+		var err error
+
+		if ids, err = p.ids(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	var changed int64
+
+	for _, id := range ids {
+		err := p.recomputeScore(ctx, id)
+
+		if errors.Is(err, domain.ErrConflict) {
+			continue
+		}
+
+		if err != nil {
+			return changed, err
+		}
+
+		changed++
+	}
+
+	return changed, nil
+}
+
+func (p postStorage) recomputeScore(ctx context.Context, postID string) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	return p.updateScorePercent(ctx, postID)
+}
+
+func (p postStorage) ids(ctx context.Context) ([]string, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	c, err := p.DB.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"id": 1}))
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get posts from db: %w", err)
+	}
+
+	var posts []struct {
+		ID string `bson:"id"`
+	}
+
+	if err = c.All(ctx, &posts); err != nil {
+		return nil, fmt.Errorf("can't read posts: %w", err)
+	}
+
+	ids := make([]string, 0, len(posts))
+
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+
+	return ids, nil
+}
+
+// postPage is how many posts Walk reads at once, each page is bounded by
+// the timeout on its own.
+const postPage = 100
+
+func (p postStorage) Walk(ctx context.Context, fn func(post *domain.Post) error) error {
+	after := primitive.NilObjectID
+
+	for {
+		posts, last, err := p.page(ctx, after)
+
+		if err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			if err = fn(post); err != nil {
+				return err
+			}
+		}
+
+		if len(posts) < postPage {
+			return nil
+		}
+
+		after = last
+	}
+}
+
+// page reads the posts inserted after the one with the given _id, the
+// object IDs grow with the insertion time.
+func (p postStorage) page(ctx context.Context, after primitive.ObjectID) ([]*domain.Post, primitive.ObjectID, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	filter := bson.M{}
+
+	if !after.IsZero() {
+		filter["_id"] = bson.M{"$gt": after}
+	}
+
+	c, err := p.DB.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(postPage))
+
+	if err != nil {
+		return nil, after, fmt.Errorf("can't get posts from db: %w", err)
+	}
+
+	var docs []struct {
+		ObjectID    primitive.ObjectID `bson:"_id"`
+		domain.Post `bson:",inline"`
+	}
+
+	if err = c.All(ctx, &docs); err != nil {
+		return nil, after, fmt.Errorf("can't read posts in []*Post{}: %w", err)
+	}
+
+	posts := make([]*domain.Post, 0, len(docs))
+
+	for i := range docs {
+		posts = append(posts, &docs[i].Post)
+		after = docs[i].ObjectID
+	}
+
+	return posts, after, nil
+}
+
+// Import inserts the post only if there is none with the ID, in one
+// operation, so two imports of the same archive don't both insert it.
+func (p postStorage) Import(ctx context.Context, post *domain.Post) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	newPost := bson.M{
+		"id":               post.ID,
+		"score":            post.Score,
+		"views":            post.Views,
+		"type":             post.Type,
+		"title":            post.Title,
+		"url":              post.URL,
+		"author":           post.Author,
+		"category":         post.Category,
+		"text":             post.Text,
+		"votes":            post.Votes,
+		"comments":         post.Comments,
+		"created":          post.Created,
+		"upvotePercentage": post.UpvotePercentage,
+		"removed":          post.Removed,
+		"locked":           post.Locked,
+		"stickied":         post.Stickied,
+		"archived":         post.Archived,
+		"flair":            post.Flair,
+	}
+
+	if post.DeletedAt != nil {
+		newPost["deletedAt"] = post.DeletedAt
+		newPost["deletedBy"] = post.DeletedBy
+	}
+
+	res, err := p.DB.UpdateOne(ctx, bson.M{"id": post.ID}, bson.M{"$setOnInsert": newPost}, options.Update().SetUpsert(true))
+
+	if err != nil {
+		return fmt.Errorf("can't insert post into db: %w", err)
+	}
+
+	if res.UpsertedCount == 0 {
+		return domain.AlreadyExists("post %s already exists", post.ID)
 	}
 
 	return nil
 }
 
-func (p postStorage) setVote(userID, postID string, inc int8) error {
-	err := p.DB.FindOne(p.ctx, bson.M{"id": postID, "votes.user": userID}).Err()
+func (p postStorage) setVote(ctx context.Context, userID, postID string, inc int8) error {
+	err := p.DB.FindOne(ctx, bson.M{"id": postID, "votes.user": userID}).Err()
 
 	if err != nil && err != mongo.ErrNoDocuments {
 		return err
 	}
 
 	if err == mongo.ErrNoDocuments {
-		res, err := p.DB.UpdateOne(p.ctx, bson.M{"id": postID}, bson.M{"$push": bson.M{"votes": bson.M{"user": userID, "vote": inc}}})
+		res, err := p.DB.UpdateOne(ctx, bson.M{"id": postID}, bson.M{"$push": bson.M{"votes": bson.M{"user": userID, "vote": inc}}})
 
 		if err != nil {
 			return err
 		} else if res.ModifiedCount == 0 {
-			return errors.New("affected 0 rows")
+			return domain.NotFound("post not found")
 		}
 	} else {
-		res, err := p.DB.UpdateOne(p.ctx, bson.M{"id": postID, "votes.user": userID}, bson.M{"$set": bson.M{"votes.$.vote": inc}})
+		res, err := p.DB.UpdateOne(ctx, bson.M{"id": postID, "votes.user": userID}, bson.M{"$set": bson.M{"votes.$.vote": inc}})
 
 		if err != nil {
 			return err
 		} else if res.ModifiedCount == 0 {
-			return errors.New("affected 0 rows")
+			return domain.Conflict("vote is already counted")
 		}
 	}
 
 	return nil
 }
 
-func (p postStorage) IncrViews(postID string) error {
-	res, err := p.DB.UpdateOne(p.ctx, bson.M{"id": postID}, bson.M{"$inc": bson.M{"views": 1}})
+func (p postStorage) IncrViews(ctx context.Context, postID string) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.DB.UpdateOne(ctx, bson.M{"id": postID}, bson.M{"$inc": bson.M{"views": 1}})
 
 	if err != nil {
 		return err
 	} else if res.ModifiedCount == 0 {
-		return errors.New("affected 0 rows")
+		return domain.NotFound("post not found")
+	}
+
+	return nil
+}
+
+func (p postStorage) Delete(ctx context.Context, postID string, deletedBy *domain.Profile) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	filter := bson.M{"id": postID, "deletedAt": nil}
+	update := bson.M{"$set": bson.M{"deletedAt": p.now(), "deletedBy": deletedBy}}
+	res, err := p.DB.UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return domain.NotFound("post not found")
+	}
+
+	return nil
+}
+
+func (p postStorage) Restore(ctx context.Context, postID string) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	filter := bson.M{"id": postID, "deletedAt": bson.M{"$ne": nil}}
+	update := bson.M{"$unset": bson.M{"deletedAt": "", "deletedBy": ""}}
+	res, err := p.DB.UpdateOne(ctx, filter, update)
+
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return domain.NotFound("post not found")
+	}
+
+	return nil
+}
+
+// Purge hard-deletes posts that were soft-deleted before the given moment.
+func (p postStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.DB.DeleteMany(ctx, bson.M{"deletedAt": bson.M{"$lt": deletedBefore}})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
+
+func (p postStorage) SetRemoved(ctx context.Context, postID string, removed bool) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.DB.UpdateOne(ctx, bson.M{"id": postID}, bson.M{"$set": bson.M{"removed": removed}})
+
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return domain.NotFound("post not found")
+	}
+
+	return nil
+}
+
+func (p postStorage) SetLocked(ctx context.Context, postID string, locked bool) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.DB.UpdateOne(ctx, bson.M{"id": postID}, bson.M{"$set": bson.M{"locked": locked}})
+
+	if err != nil {
+		return err
+	} else if res.MatchedCount == 0 {
+		return domain.NotFound("post not found")
 	}
 
 	return nil
 }
 
-func (p postStorage) Delete(postID string) error {
-	res, err := p.DB.DeleteOne(p.ctx, bson.M{"id": postID})
+func (p postStorage) SetStickied(ctx context.Context, postID string, stickied bool) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.DB.UpdateOne(ctx, bson.M{"id": postID}, bson.M{"$set": bson.M{"stickied": stickied}})
 
 	if err != nil {
 		return err
-	} else if res.DeletedCount == 0 {
-		return errors.New("affected 0 rows")
+	} else if res.MatchedCount == 0 {
+		return domain.NotFound("post not found")
 	}
 
 	return nil
 }
+
+func (p postStorage) GetStickied(ctx context.Context, category string) ([]*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	posts := []*domain.Post{}
+	options := options.Find().SetSort(bson.D{{Key: "created", Value: -1}})
+	c, err := p.DB.Find(ctx, visiblePosts(bson.M{"category": category, "stickied": true}), options)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get posts from db: %w", err)
+	}
+
+	err = c.All(ctx, &posts)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read posts in []*Post{}: %w", err)
+	}
+
+	for _, post := range posts {
+		post.Comments = visibleComments(post.Comments)
+	}
+
+	return posts, nil
+}
+
+// Archive marks every post created before the given moment as read-only.
+func (p postStorage) Archive(ctx context.Context, createdBefore time.Time) (int64, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	filter := bson.M{"created": bson.M{"$lt": createdBefore}, "archived": bson.M{"$ne": true}}
+	res, err := p.DB.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"archived": true}})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// visiblePosts narrows a listing filter down to posts that were neither
+// removed by moderators nor deleted.
+func visiblePosts(filter bson.M) bson.M {
+	filter["removed"] = bson.M{"$ne": true}
+	filter["deletedAt"] = nil
+
+	return filter
+}
+
+func visibleComments(comments []*domain.Comment) []*domain.Comment {
+	if comments == nil {
+		return nil
+	}
+
+	visible := make([]*domain.Comment, 0, len(comments))
+
+	for _, comment := range comments {
+		if !comment.Removed && comment.DeletedAt == nil {
+			visible = append(visible, comment)
+		}
+	}
+
+	return visible
+}
@@ -2,13 +2,16 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/akrovv/redditclone/internal/domain"
 	"github.com/akrovv/redditclone/pkg/generator"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 )
 
@@ -36,14 +39,14 @@ func TestPostSave(t *testing.T) {
 	}
 
 	mt.Run("Save", func(mt *mtest.T) {
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 
 		mt.AddMockResponses(mtest.CreateSuccessResponse(
 			bson.D{
 				{Key: "ok", Value: 1},
 			}...))
 
-		_, err := repo.Save(post)
+		_, err := repo.Save(ctx, post)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -57,7 +60,7 @@ func TestPostSave(t *testing.T) {
 		}))
 
 		for _, test := range testNegative {
-			_, err = repo.Save(test.post)
+			_, err = repo.Save(ctx, test.post)
 
 			if err == nil {
 				t.Errorf("expected error, got nil")
@@ -89,9 +92,9 @@ func TestGetOne(t *testing.T) {
 		mt.AddMockResponses(find, killCursors)
 
 		ctx := context.Background()
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 
-		post, err := repo.GetOne(id)
+		post, err := repo.GetOne(ctx, id)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -109,19 +112,29 @@ func TestGetOne(t *testing.T) {
 			Message: "not exists",
 		}))
 
-		_, err = repo.GetOne(id)
+		_, err = repo.GetOne(ctx, id)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
 			return
 		}
 
-		_, err = repo.GetOne("")
+		_, err = repo.GetOne(ctx, "")
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
 			return
 		}
+
+		// Post doesn't exist
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "post.posts", mtest.FirstBatch))
+
+		_, err = repo.GetOne(ctx, id)
+
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected not found, got: %v", err)
+			return
+		}
 	})
 }
 
@@ -182,8 +195,8 @@ func TestGet(t *testing.T) {
 		killCursors := mtest.CreateCursorResponse(0, "homework.users", mtest.NextBatch)
 		mt.AddMockResponses(findOne, findThree, findTwo, killCursors)
 
-		repo := NewPostStorage(ctx, mt.Client)
-		posts, err := repo.Get()
+		repo := NewPostStorage(mt.DB, time.Second)
+		posts, err := repo.Get(ctx)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -200,7 +213,7 @@ func TestGet(t *testing.T) {
 			Message: "not exists",
 		}))
 
-		_, err = repo.Get()
+		_, err = repo.Get(ctx)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -208,7 +221,7 @@ func TestGet(t *testing.T) {
 		}
 
 		mt.AddMockResponses(findOne)
-		_, err = repo.Get()
+		_, err = repo.Get(ctx)
 		if err == nil {
 			t.Errorf("expected error, got nil")
 			return
@@ -230,7 +243,7 @@ func TestGetBy(t *testing.T) {
 	}
 
 	mt.Run("GetBy", func(mt *mtest.T) {
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 		expectPosts := []*domain.Post{
 			{
 				Title:    "Test 3",
@@ -269,7 +282,7 @@ func TestGetBy(t *testing.T) {
 		killCursors := mtest.CreateCursorResponse(0, "homework.users", mtest.NextBatch)
 		mt.AddMockResponses(findThree, findTwo, killCursors)
 
-		posts, err := repo.GetBy("category", "news", "score")
+		posts, err := repo.GetBy(ctx, "category", "news", "score")
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -288,7 +301,7 @@ func TestGetBy(t *testing.T) {
 		}))
 
 		for _, test := range testNegative {
-			_, err = repo.GetBy(test.threeData[0], test.threeData[1], test.threeData[2])
+			_, err = repo.GetBy(ctx, test.threeData[0], test.threeData[1], test.threeData[2])
 
 			if err == nil {
 				t.Errorf("expected error, got nil")
@@ -297,7 +310,7 @@ func TestGetBy(t *testing.T) {
 		}
 
 		mt.AddMockResponses(findThree)
-		_, err = repo.GetBy("test", "test", "test")
+		_, err = repo.GetBy(ctx, "test", "test", "test")
 		if err == nil {
 			t.Errorf("expected error, got nil")
 			return
@@ -328,12 +341,12 @@ func TestUpdateMetrics(t *testing.T) {
 	}
 
 	mt.Run("Update unVote", func(mt *mtest.T) {
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 
 		// OK
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, dbPost, bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}})
 
-		err := repo.UpdateMetrics(postID, 0, post.Author.ID)
+		err := repo.UpdateMetrics(ctx, postID, 0, post.Author.ID)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -342,7 +355,7 @@ func TestUpdateMetrics(t *testing.T) {
 
 		// updateOne error
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
-		err = repo.UpdateMetrics(postID, 0, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 0, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -351,7 +364,7 @@ func TestUpdateMetrics(t *testing.T) {
 
 		// updateScorePercent: updateOne: affected 0 rows
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, dbPost, bson.D{{Key: "ok", Value: 1}})
-		err = repo.UpdateMetrics(postID, 0, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 0, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -360,7 +373,7 @@ func TestUpdateMetrics(t *testing.T) {
 
 		// updateScorePercent: another error in UpdateOne
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, dbPost, bson.D{{Key: "ok", Value: 0}})
-		err = repo.UpdateMetrics(postID, 0, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 0, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -370,7 +383,7 @@ func TestUpdateMetrics(t *testing.T) {
 		// updateScorePercent: no matching documents
 		nonMatchingData := mtest.CreateCursorResponse(0, "homework.users", mtest.NextBatch)
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, nonMatchingData)
-		err = repo.UpdateMetrics(postID, 0, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 0, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -380,7 +393,7 @@ func TestUpdateMetrics(t *testing.T) {
 		// updateScorePercent: error decode
 		dec := mtest.CreateCursorResponse(0, "homework.users", mtest.FirstBatch, bson.D{{Key: "totalVotes", Value: "abc"}})
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, dec)
-		err = repo.UpdateMetrics(postID, 0, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 0, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -389,7 +402,7 @@ func TestUpdateMetrics(t *testing.T) {
 
 		// updateScorePercent: empty Aggregate
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, bson.D{}, bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}})
-		err = repo.UpdateMetrics(postID, 0, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 0, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -398,12 +411,12 @@ func TestUpdateMetrics(t *testing.T) {
 	})
 
 	mt.Run("Update upVote&downVote", func(mt *mtest.T) {
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 
 		// OK
 		mt.AddMockResponses(dbPost, bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, dbPost, bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}})
 
-		err := repo.UpdateMetrics(postID, 1, post.Author.ID)
+		err := repo.UpdateMetrics(ctx, postID, 1, post.Author.ID)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -413,7 +426,7 @@ func TestUpdateMetrics(t *testing.T) {
 		// Error setVote
 		mt.AddMockResponses(dbPost, bson.D{{Key: "ok", Value: 0}})
 
-		err = repo.UpdateMetrics(postID, 1, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 1, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -424,7 +437,7 @@ func TestUpdateMetrics(t *testing.T) {
 		nonMatchingData := mtest.CreateCursorResponse(0, "homework.users", mtest.FirstBatch)
 		mt.AddMockResponses(nonMatchingData)
 
-		err = repo.UpdateMetrics(postID, 1, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 1, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -434,7 +447,7 @@ func TestUpdateMetrics(t *testing.T) {
 		// setVote: in if: affected 0 rows
 		mt.AddMockResponses(nonMatchingData, bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 0}})
 
-		err = repo.UpdateMetrics(postID, 1, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 1, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -444,7 +457,7 @@ func TestUpdateMetrics(t *testing.T) {
 		// setVote: in else: findOne error
 		mt.AddMockResponses()
 
-		err = repo.UpdateMetrics(postID, 1, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 1, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -454,7 +467,7 @@ func TestUpdateMetrics(t *testing.T) {
 		// setVote: in else: affected 0 rows
 		mt.AddMockResponses(dbPost, bson.D{{Key: "ok", Value: 1}})
 
-		err = repo.UpdateMetrics(postID, 1, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 1, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -464,7 +477,7 @@ func TestUpdateMetrics(t *testing.T) {
 		// setVote: in else: affected 0 rows
 		mt.AddMockResponses(dbPost, bson.D{{Key: "ok", Value: 1}})
 
-		err = repo.UpdateMetrics(postID, 1, post.Author.ID)
+		err = repo.UpdateMetrics(ctx, postID, 1, post.Author.ID)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -473,11 +486,11 @@ func TestUpdateMetrics(t *testing.T) {
 	})
 
 	mt.Run("Common error", func(mt *mtest.T) {
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 
 		// incorrect increment
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}}, bson.D{}, bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}})
-		err := repo.UpdateMetrics(postID, -100, post.Author.ID)
+		err := repo.UpdateMetrics(ctx, postID, -100, post.Author.ID)
 		if err == nil {
 			t.Errorf("expected error, got nil")
 			return
@@ -490,12 +503,12 @@ func TestIncrViews(t *testing.T) {
 	ctx := context.Background()
 
 	mt.Run("IncrViews", func(mt *mtest.T) {
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 
 		// OK
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}})
 
-		err := repo.IncrViews("1")
+		err := repo.IncrViews(ctx, "1")
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -505,7 +518,7 @@ func TestIncrViews(t *testing.T) {
 		// updateOne error
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
 
-		err = repo.IncrViews("1")
+		err = repo.IncrViews(ctx, "1")
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -515,7 +528,7 @@ func TestIncrViews(t *testing.T) {
 		// affected 0 rows
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 0}})
 
-		err = repo.IncrViews("1")
+		err = repo.IncrViews(ctx, "1")
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -528,13 +541,15 @@ func TestDel(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	ctx := context.Background()
 
+	deletedBy := &domain.Profile{Username: "akro", ID: "1"}
+
 	mt.Run("IncrViews", func(mt *mtest.T) {
-		repo := NewPostStorage(ctx, mt.Client)
+		repo := NewPostStorage(mt.DB, time.Second)
 
 		// OK
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}})
 
-		err := repo.Delete("1")
+		err := repo.Delete(ctx, "1", deletedBy)
 
 		if err != nil {
 			t.Errorf("unexpected error: %s", err)
@@ -544,7 +559,7 @@ func TestDel(t *testing.T) {
 		// updateOne error
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
 
-		err = repo.Delete("1")
+		err = repo.Delete(ctx, "1", deletedBy)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -554,7 +569,7 @@ func TestDel(t *testing.T) {
 		// affected 0 rows
 		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
 
-		err = repo.Delete("1")
+		err = repo.Delete(ctx, "1", deletedBy)
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -562,7 +577,248 @@ func TestDel(t *testing.T) {
 		}
 
 		// empty postID
-		err = repo.Delete("")
+		err = repo.Delete(ctx, "", deletedBy)
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestSetRemoved(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("SetRemoved", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.SetRemoved(ctx, "1", true)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// updateOne error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		err = repo.SetRemoved(ctx, "1", true)
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+
+		// post not found
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
+
+		err = repo.SetRemoved(ctx, "1", true)
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestVisibleComments(t *testing.T) {
+	comments := []*domain.Comment{
+		{ID: "1", Body: "visible"},
+		{ID: "2", Body: "removed", Removed: true},
+		{ID: "3", Body: "visible"},
+	}
+
+	expected := []*domain.Comment{comments[0], comments[2]}
+
+	if got := visibleComments(comments); !reflect.DeepEqual(expected, got) {
+		t.Errorf("results not match, want %v, have %v", expected, got)
+		return
+	}
+
+	if got := visibleComments(nil); got != nil {
+		t.Errorf("expected nil, got %v", got)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Restore", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		err := repo.Restore(ctx, "1")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// Post is not deleted
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
+
+		err = repo.Restore(ctx, "1")
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestGetDeleted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+	deletedAt := time.Date(2024, 2, 17, 12, 0, 0, 0, time.UTC)
+
+	mt.Run("GetDeleted", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		find := mtest.CreateCursorResponse(1, "post.posts", mtest.FirstBatch, bson.D{
+			{Key: "id", Value: "1"},
+			{Key: "deletedAt", Value: deletedAt},
+		})
+		killCursors := mtest.CreateCursorResponse(0, "post.posts", mtest.NextBatch)
+		mt.AddMockResponses(find, killCursors)
+
+		post, err := repo.GetDeleted(ctx, "1")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if post.DeletedAt == nil || !post.DeletedAt.Equal(deletedAt) {
+			t.Errorf("expected deletedAt %v, got %v", deletedAt, post.DeletedAt)
+			return
+		}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.GetDeleted(ctx, "1")
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestPurge(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Purge", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 3}})
+
+		n, err := repo.Purge(ctx, time.Now())
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if n != 3 {
+			t.Errorf("expected 3, got %d", n)
+			return
+		}
+
+		// deleteMany error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.Purge(ctx, time.Now())
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestSetLockedAndStickied(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("SetLocked", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		if err := repo.SetLocked(ctx, "1", true); err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// post not found
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}})
+
+		if err := repo.SetLocked(ctx, "1", true); err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+
+		if err := repo.SetStickied(ctx, "1", true); err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// updateOne error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		if err := repo.SetStickied(ctx, "1", true); err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestGetStickied(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("GetStickied", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+		expectPosts := []*domain.Post{
+			{ID: "1", Category: "news", Stickied: true},
+		}
+
+		find := mtest.CreateCursorResponse(1, "post.posts", mtest.FirstBatch, bson.D{
+			{Key: "id", Value: "1"},
+			{Key: "category", Value: "news"},
+			{Key: "stickied", Value: true},
+		})
+		killCursors := mtest.CreateCursorResponse(0, "post.posts", mtest.NextBatch)
+		mt.AddMockResponses(find, killCursors)
+
+		posts, err := repo.GetStickied(ctx, "news")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if !reflect.DeepEqual(expectPosts, posts) {
+			t.Errorf("results not match, want %v, have %v", expectPosts, posts)
+			return
+		}
+
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.GetStickied(ctx, "news")
 
 		if err == nil {
 			t.Errorf("expected error, got nil")
@@ -570,3 +826,157 @@ func TestDel(t *testing.T) {
 		}
 	})
 }
+
+func TestArchive(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Archive", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 4}, {Key: "nModified", Value: 4}})
+
+		n, err := repo.Archive(ctx, time.Now())
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if n != 4 {
+			t.Errorf("expected 4, got %d", n)
+			return
+		}
+
+		// updateMany error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.Archive(ctx, time.Now())
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestRecomputeScores(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	score := func() bson.D {
+		return mtest.CreateCursorResponse(0, "post.posts", mtest.FirstBatch, bson.D{
+			{Key: "totalVotes", Value: 2},
+			{Key: "voteCount", Value: 2},
+			{Key: "positiveVoteCount", Value: 2},
+		})
+	}
+
+	mt.Run("RecomputeScores", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		// OK, one post
+		mt.AddMockResponses(score(), bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}})
+		changed, err := repo.RecomputeScores(ctx, "post-id")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if changed != 1 {
+			t.Errorf("expected 1, got: %d", changed)
+			return
+		}
+
+		// OK, every post, the second one is up to date
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "post.posts", mtest.FirstBatch, bson.D{{Key: "id", Value: "first"}}, bson.D{{Key: "id", Value: "second"}}),
+			score(), bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 1}},
+			score(), bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 0}},
+		)
+		changed, err = repo.RecomputeScores(ctx, "")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if changed != 1 {
+			t.Errorf("expected 1, got: %d", changed)
+			return
+		}
+
+		// Post not found
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "post.posts", mtest.FirstBatch))
+		_, err = repo.RecomputeScores(ctx, "post-id")
+
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("expected not found, got: %v", err)
+			return
+		}
+	})
+}
+
+func TestWalkAndImport(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Walk", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+
+		// OK, the deleted post too
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "post.posts", mtest.FirstBatch,
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "id", Value: "first"}},
+			bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "id", Value: "second"}, {Key: "deletedAt", Value: time.Now()}},
+		))
+
+		var ids []string
+
+		err := repo.Walk(ctx, func(post *domain.Post) error {
+			ids = append(ids, post.ID)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if !reflect.DeepEqual([]string{"first", "second"}, ids) {
+			t.Errorf("expected [first second], got: %v", ids)
+			return
+		}
+
+		// Method's Find returns error
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+
+		if err = repo.Walk(ctx, func(*domain.Post) error { return nil }); err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+
+	mt.Run("Import", func(mt *mtest.T) {
+		repo := NewPostStorage(mt.DB, time.Second)
+		post := &domain.Post{ID: "post-id", Author: &domain.Profile{Username: "akro", ID: "1"}}
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1},
+			{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: primitive.NewObjectID()}}}}})
+
+		if err := repo.Import(ctx, post); err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// Post already exists
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}})
+
+		if err := repo.Import(ctx, post); !errors.Is(err, domain.ErrAlreadyExists) {
+			t.Errorf("expected already exists, got: %v", err)
+			return
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+	"github.com/akrovv/redditclone/pkg/generator"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reportStorage struct {
+	DB      *mongo.Collection
+	timeout time.Duration
+}
+
+func NewReportStorage(db *mongo.Database, timeout time.Duration) *reportStorage {
+	collection := db.Collection("reports")
+	return &reportStorage{DB: collection, timeout: timeout}
+}
+
+func (r reportStorage) Save(ctx context.Context, report *domain.Report) (*domain.Report, error) {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	report.Created = time.Now()
+	dataForID := strings.Trim(report.PostID+report.CommentID+report.Reporter.ID+report.Created.String(), " ")
+	report.ID = generator.GenerateNewID(dataForID)
+	report.Status = domain.ReportStatusOpen
+
+	newReport := bson.M{
+		"id":         report.ID,
+		"targetType": report.TargetType,
+		"postId":     report.PostID,
+		"commentId":  report.CommentID,
+		"category":   report.Category,
+		"reason":     report.Reason,
+		"reporter":   report.Reporter,
+		"status":     report.Status,
+		"created":    report.Created,
+	}
+
+	_, err := r.DB.InsertOne(ctx, newReport)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't insert report into db: %w", err)
+	}
+
+	return report, nil
+}
+
+func (r reportStorage) GetOne(ctx context.Context, id string) (*domain.Report, error) {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	report := &domain.Report{}
+	err := r.DB.FindOne(ctx, bson.M{"id": id}).Decode(report)
+
+	if err != nil {
+		return nil, findError(err, "report")
+	}
+
+	return report, nil
+}
+
+func (r reportStorage) GetOpen(ctx context.Context, category string) ([]*domain.Report, error) {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	reports := []*domain.Report{}
+	filter := bson.M{"category": category, "status": domain.ReportStatusOpen}
+
+	options := options.Find().SetSort(bson.D{{Key: "created", Value: 1}})
+	c, err := r.DB.Find(ctx, filter, options)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get reports from db: %w", err)
+	}
+
+	err = c.All(ctx, &reports)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read reports in []*Report{}: %w", err)
+	}
+
+	return reports, nil
+}
+
+// Resolve closes every open report filed against the same post or comment,
+// so a moderator deals with a piece of content once regardless of how many
+// members reported it.
+func (r reportStorage) Resolve(ctx context.Context, postID, commentID, status string, moderator *domain.Profile) error {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	filter := bson.M{"postId": postID, "commentId": commentID, "status": domain.ReportStatusOpen}
+	update := bson.M{"$set": bson.M{
+		"status":     status,
+		"resolvedBy": moderator,
+		"resolved":   time.Now(),
+	}}
+
+	res, err := r.DB.UpdateMany(ctx, filter, update)
+
+	if err != nil {
+		return err
+	} else if res.ModifiedCount == 0 {
+		return domain.NotFound("no open reports for the content")
+	}
+
+	return nil
+}
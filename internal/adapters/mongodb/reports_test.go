@@ -0,0 +1,147 @@
+package mongodb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestReportSave(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Save", func(mt *mtest.T) {
+		repo := NewReportStorage(mt.DB, time.Second)
+		report := &domain.Report{
+			TargetType: domain.ReportTargetPost,
+			PostID:     "1",
+			Category:   "music",
+			Reason:     "spam",
+			Reporter:   &domain.Profile{Username: "akro", ID: "1"},
+		}
+
+		// OK
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.D{{Key: "ok", Value: 1}}...))
+
+		saved, err := repo.Save(ctx, report)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if saved.ID == "" || saved.Status != domain.ReportStatusOpen {
+			t.Errorf("expected open report with id, got %v", saved)
+			return
+		}
+
+		// Insert error
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Index:   1,
+			Code:    11000,
+			Message: "duplicate key error",
+		}))
+
+		_, err = repo.Save(ctx, report)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestReportGetOpen(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("GetOpen", func(mt *mtest.T) {
+		repo := NewReportStorage(mt.DB, time.Second)
+		expectReports := []*domain.Report{
+			{ID: "1", PostID: "1", Category: "music", Status: domain.ReportStatusOpen},
+			{ID: "2", PostID: "2", Category: "music", Status: domain.ReportStatusOpen},
+		}
+
+		first := mtest.CreateCursorResponse(1, "post.reports", mtest.FirstBatch, bson.D{
+			{Key: "id", Value: "1"},
+			{Key: "postId", Value: "1"},
+			{Key: "category", Value: "music"},
+			{Key: "status", Value: domain.ReportStatusOpen},
+		})
+		second := mtest.CreateCursorResponse(1, "post.reports", mtest.NextBatch, bson.D{
+			{Key: "id", Value: "2"},
+			{Key: "postId", Value: "2"},
+			{Key: "category", Value: "music"},
+			{Key: "status", Value: domain.ReportStatusOpen},
+		})
+		killCursors := mtest.CreateCursorResponse(0, "post.reports", mtest.NextBatch)
+		mt.AddMockResponses(first, second, killCursors)
+
+		reports, err := repo.GetOpen(ctx, "music")
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if !reflect.DeepEqual(expectReports, reports) {
+			t.Errorf("results not match, want %v, have %v", expectReports, reports)
+			return
+		}
+
+		// Find error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		_, err = repo.GetOpen(ctx, "music")
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
+
+func TestReportResolve(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+	moderator := &domain.Profile{Username: "mod", ID: "2"}
+
+	mt.Run("Resolve", func(mt *mtest.T) {
+		repo := NewReportStorage(mt.DB, time.Second)
+
+		// OK
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}})
+
+		err := repo.Resolve(ctx, "1", "", domain.ReportStatusRemoved, moderator)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		// Nothing to resolve
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "nModified", Value: 0}})
+
+		err = repo.Resolve(ctx, "1", "", domain.ReportStatusRemoved, moderator)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+
+		// Update error
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		err = repo.Resolve(ctx, "1", "", domain.ReportStatusRemoved, moderator)
+
+		if err == nil {
+			t.Error("expected error, got nil")
+			return
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package pgsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+)
+
+type automodStorage struct {
+	db      *sql.DB
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func NewAutomodStorage(db *sql.DB, timeout time.Duration) *automodStorage {
+	return &automodStorage{db: db, timeout: timeout, now: now}
+}
+
+// Get returns nil without an error when the category has no rules yet.
+func (a automodStorage) Get(ctx context.Context, category string) (*domain.AutomodRules, error) {
+	ctx, cancel := deadline.Bound(ctx, a.timeout)
+	defer cancel()
+
+	var (
+		rules                  = &domain.AutomodRules{}
+		updatedByID, updatedBy sql.NullString
+	)
+
+	err := a.db.QueryRowContext(ctx, `SELECT category, source, updated, updated_by_id, updated_by_username
+		FROM automod_rules WHERE category = $1`, category).Scan(&rules.Category, &rules.Source, &rules.Updated, &updatedByID, &updatedBy)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't read data in *AutomodRules{}: %w", err)
+	}
+
+	if updatedByID.Valid {
+		rules.UpdatedBy = &domain.Profile{ID: updatedByID.String, Username: updatedBy.String}
+	}
+
+	return rules, nil
+}
+
+func (a automodStorage) Save(ctx context.Context, rules *domain.AutomodRules) (*domain.AutomodRules, error) {
+	ctx, cancel := deadline.Bound(ctx, a.timeout)
+	defer cancel()
+
+	rules.Updated = a.now()
+	updatedByID, updatedBy := profileColumns(rules.UpdatedBy)
+
+	_, err := a.db.ExecContext(ctx, `INSERT INTO automod_rules (category, source, updated, updated_by_id, updated_by_username)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (category) DO UPDATE SET source = EXCLUDED.source, updated = EXCLUDED.updated,
+			updated_by_id = EXCLUDED.updated_by_id, updated_by_username = EXCLUDED.updated_by_username`,
+		rules.Category, rules.Source, rules.Updated, updatedByID, updatedBy)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't save automod rules into db: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,69 @@
+package pgsqldb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestAutomodStorage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewAutomodStorage(db, time.Second)
+	updated := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return updated }
+	ctx := context.Background()
+	columns := []string{"category", "source", "updated", "updated_by_id", "updated_by_username"}
+
+	// No rules yet
+	mock.ExpectQuery("SELECT (.+) FROM automod_rules WHERE category = \\$1").WithArgs("music").WillReturnRows(sqlmock.NewRows(columns))
+	rules, err := repo.Get(ctx, "music")
+
+	if err != nil || rules != nil {
+		t.Errorf("expected no rules, got: %v, %v", rules, err)
+		return
+	}
+
+	// OK, saved over the old ones
+	mock.ExpectExec("INSERT INTO automod_rules (.+) ON CONFLICT \\(category\\) DO UPDATE").
+		WithArgs("music", "rules: []", updated, "moderator-id", "moderator").WillReturnResult(sqlmock.NewResult(1, 1))
+	rules, err = repo.Save(ctx, &domain.AutomodRules{Category: "music", Source: "rules: []",
+		UpdatedBy: &domain.Profile{Username: "moderator", ID: "moderator-id"}})
+
+	if err != nil || !rules.Updated.Equal(updated) {
+		t.Errorf("unexpected rules: %+v, %v", rules, err)
+		return
+	}
+
+	// OK
+	mock.ExpectQuery("SELECT (.+) FROM automod_rules").WithArgs("music").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("music", "rules: []", updated, "moderator-id", "moderator"))
+	rules, err = repo.Get(ctx, "music")
+
+	if err != nil || rules.Source != "rules: []" || rules.UpdatedBy == nil || rules.UpdatedBy.Username != "moderator" {
+		t.Errorf("unexpected rules: %+v, %v", rules, err)
+		return
+	}
+
+	// Method's ExecContext returns error
+	mock.ExpectExec("INSERT INTO automod_rules").WillReturnError(errors.New("some error"))
+
+	if _, err = repo.Save(ctx, &domain.AutomodRules{Category: "music"}); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
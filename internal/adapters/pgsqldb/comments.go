@@ -0,0 +1,185 @@
+package pgsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type commentStorage struct {
+	db      *sql.DB
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func NewCommentStorage(db *sql.DB, timeout time.Duration) *commentStorage {
+	return &commentStorage{db: db, timeout: timeout, now: now}
+}
+
+const commentColumns = "id, author_id, author_username, body, removed, created, deleted_at, deleted_by_id, deleted_by_username"
+
+// visibleComments leaves out comments that were removed by moderators or
+// deleted.
+const visibleComments = "NOT removed AND deleted_at IS NULL"
+
+func (c commentStorage) Add(ctx context.Context, author *domain.Profile, body, postID string) (*domain.Comment, error) {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	t := c.now()
+	dataForID := strings.Trim(body+author.Username+author.ID+t.String(), " ")
+	id := generator.GenerateNewID(dataForID)
+
+	res, err := c.db.ExecContext(ctx, `INSERT INTO comments (id, post_id, author_id, author_username, body, created)
+		SELECT $1, id, $3, $4, $5, $6 FROM posts WHERE id = $2`, id, postID, author.ID, author.Username, body, t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, domain.NotFound("post not found")
+	}
+
+	return &domain.Comment{ID: id, Author: author, Body: body, Created: t}, nil
+}
+
+func (c commentStorage) GetDeleted(ctx context.Context, postID, commentID string) (*domain.Comment, error) {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	row := c.db.QueryRowContext(ctx, "SELECT "+commentColumns+" FROM comments WHERE post_id = $1 AND id = $2", postID, commentID)
+	comment, err := scanComment(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.NotFound("comment not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if comment.DeletedAt == nil {
+		return nil, domain.NotFound("deleted comment not found")
+	}
+
+	return comment, nil
+}
+
+func (c commentStorage) Delete(ctx context.Context, postID, commentID string, deletedBy *domain.Profile) error {
+	return c.update(ctx, `UPDATE comments SET deleted_at = $3, deleted_by_id = $4, deleted_by_username = $5
+		WHERE post_id = $1 AND id = $2 AND deleted_at IS NULL`, postID, commentID, c.now(), deletedBy.ID, deletedBy.Username)
+}
+
+func (c commentStorage) Restore(ctx context.Context, postID, commentID string) error {
+	return c.update(ctx, `UPDATE comments SET deleted_at = NULL, deleted_by_id = NULL, deleted_by_username = NULL
+		WHERE post_id = $1 AND id = $2 AND deleted_at IS NOT NULL`, postID, commentID)
+}
+
+func (c commentStorage) SetRemoved(ctx context.Context, postID, commentID string, removed bool) error {
+	return c.update(ctx, "UPDATE comments SET removed = $3 WHERE post_id = $1 AND id = $2", postID, commentID, removed)
+}
+
+// update runs a statement on one comment, args start with the ids of the
+// post and the comment.
+func (c commentStorage) update(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	res, err := c.db.ExecContext(ctx, query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.NotFound("comment not found")
+	}
+
+	return nil
+}
+
+// Purge hard-deletes comments that were soft-deleted before the given moment
+// and reports how many posts were touched.
+func (c commentStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	var touched int64
+	err := c.db.QueryRowContext(ctx, `WITH purged AS (DELETE FROM comments WHERE deleted_at < $1 RETURNING post_id)
+		SELECT COUNT(DISTINCT post_id) FROM purged`, deletedBefore).Scan(&touched)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return touched, nil
+}
+
+// Import inserts the comment only if there is none with the ID, a comment ID
+// is unique over all posts here.
+func (c commentStorage) Import(ctx context.Context, postID string, comment *domain.Comment) error {
+	ctx, cancel := deadline.Bound(ctx, c.timeout)
+	defer cancel()
+
+	deletedByID, deletedBy := profileColumns(comment.DeletedBy)
+	res, err := c.db.ExecContext(ctx, `INSERT INTO comments (post_id, `+commentColumns+`)
+		SELECT id, $2, $3, $4, $5, $6, $7, $8, $9, $10 FROM posts WHERE id = $1
+		ON CONFLICT (id) DO NOTHING`,
+		postID, comment.ID, comment.Author.ID, comment.Author.Username, comment.Body, comment.Removed, comment.Created,
+		comment.DeletedAt, deletedByID, deletedBy)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n > 0 {
+		return nil
+	}
+
+	// Nothing was inserted, either the post or the comment is missing
+	var exists bool
+
+	if err = c.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM posts WHERE id = $1)", postID).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return domain.NotFound("post not found")
+	}
+
+	return domain.AlreadyExists("comment %s already exists", comment.ID)
+}
+
+// scanComment reads the comment columns, dest takes the columns selected in
+// front of them.
+func scanComment(row scanner, dest ...any) (*domain.Comment, error) {
+	var (
+		comment                = &domain.Comment{Author: &domain.Profile{}}
+		deletedAt              sql.NullTime
+		deletedByID, deletedBy sql.NullString
+	)
+
+	dest = append(dest, &comment.ID, &comment.Author.ID, &comment.Author.Username, &comment.Body, &comment.Removed,
+		&comment.Created, &deletedAt, &deletedByID, &deletedBy)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	comment.DeletedAt, comment.DeletedBy = deletion(deletedAt, deletedByID, deletedBy)
+
+	return comment, nil
+}
@@ -0,0 +1,200 @@
+package pgsqldb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestCommentAdd(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewCommentStorage(db, time.Second)
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return created }
+	ctx := context.Background()
+	author := &domain.Profile{Username: "akro", ID: "akro-id"}
+
+	// OK
+	mock.ExpectExec("INSERT INTO comments (.+) FROM posts WHERE id = \\$2").
+		WithArgs(sqlmock.AnyArg(), "post-id", "akro-id", "akro", "body", created).WillReturnResult(sqlmock.NewResult(1, 1))
+	comment, err := repo.Add(ctx, author, "body", "post-id")
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if comment.ID == "" || comment.Body != "body" || comment.Author != author || !comment.Created.Equal(created) {
+		t.Errorf("unexpected comment: %+v", comment)
+		return
+	}
+
+	// Post not found
+	mock.ExpectExec("INSERT INTO comments").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err = repo.Add(ctx, author, "body", "post-id"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// Method's ExecContext returns error
+	mock.ExpectExec("INSERT INTO comments").WillReturnError(errors.New("some error"))
+
+	if _, err = repo.Add(ctx, author, "body", "post-id"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCommentGetDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewCommentStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := created.Add(time.Hour)
+	columns := commentRowColumns[1:]
+
+	// OK
+	mock.ExpectQuery("SELECT (.+) FROM comments WHERE post_id = \\$1 AND id = \\$2").WithArgs("post-id", "comment-id").WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("comment-id", "akro-id", "akro", "body", false, created, deletedAt, "moderator-id", "moderator"))
+	comment, err := repo.GetDeleted(ctx, "post-id", "comment-id")
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if comment.DeletedAt == nil || !comment.DeletedAt.Equal(deletedAt) || comment.DeletedBy.Username != "moderator" {
+		t.Errorf("expected deletedAt and deletedBy, got: %v, %v", comment.DeletedAt, comment.DeletedBy)
+		return
+	}
+
+	// Comment isn't deleted
+	mock.ExpectQuery("SELECT (.+) FROM comments").WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("comment-id", "akro-id", "akro", "body", false, created, nil, nil, nil))
+
+	if _, err = repo.GetDeleted(ctx, "post-id", "comment-id"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// Comment not found
+	mock.ExpectQuery("SELECT (.+) FROM comments").WillReturnRows(sqlmock.NewRows(columns))
+
+	if _, err = repo.GetDeleted(ctx, "post-id", "comment-id"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCommentPurge(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewCommentStorage(db, time.Second)
+	ctx := context.Background()
+	deletedBefore := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	// OK
+	mock.ExpectQuery("DELETE FROM comments WHERE deleted_at < \\$1").WithArgs(deletedBefore).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	n, err := repo.Purge(ctx, deletedBefore)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if n != 2 {
+		t.Errorf("expected 2, got: %d", n)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCommentImport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewCommentStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	comment := &domain.Comment{ID: "comment-id", Author: &domain.Profile{Username: "akro", ID: "akro-id"}, Body: "body", Created: created}
+
+	// OK, the ID and created are kept
+	mock.ExpectExec("INSERT INTO comments (.+) FROM posts WHERE id = \\$1 ON CONFLICT \\(id\\) DO NOTHING").
+		WithArgs("post-id", "comment-id", "akro-id", "akro", "body", false, created, nil, nil, nil).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err = repo.Import(ctx, "post-id", comment); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// The comment exists
+	mock.ExpectExec("INSERT INTO comments").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	if err = repo.Import(ctx, "post-id", comment); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("expected already exists, got: %v", err)
+		return
+	}
+
+	// Post not found
+	mock.ExpectExec("INSERT INTO comments").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	if err = repo.Import(ctx, "post-id", comment); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// Method's ExecContext returns error
+	mock.ExpectExec("INSERT INTO comments").WillReturnError(errors.New("some error"))
+
+	if err = repo.Import(ctx, "post-id", comment); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,144 @@
+package pgsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/deploy/migrations"
+	"github.com/akrovv/redditclone/internal/migrate"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/internal/service/storagetest"
+
+	"github.com/lib/pq"
+)
+
+// testSchema connects to the database of POSTGRES_TEST_DSN with an empty
+// schema of its own, which is dropped after the test, so the tables of the
+// database are never touched. Without it the tests are skipped, they need a
+// real Postgres.
+func testSchema(t *testing.T) *sql.DB {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN is not set")
+	}
+
+	connector, err := pq.NewConnector(dsn)
+
+	if err != nil {
+		t.Fatalf("can't parse dsn: %s", err)
+	}
+
+	schema := fmt.Sprintf("redditclone_test_%d", time.Now().UnixNano())
+	admin := sql.OpenDB(connector)
+
+	t.Cleanup(func() { _ = admin.Close() })
+
+	if _, err = admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("can't create schema: %s", err)
+	}
+
+	t.Cleanup(func() {
+		if _, err := admin.Exec("DROP SCHEMA " + schema + " CASCADE"); err != nil {
+			t.Errorf("can't drop schema: %s", err)
+		}
+	})
+
+	// Every connection of the pool gets the search path, not only the one
+	// a SET would run on
+	db := sql.OpenDB(&schemaConnector{Connector: connector, schema: schema})
+
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+// testDB is testSchema with the tables created by the migrations.
+func testDB(t *testing.T) *sql.DB {
+	db := testSchema(t)
+	r, err := migrate.NewPostgres(db, migrations.FS)
+
+	if err != nil {
+		t.Fatalf("can't read migrations: %s", err)
+	}
+
+	if _, err = r.Up(context.Background()); err != nil {
+		t.Fatalf("can't migrate: %s", err)
+	}
+
+	return db
+}
+
+// schemaConnector sets the search path of the connections it opens to schema.
+type schemaConnector struct {
+	*pq.Connector
+	schema string
+}
+
+func (c *schemaConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = conn.(driver.ExecerContext).ExecContext(ctx, "SET search_path TO "+c.schema, nil); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return conn, nil
+}
+
+func truncate(t *testing.T, db *sql.DB) {
+	if _, err := db.Exec("TRUNCATE posts, comments, votes"); err != nil {
+		t.Fatalf("can't truncate tables: %s", err)
+	}
+}
+
+func TestPostStorageContract(t *testing.T) {
+	db := testDB(t)
+
+	storagetest.TestPostStorage(t, func(t *testing.T, clock *storagetest.Clock) service.PostStorage {
+		truncate(t, db)
+
+		posts := NewPostStorage(db, 0)
+		posts.now = clock.Now
+
+		return posts
+	})
+}
+
+func TestCommentStorageContract(t *testing.T) {
+	db := testDB(t)
+
+	storagetest.TestCommentStorage(t, func(t *testing.T, clock *storagetest.Clock) (service.PostStorage, service.CommentStorage) {
+		truncate(t, db)
+
+		posts := NewPostStorage(db, 0)
+		posts.now = clock.Now
+		comments := NewCommentStorage(db, 0)
+		comments.now = clock.Now
+
+		return posts, comments
+	})
+}
+
+func TestUserStorageContract(t *testing.T) {
+	db := testDB(t)
+
+	storagetest.TestUserStorage(t, func(t *testing.T, clock *storagetest.Clock) service.UserStorage {
+		if _, err := db.Exec("TRUNCATE users"); err != nil {
+			t.Fatalf("can't truncate users: %s", err)
+		}
+
+		// created comes from the database clock, which the suite allows
+		// to be a second off
+		return NewUserStorage(db, 0)
+	})
+}
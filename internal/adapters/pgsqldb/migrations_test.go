@@ -0,0 +1,76 @@
+package pgsqldb
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/akrovv/redditclone/deploy/migrations"
+	"github.com/akrovv/redditclone/internal/migrate"
+)
+
+// TestMigrationsRoundTrip runs the migrations down and up again over the
+// table deploy/init.sql creates. The accounts and the columns the binary
+// reads have to survive.
+func TestMigrationsRoundTrip(t *testing.T) {
+	db := testSchema(t)
+	ctx := context.Background()
+
+	initSQL, err := os.ReadFile("../../../deploy/init.sql")
+
+	if err != nil {
+		t.Fatalf("can't read init.sql: %s", err)
+	}
+
+	if _, err = db.Exec(string(initSQL)); err != nil {
+		t.Fatalf("can't run init.sql: %s", err)
+	}
+
+	_, err = db.Exec("INSERT INTO users (username, password, is_active, role) VALUES ('akro', 'hash', true, 'admin')")
+
+	if err != nil {
+		t.Fatalf("can't insert user: %s", err)
+	}
+
+	r, err := migrate.NewPostgres(db, migrations.FS)
+
+	if err != nil {
+		t.Fatalf("can't read migrations: %s", err)
+	}
+
+	if _, err = r.Up(ctx); err != nil {
+		t.Fatalf("can't migrate up: %s", err)
+	}
+
+	status, err := r.Status(ctx)
+
+	if err != nil {
+		t.Fatalf("can't read status: %s", err)
+	}
+
+	if _, err = r.Down(ctx, len(status)); err != nil {
+		t.Fatalf("can't migrate down: %s", err)
+	}
+
+	if _, err = r.Up(ctx); err != nil {
+		t.Fatalf("can't migrate up again: %s", err)
+	}
+
+	var role string
+
+	if err = db.QueryRow("SELECT role FROM users WHERE username = 'akro' AND created IS NOT NULL").Scan(&role); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if role != "admin" {
+		t.Errorf("expected role admin, got: %s", role)
+	}
+
+	// The index is back as well
+	_, err = db.Exec("INSERT INTO users (username, password, is_active) VALUES ('akro', 'hash', true)")
+
+	if err == nil {
+		t.Errorf("expected a duplicate username to fail")
+	}
+}
@@ -0,0 +1,112 @@
+package pgsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type modLogStorage struct {
+	db      *sql.DB
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func NewModLogStorage(db *sql.DB, timeout time.Duration) *modLogStorage {
+	return &modLogStorage{db: db, timeout: timeout, now: now}
+}
+
+const modLogColumns = "id, actor_id, actor_username, action, target_type, target_id, category, reason, created, before, after"
+
+// modLogFields are the columns Get can filter on, in the order of the filter.
+var modLogFields = []string{"category", "action", "actor_username", "target_id"}
+
+func (m modLogStorage) Save(ctx context.Context, entry *domain.ModLogEntry) error {
+	ctx, cancel := deadline.Bound(ctx, m.timeout)
+	defer cancel()
+
+	entry.Created = m.now()
+	dataForID := strings.Trim(entry.Action+entry.TargetID+entry.Actor.ID+entry.Created.String(), " ")
+	entry.ID = generator.GenerateNewID(dataForID)
+
+	_, err := m.db.ExecContext(ctx, `INSERT INTO mod_log (`+modLogColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		entry.ID, entry.Actor.ID, entry.Actor.Username, entry.Action, entry.TargetType, entry.TargetID, entry.Category,
+		entry.Reason, entry.Created, jsonColumn(entry.Before), jsonColumn(entry.After))
+
+	if err != nil {
+		return fmt.Errorf("can't insert mod log entry into db: %w", err)
+	}
+
+	return nil
+}
+
+// Get returns the newest entries first. A zero limit returns all of them.
+func (m modLogStorage) Get(ctx context.Context, filter *domain.ModLogFilter) ([]*domain.ModLogEntry, error) {
+	ctx, cancel := deadline.Bound(ctx, m.timeout)
+	defer cancel()
+
+	var (
+		where []string
+		args  []any
+	)
+
+	for i, value := range []string{filter.Category, filter.Action, filter.Actor, filter.TargetID} {
+		if value != "" {
+			args = append(args, value)
+			where = append(where, fmt.Sprintf("%s = $%d", modLogFields[i], len(args)))
+		}
+	}
+
+	query := "SELECT " + modLogColumns + " FROM mod_log"
+
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+
+	// LIMIT NULL is no limit
+	limit := sql.NullInt64{Int64: int64(filter.Limit), Valid: filter.Limit > 0}
+	args = append(args, limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY created DESC, seq DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := m.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get mod log from db: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*domain.ModLogEntry{}
+
+	for rows.Next() {
+		var (
+			entry         = &domain.ModLogEntry{Actor: &domain.Profile{}}
+			before, after []byte
+		)
+
+		err = rows.Scan(&entry.ID, &entry.Actor.ID, &entry.Actor.Username, &entry.Action, &entry.TargetType, &entry.TargetID,
+			&entry.Category, &entry.Reason, &entry.Created, &before, &after)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entry.Before, entry.After = before, after
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// jsonColumn passes the document as text, lib/pq would send a []byte as
+// bytea, which a jsonb column doesn't take. An empty one is NULL.
+func jsonColumn(raw json.RawMessage) sql.NullString {
+	return sql.NullString{String: string(raw), Valid: len(raw) > 0}
+}
@@ -0,0 +1,115 @@
+package pgsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var modLogRowColumns = []string{"id", "actor_id", "actor_username", "action", "target_type", "target_id", "category",
+	"reason", "created", "before", "after"}
+
+func TestModLogSave(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewModLogStorage(db, time.Second)
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return created }
+	ctx := context.Background()
+	entry := &domain.ModLogEntry{Actor: &domain.Profile{Username: "moderator", ID: "moderator-id"}, Action: domain.ModActionLockPost,
+		TargetType: domain.ReportTargetPost, TargetID: "post-id", Category: "music", Before: json.RawMessage(`{"locked":false}`)}
+
+	// OK, the documents go as text and a missing one as NULL
+	mock.ExpectExec("INSERT INTO mod_log").
+		WithArgs(sqlmock.AnyArg(), "moderator-id", "moderator", domain.ModActionLockPost, domain.ReportTargetPost, "post-id", "music",
+			"", created, `{"locked":false}`, nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	if err = repo.Save(ctx, entry); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if entry.ID == "" || !entry.Created.Equal(created) {
+		t.Errorf("unexpected entry: %+v", entry)
+		return
+	}
+
+	// Method's ExecContext returns error
+	mock.ExpectExec("INSERT INTO mod_log").WillReturnError(errors.New("some error"))
+
+	if err = repo.Save(ctx, entry); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestModLogGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewModLogStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	// OK, filtered and paged
+	mock.ExpectQuery("SELECT (.+) FROM mod_log WHERE category = \\$1 AND actor_username = \\$2 ORDER BY created DESC, seq DESC LIMIT \\$3 OFFSET \\$4").
+		WithArgs("music", "moderator", sql.NullInt64{Int64: 10, Valid: true}, 20).
+		WillReturnRows(sqlmock.NewRows(modLogRowColumns).
+			AddRow("entry-id", "moderator-id", "moderator", domain.ModActionLockPost, domain.ReportTargetPost, "post-id", "music", "",
+				created, []byte(`{"locked": false}`), nil))
+	entries, err := repo.Get(ctx, &domain.ModLogFilter{Category: "music", Actor: "moderator", Offset: 20, Limit: 10})
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if len(entries) != 1 || entries[0].Actor.Username != "moderator" || string(entries[0].Before) != `{"locked": false}` ||
+		entries[0].After != nil {
+		t.Errorf("unexpected entries: %+v", entries)
+		return
+	}
+
+	// OK, no filter and no limit
+	mock.ExpectQuery("SELECT (.+) FROM mod_log ORDER BY created DESC, seq DESC LIMIT \\$1 OFFSET \\$2").
+		WithArgs(sql.NullInt64{}, 0).WillReturnRows(sqlmock.NewRows(modLogRowColumns))
+
+	if entries, err = repo.Get(ctx, &domain.ModLogFilter{}); err != nil || len(entries) != 0 {
+		t.Errorf("expected no entries, got: %v, %v", entries, err)
+		return
+	}
+
+	// Method's QueryContext returns error
+	mock.ExpectQuery("SELECT (.+) FROM mod_log").WillReturnError(errors.New("some error"))
+
+	if _, err = repo.Get(ctx, &domain.ModLogFilter{}); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
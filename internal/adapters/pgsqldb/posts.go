@@ -0,0 +1,601 @@
+package pgsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+	"github.com/akrovv/redditclone/pkg/generator"
+	"github.com/lib/pq"
+)
+
+type postStorage struct {
+	db      *sql.DB
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func NewPostStorage(db *sql.DB, timeout time.Duration) *postStorage {
+	return &postStorage{db: db, timeout: timeout, now: now}
+}
+
+// now is the time the storages write, cut to the precision of timestamptz,
+// so a post reads back with the same created it was saved with.
+func now() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+const postColumns = `id, type, title, url, text, category, flair, author_id, author_username, score, views,
+	upvote_percentage, removed, locked, stickied, archived, created, deleted_at, deleted_by_id, deleted_by_username`
+
+// postFields are the columns GetBy can filter on, by the field names of the
+// Mongo documents the services pass.
+var postFields = map[string]string{
+	"id":              "id",
+	"type":            "type",
+	"category":        "category",
+	"author.username": "author_username",
+}
+
+// postSorts are the columns GetBy can sort on. Posts that tie keep the order
+// they were saved in, like in Mongo.
+var postSorts = map[string]string{
+	"score":   "score",
+	"views":   "views",
+	"created": "created",
+}
+
+// visiblePosts leaves out posts that were removed by moderators or deleted.
+const visiblePosts = "NOT removed AND deleted_at IS NULL"
+
+func (p postStorage) Save(ctx context.Context, post *domain.Post) (*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	dataForID := strings.Trim(post.Title+post.Author.Username+post.Category, " ")
+	post.ID = generator.GenerateNewID(dataForID)
+	post.Created = p.now()
+
+	err := inTx(ctx, p.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO posts (id, type, title, url, text, category, flair, author_id, author_username,
+			score, views, upvote_percentage, removed, created) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+			post.ID, post.Type, post.Title, post.URL, post.Text, post.Category, post.Flair, post.Author.ID, post.Author.Username,
+			post.Score, post.Views, post.UpvotePercentage, post.Removed, post.Created)
+
+		if err != nil {
+			return err
+		}
+
+		for _, vote := range post.Votes {
+			if _, err = tx.ExecContext(ctx, "INSERT INTO votes (post_id, user_id, vote) VALUES ($1, $2, $3)", post.ID, vote.User, vote.Vote); err != nil {
+				return err
+			}
+		}
+
+		for _, comment := range post.Comments {
+			if _, err = tx.ExecContext(ctx, "INSERT INTO comments (id, post_id, author_id, author_username, body, created) VALUES ($1, $2, $3, $4, $5, $6)",
+				comment.ID, post.ID, comment.Author.ID, comment.Author.Username, comment.Body, comment.Created); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("can't insert post into db: %w", err)
+	}
+
+	return post, nil
+}
+
+func (p postStorage) GetOne(ctx context.Context, id string) (*domain.Post, error) {
+	return p.getOne(ctx, "id = $1 AND deleted_at IS NULL", id)
+}
+
+func (p postStorage) GetDeleted(ctx context.Context, id string) (*domain.Post, error) {
+	return p.getOne(ctx, "id = $1 AND deleted_at IS NOT NULL", id)
+}
+
+func (p postStorage) getOne(ctx context.Context, where, id string) (*domain.Post, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	post, err := scanPost(p.db.QueryRowContext(ctx, "SELECT "+postColumns+" FROM posts WHERE "+where, id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.NotFound("post not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.loadRelations(ctx, []*domain.Post{post}, visibleComments); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+func (p postStorage) Get(ctx context.Context) ([]*domain.Post, error) {
+	return p.list(ctx, "TRUE", "score")
+}
+
+func (p postStorage) GetBy(ctx context.Context, category, data, sortField string) ([]*domain.Post, error) {
+	column, ok := postFields[category]
+
+	if !ok {
+		return nil, fmt.Errorf("can't filter posts by %s", category)
+	}
+
+	return p.list(ctx, column+" = $1", sortField, data)
+}
+
+func (p postStorage) GetStickied(ctx context.Context, category string) ([]*domain.Post, error) {
+	return p.list(ctx, "category = $1 AND stickied", "created", category)
+}
+
+// list returns the visible posts that match where, sorted by the field
+// descending.
+func (p postStorage) list(ctx context.Context, where, sortField string, args ...any) ([]*domain.Post, error) {
+	column, ok := postSorts[sortField]
+
+	if !ok {
+		return nil, fmt.Errorf("can't sort posts by %s", sortField)
+	}
+
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	query := "SELECT " + postColumns + " FROM posts WHERE " + where + " AND " + visiblePosts + " ORDER BY " + column + " DESC, seq"
+	rows, err := p.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get posts from db: %w", err)
+	}
+	defer rows.Close()
+
+	posts := []*domain.Post{}
+
+	for rows.Next() {
+		post, err := scanPost(rows)
+
+		if err != nil {
+			return nil, fmt.Errorf("can't read posts in []*Post{}: %w", err)
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("can't get posts from db: %w", err)
+	}
+
+	if err = p.loadRelations(ctx, posts, visibleComments); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// loadRelations fills the votes and the comments that match commentsWhere
+// of the posts, two queries for the whole list.
+func (p postStorage) loadRelations(ctx context.Context, posts []*domain.Post, commentsWhere string) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*domain.Post, len(posts))
+	ids := make([]string, 0, len(posts))
+
+	for _, post := range posts {
+		post.Votes = []*domain.Vote{}
+		post.Comments = []*domain.Comment{}
+		byID[post.ID] = post
+		ids = append(ids, post.ID)
+	}
+
+	if err := p.loadVotes(ctx, ids, byID); err != nil {
+		return err
+	}
+
+	return p.loadComments(ctx, ids, byID, commentsWhere)
+}
+
+func (p postStorage) loadVotes(ctx context.Context, ids []string, byID map[string]*domain.Post) error {
+	rows, err := p.db.QueryContext(ctx, "SELECT post_id, user_id, vote FROM votes WHERE post_id = ANY($1) ORDER BY seq", pq.Array(ids))
+
+	if err != nil {
+		return fmt.Errorf("can't get votes from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID string
+		vote := &domain.Vote{}
+
+		if err = rows.Scan(&postID, &vote.User, &vote.Vote); err != nil {
+			return fmt.Errorf("can't read votes: %w", err)
+		}
+
+		byID[postID].Votes = append(byID[postID].Votes, vote)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("can't get votes from db: %w", err)
+	}
+
+	return nil
+}
+
+func (p postStorage) loadComments(ctx context.Context, ids []string, byID map[string]*domain.Post, where string) error {
+	rows, err := p.db.QueryContext(ctx, "SELECT post_id, "+commentColumns+" FROM comments WHERE post_id = ANY($1) AND "+where+" ORDER BY seq", pq.Array(ids))
+
+	if err != nil {
+		return fmt.Errorf("can't get comments from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID string
+		comment, err := scanComment(rows, &postID)
+
+		if err != nil {
+			return fmt.Errorf("can't read comments: %w", err)
+		}
+
+		byID[postID].Comments = append(byID[postID].Comments, comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("can't get comments from db: %w", err)
+	}
+
+	return nil
+}
+
+// postPage is how many posts Walk reads at once, each page is bounded by
+// the timeout on its own.
+const postPage = 100
+
+func (p postStorage) Walk(ctx context.Context, fn func(post *domain.Post) error) error {
+	var after int64
+
+	for {
+		posts, last, err := p.page(ctx, after)
+
+		if err != nil {
+			return err
+		}
+
+		for _, post := range posts {
+			if err = fn(post); err != nil {
+				return err
+			}
+		}
+
+		if len(posts) < postPage {
+			return nil
+		}
+
+		after = last
+	}
+}
+
+// page reads the posts saved after the one with the given seq, with all of
+// their comments, and returns the seq of the last of them.
+func (p postStorage) page(ctx context.Context, after int64) ([]*domain.Post, int64, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	rows, err := p.db.QueryContext(ctx, "SELECT seq, "+postColumns+" FROM posts WHERE seq > $1 ORDER BY seq LIMIT $2", after, postPage)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("can't get posts from db: %w", err)
+	}
+	defer rows.Close()
+
+	posts := []*domain.Post{}
+
+	for rows.Next() {
+		post, err := scanPost(withSeq{rows, &after})
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("can't read posts in []*Post{}: %w", err)
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("can't get posts from db: %w", err)
+	}
+
+	if err = p.loadRelations(ctx, posts, "TRUE"); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, after, nil
+}
+
+// withSeq scans the seq column in front of the ones scanPost knows.
+type withSeq struct {
+	scanner
+	seq *int64
+}
+
+func (s withSeq) Scan(dest ...any) error {
+	return s.scanner.Scan(append([]any{s.seq}, dest...)...)
+}
+
+func (p postStorage) Import(ctx context.Context, post *domain.Post) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	return inTx(ctx, p.db, func(tx *sql.Tx) error {
+		deletedByID, deletedBy := profileColumns(post.DeletedBy)
+		res, err := tx.ExecContext(ctx, `INSERT INTO posts (`+postColumns+`)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+			ON CONFLICT (id) DO NOTHING`,
+			post.ID, post.Type, post.Title, post.URL, post.Text, post.Category, post.Flair, post.Author.ID, post.Author.Username,
+			post.Score, post.Views, post.UpvotePercentage, post.Removed, post.Locked, post.Stickied, post.Archived, post.Created,
+			post.DeletedAt, deletedByID, deletedBy)
+
+		if err != nil {
+			return err
+		}
+
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return domain.AlreadyExists("post %s already exists", post.ID)
+		}
+
+		for _, vote := range post.Votes {
+			if _, err = tx.ExecContext(ctx, "INSERT INTO votes (post_id, user_id, vote) VALUES ($1, $2, $3)", post.ID, vote.User, vote.Vote); err != nil {
+				return err
+			}
+		}
+
+		for _, comment := range post.Comments {
+			deletedByID, deletedBy := profileColumns(comment.DeletedBy)
+
+			_, err = tx.ExecContext(ctx, `INSERT INTO comments (post_id, `+commentColumns+`)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+				post.ID, comment.ID, comment.Author.ID, comment.Author.Username, comment.Body, comment.Removed, comment.Created,
+				comment.DeletedAt, deletedByID, deletedBy)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// UpdateMetrics changes the vote of the user and counts the score anew in
+// one transaction. The post row is locked first, so concurrent votes on a
+// post don't count over each other.
+func (p postStorage) UpdateMetrics(ctx context.Context, postID string, inc int8, authorID string) error {
+	if inc < -1 || inc > 1 {
+		return domain.Validation("unknown vote %d", inc)
+	}
+
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	return inTx(ctx, p.db, func(tx *sql.Tx) error {
+		var locked string
+		err := tx.QueryRowContext(ctx, "SELECT id FROM posts WHERE id = $1 FOR UPDATE", postID).Scan(&locked)
+
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.NotFound("post not found")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var res sql.Result
+
+		if inc == 0 {
+			res, err = tx.ExecContext(ctx, "DELETE FROM votes WHERE post_id = $1 AND user_id = $2", postID, authorID)
+		} else {
+			res, err = tx.ExecContext(ctx, `INSERT INTO votes (post_id, user_id, vote) VALUES ($1, $2, $3)
+				ON CONFLICT (post_id, user_id) DO UPDATE SET vote = EXCLUDED.vote WHERE votes.vote <> EXCLUDED.vote`, postID, authorID, inc)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 && inc == 0 {
+			return domain.Conflict("post score was not changed")
+		} else if n == 0 {
+			return domain.Conflict("vote is already counted")
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE posts SET (score, upvote_percentage) = ("+countVotes+") WHERE id = $1", postID)
+
+		return err
+	})
+}
+
+// countVotes selects the score and the upvote percentage of the post
+// posts.id from its votes.
+const countVotes = `SELECT COALESCE(SUM(vote), 0), COALESCE(COUNT(*) FILTER (WHERE vote > 0) * 100 / NULLIF(COUNT(*), 0), 0)
+	FROM votes WHERE post_id = posts.id`
+
+func (p postStorage) RecomputeScores(ctx context.Context, postID string) (int64, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, `UPDATE posts SET (score, upvote_percentage) = (`+countVotes+`)
+		WHERE ($1 = '' OR id = $1) AND (score, upvote_percentage) <> (`+countVotes+`)`, postID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	changed, err := res.RowsAffected()
+
+	if err != nil || changed != 0 || postID == "" {
+		return changed, err
+	}
+
+	var exists bool
+
+	if err = p.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM posts WHERE id = $1)", postID).Scan(&exists); err != nil {
+		return 0, err
+	}
+
+	if !exists {
+		return 0, domain.NotFound("post not found")
+	}
+
+	return 0, nil
+}
+
+func (p postStorage) IncrViews(ctx context.Context, postID string) error {
+	return p.update(ctx, "UPDATE posts SET views = views + 1 WHERE id = $1", postID)
+}
+
+func (p postStorage) Delete(ctx context.Context, postID string, deletedBy *domain.Profile) error {
+	return p.update(ctx, "UPDATE posts SET deleted_at = $2, deleted_by_id = $3, deleted_by_username = $4 WHERE id = $1 AND deleted_at IS NULL",
+		postID, p.now(), deletedBy.ID, deletedBy.Username)
+}
+
+func (p postStorage) Restore(ctx context.Context, postID string) error {
+	return p.update(ctx, "UPDATE posts SET deleted_at = NULL, deleted_by_id = NULL, deleted_by_username = NULL WHERE id = $1 AND deleted_at IS NOT NULL", postID)
+}
+
+func (p postStorage) SetRemoved(ctx context.Context, postID string, removed bool) error {
+	return p.update(ctx, "UPDATE posts SET removed = $2 WHERE id = $1", postID, removed)
+}
+
+func (p postStorage) SetLocked(ctx context.Context, postID string, locked bool) error {
+	return p.update(ctx, "UPDATE posts SET locked = $2 WHERE id = $1", postID, locked)
+}
+
+func (p postStorage) SetStickied(ctx context.Context, postID string, stickied bool) error {
+	return p.update(ctx, "UPDATE posts SET stickied = $2 WHERE id = $1", postID, stickied)
+}
+
+// update runs a statement on one post, args start with the id of the post.
+func (p postStorage) update(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.NotFound("post not found")
+	}
+
+	return nil
+}
+
+// Purge hard-deletes posts that were soft-deleted before the given moment,
+// their comments and votes go with them.
+func (p postStorage) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	return p.updateMany(ctx, "DELETE FROM posts WHERE deleted_at < $1", deletedBefore)
+}
+
+// Archive marks every post created before the given moment as read-only.
+func (p postStorage) Archive(ctx context.Context, createdBefore time.Time) (int64, error) {
+	return p.updateMany(ctx, "UPDATE posts SET archived = TRUE WHERE created < $1 AND NOT archived", createdBefore)
+}
+
+func (p postStorage) updateMany(ctx context.Context, query string, args ...any) (int64, error) {
+	ctx, cancel := deadline.Bound(ctx, p.timeout)
+	defer cancel()
+
+	res, err := p.db.ExecContext(ctx, query, args...)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPost(row scanner) (*domain.Post, error) {
+	var (
+		post                   = &domain.Post{Author: &domain.Profile{}}
+		deletedAt              sql.NullTime
+		deletedByID, deletedBy sql.NullString
+	)
+
+	err := row.Scan(&post.ID, &post.Type, &post.Title, &post.URL, &post.Text, &post.Category, &post.Flair, &post.Author.ID,
+		&post.Author.Username, &post.Score, &post.Views, &post.UpvotePercentage, &post.Removed, &post.Locked, &post.Stickied,
+		&post.Archived, &post.Created, &deletedAt, &deletedByID, &deletedBy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	post.DeletedAt, post.DeletedBy = deletion(deletedAt, deletedByID, deletedBy)
+
+	return post, nil
+}
+
+// deletion turns the nullable deleted_* columns into the fields of the
+// domain, nil while the row isn't deleted.
+func deletion(at sql.NullTime, byID, by sql.NullString) (*time.Time, *domain.Profile) {
+	if !at.Valid {
+		return nil, nil
+	}
+
+	deletedAt := at.Time
+
+	return &deletedAt, &domain.Profile{ID: byID.String, Username: by.String}
+}
+
+// profileColumns turns the profile into the nullable *_id and *_username
+// columns, the reverse of deletion.
+func profileColumns(profile *domain.Profile) (id, username sql.NullString) {
+	if profile == nil {
+		return id, username
+	}
+
+	return sql.NullString{String: profile.ID, Valid: true}, sql.NullString{String: profile.Username, Valid: true}
+}
+
+// inTx runs fn in a transaction, commits when it succeeds and rolls back
+// otherwise.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w, rollback: %v", err, rbErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
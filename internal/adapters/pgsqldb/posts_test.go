@@ -0,0 +1,487 @@
+package pgsqldb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var (
+	postRowColumns = []string{"id", "type", "title", "url", "text", "category", "flair", "author_id", "author_username", "score",
+		"views", "upvote_percentage", "removed", "locked", "stickied", "archived", "created", "deleted_at", "deleted_by_id",
+		"deleted_by_username"}
+	commentRowColumns = []string{"post_id", "id", "author_id", "author_username", "body", "removed", "created", "deleted_at",
+		"deleted_by_id", "deleted_by_username"}
+)
+
+func TestPostSave(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return created }
+	ctx := context.Background()
+
+	newPost := func() *domain.Post {
+		return &domain.Post{
+			Score:            1,
+			Views:            1,
+			Type:             domain.PostTypeText,
+			Title:            "title",
+			Author:           &domain.Profile{Username: "akro", ID: "akro-id"},
+			Category:         "music",
+			Text:             "text",
+			Votes:            []*domain.Vote{{User: "akro-id", Vote: 1}},
+			Comments:         []*domain.Comment{},
+			UpvotePercentage: 100,
+		}
+	}
+
+	// OK
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO posts").WithArgs(sqlmock.AnyArg(), domain.PostTypeText, "title", "", "text", "music", "", "akro-id", "akro",
+		1, 1, 100, false, created).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO votes").WithArgs(sqlmock.AnyArg(), "akro-id", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	post, err := repo.Save(ctx, newPost())
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if post.ID == "" || !post.Created.Equal(created) {
+		t.Errorf("expected id and created to be set, got: %q, %v", post.ID, post.Created)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// Method's ExecContext returns error, the post is rolled back
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO posts").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO votes").WillReturnError(errors.New("some error"))
+	mock.ExpectRollback()
+
+	post, err = repo.Save(ctx, newPost())
+
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if post != nil {
+		t.Error("expected nil, got post")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostGetOne(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	postExpected := &domain.Post{
+		Score:            1,
+		Views:            2,
+		Type:             domain.PostTypeText,
+		Title:            "title",
+		Author:           &domain.Profile{Username: "akro", ID: "akro-id"},
+		Category:         "music",
+		Text:             "text",
+		Votes:            []*domain.Vote{{User: "akro-id", Vote: 1}},
+		Comments:         []*domain.Comment{{ID: "comment-id", Author: &domain.Profile{Username: "other", ID: "other-id"}, Body: "body", Created: created}},
+		Created:          created,
+		UpvotePercentage: 100,
+		ID:               "post-id",
+	}
+
+	// OK
+	mock.ExpectQuery("SELECT (.+) FROM posts WHERE id = \\$1 AND deleted_at IS NULL").WithArgs("post-id").WillReturnRows(
+		sqlmock.NewRows(postRowColumns).AddRow("post-id", domain.PostTypeText, "title", "", "text", "music", "", "akro-id", "akro", 1, 2, 100,
+			false, false, false, false, created, nil, nil, nil))
+	mock.ExpectQuery("SELECT post_id, user_id, vote FROM votes").WithArgs(sqlmock.AnyArg()).WillReturnRows(
+		sqlmock.NewRows([]string{"post_id", "user_id", "vote"}).AddRow("post-id", "akro-id", 1))
+	mock.ExpectQuery("SELECT post_id, (.+) FROM comments").WithArgs(sqlmock.AnyArg()).WillReturnRows(
+		sqlmock.NewRows(commentRowColumns).AddRow("post-id", "comment-id", "other-id", "other", "body", false, created, nil, nil, nil))
+
+	post, err := repo.GetOne(ctx, "post-id")
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if !reflect.DeepEqual(postExpected, post) {
+		t.Errorf("expected: %v, got: %v", postExpected, post)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// Post not found
+	mock.ExpectQuery("SELECT (.+) FROM posts WHERE").WithArgs("post-id").WillReturnRows(sqlmock.NewRows(postRowColumns))
+	_, err = repo.GetOne(ctx, "post-id")
+
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostGetBy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	ctx := context.Background()
+
+	// OK, nothing found
+	mock.ExpectQuery("SELECT (.+) FROM posts WHERE author_username = \\$1 AND NOT removed AND deleted_at IS NULL ORDER BY created DESC, seq").
+		WithArgs("akro").WillReturnRows(sqlmock.NewRows(postRowColumns))
+	posts, err := repo.GetBy(ctx, "author.username", "akro", "created")
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if posts == nil || len(posts) != 0 {
+		t.Errorf("expected an empty list, got: %v", posts)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// Pass an unknown field
+	if _, err = repo.GetBy(ctx, "text", "akro", "created"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	// Pass an unknown sort
+	if _, err = repo.GetBy(ctx, "category", "music", "title"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	// Method's QueryContext returns error
+	mock.ExpectQuery("SELECT (.+) FROM posts").WillReturnError(errors.New("some error"))
+
+	if _, err = repo.GetBy(ctx, "category", "music", "score"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostUpdateMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		inc    int8
+		expect func()
+		err    error
+	}{
+		{
+			name: "OK",
+			inc:  1,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM posts WHERE id = \\$1 FOR UPDATE").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("post-id"))
+				mock.ExpectExec("INSERT INTO votes").WithArgs("post-id", "user-id", 1).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("UPDATE posts SET").WithArgs("post-id").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Vote is already counted",
+			inc:  -1,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM posts").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("post-id"))
+				mock.ExpectExec("INSERT INTO votes").WithArgs("post-id", "user-id", -1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			err: domain.ErrConflict,
+		},
+		{
+			name: "Unvote without a vote",
+			inc:  0,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM posts").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("post-id"))
+				mock.ExpectExec("DELETE FROM votes").WithArgs("post-id", "user-id").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			err: domain.ErrConflict,
+		},
+		{
+			name: "Post not found",
+			inc:  1,
+			expect: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("SELECT id FROM posts").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+				mock.ExpectRollback()
+			},
+			err: domain.ErrNotFound,
+		},
+		{
+			name:   "Unknown vote",
+			inc:    2,
+			expect: func() {},
+			err:    domain.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		tt.expect()
+		err := repo.UpdateMetrics(ctx, "post-id", tt.inc, "user-id")
+
+		if tt.err == nil && err != nil || tt.err != nil && !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v, got: %v", tt.name, tt.err, err)
+			return
+		}
+
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: there were unfulfilled expectations: %s", tt.name, err)
+		}
+	}
+}
+
+func TestPostDelete(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	deletedAt := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return deletedAt }
+	ctx := context.Background()
+	moderator := &domain.Profile{Username: "moderator", ID: "moderator-id"}
+
+	// OK
+	mock.ExpectExec("UPDATE posts SET deleted_at = \\$2, (.+) WHERE id = \\$1 AND deleted_at IS NULL").
+		WithArgs("post-id", deletedAt, "moderator-id", "moderator").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err = repo.Delete(ctx, "post-id", moderator); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// Post not found or already deleted
+	mock.ExpectExec("UPDATE posts SET deleted_at").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err = repo.Delete(ctx, "post-id", moderator); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostRecomputeScores(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	ctx := context.Background()
+
+	// OK, every post
+	mock.ExpectExec("UPDATE posts SET \\(score, upvote_percentage\\)").WithArgs("").WillReturnResult(sqlmock.NewResult(0, 3))
+	changed, err := repo.RecomputeScores(ctx, "")
+
+	if err != nil || changed != 3 {
+		t.Errorf("expected 3, got: %d, %v", changed, err)
+		return
+	}
+
+	// OK, the post is up to date
+	mock.ExpectExec("UPDATE posts SET").WithArgs("post-id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	changed, err = repo.RecomputeScores(ctx, "post-id")
+
+	if err != nil || changed != 0 {
+		t.Errorf("expected 0, got: %d, %v", changed, err)
+		return
+	}
+
+	// Post not found
+	mock.ExpectExec("UPDATE posts SET").WithArgs("post-id").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT EXISTS").WithArgs("post-id").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	if _, err = repo.RecomputeScores(ctx, "post-id"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostWalk(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	// OK, the deleted comment too
+	mock.ExpectQuery("SELECT seq, (.+) FROM posts WHERE seq > \\$1 ORDER BY seq").WithArgs(0, postPage).WillReturnRows(
+		sqlmock.NewRows(append([]string{"seq"}, postRowColumns...)).AddRow(7, "post-id", domain.PostTypeText, "title", "", "text", "music", "",
+			"akro-id", "akro", 0, 0, 0, false, false, false, false, created, nil, nil, nil))
+	mock.ExpectQuery("SELECT post_id, user_id, vote FROM votes").WithArgs(sqlmock.AnyArg()).WillReturnRows(
+		sqlmock.NewRows([]string{"post_id", "user_id", "vote"}))
+	mock.ExpectQuery("SELECT post_id, (.+) FROM comments WHERE post_id = ANY\\(\\$1\\) AND TRUE").WithArgs(sqlmock.AnyArg()).WillReturnRows(
+		sqlmock.NewRows(commentRowColumns).AddRow("post-id", "comment-id", "other-id", "other", "body", false, created, created, "akro-id", "akro"))
+
+	var posts []*domain.Post
+
+	err = repo.Walk(ctx, func(post *domain.Post) error {
+		posts = append(posts, post)
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if len(posts) != 1 || posts[0].ID != "post-id" || len(posts[0].Comments) != 1 || posts[0].Comments[0].DeletedAt == nil {
+		t.Errorf("unexpected posts: %v", posts)
+		return
+	}
+
+	// Method's QueryContext returns error
+	mock.ExpectQuery("SELECT seq, (.+) FROM posts").WillReturnError(errors.New("some error"))
+
+	if err = repo.Walk(ctx, func(*domain.Post) error { return nil }); err == nil {
+		t.Error("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostImport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewPostStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	post := &domain.Post{
+		ID:       "post-id",
+		Type:     domain.PostTypeText,
+		Title:    "title",
+		Author:   &domain.Profile{Username: "akro", ID: "akro-id"},
+		Category: "music",
+		Votes:    []*domain.Vote{{User: "other-id", Vote: 1}},
+		Comments: []*domain.Comment{{ID: "comment-id", Author: &domain.Profile{Username: "other", ID: "other-id"}, Body: "body", Created: created}},
+		Created:  created,
+	}
+
+	// OK
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO posts (.+) ON CONFLICT \\(id\\) DO NOTHING").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO votes").WithArgs("post-id", "other-id", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO comments").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err = repo.Import(ctx, post); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// Post already exists
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO posts").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err = repo.Import(ctx, post); !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("expected already exists, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
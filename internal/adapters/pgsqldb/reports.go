@@ -0,0 +1,136 @@
+package pgsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type reportStorage struct {
+	db      *sql.DB
+	timeout time.Duration
+	now     func() time.Time
+}
+
+func NewReportStorage(db *sql.DB, timeout time.Duration) *reportStorage {
+	return &reportStorage{db: db, timeout: timeout, now: now}
+}
+
+const reportColumns = `id, target_type, post_id, comment_id, category, reason, reporter_id, reporter_username, status,
+	created, resolved, resolved_by_id, resolved_by_username`
+
+func (r reportStorage) Save(ctx context.Context, report *domain.Report) (*domain.Report, error) {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	report.Created = r.now()
+	dataForID := strings.Trim(report.PostID+report.CommentID+report.Reporter.ID+report.Created.String(), " ")
+	report.ID = generator.GenerateNewID(dataForID)
+	report.Status = domain.ReportStatusOpen
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO reports (id, target_type, post_id, comment_id, category, reason, reporter_id,
+		reporter_username, status, created) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		report.ID, report.TargetType, report.PostID, report.CommentID, report.Category, report.Reason, report.Reporter.ID,
+		report.Reporter.Username, report.Status, report.Created)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't insert report into db: %w", err)
+	}
+
+	return report, nil
+}
+
+func (r reportStorage) GetOne(ctx context.Context, id string) (*domain.Report, error) {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	report, err := scanReport(r.db.QueryRowContext(ctx, "SELECT "+reportColumns+" FROM reports WHERE id = $1", id))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.NotFound("report not found")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (r reportStorage) GetOpen(ctx context.Context, category string) ([]*domain.Report, error) {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+reportColumns+" FROM reports WHERE category = $1 AND status = $2 ORDER BY created, seq",
+		category, domain.ReportStatusOpen)
+
+	if err != nil {
+		return nil, fmt.Errorf("can't get reports from db: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*domain.Report{}
+
+	for rows.Next() {
+		report, err := scanReport(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// Resolve closes every open report filed against the same post or comment,
+// so a moderator deals with a piece of content once regardless of how many
+// members reported it.
+func (r reportStorage) Resolve(ctx context.Context, postID, commentID, status string, moderator *domain.Profile) error {
+	ctx, cancel := deadline.Bound(ctx, r.timeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE reports SET status = $3, resolved = $4, resolved_by_id = $5, resolved_by_username = $6
+		WHERE post_id = $1 AND comment_id = $2 AND status = $7`,
+		postID, commentID, status, r.now(), moderator.ID, moderator.Username, domain.ReportStatusOpen)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.NotFound("no open reports for the content")
+	}
+
+	return nil
+}
+
+func scanReport(row scanner) (*domain.Report, error) {
+	var (
+		report                   = &domain.Report{Reporter: &domain.Profile{}}
+		resolved                 sql.NullTime
+		resolvedByID, resolvedBy sql.NullString
+	)
+
+	err := row.Scan(&report.ID, &report.TargetType, &report.PostID, &report.CommentID, &report.Category, &report.Reason,
+		&report.Reporter.ID, &report.Reporter.Username, &report.Status, &report.Created, &resolved, &resolvedByID, &resolvedBy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The resolved columns are set together like the deleted ones
+	report.Resolved, report.ResolvedBy = deletion(resolved, resolvedByID, resolvedBy)
+
+	return report, nil
+}
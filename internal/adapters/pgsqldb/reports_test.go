@@ -0,0 +1,170 @@
+package pgsqldb
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+var reportRowColumns = []string{"id", "target_type", "post_id", "comment_id", "category", "reason", "reporter_id",
+	"reporter_username", "status", "created", "resolved", "resolved_by_id", "resolved_by_username"}
+
+func TestReportSave(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewReportStorage(db, time.Second)
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return created }
+	ctx := context.Background()
+	report := func() *domain.Report {
+		return &domain.Report{TargetType: domain.ReportTargetPost, PostID: "post-id", Category: "music", Reason: "spam",
+			Reporter: &domain.Profile{Username: "akro", ID: "akro-id"}}
+	}
+
+	// OK
+	mock.ExpectExec("INSERT INTO reports").
+		WithArgs(sqlmock.AnyArg(), domain.ReportTargetPost, "post-id", "", "music", "spam", "akro-id", "akro", domain.ReportStatusOpen, created).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	saved, err := repo.Save(ctx, report())
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if saved.ID == "" || saved.Status != domain.ReportStatusOpen || !saved.Created.Equal(created) {
+		t.Errorf("unexpected report: %+v", saved)
+		return
+	}
+
+	// Method's ExecContext returns error
+	mock.ExpectExec("INSERT INTO reports").WillReturnError(errors.New("some error"))
+
+	if _, err = repo.Save(ctx, report()); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewReportStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	resolved := created.Add(time.Hour)
+
+	// OK, resolved
+	mock.ExpectQuery("SELECT (.+) FROM reports WHERE id = \\$1").WithArgs("report-id").WillReturnRows(
+		sqlmock.NewRows(reportRowColumns).AddRow("report-id", domain.ReportTargetComment, "post-id", "comment-id", "music", "spam",
+			"akro-id", "akro", domain.ReportStatusRemoved, created, resolved, "moderator-id", "moderator"))
+	report, err := repo.GetOne(ctx, "report-id")
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if report.CommentID != "comment-id" || report.Reporter.Username != "akro" || report.Resolved == nil ||
+		!report.Resolved.Equal(resolved) || report.ResolvedBy.Username != "moderator" {
+		t.Errorf("unexpected report: %+v", report)
+		return
+	}
+
+	// Not found
+	mock.ExpectQuery("SELECT (.+) FROM reports WHERE id = \\$1").WillReturnRows(sqlmock.NewRows(reportRowColumns))
+
+	if _, err = repo.GetOne(ctx, "report-id"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// OK, the open ones of the category, oldest first
+	mock.ExpectQuery("SELECT (.+) FROM reports WHERE category = \\$1 AND status = \\$2 ORDER BY created, seq").
+		WithArgs("music", domain.ReportStatusOpen).WillReturnRows(sqlmock.NewRows(reportRowColumns).
+		AddRow("first", domain.ReportTargetPost, "post-id", "", "music", "spam", "akro-id", "akro", domain.ReportStatusOpen,
+			created, nil, nil, nil).
+		AddRow("second", domain.ReportTargetPost, "post-id", "", "music", "rude", "other-id", "other", domain.ReportStatusOpen,
+			resolved, nil, nil, nil))
+	reports, err := repo.GetOpen(ctx, "music")
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if len(reports) != 2 || reports[0].ID != "first" || reports[0].Resolved != nil || reports[0].ResolvedBy != nil {
+		t.Errorf("unexpected reports: %+v", reports)
+		return
+	}
+
+	// Method's QueryContext returns error
+	mock.ExpectQuery("SELECT (.+) FROM reports").WillReturnError(errors.New("some error"))
+
+	if _, err = repo.GetOpen(ctx, "music"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestReportResolve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewReportStorage(db, time.Second)
+	resolved := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return resolved }
+	ctx := context.Background()
+	moderator := &domain.Profile{Username: "moderator", ID: "moderator-id"}
+
+	// OK, every open report of the content
+	mock.ExpectExec("UPDATE reports SET (.+) WHERE post_id = \\$1 AND comment_id = \\$2 AND status = \\$7").
+		WithArgs("post-id", "", domain.ReportStatusApproved, resolved, "moderator-id", "moderator", domain.ReportStatusOpen).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	if err = repo.Resolve(ctx, "post-id", "", domain.ReportStatusApproved, moderator); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// No open reports
+	mock.ExpectExec("UPDATE reports").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err = repo.Resolve(ctx, "post-id", "", domain.ReportStatusApproved, moderator); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
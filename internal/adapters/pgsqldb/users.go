@@ -1,31 +1,52 @@
 package pgsqldb
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
 	"github.com/akrovv/redditclone/pkg/generator"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/argon2"
 )
 
 type userStorage struct {
-	db *sql.DB
+	db      *sql.DB
+	timeout time.Duration
 }
 
-func NewUserStorage(db *sql.DB) *userStorage {
-	return &userStorage{db: db}
+func NewUserStorage(db *sql.DB, timeout time.Duration) *userStorage {
+	return &userStorage{db: db, timeout: timeout}
 }
 
 var salt = []byte("3a1tfor5a44word123")
 
-func (s userStorage) Get(username, password string) (*domain.User, error) {
+// uniqueViolation is the PostgreSQL error code for a duplicate key.
+const uniqueViolation = "23505"
+
+func (s userStorage) Get(ctx context.Context, username, password string) (*domain.User, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
 	user := &domain.User{}
 	hashedPass := getHashPassword(password)
-	row := s.db.QueryRow("SELECT user_id FROM users WHERE username=$1 AND password=$2 AND is_active=True", username, hashedPass)
+	row := s.db.QueryRowContext(ctx, "SELECT user_id, role FROM users WHERE username=$1 AND password=$2 AND is_active=True", username, hashedPass)
 
-	var userID int
-	errScan := row.Scan(&userID)
+	var (
+		userID int
+		role   string
+	)
+	errScan := row.Scan(&userID, &role)
+
+	if errors.Is(errScan, sql.ErrNoRows) {
+		return nil, domain.NotFound("user not found")
+	}
 
 	if errScan != nil {
 		return nil, errScan
@@ -34,17 +55,29 @@ func (s userStorage) Get(username, password string) (*domain.User, error) {
 	user.Username = username
 	user.Password = password
 	user.ID = generator.GenerateNewIDByMD(username)
+	user.Role = role
 
 	return user, nil
 }
 
-func (s userStorage) Save(username, password string) (*domain.User, error) {
+func (s userStorage) Save(ctx context.Context, username, password string) (*domain.User, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
 	user := &domain.User{}
 	user.Username = username
 	user.Password = password
+	user.Role = domain.RoleMember
 
 	hashedPass := getHashPassword(password)
-	_, err := s.db.Exec(`INSERT INTO users (username, password, is_active) VALUES ($1, $2, $3)`, username, hashedPass, true)
+	_, err := s.db.ExecContext(ctx, `INSERT INTO users (username, password, is_active, role) VALUES ($1, $2, $3, $4)`, username, hashedPass, true, domain.RoleMember)
+
+	var pqErr *pq.Error
+
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return nil, domain.AlreadyExists("user %s already exists", username)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -54,8 +87,163 @@ func (s userStorage) Save(username, password string) (*domain.User, error) {
 	return user, nil
 }
 
+// GetCreated returns when the user registered. Automod uses it for account
+// age checks.
+func (s userStorage) GetCreated(ctx context.Context, username string) (time.Time, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
+	var created time.Time
+	err := s.db.QueryRowContext(ctx, "SELECT created FROM users WHERE username=$1", username).Scan(&created)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, domain.NotFound("user not found")
+	}
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return created, nil
+}
+
+func (s userStorage) SetPassword(ctx context.Context, username, password string) error {
+	return s.update(ctx, "UPDATE users SET password=$2 WHERE username=$1", username, getHashPassword(password))
+}
+
+// SetActive bans and unbans the user, Get doesn't find inactive users.
+func (s userStorage) SetActive(ctx context.Context, username string, active bool) error {
+	return s.update(ctx, "UPDATE users SET is_active=$2 WHERE username=$1", username, active)
+}
+
+// passwordScheme names the hashes of getHashPassword in the user records.
+const passwordScheme = "argon2id"
+
+// userPage is how many users Walk reads at once, each page is bounded by
+// the timeout on its own.
+const userPage = 500
+
+func (s userStorage) PasswordScheme() string {
+	return passwordScheme
+}
+
+func (s userStorage) Walk(ctx context.Context, fn func(user *domain.UserRecord) error) error {
+	after := 0
+
+	for {
+		records, last, err := s.page(ctx, after)
+
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if err = fn(record); err != nil {
+				return err
+			}
+		}
+
+		if len(records) < userPage {
+			return nil
+		}
+
+		after = last
+	}
+}
+
+// page reads the users registered after the one with the given user_id
+// and returns the user_id of the last of them.
+func (s userStorage) page(ctx context.Context, after int) ([]*domain.UserRecord, int, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, "SELECT user_id, username, password, is_active, role, created FROM users WHERE user_id > $1 ORDER BY user_id LIMIT $2", after, userPage)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("can't get users from db: %w", err)
+	}
+	defer rows.Close()
+
+	records := []*domain.UserRecord{}
+
+	for rows.Next() {
+		record := &domain.UserRecord{Password: &domain.PasswordHash{Scheme: passwordScheme}}
+
+		if err = rows.Scan(&after, &record.Username, &record.Password.Hash, &record.Active, &record.Role, &record.Created); err != nil {
+			return nil, 0, fmt.Errorf("can't read users: %w", err)
+		}
+
+		record.ID = generator.GenerateNewIDByMD(record.Username)
+		records = append(records, record)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("can't get users from db: %w", err)
+	}
+
+	return records, after, nil
+}
+
+// Import skips a user whose username is taken, the unique index on
+// username tells it so without a race between two imports.
+func (s userStorage) Import(ctx context.Context, record *domain.UserRecord) (string, bool, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
+	password := record.Password
+
+	if password == nil || password.Scheme != passwordScheme {
+		// Nobody knows a password for a random hash, until it is reset.
+		password = &domain.PasswordHash{Scheme: passwordScheme, Hash: randomHash()}
+	}
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO users (username, password, is_active, role, created)
+		VALUES ($1, $2, $3, $4, $5) ON CONFLICT (username) DO NOTHING`,
+		record.Username, password.Hash, record.Active, record.Role, record.Created)
+
+	if err != nil {
+		return "", false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	if err != nil {
+		return "", false, err
+	}
+
+	return generator.GenerateNewIDByMD(record.Username), n == 1, nil
+}
+
+func (s userStorage) update(ctx context.Context, query string, args ...any) error {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.NotFound("user not found")
+	}
+
+	return nil
+}
+
 func getHashPassword(password string) string {
 	pass := []byte(password)
 	hashedPass := argon2.IDKey(pass, salt, 1, 64*1024, 4, 32)
 	return hex.EncodeToString(hashedPass)
 }
+
+func randomHash() string {
+	hash := make([]byte, 32)
+
+	// crypto/rand doesn't fail on the supported platforms.
+	_, _ = rand.Read(hash)
+
+	return hex.EncodeToString(hash)
+}
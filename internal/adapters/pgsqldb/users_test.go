@@ -1,11 +1,14 @@
 package pgsqldb
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/lib/pq"
 	"gopkg.in/DATA-DOG/go-sqlmock.v1"
 )
 
@@ -18,7 +21,8 @@ func TestSave(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewUserStorage(db)
+	repo := NewUserStorage(db, time.Second)
+	ctx := context.Background()
 
 	username := "username"
 	password := "password"
@@ -29,11 +33,12 @@ func TestSave(t *testing.T) {
 		Username: username,
 		Password: password,
 		ID:       "14c4b06b824ec59323936251",
+		Role:     domain.RoleMember,
 	}
 
 	// OK
-	mock.ExpectExec(`INSERT INTO users`).WithArgs(username, hashedPass, isActive).WillReturnResult(sqlmock.NewResult(1, 1))
-	user, err := repo.Save(username, password)
+	mock.ExpectExec(`INSERT INTO users`).WithArgs(username, hashedPass, isActive, domain.RoleMember).WillReturnResult(sqlmock.NewResult(1, 1))
+	user, err := repo.Save(ctx, username, password)
 
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
@@ -50,9 +55,9 @@ func TestSave(t *testing.T) {
 	}
 
 	// Save error
-	mock.ExpectExec(`INSERT INTO users`).WithArgs(username, hashedPass, isActive).WillReturnError(errors.New("some error"))
+	mock.ExpectExec(`INSERT INTO users`).WithArgs(username, hashedPass, isActive, domain.RoleMember).WillReturnError(errors.New("some error"))
 
-	user, err = repo.Save(username, password)
+	user, err = repo.Save(ctx, username, password)
 
 	if err == nil {
 		t.Errorf("expected error, got nil")
@@ -67,6 +72,52 @@ func TestSave(t *testing.T) {
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
+
+	// Username is taken
+	mock.ExpectExec(`INSERT INTO users`).WithArgs(username, hashedPass, isActive, domain.RoleMember).WillReturnError(&pq.Error{Code: uniqueViolation})
+
+	_, err = repo.Save(ctx, username, password)
+
+	if !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("expected already exists, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSaveDuplicate(t *testing.T) {
+	db := testDB(t)
+	repo := NewUserStorage(db, 0)
+	ctx := context.Background()
+
+	if _, err := repo.Save(ctx, "akro", "password"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The unique index refuses the second registration
+	_, err := repo.Save(ctx, "akro", "other password")
+
+	if !errors.Is(err, domain.ErrAlreadyExists) {
+		t.Errorf("expected already exists, got: %v", err)
+		return
+	}
+
+	// Import skips the taken username
+	_, created, err := repo.Import(ctx, &domain.UserRecord{Username: "akro", Role: domain.RoleMember, Active: true, Created: time.Now()})
+
+	if err != nil || created {
+		t.Errorf("expected the user to be skipped, got created=%v, err=%v", created, err)
+		return
+	}
+
+	var n int
+
+	if err = db.QueryRow("SELECT count(*) FROM users WHERE username=$1", "akro").Scan(&n); err != nil || n != 1 {
+		t.Errorf("expected one user, got %d, err=%v", n, err)
+	}
 }
 
 func TestGet(t *testing.T) {
@@ -78,7 +129,8 @@ func TestGet(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := NewUserStorage(db)
+	repo := NewUserStorage(db, time.Second)
+	ctx := context.Background()
 
 	userID := 1
 	username := "username"
@@ -89,12 +141,13 @@ func TestGet(t *testing.T) {
 		Username: username,
 		Password: password,
 		ID:       "14c4b06b824ec59323936251",
+		Role:     domain.RoleMember,
 	}
 
 	// OK
-	rows := sqlmock.NewRows([]string{"user_id"}).AddRow(userID)
-	mock.ExpectQuery("SELECT user_id FROM users WHERE").WithArgs(username, hashedPass).WillReturnRows(rows)
-	user, err := repo.Get(username, password)
+	rows := sqlmock.NewRows([]string{"user_id", "role"}).AddRow(userID, domain.RoleMember)
+	mock.ExpectQuery("SELECT user_id, role FROM users WHERE").WithArgs(username, hashedPass).WillReturnRows(rows)
+	user, err := repo.Get(ctx, username, password)
 
 	if err != nil {
 		t.Errorf("unexpected error: %s", err)
@@ -110,8 +163,8 @@ func TestGet(t *testing.T) {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 
-	mock.ExpectQuery("SELECT user_id FROM users WHERE").WithArgs(username, hashedPass).WillReturnError(errors.New("some error"))
-	user, err = repo.Get(username, password)
+	mock.ExpectQuery("SELECT user_id, role FROM users WHERE").WithArgs(username, hashedPass).WillReturnError(errors.New("some error"))
+	user, err = repo.Get(ctx, username, password)
 
 	if err == nil {
 		t.Errorf("expected error, got nil")
@@ -126,4 +179,180 @@ func TestGet(t *testing.T) {
 	if err = mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
+
+	// Bad login or password
+	mock.ExpectQuery("SELECT user_id, role FROM users WHERE").WithArgs(username, hashedPass).WillReturnRows(sqlmock.NewRows([]string{"user_id", "role"}))
+	_, err = repo.Get(ctx, username, password)
+
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetCreated(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewUserStorage(db, time.Second)
+	ctx := context.Background()
+
+	username := "username"
+	createdExpected := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+	// OK
+	rows := sqlmock.NewRows([]string{"created"}).AddRow(createdExpected)
+	mock.ExpectQuery("SELECT created FROM users WHERE").WithArgs(username).WillReturnRows(rows)
+	created, err := repo.GetCreated(ctx, username)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if !created.Equal(createdExpected) {
+		t.Errorf("expected: %v, got: %v", createdExpected, created)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// Query error
+	mock.ExpectQuery("SELECT created FROM users WHERE").WithArgs(username).WillReturnError(errors.New("some error"))
+	_, err = repo.GetCreated(ctx, username)
+
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetPasswordAndActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewUserStorage(db, time.Second)
+	ctx := context.Background()
+
+	// OK
+	mock.ExpectExec("UPDATE users SET password").WithArgs("username", getHashPassword("new password")).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err = repo.SetPassword(ctx, "username", "new password"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	mock.ExpectExec("UPDATE users SET is_active").WithArgs("username", false).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err = repo.SetActive(ctx, "username", false); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// User not found
+	mock.ExpectExec("UPDATE users SET is_active").WithArgs("username", true).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if err = repo.SetActive(ctx, "username", true); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+		return
+	}
+
+	// Method's ExecContext returns error
+	mock.ExpectExec("UPDATE users SET password").WillReturnError(errors.New("some error"))
+
+	if err = repo.SetPassword(ctx, "username", "new password"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUserWalkAndImport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	repo := NewUserStorage(db, time.Second)
+	ctx := context.Background()
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	hashedPass := getHashPassword("password")
+
+	// OK
+	mock.ExpectQuery("SELECT user_id, username, password, is_active, role, created FROM users WHERE user_id > \\$1").WithArgs(0, userPage).WillReturnRows(
+		sqlmock.NewRows([]string{"user_id", "username", "password", "is_active", "role", "created"}).AddRow(1, "username", hashedPass, true, domain.RoleMember, created))
+
+	var users []*domain.UserRecord
+
+	err = repo.Walk(ctx, func(user *domain.UserRecord) error {
+		users = append(users, user)
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	userExpected := &domain.UserRecord{
+		Username: "username",
+		ID:       "14c4b06b824ec59323936251",
+		Role:     domain.RoleMember,
+		Active:   true,
+		Created:  created,
+		Password: &domain.PasswordHash{Scheme: passwordScheme, Hash: hashedPass},
+	}
+
+	if len(users) != 1 || !reflect.DeepEqual(userExpected, users[0]) {
+		t.Errorf("expected: %v, got: %v", userExpected, users)
+		return
+	}
+
+	// Import, the hash of the same scheme is taken over
+	mock.ExpectExec("INSERT INTO users (.+) ON CONFLICT").WithArgs("username", hashedPass, true, domain.RoleMember, created).WillReturnResult(sqlmock.NewResult(1, 1))
+	id, isNew, err := repo.Import(ctx, userExpected)
+
+	if err != nil || !isNew || id != userExpected.ID {
+		t.Errorf("expected created %s, got: %s, %v, %v", userExpected.ID, id, isNew, err)
+		return
+	}
+
+	// Import, the username is taken
+	mock.ExpectExec("INSERT INTO users (.+) ON CONFLICT").WillReturnResult(sqlmock.NewResult(0, 0))
+	id, isNew, err = repo.Import(ctx, &domain.UserRecord{Username: "username", Password: &domain.PasswordHash{Scheme: "other", Hash: "hash"}})
+
+	if err != nil || isNew || id != userExpected.ID {
+		t.Errorf("expected existing %s, got: %s, %v, %v", userExpected.ID, id, isNew, err)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
 }
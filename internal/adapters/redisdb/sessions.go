@@ -3,9 +3,12 @@ package redisdb
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"sort"
 	"time"
 
 	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/deadline"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/redis/go-redis/v9"
 )
@@ -13,18 +16,24 @@ import (
 var tokenSecret = []byte("super secret key")
 
 type sessionStorage struct {
-	ctx context.Context
-	db  *redis.Client
+	db      *redis.Client
+	timeout time.Duration
+	// ttl is how long a session lives.
+	ttl time.Duration
 }
 
-func NewSessionStorage(ctx context.Context, db *redis.Client) *sessionStorage {
-	return &sessionStorage{ctx: ctx, db: db}
+func NewSessionStorage(db *redis.Client, timeout, ttl time.Duration) *sessionStorage {
+	return &sessionStorage{db: db, timeout: timeout, ttl: ttl}
 }
 
-func (s sessionStorage) Create(ID, username string) (*domain.Session, error) {
+func (s sessionStorage) Create(ctx context.Context, ID, username, role string) (*domain.Session, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
 	userWithoutPassword := &domain.User{
 		Username: username,
 		ID:       ID,
+		Role:     role,
 	}
 
 	sess, errSession := newSession(userWithoutPassword)
@@ -33,18 +42,33 @@ func (s sessionStorage) Create(ID, username string) (*domain.Session, error) {
 		return nil, errSession
 	}
 
-	errSet := set(s.ctx, s.db, sess.ID, sess.User)
+	errSet := set(ctx, s.db, sess.ID, sess.User, s.ttl)
 
 	if errSet != nil {
 		return nil, errSet
 	}
 
+	// The set of the tokens of a user lets the admin tools find them, it
+	// lives as long as the newest session.
+	index := userSessionsKey(username)
+
+	if err := s.db.SAdd(ctx, index, sess.ID).Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Expire(ctx, index, s.ttl).Err(); err != nil {
+		return nil, err
+	}
+
 	return sess, nil
 }
 
-func (s sessionStorage) Get(key string) (*domain.User, error) {
+func (s sessionStorage) Get(ctx context.Context, key string) (*domain.User, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
 	user := &domain.User{}
-	err := get(s.ctx, s.db, key, user)
+	err := get(ctx, s.db, key, user)
 
 	if err != nil {
 		return nil, err
@@ -53,14 +77,80 @@ func (s sessionStorage) Get(key string) (*domain.User, error) {
 	return user, nil
 }
 
-func set(ctx context.Context, client *redis.Client, key string, value any) error {
+// List returns the live sessions of the user. Tokens of the index whose
+// sessions have expired are dropped from it on the way.
+func (s sessionStorage) List(ctx context.Context, username string) ([]*domain.SessionInfo, error) {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
+	index := userSessionsKey(username)
+	keys, err := s.db.SMembers(ctx, index).Result()
+
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []*domain.SessionInfo{}
+
+	for _, key := range keys {
+		user := &domain.User{}
+		err := get(ctx, s.db, key, user)
+
+		if errors.Is(err, domain.ErrNotFound) {
+			if err = s.db.SRem(ctx, index, key).Err(); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		ttl, err := s.db.TTL(ctx, key).Result()
+
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, &domain.SessionInfo{ID: key, User: user, Expires: time.Now().Add(ttl)})
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Expires.Before(sessions[j].Expires) })
+
+	return sessions, nil
+}
+
+func (s sessionStorage) Delete(ctx context.Context, key string) error {
+	ctx, cancel := deadline.Bound(ctx, s.timeout)
+	defer cancel()
+
+	user := &domain.User{}
+
+	if err := get(ctx, s.db, key, user); err != nil {
+		return err
+	}
+
+	if err := s.db.Del(ctx, key).Err(); err != nil {
+		return err
+	}
+
+	return s.db.SRem(ctx, userSessionsKey(user.Username), key).Err()
+}
+
+func userSessionsKey(username string) string {
+	return "sessions:" + username
+}
+
+func set(ctx context.Context, client *redis.Client, key string, value any, ttl time.Duration) error {
 	p, err := json.Marshal(value)
 
 	if err != nil {
 		return err
 	}
 
-	status := client.Set(ctx, key, p, time.Hour*9)
+	status := client.Set(ctx, key, p, ttl)
 
 	if status.Err() != nil {
 		return status.Err()
@@ -72,6 +162,10 @@ func set(ctx context.Context, client *redis.Client, key string, value any) error
 func get(ctx context.Context, client *redis.Client, key string, dest any) error {
 	value, err := client.Get(ctx, key).Result()
 
+	if errors.Is(err, redis.Nil) {
+		return domain.NotFound("session not found")
+	}
+
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,234 @@
+// Package archive reads and writes the data of the site as NDJSON, one
+// record per line, so it can move between installations with different
+// storages. The records have their own types, a change of the domain doesn't
+// change the format, and the first line carries the version of the format.
+//
+// The header comes first and the end record with the counts last, an archive
+// without it is truncated. Users come before the posts, and the comments and
+// the votes of a post follow right after it.
+package archive
+
+import (
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+const (
+	// Format tells an archive from any other NDJSON file.
+	Format = "redditclone"
+	// Version is the version of the format this build writes and the newest
+	// it reads.
+	Version = 1
+)
+
+const (
+	kindHeader  = "header"
+	kindUser    = "user"
+	kindPost    = "post"
+	kindComment = "comment"
+	kindVote    = "vote"
+	kindEnd     = "end"
+)
+
+type Header struct {
+	Format  string    `json:"format"`
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+}
+
+// Counts are the records of each kind in the archive.
+type Counts struct {
+	Users    int64 `json:"users"`
+	Posts    int64 `json:"posts"`
+	Comments int64 `json:"comments"`
+	Votes    int64 `json:"votes"`
+}
+
+type Profile struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type Password struct {
+	Scheme string `json:"scheme"`
+	Hash   string `json:"hash"`
+}
+
+type User struct {
+	ID       string    `json:"id"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	Active   bool      `json:"active"`
+	Created  time.Time `json:"created"`
+	Password *Password `json:"password,omitempty"`
+}
+
+type Post struct {
+	ID               string     `json:"id"`
+	Type             string     `json:"type"`
+	Title            string     `json:"title"`
+	URL              string     `json:"url,omitempty"`
+	Text             string     `json:"text,omitempty"`
+	Category         string     `json:"category"`
+	Flair            string     `json:"flair,omitempty"`
+	Author           Profile    `json:"author"`
+	Score            int        `json:"score"`
+	Views            uint       `json:"views"`
+	UpvotePercentage uint       `json:"upvotePercentage"`
+	Removed          bool       `json:"removed,omitempty"`
+	Locked           bool       `json:"locked,omitempty"`
+	Stickied         bool       `json:"stickied,omitempty"`
+	Archived         bool       `json:"archived,omitempty"`
+	Created          time.Time  `json:"created"`
+	DeletedAt        *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy        *Profile   `json:"deletedBy,omitempty"`
+}
+
+type Comment struct {
+	PostID    string     `json:"postId"`
+	ID        string     `json:"id"`
+	Author    Profile    `json:"author"`
+	Body      string     `json:"body"`
+	Removed   bool       `json:"removed,omitempty"`
+	Created   time.Time  `json:"created"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	DeletedBy *Profile   `json:"deletedBy,omitempty"`
+}
+
+// Vote refers to the user by the ID the user has in the archive.
+type Vote struct {
+	PostID string `json:"postId"`
+	User   string `json:"user"`
+	Vote   int    `json:"vote"`
+}
+
+// record is a line of the archive, the kind tells which field is set.
+type record struct {
+	Kind    string   `json:"kind"`
+	Header  *Header  `json:"header,omitempty"`
+	User    *User    `json:"user,omitempty"`
+	Post    *Post    `json:"post,omitempty"`
+	Comment *Comment `json:"comment,omitempty"`
+	Vote    *Vote    `json:"vote,omitempty"`
+	Counts  *Counts  `json:"counts,omitempty"`
+}
+
+func fromProfile(p *domain.Profile) *Profile {
+	if p == nil {
+		return nil
+	}
+
+	return &Profile{ID: p.ID, Username: p.Username}
+}
+
+func (p *Profile) domain() *domain.Profile {
+	if p == nil {
+		return nil
+	}
+
+	return &domain.Profile{ID: p.ID, Username: p.Username}
+}
+
+func fromUser(u *domain.UserRecord) *User {
+	user := &User{ID: u.ID, Username: u.Username, Role: u.Role, Active: u.Active, Created: u.Created}
+
+	if u.Password != nil {
+		user.Password = &Password{Scheme: u.Password.Scheme, Hash: u.Password.Hash}
+	}
+
+	return user
+}
+
+func (u *User) domain() *domain.UserRecord {
+	user := &domain.UserRecord{ID: u.ID, Username: u.Username, Role: u.Role, Active: u.Active, Created: u.Created}
+
+	if u.Password != nil {
+		user.Password = &domain.PasswordHash{Scheme: u.Password.Scheme, Hash: u.Password.Hash}
+	}
+
+	return user
+}
+
+func fromPost(p *domain.Post) *Post {
+	post := &Post{
+		ID:               p.ID,
+		Type:             p.Type,
+		Title:            p.Title,
+		URL:              p.URL,
+		Text:             p.Text,
+		Category:         p.Category,
+		Flair:            p.Flair,
+		Score:            p.Score,
+		Views:            p.Views,
+		UpvotePercentage: p.UpvotePercentage,
+		Removed:          p.Removed,
+		Locked:           p.Locked,
+		Stickied:         p.Stickied,
+		Archived:         p.Archived,
+		Created:          p.Created,
+		DeletedAt:        p.DeletedAt,
+		DeletedBy:        fromProfile(p.DeletedBy),
+	}
+
+	if p.Author != nil {
+		post.Author = *fromProfile(p.Author)
+	}
+
+	return post
+}
+
+func (p *Post) domain() *domain.Post {
+	return &domain.Post{
+		ID:               p.ID,
+		Type:             p.Type,
+		Title:            p.Title,
+		URL:              p.URL,
+		Text:             p.Text,
+		Category:         p.Category,
+		Flair:            p.Flair,
+		Author:           p.Author.domain(),
+		Score:            p.Score,
+		Views:            p.Views,
+		UpvotePercentage: p.UpvotePercentage,
+		Removed:          p.Removed,
+		Locked:           p.Locked,
+		Stickied:         p.Stickied,
+		Archived:         p.Archived,
+		Created:          p.Created,
+		DeletedAt:        p.DeletedAt,
+		DeletedBy:        p.DeletedBy.domain(),
+		Votes:            []*domain.Vote{},
+		Comments:         []*domain.Comment{},
+	}
+}
+
+func fromComment(postID string, c *domain.Comment) *Comment {
+	comment := &Comment{
+		PostID:    postID,
+		ID:        c.ID,
+		Body:      c.Body,
+		Removed:   c.Removed,
+		Created:   c.Created,
+		DeletedAt: c.DeletedAt,
+		DeletedBy: fromProfile(c.DeletedBy),
+	}
+
+	if c.Author != nil {
+		comment.Author = *fromProfile(c.Author)
+	}
+
+	return comment
+}
+
+func (c *Comment) domain() *domain.Comment {
+	return &domain.Comment{
+		ID:        c.ID,
+		Author:    c.Author.domain(),
+		Body:      c.Body,
+		Removed:   c.Removed,
+		Created:   c.Created,
+		DeletedAt: c.DeletedAt,
+		DeletedBy: c.DeletedBy.domain(),
+	}
+}
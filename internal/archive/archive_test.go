@@ -0,0 +1,185 @@
+package archive
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	deleted := created.Add(time.Hour)
+
+	user := &domain.UserRecord{
+		Username: "akro",
+		ID:       "akro-id",
+		Role:     domain.RoleMember,
+		Active:   true,
+		Created:  created,
+		Password: &domain.PasswordHash{Scheme: "argon2id", Hash: "hash"},
+	}
+
+	post := &domain.Post{
+		ID:        "post-id",
+		Type:      domain.PostTypeText,
+		Title:     "title",
+		Text:      "line\nanother line",
+		Category:  "music",
+		Author:    &domain.Profile{Username: "akro", ID: "akro-id"},
+		Score:     1,
+		Created:   created,
+		DeletedAt: &deleted,
+		DeletedBy: &domain.Profile{Username: "admin", ID: "admin-id"},
+		Votes:     []*domain.Vote{{User: "akro-id", Vote: 1}},
+		Comments:  []*domain.Comment{{ID: "comment-id", Author: &domain.Profile{Username: "akro", ID: "akro-id"}, Body: "body", Created: created}},
+	}
+
+	empty := &domain.Post{ID: "empty", Author: &domain.Profile{Username: "akro", ID: "akro-id"}, Created: created}
+
+	buf := &bytes.Buffer{}
+	w, err := NewWriter(buf, created)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, write := range []func() error{
+		func() error { return w.WriteUser(user) },
+		func() error { return w.WritePost(post) },
+		func() error { return w.WritePost(empty) },
+	} {
+		if err = write(); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	counts, err := w.Close()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if *counts != (Counts{Users: 1, Posts: 2, Comments: 1, Votes: 1}) {
+		t.Errorf("unexpected counts: %+v", *counts)
+		return
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != 7 {
+		t.Errorf("expected 7 lines, got: %d", lines)
+		return
+	}
+
+	// OK, everything comes back in order
+	r, err := NewReader(bytes.NewReader(buf.Bytes()))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if r.Header().Version != Version || !r.Header().Created.Equal(created) {
+		t.Errorf("unexpected header: %+v", r.Header())
+		return
+	}
+
+	gotUser, _, err := r.Next()
+
+	if err != nil || !reflect.DeepEqual(user, gotUser) {
+		t.Errorf("expected: %v, got: %v, %v", user, gotUser, err)
+		return
+	}
+
+	_, gotPost, err := r.Next()
+
+	if err != nil || !reflect.DeepEqual(post, gotPost) {
+		t.Errorf("expected: %v, got: %v, %v", post, gotPost, err)
+		return
+	}
+
+	_, gotPost, err = r.Next()
+	empty.Votes, empty.Comments = []*domain.Vote{}, []*domain.Comment{}
+
+	if err != nil || !reflect.DeepEqual(empty, gotPost) {
+		t.Errorf("expected: %v, got: %v, %v", empty, gotPost, err)
+		return
+	}
+
+	if _, _, err = r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected EOF, got: %v", err)
+		return
+	}
+
+	// Truncated archive
+	lines := strings.SplitAfter(buf.String(), "\n")
+	r, err = NewReader(strings.NewReader(strings.Join(lines[:4], "")))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, _, err = r.Next(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if _, _, err = r.Next(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if _, _, err = r.Next(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected unexpected EOF, got: %v", err)
+		return
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	// Not an archive
+	if _, err := NewReader(strings.NewReader(`{"kind":"user","user":{"username":"akro"}}` + "\n")); err == nil {
+		t.Error("expected error, got nil")
+		return
+	}
+
+	// Empty file
+	if _, err := NewReader(strings.NewReader("")); err == nil {
+		t.Error("expected error, got nil")
+		return
+	}
+
+	// Newer version
+	if _, err := NewReader(strings.NewReader(`{"kind":"header","header":{"format":"redditclone","version":2}}` + "\n")); err == nil {
+		t.Error("expected error, got nil")
+		return
+	}
+}
+
+func TestReaderNext(t *testing.T) {
+	header := `{"kind":"header","header":{"format":"redditclone","version":1}}` + "\n"
+
+	for name, body := range map[string]string{
+		"comment without its post": `{"kind":"comment","comment":{"postId":"post-id","id":"comment-id"}}`,
+		"vote of another post":     `{"kind":"post","post":{"id":"first"}}` + "\n" + `{"kind":"vote","vote":{"postId":"second","user":"1","vote":1}}`,
+		"wrong counts":             `{"kind":"end","counts":{"users":1,"posts":0,"comments":0,"votes":0}}`,
+		"broken line":              `{"kind":`,
+	} {
+		r, err := NewReader(strings.NewReader(header + body + "\n"))
+
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", name, err)
+		}
+
+		for err == nil {
+			_, _, err = r.Next()
+		}
+
+		if errors.Is(err, io.EOF) {
+			t.Errorf("%s: expected error, got EOF", name)
+			return
+		}
+	}
+}
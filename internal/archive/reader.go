@@ -0,0 +1,168 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// maxLine bounds a line of the archive, a post with a long text and all of
+// its fields fits easily.
+const maxLine = 16 << 20
+
+type Reader struct {
+	lines  *bufio.Scanner
+	line   int
+	header Header
+	// next is the record read ahead while the comments and the votes of a
+	// post were collected.
+	next   *record
+	counts Counts
+	done   bool
+}
+
+// NewReader reads the header and fails on anything that is not an archive
+// or is an archive of a newer version.
+func NewReader(r io.Reader) (*Reader, error) {
+	lines := bufio.NewScanner(r)
+	lines.Buffer(make([]byte, 0, 64<<10), maxLine)
+	ar := &Reader{lines: lines}
+
+	rec, err := ar.read()
+
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("archive is empty")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rec.Kind != kindHeader || rec.Header == nil || rec.Header.Format != Format {
+		return nil, errors.New("not an archive: the header is missing")
+	}
+
+	if rec.Header.Version < 1 || rec.Header.Version > Version {
+		return nil, fmt.Errorf("archive version %d is not supported, this build reads up to %d", rec.Header.Version, Version)
+	}
+
+	ar.header = *rec.Header
+
+	return ar, nil
+}
+
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Next returns the next user or the next post with its comments and votes,
+// the other one is nil. It returns io.EOF after the end record and
+// io.ErrUnexpectedEOF if the archive ends without one.
+func (r *Reader) Next() (*domain.UserRecord, *domain.Post, error) {
+	if r.done {
+		return nil, nil, io.EOF
+	}
+
+	rec, err := r.pop()
+
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("archive is truncated after line %d: %w", r.line, io.ErrUnexpectedEOF)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case rec.Kind == kindUser && rec.User != nil:
+		r.counts.Users++
+		return rec.User.domain(), nil, nil
+
+	case rec.Kind == kindPost && rec.Post != nil:
+		r.counts.Posts++
+		post, err := r.relations(rec.Post.domain())
+
+		return nil, post, err
+
+	case rec.Kind == kindEnd && rec.Counts != nil:
+		if *rec.Counts != r.counts {
+			return nil, nil, fmt.Errorf("archive counts %+v, but has %+v", *rec.Counts, r.counts)
+		}
+
+		r.done = true
+
+		return nil, nil, io.EOF
+	}
+
+	return nil, nil, fmt.Errorf("line %d: unexpected %q record", r.line, rec.Kind)
+}
+
+// relations collects the comments and the votes that follow the post.
+func (r *Reader) relations(post *domain.Post) (*domain.Post, error) {
+	for {
+		rec, err := r.pop()
+
+		if errors.Is(err, io.EOF) {
+			// Next reports the missing end record.
+			return post, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case rec.Kind == kindComment && rec.Comment != nil && rec.Comment.PostID == post.ID:
+			r.counts.Comments++
+			post.Comments = append(post.Comments, rec.Comment.domain())
+
+		case rec.Kind == kindVote && rec.Vote != nil && rec.Vote.PostID == post.ID:
+			r.counts.Votes++
+			post.Votes = append(post.Votes, &domain.Vote{User: rec.Vote.User, Vote: rec.Vote.Vote})
+
+		case rec.Kind == kindComment || rec.Kind == kindVote:
+			return nil, fmt.Errorf("line %d: %s doesn't follow its post", r.line, rec.Kind)
+
+		default:
+			r.next = rec
+			return post, nil
+		}
+	}
+}
+
+func (r *Reader) pop() (*record, error) {
+	if rec := r.next; rec != nil {
+		r.next = nil
+		return rec, nil
+	}
+
+	return r.read()
+}
+
+func (r *Reader) read() (*record, error) {
+	for r.lines.Scan() {
+		r.line++
+
+		if len(r.lines.Bytes()) == 0 {
+			continue
+		}
+
+		rec := &record{}
+
+		if err := json.Unmarshal(r.lines.Bytes(), rec); err != nil {
+			return nil, fmt.Errorf("line %d: %w", r.line, err)
+		}
+
+		return rec, nil
+	}
+
+	if err := r.lines.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+
+	return nil, io.EOF
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+type Writer struct {
+	buf    *bufio.Writer
+	enc    *json.Encoder
+	counts Counts
+}
+
+// NewWriter writes the header of an archive created at the given moment.
+func NewWriter(w io.Writer, created time.Time) (*Writer, error) {
+	buf := bufio.NewWriter(w)
+	aw := &Writer{buf: buf, enc: json.NewEncoder(buf)}
+
+	if err := aw.write(&record{Kind: kindHeader, Header: &Header{Format: Format, Version: Version, Created: created.UTC()}}); err != nil {
+		return nil, err
+	}
+
+	return aw, nil
+}
+
+func (w *Writer) WriteUser(user *domain.UserRecord) error {
+	w.counts.Users++
+
+	return w.write(&record{Kind: kindUser, User: fromUser(user)})
+}
+
+// WritePost writes the post, then its comments and its votes.
+func (w *Writer) WritePost(post *domain.Post) error {
+	w.counts.Posts++
+
+	if err := w.write(&record{Kind: kindPost, Post: fromPost(post)}); err != nil {
+		return err
+	}
+
+	for _, comment := range post.Comments {
+		w.counts.Comments++
+
+		if err := w.write(&record{Kind: kindComment, Comment: fromComment(post.ID, comment)}); err != nil {
+			return err
+		}
+	}
+
+	for _, vote := range post.Votes {
+		w.counts.Votes++
+
+		if err := w.write(&record{Kind: kindVote, Vote: &Vote{PostID: post.ID, User: vote.User, Vote: vote.Vote}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close writes the end record and flushes the archive. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() (*Counts, error) {
+	counts := w.counts
+
+	if err := w.write(&record{Kind: kindEnd, Counts: &counts}); err != nil {
+		return nil, err
+	}
+
+	if err := w.buf.Flush(); err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// write puts the record on a line of its own, the encoder ends every value
+// with a newline and escapes the ones inside strings.
+func (w *Writer) write(r *record) error {
+	return w.enc.Encode(r)
+}
@@ -0,0 +1,142 @@
+package automod
+
+import (
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Author carries the stats the account conditions are checked against.
+type Author struct {
+	AccountAge time.Duration
+	Karma      int
+}
+
+// Content is a post or a comment about to be checked. Comments leave Title
+// and URL empty.
+type Content struct {
+	Target string
+	Title  string
+	Body   string
+	URL    string
+	Author *Author
+}
+
+type Match struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Flair   string `json:"flair,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// Result is the outcome of a dry run for a single post or comment.
+type Result struct {
+	PostID    string   `json:"postId"`
+	CommentID string   `json:"commentId,omitempty"`
+	Title     string   `json:"title,omitempty"`
+	Matches   []*Match `json:"matches"`
+}
+
+// NeedsAuthor reports whether any rule checks account age or karma, so the
+// caller can skip loading author stats otherwise.
+func (s *RuleSet) NeedsAuthor() bool {
+	for _, rule := range s.Rules {
+		if rule.AccountAgeBelow != nil || rule.KarmaBelow != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *RuleSet) Evaluate(content *Content) []*Match {
+	var matches []*Match
+
+	for _, rule := range s.Rules {
+		if rule.matches(content) {
+			matches = append(matches, &Match{
+				Rule:    rule.Name,
+				Action:  rule.Action,
+				Flair:   rule.Flair,
+				Message: rule.Message,
+			})
+		}
+	}
+
+	return matches
+}
+
+// First returns the first match with the given action or nil.
+func First(matches []*Match, action string) *Match {
+	for _, match := range matches {
+		if match.Action == action {
+			return match
+		}
+	}
+
+	return nil
+}
+
+func (r *Rule) matches(content *Content) bool {
+	if r.Target != TargetAny && r.Target != content.Target {
+		return false
+	}
+
+	if r.title != nil && !r.title.MatchString(content.Title) {
+		return false
+	}
+
+	if r.body != nil && !r.body.MatchString(content.Body) {
+		return false
+	}
+
+	if len(r.Domains) != 0 && !matchDomain(content.URL, r.Domains) {
+		return false
+	}
+
+	if len(r.Keywords) != 0 && !matchKeyword(content.Title+"\n"+content.Body, r.Keywords) {
+		return false
+	}
+
+	if r.AccountAgeBelow != nil && (content.Author == nil || content.Author.AccountAge >= r.AccountAgeBelow.Duration) {
+		return false
+	}
+
+	if r.KarmaBelow != nil && (content.Author == nil || content.Author.Karma >= *r.KarmaBelow) {
+		return false
+	}
+
+	return true
+}
+
+func matchDomain(link string, domains []string) bool {
+	u, err := url.Parse(link)
+
+	if err != nil || u.Hostname() == "" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchKeyword(text string, keywords []string) bool {
+	text = strings.ToLower(text)
+
+	for _, keyword := range keywords {
+		if keyword != "" && strings.Contains(text, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+
+	return false
+}
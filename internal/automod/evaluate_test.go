@@ -0,0 +1,85 @@
+package automod
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	set, err := Parse([]byte(testRules))
+
+	if err != nil {
+		t.Fatalf("can't parse rules: %s", err)
+		return
+	}
+
+	veteran := &Author{AccountAge: 30 * 24 * time.Hour, Karma: 100}
+	newcomer := &Author{AccountAge: time.Hour, Karma: 1}
+
+	cases := []struct {
+		name     string
+		content  *Content
+		expected []string
+	}{
+		{
+			name:     "clean post",
+			content:  &Content{Target: TargetPost, Title: "Weekly thread", Body: "hello", Author: veteran},
+			expected: nil,
+		},
+		{
+			name:     "title regex",
+			content:  &Content{Target: TargetPost, Title: "FREE iPhone inside", Author: veteran},
+			expected: []string{"no-giveaways"},
+		},
+		{
+			name:     "new account comment",
+			content:  &Content{Target: TargetComment, Body: "free money", Author: newcomer},
+			expected: []string{"new-accounts"},
+		},
+		{
+			name:     "subdomain of filtered domain",
+			content:  &Content{Target: TargetPost, Title: "look", URL: "https://m.bit.ly/abc", Author: veteran},
+			expected: []string{"shorteners"},
+		},
+		{
+			name:     "similar domain",
+			content:  &Content{Target: TargetPost, Title: "look", URL: "https://notbit.ly/abc", Author: veteran},
+			expected: nil,
+		},
+		{
+			name:     "keyword in body",
+			content:  &Content{Target: TargetPost, Title: "Go modules", Body: "How do I vendor?", Author: newcomer},
+			expected: []string{"new-accounts", "questions"},
+		},
+		{
+			name:     "unknown author",
+			content:  &Content{Target: TargetPost, Title: "hi"},
+			expected: nil,
+		},
+	}
+
+	for _, c := range cases {
+		var got []string
+
+		for _, match := range set.Evaluate(c.content) {
+			got = append(got, match.Rule)
+		}
+
+		if !reflect.DeepEqual(c.expected, got) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, got)
+			return
+		}
+	}
+
+	matches := set.Evaluate(&Content{Target: TargetPost, Title: "help", Author: veteran})
+
+	if match := First(matches, ActionFlair); match == nil || match.Flair != "Question" {
+		t.Errorf("expected flair match, got %v", match)
+		return
+	}
+
+	if match := First(matches, ActionReject); match != nil {
+		t.Errorf("expected no reject match, got %v", match)
+	}
+}
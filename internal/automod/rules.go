@@ -0,0 +1,176 @@
+// Package automod holds the declarative per-category moderation rules and
+// evaluates them against new posts and comments.
+package automod
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	TargetPost    = "post"
+	TargetComment = "comment"
+	TargetAny     = "any"
+
+	ActionRemove = "remove"
+	ActionReject = "reject"
+	ActionFlair  = "flair"
+	ActionQueue  = "queue"
+)
+
+// Duration reads values like "72h" from both YAML and JSON.
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// Rule matches when every condition it sets holds for the content.
+type Rule struct {
+	Name            string    `json:"name" yaml:"name"`
+	Target          string    `json:"target,omitempty" yaml:"target,omitempty"`
+	TitleRegex      string    `json:"title_regex,omitempty" yaml:"title_regex,omitempty"`
+	BodyRegex       string    `json:"body_regex,omitempty" yaml:"body_regex,omitempty"`
+	Domains         []string  `json:"domains,omitempty" yaml:"domains,omitempty"`
+	Keywords        []string  `json:"keywords,omitempty" yaml:"keywords,omitempty"`
+	AccountAgeBelow *Duration `json:"account_age_below,omitempty" yaml:"account_age_below,omitempty"`
+	KarmaBelow      *int      `json:"karma_below,omitempty" yaml:"karma_below,omitempty"`
+	Action          string    `json:"action" yaml:"action"`
+	Flair           string    `json:"flair,omitempty" yaml:"flair,omitempty"`
+	Message         string    `json:"message,omitempty" yaml:"message,omitempty"`
+
+	title *regexp.Regexp
+	body  *regexp.Regexp
+}
+
+type RuleSet struct {
+	Rules []*Rule `json:"rules" yaml:"rules"`
+}
+
+// Parse reads a rule set written in YAML or JSON and validates it. Every
+// problem found is reported at once in a *domain.ValidationError.
+func Parse(source []byte) (*RuleSet, error) {
+	set := &RuleSet{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(source))
+	decoder.KnownFields(true)
+
+	err := decoder.Decode(set)
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, &domain.ValidationError{Errors: []domain.FieldError{{Param: "source", Msg: err.Error()}}}
+	}
+
+	if err = set.validate(); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+func (s *RuleSet) validate() error {
+	var errs []domain.FieldError
+
+	addErr := func(i int, field, msg string) {
+		errs = append(errs, domain.FieldError{Param: fmt.Sprintf("rules[%d].%s", i, field), Msg: msg})
+	}
+
+	if len(s.Rules) == 0 {
+		errs = append(errs, domain.FieldError{Param: "rules", Msg: "at least one rule is required"})
+	}
+
+	names := make(map[string]bool, len(s.Rules))
+
+	for i, rule := range s.Rules {
+		if rule == nil {
+			addErr(i, "name", "rule is empty")
+			continue
+		}
+
+		if rule.Name == "" {
+			addErr(i, "name", "name is required")
+		} else if names[rule.Name] {
+			addErr(i, "name", "duplicate rule name")
+		}
+		names[rule.Name] = true
+
+		if rule.Target == "" {
+			rule.Target = TargetPost
+		}
+
+		switch rule.Target {
+		case TargetPost, TargetComment, TargetAny:
+		default:
+			addErr(i, "target", "target must be post, comment or any")
+		}
+
+		var err error
+
+		if rule.TitleRegex != "" {
+			if rule.title, err = regexp.Compile(rule.TitleRegex); err != nil {
+				addErr(i, "title_regex", err.Error())
+			}
+		}
+
+		if rule.BodyRegex != "" {
+			if rule.body, err = regexp.Compile(rule.BodyRegex); err != nil {
+				addErr(i, "body_regex", err.Error())
+			}
+		}
+
+		if rule.Target == TargetComment && rule.TitleRegex != "" {
+			addErr(i, "title_regex", "comments have no title")
+		}
+
+		if rule.Target == TargetComment && len(rule.Domains) != 0 {
+			addErr(i, "domains", "comments have no link")
+		}
+
+		if rule.KarmaBelow == nil && rule.AccountAgeBelow == nil && rule.TitleRegex == "" &&
+			rule.BodyRegex == "" && len(rule.Domains) == 0 && len(rule.Keywords) == 0 {
+			addErr(i, "action", "rule has no conditions")
+		}
+
+		switch rule.Action {
+		case ActionRemove, ActionReject, ActionQueue:
+		case ActionFlair:
+			if rule.Flair == "" {
+				addErr(i, "flair", "flair is required for the flair action")
+			}
+
+			if rule.Target != TargetPost {
+				addErr(i, "target", "only posts can be flaired")
+			}
+		case "":
+			addErr(i, "action", "action is required")
+		default:
+			addErr(i, "action", "action must be remove, reject, flair or queue")
+		}
+	}
+
+	if len(errs) != 0 {
+		return &domain.ValidationError{Errors: errs}
+	}
+
+	return nil
+}
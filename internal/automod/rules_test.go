@@ -0,0 +1,138 @@
+package automod
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+const testRules = `
+rules:
+  - name: no-giveaways
+    title_regex: "(?i)free (money|iphone)"
+    action: remove
+    message: giveaways are not allowed
+  - name: new-accounts
+    target: any
+    account_age_below: 72h
+    karma_below: 10
+    action: queue
+  - name: shorteners
+    domains: [bit.ly, tinyurl.com]
+    action: reject
+    message: link shorteners are not allowed
+  - name: questions
+    keywords: [how do i, help]
+    action: flair
+    flair: Question
+`
+
+func TestParse(t *testing.T) {
+	// OK
+	set, err := Parse([]byte(testRules))
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if len(set.Rules) != 4 {
+		t.Errorf("expected 4 rules, got %d", len(set.Rules))
+		return
+	}
+
+	if set.Rules[0].Target != TargetPost {
+		t.Errorf("expected default target %s, got %s", TargetPost, set.Rules[0].Target)
+		return
+	}
+
+	if set.Rules[1].AccountAgeBelow.Duration != 72*time.Hour {
+		t.Errorf("expected 72h, got %s", set.Rules[1].AccountAgeBelow)
+		return
+	}
+
+	if !set.NeedsAuthor() {
+		t.Error("expected rule set to need author stats")
+		return
+	}
+
+	// Pass JSON
+	set, err = Parse([]byte(`{"rules":[{"name":"spam","body_regex":"casino","action":"remove"}]}`))
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if set.NeedsAuthor() {
+		t.Error("expected rule set not to need author stats")
+		return
+	}
+
+	// Pass syntax error
+	_, err = Parse([]byte("rules: [name"))
+	validationErr := &domain.ValidationError{}
+
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected validation error, got %v", err)
+		return
+	}
+
+	// Pass unknown field
+	_, err = Parse([]byte("rules:\n  - name: a\n    titel_regex: x\n    action: remove\n"))
+
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected validation error, got %v", err)
+		return
+	}
+
+	// Pass every invalid field at once
+	_, err = Parse([]byte(`
+rules:
+  - title_regex: "("
+    action: explode
+  - name: flair-comments
+    target: comment
+    keywords: [help]
+    action: flair
+`))
+
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected validation error, got %v", err)
+		return
+	}
+
+	expected := map[string]bool{
+		"rules[0].name":        true,
+		"rules[0].title_regex": true,
+		"rules[0].action":      true,
+		"rules[1].flair":       true,
+		"rules[1].target":      true,
+	}
+
+	for _, fieldErr := range validationErr.Errors {
+		delete(expected, fieldErr.Param)
+	}
+
+	if len(expected) != 0 {
+		t.Errorf("expected errors for %v, got %v", expected, validationErr.Errors)
+		return
+	}
+
+	// Pass empty rule set
+	_, err = Parse([]byte(""))
+
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected validation error, got %v", err)
+		return
+	}
+
+	// Pass rule without conditions
+	_, err = Parse([]byte("rules:\n  - name: all\n    action: remove\n"))
+
+	if !errors.As(err, &validationErr) {
+		t.Errorf("expected validation error, got %v", err)
+	}
+}
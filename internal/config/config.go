@@ -1,39 +1,509 @@
 package config
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
 )
 
-type config struct {
+type Config struct {
 	ServerHost string `mapstructure:"SERVER_HOST"`
-	ServerPort string `mapstructure:"SERVER_PORT"`
+	ServerPort int    `mapstructure:"SERVER_PORT"`
+
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+
+	// Storage is db for Postgres, Mongo and Redis or memory to keep
+	// everything in the process.
+	Storage string `mapstructure:"STORAGE"`
+	// ContentStorage is where the db storage keeps posts, comments,
+	// reports, the mod log and the automod rules: mongo or postgres.
+	ContentStorage string `mapstructure:"CONTENT_STORAGE"`
+
+	OpenAPIValidateResponses bool `mapstructure:"OPENAPI_VALIDATE_RESPONSES"`
+
+	// MigrateOnStart applies the pending schema migrations before the
+	// server reports ready.
+	MigrateOnStart bool `mapstructure:"MIGRATE_ON_START"`
+
+	ReadTimeout     time.Duration `mapstructure:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `mapstructure:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `mapstructure:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ShutdownDelay   time.Duration `mapstructure:"SHUTDOWN_DELAY"`
+	HealthTimeout   time.Duration `mapstructure:"HEALTH_TIMEOUT"`
+
+	// TLSCertFile and TLSKeyFile turn on HTTPS and HTTP/2 on SERVER_PORT.
+	// The files are checked every TLSReloadInterval, a renewed certificate
+	// is served without a restart.
+	TLSCertFile       string        `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `mapstructure:"TLS_KEY_FILE"`
+	TLSReloadInterval time.Duration `mapstructure:"TLS_RELOAD_INTERVAL"`
+	// TLSRedirectPort is a plain HTTP listener that redirects to HTTPS, 0
+	// turns it off.
+	TLSRedirectPort int `mapstructure:"TLS_REDIRECT_PORT"`
+	// HSTSMaxAge is the max-age of Strict-Transport-Security on the HTTPS
+	// responses, 0 turns the header off.
+	HSTSMaxAge            time.Duration `mapstructure:"HSTS_MAX_AGE"`
+	HSTSIncludeSubdomains bool          `mapstructure:"HSTS_INCLUDE_SUBDOMAINS"`
+
+	// SessionTTL is how long a session lives after the login.
+	SessionTTL time.Duration `mapstructure:"SESSION_TTL"`
+	// AuthCookie also keeps the session in an HttpOnly cookie the login
+	// sets. A request is authenticated by the cookie only together with the
+	// X-CSRF-Token header, the HMAC of the session with CSRFKey.
+	AuthCookie bool   `mapstructure:"AUTH_COOKIE"`
+	CSRFKey    Secret `mapstructure:"CSRF_KEY"`
+
+	// CORSOrigins are the origins of the web clients that may call the API
+	// from another site, e.g. https://app.example.com, or * for any.
+	CORSOrigins []string      `mapstructure:"CORS_ORIGINS"`
+	CORSMaxAge  time.Duration `mapstructure:"CORS_MAX_AGE"`
+
+	// ContentSecurityPolicy is sent with every response, the hashes of the
+	// inline scripts of the frontend are added to its script-src.
+	ContentSecurityPolicy string `mapstructure:"CONTENT_SECURITY_POLICY"`
+	FrameOptions          string `mapstructure:"FRAME_OPTIONS"`
+	ReferrerPolicy        string `mapstructure:"REFERRER_POLICY"`
+
+	DBUser         string        `mapstructure:"DB_USER"`
+	DBHost         string        `mapstructure:"DB_HOST"`
+	DBPassword     Secret        `mapstructure:"DB_PASSWORD"`
+	DBName         string        `mapstructure:"DB_NAME"`
+	DBPort         int           `mapstructure:"DB_PORT"`
+	SSLMode        string        `mapstructure:"SSL_MODE"`
+	DBTimeout      time.Duration `mapstructure:"DB_TIMEOUT"`
+	DBMaxOpenConns int           `mapstructure:"DB_MAX_OPEN_CONNS"`
+
+	RedisHost    string        `mapstructure:"R_HOST"`
+	RedisPort    int           `mapstructure:"R_PORT"`
+	RedisTimeout time.Duration `mapstructure:"R_TIMEOUT"`
+
+	MongoHost    string        `mapstructure:"M_HOST"`
+	MongoTimeout time.Duration `mapstructure:"M_TIMEOUT"`
+	// MongoDatabase keeps the posts, the reports, the moderation log and
+	// the automod rules.
+	MongoDatabase string `mapstructure:"M_DATABASE"`
+
+	TraceExporter string `mapstructure:"TRACE_EXPORTER"`
+	TraceEndpoint string `mapstructure:"TRACE_ENDPOINT"`
+	TraceInsecure bool   `mapstructure:"TRACE_INSECURE"`
+
+	RestoreWindow time.Duration `mapstructure:"RESTORE_WINDOW"`
+	PurgeInterval time.Duration `mapstructure:"PURGE_INTERVAL"`
+
+	ArchiveAfterMonths int           `mapstructure:"ARCHIVE_AFTER_MONTHS"`
+	ArchiveInterval    time.Duration `mapstructure:"ARCHIVE_INTERVAL"`
+}
+
+// Secret is a value that is never printed, e.g. by Write or in a log line
+// with %v.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return "[redacted]"
+}
+
+// Default is the config of a key that no source sets.
+func Default() *Config {
+	return &Config{
+		ServerHost:      "0.0.0.0",
+		ServerPort:      8080,
+		LogLevel:        "info",
+		Storage:         "db",
+		ContentStorage:  "mongo",
+		ReadTimeout:     10 * time.Second,
+		WriteTimeout:    30 * time.Second,
+		IdleTimeout:     120 * time.Second,
+		ShutdownTimeout: 20 * time.Second,
+		ShutdownDelay:   5 * time.Second,
+		HealthTimeout:   time.Second,
+		SessionTTL:      9 * time.Hour,
+
+		CORSOrigins: []string{},
+		CORSMaxAge:  10 * time.Minute,
+		// The frontend loads its font from Google Fonts.
+		ContentSecurityPolicy: "default-src 'self'; script-src 'self'; style-src 'self' 'unsafe-inline' https://fonts.googleapis.com; " +
+			"font-src 'self' https://fonts.gstatic.com; img-src 'self' data: https:; connect-src 'self'; object-src 'none'; " +
+			"base-uri 'self'; form-action 'self'; frame-ancestors 'none'",
+		FrameOptions:   "DENY",
+		ReferrerPolicy: "strict-origin-when-cross-origin",
+
+		TLSReloadInterval: time.Minute,
+		HSTSMaxAge:        365 * 24 * time.Hour,
+
+		DBHost:         "localhost",
+		DBName:         "usersdb",
+		DBPort:         5432,
+		SSLMode:        "require",
+		DBTimeout:      3 * time.Second,
+		DBMaxOpenConns: 10,
+
+		RedisHost:    "localhost",
+		RedisPort:    6379,
+		RedisTimeout: time.Second,
+
+		MongoHost:     "localhost",
+		MongoTimeout:  3 * time.Second,
+		MongoDatabase: "post",
+
+		TraceExporter: "none",
+		TraceEndpoint: "localhost:4318",
+
+		RestoreWindow:      720 * time.Hour,
+		PurgeInterval:      time.Hour,
+		ArchiveAfterMonths: 6,
+		ArchiveInterval:    time.Hour,
+	}
+}
+
+const usage = `usage: redditclone [flags] [migrate|ctl|config] [args]
+
+Every key is read from, the later winning: the defaults, the .env file, the
+YAML file of -config or CONFIG_FILE, the environment and the flags below.
+config prints the effective config with the secrets redacted.
+
+`
 
-	DBUser     string `mapstructure:"DB_USER"`
-	DBHost     string `mapstructure:"DB_HOST"`
-	DBPassword string `mapstructure:"DB_PASSWORD"`
-	DBName     string `mapstructure:"DB_NAME"`
-	DBPort     string `mapstructure:"DB_PORT"`
-	SSLMode    string `mapstructure:"SSL_MODE"`
+// Load reads the config from all of its sources and validates it. The
+// .env file may be missing, the YAML file may not. It returns the
+// arguments after the flags, the subcommand and its own arguments.
+func Load(envFile string, args []string) (*Config, []string, error) {
+	v := viper.New()
 
-	RedisHost string `mapstructure:"R_HOST"`
-	RedisPort string `mapstructure:"R_PORT"`
+	flags := flag.NewFlagSet("redditclone", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	yamlFile := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML file with the keys in lower or upper case")
+
+	for _, f := range fields(Default()) {
+		if err := v.BindEnv(f.key); err != nil {
+			return nil, nil, err
+		}
+
+		flags.Var(&setting{v: v, field: f}, strings.ToLower(strings.ReplaceAll(f.key, "_", "-")), "sets "+f.key)
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := os.Stat(envFile); err == nil {
+		v.SetConfigFile(envFile)
+		v.SetConfigType("env")
+
+		if err = v.ReadInConfig(); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", envFile, err)
+		}
+	}
 
-	MongoHost string `mapstructure:"M_HOST"`
+	if *yamlFile != "" {
+		f, err := os.Open(*yamlFile)
+
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+
+		v.SetConfigType("yaml")
+
+		if err = v.MergeConfig(f); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", *yamlFile, err)
+		}
+	}
+
+	// The keys no source sets keep their defaults.
+	cfg := Default()
+
+	if err := errors.Join(decode(v, cfg), cfg.Validate()); err != nil {
+		return nil, nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	return cfg, flags.Args(), nil
 }
 
-func New(filename, path string) (*config, error) {
-	cfg := new(config)
+// decode sets the fields of the keys a source sets. A key no field has is
+// a typo, it is reported with the values that don't parse.
+func decode(v *viper.Viper, cfg *Config) error {
+	var errs []error
+
+	known := map[string]bool{}
 
-	viper.AddConfigPath(path)
-	viper.SetConfigFile(filename)
+	for _, f := range fields(cfg) {
+		known[strings.ToLower(f.key)] = true
 
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, err
+		if !v.IsSet(f.key) {
+			continue
+		}
+
+		if err := f.set(text(v.Get(f.key))); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", f.key, err))
+		}
+	}
+
+	for _, key := range v.AllKeys() {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("%s: unknown key", strings.ToUpper(key)))
+		}
 	}
 
-	if err := viper.Unmarshal(cfg); err != nil {
-		return nil, err
+	return errors.Join(errs...)
+}
+
+// Validate reports every invalid field, not only the first one.
+func (c *Config) Validate() error {
+	var errs []error
+
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+		}
+	}
+
+	oneOf := func(key, value string, allowed ...string) {
+		for _, a := range allowed {
+			if value == a {
+				return
+			}
+		}
+
+		check(false, key, "%q is not one of %s", value, strings.Join(allowed, ", "))
 	}
 
-	return cfg, nil
+	port := func(key string, value int) {
+		check(value > 0 && value < 1<<16, key, "%d is not a port", value)
+	}
+
+	positive := func(key string, value time.Duration) {
+		check(value > 0, key, "must be positive, got %s", value)
+	}
+
+	_, errLevel := zapcore.ParseLevel(c.LogLevel)
+	check(errLevel == nil, "LOG_LEVEL", "%q is not a log level", c.LogLevel)
+
+	port("SERVER_PORT", c.ServerPort)
+	oneOf("STORAGE", c.Storage, "db", "memory")
+	oneOf("CONTENT_STORAGE", c.ContentStorage, "mongo", "postgres")
+	oneOf("TRACE_EXPORTER", c.TraceExporter, "otlp", "stdout", "none")
+
+	positive("SERVER_READ_TIMEOUT", c.ReadTimeout)
+	positive("SERVER_WRITE_TIMEOUT", c.WriteTimeout)
+	positive("SERVER_IDLE_TIMEOUT", c.IdleTimeout)
+	positive("SHUTDOWN_TIMEOUT", c.ShutdownTimeout)
+	positive("HEALTH_TIMEOUT", c.HealthTimeout)
+	positive("SESSION_TTL", c.SessionTTL)
+	positive("PURGE_INTERVAL", c.PurgeInterval)
+	positive("ARCHIVE_INTERVAL", c.ArchiveInterval)
+	check(c.ShutdownDelay >= 0, "SHUTDOWN_DELAY", "must not be negative, got %s", c.ShutdownDelay)
+	check(c.RestoreWindow >= 0, "RESTORE_WINDOW", "must not be negative, got %s", c.RestoreWindow)
+	check(c.ArchiveAfterMonths >= 0, "ARCHIVE_AFTER_MONTHS", "must not be negative, 0 turns archiving off, got %d", c.ArchiveAfterMonths)
+
+	check((c.TLSCertFile == "") == (c.TLSKeyFile == ""), "TLS_KEY_FILE", "TLS_CERT_FILE and TLS_KEY_FILE are set together")
+
+	if c.TLS() {
+		positive("TLS_RELOAD_INTERVAL", c.TLSReloadInterval)
+		check(c.HSTSMaxAge >= 0, "HSTS_MAX_AGE", "must not be negative, 0 turns the header off, got %s", c.HSTSMaxAge)
+	}
+
+	if c.TLSRedirectPort != 0 {
+		port("TLS_REDIRECT_PORT", c.TLSRedirectPort)
+		check(c.TLS(), "TLS_REDIRECT_PORT", "needs TLS_CERT_FILE and TLS_KEY_FILE")
+		check(c.TLSRedirectPort != c.ServerPort, "TLS_REDIRECT_PORT", "is SERVER_PORT")
+	}
+
+	if c.AuthCookie {
+		check(len(c.CSRFKey) >= 32, "CSRF_KEY", "needs at least 32 characters for AUTH_COOKIE")
+	}
+
+	for _, origin := range c.CORSOrigins {
+		check(validOrigin(origin), "CORS_ORIGINS", "%q is not * or scheme://host[:port]", origin)
+		check(origin != "*" || !c.AuthCookie, "CORS_ORIGINS", "* would let any site use the session cookie of AUTH_COOKIE")
+	}
+
+	check(c.CORSMaxAge >= 0, "CORS_MAX_AGE", "must not be negative, got %s", c.CORSMaxAge)
+	oneOf("FRAME_OPTIONS", c.FrameOptions, "DENY", "SAMEORIGIN", "")
+
+	if c.TraceExporter == "otlp" {
+		check(c.TraceEndpoint != "", "TRACE_ENDPOINT", "is required for the otlp exporter")
+	}
+
+	// The memory storage connects to nothing.
+	if c.Storage != "memory" {
+		check(c.DBHost != "", "DB_HOST", "is required")
+		check(c.DBUser != "", "DB_USER", "is required")
+		check(c.DBName != "", "DB_NAME", "is required")
+		port("DB_PORT", c.DBPort)
+		oneOf("SSL_MODE", c.SSLMode, "disable", "require", "verify-ca", "verify-full")
+		positive("DB_TIMEOUT", c.DBTimeout)
+		check(c.DBMaxOpenConns > 0, "DB_MAX_OPEN_CONNS", "must be positive, got %d", c.DBMaxOpenConns)
+
+		check(c.RedisHost != "", "R_HOST", "is required")
+		port("R_PORT", c.RedisPort)
+		positive("R_TIMEOUT", c.RedisTimeout)
+
+	}
+
+	// With the content in Postgres nothing is left in Mongo.
+	if c.Storage != "memory" && c.ContentStorage != "postgres" {
+		check(c.MongoHost != "", "M_HOST", "is required")
+		check(c.MongoDatabase != "", "M_DATABASE", "is required")
+		positive("M_TIMEOUT", c.MongoTimeout)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validOrigin accepts what a browser sends in the Origin header.
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.Fragment == "" && u.User == nil
+}
+
+// TLS reports whether the server speaks HTTPS.
+func (c *Config) TLS() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// Write prints the config in the format of the .env file, the secrets
+// redacted.
+func (c *Config) Write(w io.Writer) error {
+	for _, f := range fields(c) {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", f.key, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type field struct {
+	key   string
+	value reflect.Value
+}
+
+func fields(c *Config) []field {
+	v := reflect.ValueOf(c).Elem()
+	fields := make([]field, 0, v.NumField())
+
+	for i := 0; i < v.NumField(); i++ {
+		fields = append(fields, field{key: v.Type().Field(i).Tag.Get("mapstructure"), value: v.Field(i)})
+	}
+
+	return fields
+}
+
+// String formats the value the way set parses it, a list is comma
+// separated.
+func (f field) String() string {
+	if list, ok := f.value.Interface().([]string); ok {
+		return strings.Join(list, ",")
+	}
+
+	return fmt.Sprint(f.value.Interface())
+}
+
+// text is a value of a source as set parses it, a YAML list becomes comma
+// separated.
+func text(value any) string {
+	if list, ok := value.([]any); ok {
+		items := make([]string, 0, len(list))
+
+		for _, item := range list {
+			items = append(items, fmt.Sprint(item))
+		}
+
+		return strings.Join(items, ",")
+	}
+
+	return fmt.Sprint(value)
+}
+
+func (f field) set(s string) error {
+	if _, ok := f.value.Interface().(time.Duration); ok {
+		d, err := time.ParseDuration(s)
+
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", s)
+		}
+
+		f.value.SetInt(int64(d))
+		return nil
+	}
+
+	switch f.value.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(s)
+
+		if err != nil {
+			return fmt.Errorf("%q is not a number", s)
+		}
+
+		f.value.SetInt(int64(n))
+	case reflect.Slice:
+		list := []string{}
+
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+
+		f.value.Set(reflect.ValueOf(list))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", s)
+		}
+
+		f.value.SetBool(b)
+	default:
+		f.value.SetString(s)
+	}
+
+	return nil
+}
+
+// setting is the flag of a key, it overrides every other source.
+type setting struct {
+	v     *viper.Viper
+	field field
+}
+
+func (s *setting) String() string {
+	if s.v == nil {
+		return ""
+	}
+
+	return s.field.String()
+}
+
+func (s *setting) Set(value string) error {
+	s.v.Set(s.field.key, value)
+	return nil
+}
+
+func (s *setting) IsBoolFlag() bool {
+	return s.field.value.Kind() == reflect.Bool
 }
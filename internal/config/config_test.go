@@ -0,0 +1,169 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	env := writeFile(t, ".env", "DB_USER=reddit\nDB_PASSWORD=secret\nSERVER_PORT=1000\nR_PORT=1000\nM_DATABASE=env\nSESSION_TTL=1h\n")
+	yaml := writeFile(t, "config.yaml", "r_port: 2000\nm_database: yaml\nsession_ttl: 2h\nTRACE_INSECURE: true\ncors_origins:\n  - https://a.example.com\n  - https://b.example.com\n")
+
+	t.Setenv("M_DATABASE", "environment")
+	t.Setenv("SESSION_TTL", "3h")
+
+	cfg, args, err := Load(env, []string{"-config", yaml, "-session-ttl", "4h", "ctl", "reindex"})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// OK, the later source wins and the rest keeps the defaults
+	expected := Default()
+	expected.DBUser, expected.DBPassword = "reddit", "secret"
+	expected.ServerPort, expected.RedisPort, expected.TraceInsecure = 1000, 2000, true
+	expected.MongoDatabase, expected.SessionTTL = "environment", 4*time.Hour
+	expected.CORSOrigins = []string{"https://a.example.com", "https://b.example.com"}
+
+	if !reflect.DeepEqual(cfg, expected) {
+		t.Errorf("expected: %+v, got: %+v", *expected, *cfg)
+		return
+	}
+
+	if strings.Join(args, " ") != "ctl reindex" {
+		t.Errorf("unexpected args: %v", args)
+		return
+	}
+
+	// A missing .env is fine, a missing YAML file is not
+	if _, _, err = Load(filepath.Join(t.TempDir(), ".env"), []string{"-db-user", "reddit"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if _, _, err = Load(env, []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	env := writeFile(t, ".env", "SERVER_PORT=http\nSTORAGE=disk\nDB_TYPO=1\n")
+
+	_, _, err := Load(env, []string{"-db-timeout", "-1s", "-purge-interval", "soon"})
+
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+
+	// Every invalid field is reported at once
+	for _, key := range []string{"SERVER_PORT", "STORAGE", "DB_TYPO", "DB_TIMEOUT", "PURGE_INTERVAL", "DB_USER"} {
+		if !strings.Contains(err.Error(), key+":") {
+			t.Errorf("expected %s in: %s", key, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := Default()
+	cfg.Storage = "memory"
+
+	// The memory storage needs no database
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	cfg.Storage = "db"
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "DB_USER") {
+		t.Errorf("expected DB_USER error, got: %v", err)
+		return
+	}
+
+	// The redirect needs TLS, the certificate needs its key
+	cfg.DBUser, cfg.TLSRedirectPort = "reddit", 8081
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "TLS_REDIRECT_PORT") {
+		t.Errorf("expected TLS_REDIRECT_PORT error, got: %v", err)
+		return
+	}
+
+	cfg.TLSCertFile = "tls.crt"
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "TLS_KEY_FILE") {
+		t.Errorf("expected TLS_KEY_FILE error, got: %v", err)
+		return
+	}
+
+	cfg.TLSKeyFile = "tls.key"
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// Mongo is needed only while the content is kept there
+	cfg.MongoHost = ""
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "M_HOST") {
+		t.Errorf("expected M_HOST error, got: %v", err)
+		return
+	}
+
+	cfg.ContentStorage = "postgres"
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// The cookie needs a key and a closed list of origins
+	cfg.AuthCookie, cfg.CORSOrigins = true, []string{"*", "https://example.com/path"}
+	err := cfg.Validate()
+
+	for _, key := range []string{"CSRF_KEY", "CORS_ORIGINS: * would", "https://example.com/path"} {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("expected %s error, got: %v", key, err)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	cfg := Default()
+	cfg.DBPassword = "secret"
+	buf := &bytes.Buffer{}
+
+	if err := cfg.Write(buf); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The secret shows up neither in Write nor in %v
+	for _, out := range []string{buf.String(), fmt.Sprintf("%v %+v", cfg, *cfg)} {
+		if strings.Contains(out, "secret") {
+			t.Errorf("secret leaked: %s", out)
+			return
+		}
+	}
+
+	if !strings.Contains(buf.String(), "DB_PASSWORD=[redacted]\n") || !strings.Contains(buf.String(), "SESSION_TTL=9h0m0s\n") {
+		t.Errorf("unexpected output: %s", buf)
+	}
+}
@@ -0,0 +1,167 @@
+package rest
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	jsontransfer "github.com/akrovv/redditclone/pkg/jsonTransfer"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+type automodHandler struct {
+	logger         logger.Logger
+	automodService AutomodService
+}
+
+func NewAutomodHandler(logger logger.Logger, automodService AutomodService) *automodHandler {
+	return &automodHandler{logger: logger, automodService: automodService}
+}
+
+func (h automodHandler) log(r *http.Request) logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
+func (h automodHandler) GetRules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	cn, in := vars["CATEGORY_NAME"]
+
+	if !in {
+		h.log(r).Info("category not found")
+		WriteError(w, "category not found", http.StatusNotFound)
+		return
+	}
+
+	rules, err := h.automodService.Get(r.Context(), &service.GetAutomod{Category: cn})
+
+	if err != nil {
+		h.log(r).Infof("can't get automod rules: %v", err)
+		writeDomainError(w, err, "can't get automod rules")
+		return
+	}
+
+	h.writeJSON(w, r, rules)
+}
+
+// SaveRules takes the rule set as the raw request body, YAML or JSON.
+func (h automodHandler) SaveRules(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	cn, in := vars["CATEGORY_NAME"]
+
+	if !in {
+		h.log(r).Info("category not found")
+		WriteError(w, "category not found", http.StatusNotFound)
+		return
+	}
+
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	rules, err := h.automodService.Save(r.Context(), &service.SaveAutomod{User: user, Category: cn, Source: string(data)})
+
+	if err != nil {
+		h.log(r).Infof("can't save automod rules: %v", err)
+		writeDomainError(w, err, "can't save automod rules")
+		return
+	}
+
+	h.writeJSON(w, r, rules)
+}
+
+func (h automodHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
+		return
+	}
+
+	set, err := h.automodService.Validate(r.Context(), &service.ValidateAutomod{Source: string(data)})
+
+	if err != nil {
+		h.log(r).Infof("invalid automod rules: %v", err)
+		writeDomainError(w, err, "can't validate automod rules")
+		return
+	}
+
+	h.writeJSON(w, r, set)
+}
+
+// DryRun checks the posted rule set, or the stored one when the body is
+// empty, against the posts of the category.
+func (h automodHandler) DryRun(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
+		return
+	}
+
+	vars := mux.Vars(r)
+
+	cn, in := vars["CATEGORY_NAME"]
+
+	if !in {
+		h.log(r).Info("category not found")
+		WriteError(w, "category not found", http.StatusNotFound)
+		return
+	}
+
+	results, err := h.automodService.DryRun(r.Context(), &service.DryRunAutomod{Category: cn, Source: string(data)})
+
+	if err != nil {
+		h.log(r).Infof("can't run automod rules: %v", err)
+		writeDomainError(w, err, "can't run automod rules")
+		return
+	}
+
+	h.writeJSON(w, r, results)
+}
+
+func (h automodHandler) writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	data, err := jsontransfer.GetJSON(v)
+
+	if err != nil {
+		h.log(r).Infof("can't marshall to json: %v", err)
+		WriteError(w, "can't marshall to json", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	_, err = w.Write(data)
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,268 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/automod"
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/internal/service/mocks"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+const testAutomodRules = "rules:\n  - name: spam\n    body_regex: casino\n    action: remove\n"
+
+func TestGetAutomodRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aSrv := mocks.NewMockAutomodService(ctrl)
+	automodHandler := NewAutomodHandler(logger.NewLogger(), aSrv)
+
+	vars := map[string]string{"CATEGORY_NAME": "music"}
+	getDto := &service.GetAutomod{Category: "music"}
+
+	// OK
+	w, req := getRequestRecorder(context.TODO(), false, "GET", "/api/mod/{CATEGORY_NAME}/automod", "")
+	req = mux.SetURLVars(req, vars)
+
+	aSrv.EXPECT().Get(gomock.Any(), getDto).Return(&domain.AutomodRules{Category: "music", Source: testAutomodRules}, nil)
+
+	automodHandler.GetRules(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Pass CATEGORY_NAME
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/api/mod/{CATEGORY_NAME}/automod", "")
+
+	automodHandler.GetRules(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Method's Get returns error
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/api/mod/{CATEGORY_NAME}/automod", "")
+	req = mux.SetURLVars(req, vars)
+
+	aSrv.EXPECT().Get(gomock.Any(), getDto).Return(nil, domain.NotFound("automod rules not found"))
+
+	automodHandler.GetRules(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Writter returns error
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/api/mod/{CATEGORY_NAME}/automod", "")
+	req = mux.SetURLVars(req, vars)
+
+	aSrv.EXPECT().Get(gomock.Any(), getDto).Return(&domain.AutomodRules{}, nil)
+
+	automodHandler.GetRules(&BadResponseWriter{ResponseWriter: w}, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestSaveAutomodRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aSrv := mocks.NewMockAutomodService(ctrl)
+	automodHandler := NewAutomodHandler(logger.NewLogger(), aSrv)
+
+	var sess domain.SessionContextKey = "session"
+	user, _ := getTestData()
+	ctx := context.WithValue(context.TODO(), sess, &domain.Session{User: user})
+
+	vars := map[string]string{"CATEGORY_NAME": "music"}
+	saveDto := &service.SaveAutomod{User: user, Category: "music", Source: testAutomodRules}
+
+	// OK
+	w, req := getRequestRecorder(ctx, true, "PUT", "/api/mod/{CATEGORY_NAME}/automod", testAutomodRules)
+	req = mux.SetURLVars(req, vars)
+
+	aSrv.EXPECT().Save(gomock.Any(), saveDto).Return(&domain.AutomodRules{Category: "music", Source: testAutomodRules}, nil)
+
+	automodHandler.SaveRules(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// ReadAll returns error
+	req = httptest.NewRequest("PUT", "/api/mod/{CATEGORY_NAME}/automod", &BadReader{})
+	w = httptest.NewRecorder()
+
+	automodHandler.SaveRules(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Pass CATEGORY_NAME
+	w, req = getRequestRecorder(ctx, true, "PUT", "/api/mod/{CATEGORY_NAME}/automod", testAutomodRules)
+
+	automodHandler.SaveRules(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Pass session
+	w, req = getRequestRecorder(ctx, false, "PUT", "/api/mod/{CATEGORY_NAME}/automod", testAutomodRules)
+	req = mux.SetURLVars(req, vars)
+
+	automodHandler.SaveRules(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Method's Save returns validation error
+	w, req = getRequestRecorder(ctx, true, "PUT", "/api/mod/{CATEGORY_NAME}/automod", testAutomodRules)
+	req = mux.SetURLVars(req, vars)
+
+	validationErr := &domain.ValidationError{Errors: []domain.FieldError{{Param: "rules[0].action", Msg: "action is required"}}}
+	aSrv.EXPECT().Save(gomock.Any(), saveDto).Return(nil, validationErr)
+
+	automodHandler.SaveRules(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got: %d", w.Code)
+		return
+	}
+
+	body := &struct {
+		Errors []domain.FieldError `json:"errors"`
+	}{}
+
+	if err := json.Unmarshal(w.Body.Bytes(), body); err != nil || len(body.Errors) != 1 {
+		t.Errorf("expected field errors, got: %s", w.Body.String())
+		return
+	}
+
+	// Method's Save returns error
+	w, req = getRequestRecorder(ctx, true, "PUT", "/api/mod/{CATEGORY_NAME}/automod", testAutomodRules)
+	req = mux.SetURLVars(req, vars)
+
+	aSrv.EXPECT().Save(gomock.Any(), saveDto).Return(nil, errors.New("some error"))
+
+	automodHandler.SaveRules(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestValidateAutomodRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aSrv := mocks.NewMockAutomodService(ctrl)
+	automodHandler := NewAutomodHandler(logger.NewLogger(), aSrv)
+
+	validateDto := &service.ValidateAutomod{Source: testAutomodRules}
+
+	// OK
+	w, req := getRequestRecorder(context.TODO(), false, "POST", "/api/mod/automod/validate", testAutomodRules)
+
+	aSrv.EXPECT().Validate(gomock.Any(), validateDto).Return(&automod.RuleSet{}, nil)
+
+	automodHandler.Validate(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// ReadAll returns error
+	req = httptest.NewRequest("POST", "/api/mod/automod/validate", &BadReader{})
+	w = httptest.NewRecorder()
+
+	automodHandler.Validate(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Method's Validate returns validation error
+	w, req = getRequestRecorder(context.TODO(), false, "POST", "/api/mod/automod/validate", testAutomodRules)
+
+	aSrv.EXPECT().Validate(gomock.Any(), validateDto).Return(nil, &domain.ValidationError{})
+
+	automodHandler.Validate(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got: %d", w.Code)
+		return
+	}
+}
+
+func TestDryRunAutomodRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	aSrv := mocks.NewMockAutomodService(ctrl)
+	automodHandler := NewAutomodHandler(logger.NewLogger(), aSrv)
+
+	vars := map[string]string{"CATEGORY_NAME": "music"}
+	dryRunDto := &service.DryRunAutomod{Category: "music", Source: testAutomodRules}
+
+	// OK
+	w, req := getRequestRecorder(context.TODO(), false, "POST", "/api/mod/{CATEGORY_NAME}/automod/dryrun", testAutomodRules)
+	req = mux.SetURLVars(req, vars)
+
+	aSrv.EXPECT().DryRun(gomock.Any(), dryRunDto).Return([]*automod.Result{{PostID: "1"}}, nil)
+
+	automodHandler.DryRun(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Pass CATEGORY_NAME
+	w, req = getRequestRecorder(context.TODO(), false, "POST", "/api/mod/{CATEGORY_NAME}/automod/dryrun", testAutomodRules)
+
+	automodHandler.DryRun(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Method's DryRun returns error
+	w, req = getRequestRecorder(context.TODO(), false, "POST", "/api/mod/{CATEGORY_NAME}/automod/dryrun", testAutomodRules)
+	req = mux.SetURLVars(req, vars)
+
+	aSrv.EXPECT().DryRun(gomock.Any(), dryRunDto).Return(nil, errors.New("some error"))
+
+	automodHandler.DryRun(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
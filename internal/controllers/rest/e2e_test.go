@@ -0,0 +1,278 @@
+package rest_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/api"
+	"github.com/akrovv/redditclone/internal/adapters/memory"
+	"github.com/akrovv/redditclone/internal/controllers/rest"
+	"github.com/akrovv/redditclone/internal/controllers/rest/middleware"
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/casbin/casbin"
+)
+
+// newTestServer serves the API the way main does, on the memory storages.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	db := memory.New()
+	posts := memory.NewPostStorage(db)
+	comments := memory.NewCommentStorage(db)
+	users := memory.NewUserStorage(db)
+	reports := memory.NewReportStorage(db)
+	modLog := memory.NewModLogStorage(db)
+
+	policy := service.ContentPolicy{RestoreWindow: 720 * time.Hour, ArchiveAfterMonths: 6}
+	automodService := service.NewAutomodService(memory.NewAutomodStorage(db), posts, users, reports, modLog)
+	postService := service.NewPostService(posts, modLog, policy, automodService)
+	commentService := service.NewCommentService(comments, posts, modLog, policy, automodService)
+	userService := service.NewUserService(users, modLog)
+	sessionService := service.NewSessionService(memory.NewSessionStorage(db, 9*time.Hour))
+	reportService := service.NewReportService(reports, posts, comments, modLog)
+	modLogService := service.NewModLogService(modLog)
+
+	l := logger.NewNop()
+	spec, err := api.Load(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	openAPIHandler, err := rest.NewOpenAPIHandler(l, spec)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := casbin.NewEnforcerSafe("../../../basic_model.conf", "../../../basic_policy.csv")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	routes := rest.APIRoutes(&rest.Handlers{
+		Users:      rest.NewUserHandler(l, userService, sessionService),
+		Posts:      rest.NewPostHandler(l, postService, commentService, sessionService),
+		Moderation: rest.NewModerationHandler(l, reportService, modLogService, postService),
+		Automod:    rest.NewAutomodHandler(l, automodService),
+		OpenAPI:    openAPIHandler,
+		V2:         rest.NewV2Handler(l, userService, sessionService, postService, commentService, reportService, modLogService),
+	})
+
+	router := rest.NewRouter(rest.NewRootHandler(l), routes)
+
+	var handler http.Handler = router
+	handler = middleware.OpenAPI(handler, router, spec, l, true)
+	handler = middleware.Permissions(handler, l, e)
+	handler = middleware.Auth(handler, sessionService)
+	handler = middleware.LegacyAPI(handler)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+type client struct {
+	t      *testing.T
+	server *httptest.Server
+	token  string
+}
+
+// do sends the request and decodes the JSON answer into out, if given.
+func (c *client) do(method, path, body string, out any) *http.Response {
+	c.t.Helper()
+
+	req, err := http.NewRequest(method, c.server.URL+path, strings.NewReader(body))
+
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	if body != "" {
+		req.Header.Set("Content-type", "application/json")
+	}
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.server.Client().Do(req)
+
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+
+	if err != nil {
+		c.t.Fatal(err)
+	}
+
+	if out != nil && len(data) > 0 {
+		if err = json.Unmarshal(data, out); err != nil {
+			c.t.Fatalf("%s %s: can't decode %q: %v", method, path, data, err)
+		}
+	}
+
+	return resp
+}
+
+func expectStatus(t *testing.T, resp *http.Response, status int) {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: expected %d, got: %d", resp.Request.Method, resp.Request.URL.Path, status, resp.StatusCode)
+	}
+}
+
+func TestE2EFrontendFlow(t *testing.T) {
+	c := &client{t: t, server: newTestServer(t)}
+	token := struct {
+		Token string `json:"token"`
+	}{}
+
+	// Register, the way the frontend does
+	resp := c.do("POST", "/api/register", `{"username": "akro", "password": "akroakroakro"}`, &token)
+	expectStatus(t, resp, http.StatusOK)
+
+	if resp.Header.Get("Deprecation") != "true" {
+		t.Fatalf("expected v1 to be deprecated")
+	}
+
+	resp = c.do("POST", "/api/register", `{"username": "akro", "password": "akroakroakro"}`, nil)
+	expectStatus(t, resp, http.StatusConflict)
+
+	// Creating a post needs a session
+	body := `{"type": "text", "category": "music", "title": "first", "text": "hello", "url": ""}`
+	resp = c.do("POST", "/api/posts", body, nil)
+	expectStatus(t, resp, http.StatusForbidden)
+
+	c.token = token.Token
+	post := &domain.Post{}
+	resp = c.do("POST", "/api/posts", body, post)
+	expectStatus(t, resp, http.StatusOK)
+
+	if post.ID == "" || post.Score != 1 || post.Author.Username != "akro" {
+		t.Fatalf("unexpected post: %+v", post)
+	}
+
+	// Comment and vote
+	resp = c.do("POST", "/api/post/"+post.ID, `{"comment": "nice"}`, post)
+	expectStatus(t, resp, http.StatusOK)
+
+	resp = c.do("GET", "/api/post/"+post.ID+"/downvote", "", post)
+	expectStatus(t, resp, http.StatusOK)
+
+	if post.Score != -1 || len(post.Comments) != 1 {
+		t.Fatalf("expected score -1 and one comment, got: %d and %d", post.Score, len(post.Comments))
+	}
+
+	// Lists, with and without the version
+	posts := []*domain.Post{}
+
+	for _, path := range []string{"/api/posts/", "/api/v1/posts/music", "/api/user/akro"} {
+		resp = c.do("GET", path, "", &posts)
+		expectStatus(t, resp, http.StatusOK)
+
+		if len(posts) != 1 || posts[0].ID != post.ID {
+			t.Fatalf("%s: expected the post, got: %d posts", path, len(posts))
+		}
+	}
+
+	// Login with a bad password
+	c.token = ""
+	resp = c.do("POST", "/api/login", `{"username": "akro", "password": "wrongwrong"}`, nil)
+	expectStatus(t, resp, http.StatusUnauthorized)
+}
+
+func TestE2EV2(t *testing.T) {
+	c := &client{t: t, server: newTestServer(t)}
+	token := struct {
+		Token string `json:"token"`
+	}{}
+
+	// Register and log in
+	resp := c.do("POST", "/api/v2/users", `{"username": "akro", "password": "akroakroakro"}`, nil)
+	expectStatus(t, resp, http.StatusCreated)
+
+	resp = c.do("POST", "/api/v2/sessions", `{"username": "akro", "password": "akroakroakro"}`, &token)
+	expectStatus(t, resp, http.StatusCreated)
+
+	if resp.Header.Get("Deprecation") != "" {
+		t.Fatalf("expected v2 not to be deprecated")
+	}
+
+	c.token = token.Token
+
+	// Create
+	post := &domain.Post{}
+	resp = c.do("POST", "/api/v2/posts", `{"type": "link", "category": "news", "title": "link", "url": "https://example.com"}`, post)
+	expectStatus(t, resp, http.StatusCreated)
+
+	if resp.Header.Get("Location") != "/api/v2/posts/"+post.ID {
+		t.Fatalf("unexpected location: %s", resp.Header.Get("Location"))
+	}
+
+	resp = c.do("POST", "/api/v2/posts", `{"type": "link", "category": "news", "title": "link"}`, nil)
+	expectStatus(t, resp, http.StatusUnprocessableEntity)
+
+	// Vote
+	resp = c.do("POST", "/api/v2/posts/"+post.ID+"/votes", `{"vote": 1}`, nil)
+	expectStatus(t, resp, http.StatusConflict)
+
+	resp = c.do("DELETE", "/api/v2/posts/"+post.ID+"/votes", "", nil)
+	expectStatus(t, resp, http.StatusNoContent)
+
+	resp = c.do("POST", "/api/v2/posts/"+post.ID+"/votes", `{"vote": 3}`, nil)
+	expectStatus(t, resp, http.StatusUnprocessableEntity)
+
+	// Page
+	page := &rest.Page[*domain.Post]{}
+	resp = c.do("GET", "/api/v2/posts?category=news&limit=10", "", page)
+	expectStatus(t, resp, http.StatusOK)
+
+	if len(page.Items) != 1 || page.Items[0].Score != 0 || *page.Total != 1 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	// Members can't moderate
+	resp = c.do("GET", "/api/v2/mod/log", "", nil)
+	expectStatus(t, resp, http.StatusForbidden)
+
+	// Report, delete and restore
+	resp = c.do("POST", "/api/v2/posts/"+post.ID+"/reports", `{"reason": "spam"}`, nil)
+	expectStatus(t, resp, http.StatusCreated)
+
+	resp = c.do("DELETE", "/api/v2/posts/"+post.ID, "", nil)
+	expectStatus(t, resp, http.StatusNoContent)
+
+	resp = c.do("GET", "/api/v2/posts/"+post.ID, "", nil)
+	expectStatus(t, resp, http.StatusNotFound)
+
+	resp = c.do("POST", "/api/v2/posts/"+post.ID+"/restore", "", post)
+	expectStatus(t, resp, http.StatusOK)
+
+	// Without the session, the answer shows the views before this one
+	c.token = ""
+
+	for i := 0; i < 2; i++ {
+		resp = c.do("GET", "/api/v2/posts/"+post.ID, "", post)
+		expectStatus(t, resp, http.StatusOK)
+	}
+
+	if post.Views != 2 {
+		t.Fatalf("expected 2 views, got: %d", post.Views)
+	}
+}
@@ -0,0 +1,78 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// FieldError points at the invalid part of a request. The frontend shows
+// it as "param msg".
+type FieldError = domain.FieldError
+
+// ErrorResponse is the body of every error answer.
+type ErrorResponse struct {
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// WriteError answers with the JSON error envelope. It replaces http.Error,
+// whose plain text body the frontend can't read.
+func WriteError(w http.ResponseWriter, message string, code int) {
+	WriteErrorResponse(w, &ErrorResponse{Message: message}, code)
+}
+
+// WriteErrorResponse answers with a prepared envelope, e.g. one with field
+// errors.
+func WriteErrorResponse(w http.ResponseWriter, resp *ErrorResponse, code int) {
+	w.Header().Set("Content-type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+
+	// The status is already sent, a failed write can't be reported anyway.
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// statusFor maps the domain error kinds to HTTP statuses. Anything else is an
+// internal error.
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrAlreadyExists),
+		errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrValidation):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// errorMessage shows domain errors to the client as is and hides everything
+// else behind the fallback text.
+func errorMessage(err error, fallback string) string {
+	if statusFor(err) == http.StatusInternalServerError {
+		return fallback
+	}
+
+	return err.Error()
+}
+
+// writeDomainError answers with the status and message of a domain error.
+// Validation errors carry their invalid fields along.
+func writeDomainError(w http.ResponseWriter, err error, fallback string) {
+	validationErr := &domain.ValidationError{}
+
+	if errors.As(err, &validationErr) {
+		resp := &ErrorResponse{Message: domain.ErrValidation.Error(), Errors: validationErr.Errors}
+		WriteErrorResponse(w, resp, http.StatusUnprocessableEntity)
+		return
+	}
+
+	WriteError(w, errorMessage(err, fallback), statusFor(err))
+}
@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestStatusFor(t *testing.T) {
+	cases := []struct {
+		err  error
+		code int
+	}{
+		{domain.NotFound("post not found"), http.StatusNotFound},
+		{domain.AlreadyExists("user already exists"), http.StatusConflict},
+		{domain.Conflict("report already resolved"), http.StatusConflict},
+		{domain.ErrForbidden, http.StatusForbidden},
+		{domain.Validation("unknown vote"), http.StatusUnprocessableEntity},
+		{domain.ErrPostLocked, http.StatusForbidden},
+		{domain.ErrRestoreWindowClosed, http.StatusConflict},
+		{fmt.Errorf("%w: spam", domain.ErrAutomodRejected), http.StatusForbidden},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		if code := statusFor(c.err); code != c.code {
+			t.Errorf("%v: expected %d, got: %d", c.err, c.code, code)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	// Domain error is shown as is
+	w := httptest.NewRecorder()
+	writeDomainError(w, domain.NotFound("post not found"), "can't get post")
+
+	resp := &ErrorResponse{}
+
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		t.Errorf("can't unmarshall response: %s", err)
+		return
+	}
+
+	if w.Code != http.StatusNotFound || resp.Message != "post not found" {
+		t.Errorf("unexpected response: %d %s", w.Code, w.Body.String())
+		return
+	}
+
+	if w.Header().Get("Content-type") != contentType {
+		t.Errorf("expected json content type, got: %s", w.Header().Get("Content-type"))
+		return
+	}
+
+	// Internal error is hidden
+	w = httptest.NewRecorder()
+	writeDomainError(w, errors.New("dial tcp: connection refused"), "can't get post")
+
+	if err := json.Unmarshal(w.Body.Bytes(), resp); err != nil || resp.Message != "can't get post" {
+		t.Errorf("expected fallback message, got: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,129 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	jsontransfer "github.com/akrovv/redditclone/pkg/jsonTransfer"
+	"github.com/akrovv/redditclone/pkg/logger"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+)
+
+// DependencyCheck pings one external dependency, e.g. a database client.
+type DependencyCheck func(ctx context.Context) error
+
+type dependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type readiness struct {
+	Status       string                       `json:"status"`
+	Reason       string                       `json:"reason,omitempty"`
+	Dependencies map[string]*dependencyStatus `json:"dependencies,omitempty"`
+}
+
+type healthHandler struct {
+	logger  logger.Logger
+	timeout time.Duration
+	checks  map[string]DependencyCheck
+	ready   atomic.Bool
+	reason  atomic.Value
+}
+
+// NewHealthHandler starts out not ready: the server reports ready only after
+// startup is done and SetReady is called.
+func NewHealthHandler(logger logger.Logger, timeout time.Duration, checks map[string]DependencyCheck) *healthHandler {
+	h := &healthHandler{logger: logger, timeout: timeout, checks: checks}
+	h.reason.Store("starting")
+
+	return h
+}
+
+// SetReady switches readiness. reason is reported while the server is not
+// ready, e.g. "migrating" or "draining".
+func (h *healthHandler) SetReady(ready bool, reason string) {
+	h.reason.Store(reason)
+	h.ready.Store(ready)
+}
+
+// Live only tells that the process is up and serving.
+func (h *healthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	h.write(w, http.StatusOK, &readiness{Status: statusOK})
+}
+
+func (h *healthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if !h.ready.Load() {
+		h.write(w, http.StatusServiceUnavailable, &readiness{Status: statusUnavailable, Reason: h.reason.Load().(string)})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	result := &readiness{Status: statusOK, Dependencies: make(map[string]*dependencyStatus, len(h.checks))}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for name, check := range h.checks {
+		wg.Add(1)
+
+		go func(name string, check DependencyCheck) {
+			defer wg.Done()
+
+			status := &dependencyStatus{Status: statusOK}
+
+			if err := check(ctx); err != nil {
+				status.Status, status.Error = statusUnavailable, err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			result.Dependencies[name] = status
+
+			if status.Status != statusOK {
+				result.Status = statusUnavailable
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	code := http.StatusOK
+
+	if result.Status != statusOK {
+		h.logger.Warnf("readiness check failed: %v", result.Dependencies)
+		code = http.StatusServiceUnavailable
+	}
+
+	h.write(w, code, result)
+}
+
+func (h *healthHandler) write(w http.ResponseWriter, code int, v any) {
+	data, err := jsontransfer.GetJSON(v)
+
+	if err != nil {
+		h.logger.Infof("can't marshall health to json: %v", err)
+		WriteError(w, "can't marshall health to json", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	if _, err = w.Write(data); err != nil {
+		h.logger.Infof("server can't write: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/pkg/logger"
+)
+
+func TestLive(t *testing.T) {
+	healthHandler := NewHealthHandler(logger.NewLogger(), time.Second, nil)
+
+	// OK
+	w, req := getRequestRecorder(context.TODO(), false, "GET", "/healthz", "")
+	healthHandler.Live(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+	}
+}
+
+func TestReady(t *testing.T) {
+	var failMongo bool
+
+	checks := map[string]DependencyCheck{
+		"postgres": func(ctx context.Context) error { return nil },
+		"mongo": func(ctx context.Context) error {
+			if failMongo {
+				return errors.New("connection refused")
+			}
+
+			return nil
+		},
+		"redis": func(ctx context.Context) error { return nil },
+	}
+
+	healthHandler := NewHealthHandler(logger.NewLogger(), 20*time.Millisecond, checks)
+
+	// Still starting
+	w, req := getRequestRecorder(context.TODO(), false, "GET", "/readyz", "")
+	healthHandler.Ready(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got: %d", w.Code)
+		return
+	}
+
+	// OK
+	healthHandler.SetReady(true, "")
+
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/readyz", "")
+	healthHandler.Ready(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Dependency is down
+	failMongo = true
+
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/readyz", "")
+	healthHandler.Ready(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got: %d", w.Code)
+		return
+	}
+
+	result := &readiness{}
+
+	if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+		t.Errorf("can't unmarshall response: %s", err)
+		return
+	}
+
+	if result.Dependencies["mongo"].Status != statusUnavailable || result.Dependencies["postgres"].Status != statusOK {
+		t.Errorf("unexpected dependencies: %s", w.Body.String())
+		return
+	}
+
+	// Dependency hangs longer than the timeout
+	failMongo = false
+	checks["redis"] = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/readyz", "")
+	healthHandler.Ready(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got: %d", w.Code)
+		return
+	}
+
+	// Draining
+	healthHandler.SetReady(false, "draining")
+
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/readyz", "")
+	healthHandler.Ready(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got: %d", w.Code)
+		return
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), result); err != nil || result.Reason != "draining" {
+		t.Errorf("expected draining reason, got: %s", w.Body.String())
+	}
+}
@@ -1,31 +1,55 @@
 package rest
 
 import (
+	"context"
+	"github.com/akrovv/redditclone/internal/automod"
 	"github.com/akrovv/redditclone/internal/domain"
 	"github.com/akrovv/redditclone/internal/service"
 )
 
 type UserService interface {
-	Get(dto *service.GetUser) (*domain.User, error)
-	Save(dto *service.SaveUser) (*domain.User, error)
+	Get(ctx context.Context, dto *service.GetUser) (*domain.User, error)
+	Save(ctx context.Context, dto *service.SaveUser) (*domain.User, error)
 }
 
 type PostService interface {
-	Save(post *domain.Post) (*domain.Post, error)
-	Get() ([]*domain.Post, error)
-	GetOne(dto *service.GetOnePost) (*domain.Post, error)
-	GetBy(dto *service.GetByPost) ([]*domain.Post, error)
-	Delete(dto *service.DeletePost) error
-	IncrViews(dto *service.IncrViewsPost) error
-	UpdateMetrics(dto *service.UpdateMetricsPost) error
+	Save(ctx context.Context, dto *service.SavePost) (*domain.Post, error)
+	Get(ctx context.Context) ([]*domain.Post, error)
+	GetOne(ctx context.Context, dto *service.GetOnePost) (*domain.Post, error)
+	GetBy(ctx context.Context, dto *service.GetByPost) ([]*domain.Post, error)
+	Delete(ctx context.Context, dto *service.DeletePost) error
+	IncrViews(ctx context.Context, dto *service.IncrViewsPost) error
+	UpdateMetrics(ctx context.Context, dto *service.UpdateMetricsPost) error
+	Restore(ctx context.Context, dto *service.RestorePost) error
+	Lock(ctx context.Context, dto *service.LockPost) error
+	Sticky(ctx context.Context, dto *service.StickyPost) error
 }
 
 type CommentService interface {
-	Add(dto *service.AddComment) error
-	Delete(dto *service.DeleteComment) error
+	Add(ctx context.Context, dto *service.AddComment) error
+	Delete(ctx context.Context, dto *service.DeleteComment) error
+	Restore(ctx context.Context, dto *service.RestoreComment) error
 }
 
 type SessionService interface {
-	Create(dto *service.CreateSession) (*domain.Session, error)
-	Get(dto *service.GetSession) (*domain.User, error)
+	Create(ctx context.Context, dto *service.CreateSession) (*domain.Session, error)
+	Get(ctx context.Context, dto *service.GetSession) (*domain.User, error)
+}
+
+type ReportService interface {
+	Report(ctx context.Context, dto *service.ReportContent) (*domain.Report, error)
+	Queue(ctx context.Context, dto *service.GetQueue) ([]*domain.Report, error)
+	Approve(ctx context.Context, dto *service.ResolveReport) error
+	Remove(ctx context.Context, dto *service.ResolveReport) error
+}
+
+type ModLogService interface {
+	Get(ctx context.Context, dto *service.GetModLog) ([]*domain.ModLogEntry, error)
+}
+
+type AutomodService interface {
+	Get(ctx context.Context, dto *service.GetAutomod) (*domain.AutomodRules, error)
+	Save(ctx context.Context, dto *service.SaveAutomod) (*domain.AutomodRules, error)
+	Validate(ctx context.Context, dto *service.ValidateAutomod) (*automod.RuleSet, error)
+	DryRun(ctx context.Context, dto *service.DryRunAutomod) ([]*automod.Result, error)
 }
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/akrovv/redditclone/internal/controllers/rest"
 	"github.com/akrovv/redditclone/internal/domain"
 	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/pkg/logger"
 )
 
 func Auth(next http.Handler, au rest.SessionService) http.Handler {
@@ -20,19 +22,20 @@ func Auth(next http.Handler, au rest.SessionService) http.Handler {
 
 			authorization := strings.TrimPrefix(header, "Bearer ")
 			getSessionDto := &service.GetSession{Key: authorization}
-			user, err := au.Get(getSessionDto)
+			user, err := au.Get(r.Context(), getSessionDto)
 
-			if err != nil {
-				http.Redirect(w, r, "/", http.StatusInternalServerError)
+			if errors.Is(err, domain.ErrNotFound) || (err == nil && user == nil) {
+				rest.WriteError(w, "unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			if user == nil && err == nil {
-				http.Redirect(w, r, "/", http.StatusNotFound)
+			if err != nil {
+				logger.FromContext(r.Context(), logger.NewNop()).Errorf("can't get session: %v", err)
+				rest.WriteError(w, "can't check session", http.StatusInternalServerError)
 				return
 			}
 
-			ctx := r.Context()
+			ctx := setUser(r.Context(), user.ID)
 
 			ctx = context.WithValue(ctx, sess, &domain.Session{User: user})
 
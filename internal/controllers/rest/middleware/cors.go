@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/controllers/rest"
+)
+
+const (
+	corsMethods = "GET, POST, PUT, PATCH, DELETE"
+	corsHeaders = "Authorization, Content-Type, " + RequestIDHeader + ", " + CSRFHeader
+	// corsExposed are the headers of the answers a client on another
+	// origin may read.
+	corsExposed = RequestIDHeader + ", Deprecation, Link, Location"
+)
+
+// CORSPolicy says which web clients on other origins may call the API.
+type CORSPolicy struct {
+	// Origins are the allowed origins, * allows any.
+	Origins []string
+	// Credentials lets the browser send the cookies along, the session
+	// cookie of the cookie auth mode.
+	Credentials bool
+	// MaxAge is how long the browser may cache a preflight answer.
+	MaxAge time.Duration
+}
+
+// CORS answers the preflight requests and marks the answers an allowed
+// origin may read. Another origin gets no CORS headers, so the browser
+// keeps the answer from it; its preflight is refused outright.
+func CORS(next http.Handler, policy CORSPolicy) http.Handler {
+	maxAge := strconv.Itoa(int(policy.MaxAge.Seconds()))
+	anyOrigin := slices.Contains(policy.Origins, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		allowed := anyOrigin || slices.Contains(policy.Origins, origin)
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+
+			if !allowed {
+				rest.WriteError(w, "origin is not allowed", http.StatusForbidden)
+				return
+			}
+		}
+
+		if allowed {
+			header.Set("Access-Control-Allow-Origin", origin)
+
+			if policy.Credentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if !preflight {
+			if allowed {
+				header.Set("Access-Control-Expose-Headers", corsExposed)
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", corsMethods)
+		header.Set("Access-Control-Allow-Headers", corsHeaders)
+		header.Set("Access-Control-Max-Age", maxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCORS(t *testing.T) {
+	called := false
+	handler := CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}), CORSPolicy{Origins: []string{"https://app.example.com"}, Credentials: true, MaxAge: 10 * time.Minute})
+
+	request := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		called = false
+		r := httptest.NewRequest(method, "/api/v2/posts", nil)
+
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+
+		if preflight {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			r.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		return w
+	}
+
+	// OK, preflight of an allowed origin with the Authorization header
+	w := request(http.MethodOptions, "https://app.example.com", true)
+
+	if w.Code != http.StatusNoContent || called {
+		t.Errorf("expected 204 without the handler, got: %d, %v", w.Code, called)
+		return
+	}
+
+	for name, expected := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     corsMethods,
+		"Access-Control-Allow-Headers":     corsHeaders,
+		"Access-Control-Max-Age":           "600",
+	} {
+		if got := w.Header().Get(name); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+
+	// The request itself
+	w = request(http.MethodPost, "https://app.example.com", false)
+
+	if !called || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || w.Header().Get("Access-Control-Expose-Headers") != corsExposed {
+		t.Errorf("expected CORS headers, got: %v", w.Header())
+		return
+	}
+
+	// Another origin gets nothing and its preflight is refused
+	w = request(http.MethodGet, "https://evil.example.com", false)
+
+	if !called || w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Vary") != "Origin" {
+		t.Errorf("expected no CORS headers, got: %v", w.Header())
+		return
+	}
+
+	if w = request(http.MethodOptions, "https://evil.example.com", true); w.Code != http.StatusForbidden || called {
+		t.Errorf("expected 403, got: %d", w.Code)
+		return
+	}
+
+	// Same origin, no Origin header
+	if w = request(http.MethodGet, "", false); !called || len(w.Header()) != 0 {
+		t.Errorf("expected no headers, got: %v", w.Header())
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	handler := CORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), CORSPolicy{Origins: []string{"*"}})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/v2/posts", nil)
+	r.Header.Set("Origin", "https://any.example.com")
+	handler.ServeHTTP(w, r)
+
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://any.example.com" || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("expected the origin without credentials, got: %v", w.Header())
+	}
+}
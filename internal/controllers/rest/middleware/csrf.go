@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/controllers/rest"
+)
+
+const (
+	// SessionCookie keeps the session token out of the reach of scripts.
+	SessionCookie = "session"
+	// CSRFCookie is readable by the frontend, which sends it back in
+	// CSRFHeader. Another site can neither read it nor set the header.
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// csrfToken binds the CSRF token to the session, a token of another session
+// or one made up without the key doesn't pass.
+func csrfToken(key []byte, session string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(session))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// CookieAuth authenticates a request by the session cookie, for Auth it
+// looks as if the token came in the Authorization header. The cookie counts
+// only together with the CSRF token of its session: without the header the
+// request is anonymous, whatever its method, since some of the v1 routes
+// change things on GET; with a wrong one it is refused. The Authorization
+// header, which another site can't set, takes precedence.
+func CookieAuth(next http.Handler, key []byte) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(SessionCookie)
+
+		if err != nil || r.Header.Get("Authorization") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.Header.Get(CSRFHeader)
+
+		if token == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !hmac.Equal([]byte(token), []byte(csrfToken(key, cookie.Value))) {
+			rest.WriteError(w, "invalid CSRF token", http.StatusForbidden)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.Header.Set("Authorization", "Bearer "+cookie.Value)
+
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// SessionCookies wraps the routes that answer with {"token": ...}: the
+// token is also set as the session cookie, next to the CSRF cookie of the
+// session. The answer is held back until the cookies are set.
+func SessionCookies(next http.HandlerFunc, key []byte, ttl time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buf := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+		next(buf, r)
+
+		var answer struct {
+			Token string `json:"token"`
+		}
+
+		if buf.status < 300 && json.Unmarshal(buf.body.Bytes(), &answer) == nil && answer.Token != "" {
+			for _, c := range []*http.Cookie{
+				{Name: SessionCookie, Value: answer.Token, HttpOnly: true},
+				{Name: CSRFCookie, Value: csrfToken(key, answer.Token)},
+			} {
+				c.Path, c.MaxAge, c.Secure, c.SameSite = "/", int(ttl.Seconds()), r.TLS != nil, http.SameSiteLaxMode
+				http.SetCookie(w, c)
+			}
+		}
+
+		buf.flush()
+	}
+}
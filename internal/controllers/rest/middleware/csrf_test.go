@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var csrfKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSessionCookies(t *testing.T) {
+	handler := SessionCookies(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":"session-token"}`))
+	}, csrfKey, time.Hour)
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/api/v2/sessions", nil))
+
+	if w.Code != http.StatusCreated || w.Body.String() != `{"token":"session-token"}` {
+		t.Errorf("expected the answer unchanged, got: %d %s", w.Code, w.Body)
+		return
+	}
+
+	cookies := map[string]*http.Cookie{}
+
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+
+	session, csrf := cookies[SessionCookie], cookies[CSRFCookie]
+
+	if session == nil || session.Value != "session-token" || !session.HttpOnly || session.MaxAge != 3600 || session.SameSite != http.SameSiteLaxMode {
+		t.Errorf("unexpected session cookie: %+v", session)
+		return
+	}
+
+	if csrf == nil || csrf.Value != csrfToken(csrfKey, "session-token") || csrf.HttpOnly {
+		t.Errorf("unexpected CSRF cookie: %+v", csrf)
+		return
+	}
+
+	// A failed login sets nothing
+	handler = SessionCookies(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"bad login or password"}`))
+	}, csrfKey, time.Hour)
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodPost, "/api/login", nil))
+
+	if w.Code != http.StatusUnauthorized || len(w.Result().Cookies()) != 0 {
+		t.Errorf("expected 401 without cookies, got: %d %v", w.Code, w.Result().Cookies())
+	}
+}
+
+func TestCookieAuth(t *testing.T) {
+	var authorization string
+
+	handler := CookieAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}), csrfKey)
+
+	for _, tc := range []struct {
+		name          string
+		cookie        string
+		header        string
+		csrf          string
+		code          int
+		authorization string
+	}{
+		{"cookie with its CSRF token", "token", "", csrfToken(csrfKey, "token"), http.StatusOK, "Bearer token"},
+		{"cookie without CSRF token is anonymous", "token", "", "", http.StatusOK, ""},
+		{"CSRF token of another session", "token", "", csrfToken(csrfKey, "other"), http.StatusForbidden, ""},
+		{"CSRF token of another key", "token", "", csrfToken([]byte("another key"), "token"), http.StatusForbidden, ""},
+		{"header wins", "token", "Bearer header", "", http.StatusOK, "Bearer header"},
+		{"no cookie", "", "", csrfToken(csrfKey, "token"), http.StatusOK, ""},
+	} {
+		authorization = ""
+		r := httptest.NewRequest(http.MethodGet, "/api/post/1/upvote", nil)
+
+		if tc.cookie != "" {
+			r.AddCookie(&http.Cookie{Name: SessionCookie, Value: tc.cookie})
+		}
+
+		if tc.header != "" {
+			r.Header.Set("Authorization", tc.header)
+		}
+
+		if tc.csrf != "" {
+			r.Header.Set(CSRFHeader, tc.csrf)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tc.code || authorization != tc.authorization {
+			t.Errorf("%s: expected %d with %q, got: %d with %q", tc.name, tc.code, tc.authorization, w.Code, authorization)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HSTS tells the browser to use only HTTPS for the host from now on. The
+// header is sent over TLS only, browsers ignore it over plain HTTP.
+func HSTS(next http.Handler, maxAge time.Duration, includeSubdomains bool) http.Handler {
+	value := fmt.Sprintf("max-age=%d", int64(maxAge.Seconds()))
+
+	if includeSubdomains {
+		value += "; includeSubDomains"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RedirectHTTPS is the whole handler of the plain HTTP listener: every
+// request is sent to the same host and path on the HTTPS port. 308 keeps
+// the method and the body of a POST.
+func RedirectHTTPS(httpsPort int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+
+		if host == "" {
+			http.Error(w, "Host header is required", http.StatusBadRequest)
+			return
+		}
+
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		} else if ip := net.ParseIP(host); ip != nil && ip.To4() == nil {
+			host = "[" + host + "]"
+		}
+
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHSTS(t *testing.T) {
+	handler := HSTS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), 365*24*time.Hour, true)
+
+	// Plain HTTP, no header
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/posts/", nil))
+
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("expected no header, got: %q", got)
+		return
+	}
+
+	// TLS
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/api/posts/", nil)
+	r.TLS = &tls.ConnectionState{}
+	handler.ServeHTTP(w, r)
+
+	if got, expected := w.Header().Get("Strict-Transport-Security"), "max-age=31536000; includeSubDomains"; got != expected {
+		t.Errorf("expected: %q, got: %q", expected, got)
+	}
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		port     int
+		host     string
+		target   string
+		location string
+	}{
+		{"default port", 443, "example.com:80", "/api/posts/?page=2", "https://example.com/api/posts/?page=2"},
+		{"other port", 8443, "example.com:8080", "/a/music", "https://example.com:8443/a/music"},
+		{"no port", 8443, "example.com", "/", "https://example.com:8443/"},
+		{"ipv6", 443, "[::1]:8080", "/", "https://[::1]/"},
+	} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, tc.target, nil)
+		r.Host = tc.host
+		RedirectHTTPS(tc.port).ServeHTTP(w, r)
+
+		if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != tc.location {
+			t.Errorf("%s: expected 308 to %s, got: %d %s", tc.name, tc.location, w.Code, w.Header().Get("Location"))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = ""
+	RedirectHTTPS(443).ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 without a host, got: %d", w.Code)
+	}
+}
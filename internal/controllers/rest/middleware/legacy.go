@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	apiPrefix   = "/api/"
+	v1Prefix    = "/api/v1/"
+	v2Prefix    = "/api/v2/"
+	specPath    = "/api/openapi.json"
+	successorV2 = `</api/v2>; rel="successor-version"`
+)
+
+// LegacyAPI serves v1, the API the frontend was built against. It stays at
+// /api/ without a version and is also reachable under /api/v1/, which is
+// rewritten here so the router knows only one set of v1 routes. v1 answers
+// carry the Deprecation header and link to v2.
+func LegacyAPI(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.Path
+
+		if !strings.HasPrefix(path, apiPrefix) || strings.HasPrefix(path, v2Prefix) || path == specPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Deprecation", "true")
+		w.Header().Add("Link", successorV2)
+
+		if !strings.HasPrefix(path, v1Prefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = apiPrefix + strings.TrimPrefix(path, v1Prefix)
+		r2.URL.RawPath = strings.Replace(r.URL.RawPath, v1Prefix, apiPrefix, 1)
+
+		next.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLegacyAPI(t *testing.T) {
+	var path string
+
+	handler := LegacyAPI(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+
+	for _, tc := range []struct {
+		name       string
+		path       string
+		wantPath   string
+		deprecated bool
+	}{
+		{"v1 without version", "/api/posts/", "/api/posts/", true},
+		{"v1 alias", "/api/v1/post/1/upvote", "/api/post/1/upvote", true},
+		{"v2", "/api/v2/posts", "/api/v2/posts", false},
+		{"spec", "/api/openapi.json", "/api/openapi.json", false},
+		{"page", "/a/music", "/a/music", false},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+		if path != tc.wantPath {
+			t.Errorf("%s: expected path %s, got %s", tc.name, tc.wantPath, path)
+		}
+
+		if deprecated := w.Header().Get("Deprecation") == "true"; deprecated != tc.deprecated {
+			t.Errorf("%s: expected deprecated %v, got %v", tc.name, tc.deprecated, deprecated)
+		}
+
+		if tc.deprecated && w.Header().Get("Link") != successorV2 {
+			t.Errorf("%s: expected link to v2, got %q", tc.name, w.Header().Get("Link"))
+		}
+	}
+}
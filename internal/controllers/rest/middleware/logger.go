@@ -1,17 +1,70 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
-
 	"time"
 
 	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/gorilla/mux"
 )
 
-func Logger(next http.Handler, logger logger.Logger) http.Handler {
+type accessEntryKey struct{}
+
+// accessEntry collects what the inner middlewares learn about the request,
+// e.g. the user resolved by Auth.
+type accessEntry struct {
+	userID string
+}
+
+// Logger puts a request-scoped logger with the request and trace IDs in the
+// context and writes one access log line when the request is done.
+func Logger(next http.Handler, router *mux.Router, l logger.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t := time.Now()
-		next.ServeHTTP(w, r)
-		logger.Infof("[%s] %s timeAnswer=%v", r.Method, r.URL.Path, time.Since(t))
+		entry := &accessEntry{}
+
+		reqLogger := logger.WithTrace(r.Context(), l.With("request_id", RequestIDFromContext(r.Context())))
+
+		ctx := logger.NewContext(r.Context(), reqLogger)
+		ctx = context.WithValue(ctx, accessEntryKey{}, entry)
+
+		rw := newResponseWriter(w)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		accessLogger := reqLogger.With(
+			"method", r.Method,
+			"path", r.URL.Path,
+			"route", routeTemplate(router, r),
+			"status", rw.status,
+			"bytes", rw.bytes,
+			"user_id", entry.userID,
+			"duration", time.Since(t),
+		)
+
+		switch {
+		case rw.status >= http.StatusInternalServerError:
+			accessLogger.Error("request failed")
+		case rw.status >= http.StatusBadRequest:
+			accessLogger.Warn("request rejected")
+		default:
+			accessLogger.Info("request served")
+		}
 	})
 }
+
+// setUser records the user of the request for the access log and adds it to
+// the request-scoped logger.
+func setUser(ctx context.Context, userID string) context.Context {
+	if entry, ok := ctx.Value(accessEntryKey{}).(*accessEntry); ok {
+		entry.userID = userID
+	}
+
+	l := logger.FromContext(ctx, nil)
+
+	if l == nil {
+		return ctx
+	}
+
+	return logger.NewContext(ctx, l.With("user_id", userID))
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+// recordLogger keeps the lines with their fields instead of writing them.
+type recordLogger struct {
+	fields []interface{}
+	lines  *[]string
+}
+
+func newRecordLogger() *recordLogger {
+	return &recordLogger{lines: &[]string{}}
+}
+
+func (l *recordLogger) write(level, msg string) {
+	*l.lines = append(*l.lines, fmt.Sprintf("%s %s %v", level, msg, l.fields))
+}
+
+func (l *recordLogger) Info(args ...interface{}) { l.write("info", fmt.Sprint(args...)) }
+func (l *recordLogger) Infof(msg string, args ...interface{}) {
+	l.write("info", fmt.Sprintf(msg, args...))
+}
+func (l *recordLogger) Debugf(msg string, args ...interface{}) {
+	l.write("debug", fmt.Sprintf(msg, args...))
+}
+func (l *recordLogger) Warn(args ...interface{}) { l.write("warn", fmt.Sprint(args...)) }
+func (l *recordLogger) Warnf(msg string, args ...interface{}) {
+	l.write("warn", fmt.Sprintf(msg, args...))
+}
+func (l *recordLogger) Error(args ...interface{}) { l.write("error", fmt.Sprint(args...)) }
+func (l *recordLogger) Errorf(msg string, args ...interface{}) {
+	l.write("error", fmt.Sprintf(msg, args...))
+}
+func (l *recordLogger) Fatalf(msg string, args ...interface{}) {
+	l.write("fatal", fmt.Sprintf(msg, args...))
+}
+func (l *recordLogger) Panicf(msg string, args ...interface{}) {
+	l.write("panic", fmt.Sprintf(msg, args...))
+}
+
+func (l *recordLogger) With(args ...interface{}) logger.Logger {
+	return &recordLogger{fields: append(append([]interface{}{}, l.fields...), args...), lines: l.lines}
+}
+
+type stubSessionService struct{}
+
+func (stubSessionService) Create(ctx context.Context, dto *service.CreateSession) (*domain.Session, error) {
+	return nil, nil
+}
+
+func (stubSessionService) Get(ctx context.Context, dto *service.GetSession) (*domain.User, error) {
+	return &domain.User{ID: "7", Username: "admin"}, nil
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	// Incoming ID is kept
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "gw-123")
+
+	handler.ServeHTTP(w, req)
+
+	if seen != "gw-123" || w.Header().Get(RequestIDHeader) != "gw-123" {
+		t.Errorf("expected gw-123, got: %q, header: %q", seen, w.Header().Get(RequestIDHeader))
+		return
+	}
+
+	// No ID
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if seen == "" || w.Header().Get(RequestIDHeader) != seen {
+		t.Errorf("expected generated ID, got: %q", seen)
+		return
+	}
+
+	// Invalid ID is replaced
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n"+strings.Repeat("x", 200))
+
+	handler.ServeHTTP(w, req)
+
+	if strings.ContainsAny(seen, " \n") || len(seen) > maxRequestIDLength {
+		t.Errorf("expected generated ID, got: %q", seen)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	l := newRecordLogger()
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{POST_ID}", func(w http.ResponseWriter, r *http.Request) {
+		logger.FromContext(r.Context(), nil).Info("inside handler")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	}).Methods("GET")
+
+	handler := RequestID(Logger(Auth(router, stubSessionService{}), router, l))
+
+	// OK
+	req := httptest.NewRequest("GET", "/api/post/42", nil)
+	req.Header.Set(RequestIDHeader, "gw-123")
+	req.Header.Set("Authorization", "Bearer token")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := *l.lines
+
+	if len(lines) != 2 {
+		t.Errorf("expected 2 lines, got: %v", lines)
+		return
+	}
+
+	if !strings.Contains(lines[0], "inside handler") || !strings.Contains(lines[0], "request_id gw-123") || !strings.Contains(lines[0], "user_id 7") {
+		t.Errorf("handler line misses request fields: %s", lines[0])
+		return
+	}
+
+	for _, want := range []string{"warn request rejected", "request_id gw-123", "status 404", "bytes 9", "user_id 7", "route /api/post/{POST_ID}"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("expected %q in access line: %s", want, lines[1])
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/metrics"
+	"github.com/gorilla/mux"
+)
+
+const unmatchedRoute = "unmatched"
+
+// Metrics observes every request under its route template, e.g.
+// /api/post/{POST_ID}, so the label cardinality doesn't grow with the IDs.
+func Metrics(next http.Handler, router *mux.Router, m *metrics.Metrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := time.Now()
+		rw := newResponseWriter(w)
+
+		next.ServeHTTP(rw, r)
+
+		m.ObserveHTTP(routeTemplate(router, r), r.Method, rw.status, time.Since(t))
+	})
+}
+
+// CountLogins counts successful and failed logins by the status of the
+// wrapped login handler, any 2xx is a success.
+func CountLogins(next http.HandlerFunc, m *metrics.Metrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rw := newResponseWriter(w)
+
+		next.ServeHTTP(rw, r)
+
+		m.ObserveLogin(rw.status >= http.StatusOK && rw.status < http.StatusMultipleChoices)
+	}
+}
+
+func routeTemplate(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+
+	if !router.Match(r, &match) || match.Route == nil {
+		return unmatchedRoute
+	}
+
+	template, err := match.Route.GetPathTemplate()
+
+	if err != nil {
+		return unmatchedRoute
+	}
+
+	return template
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/metrics"
+	"github.com/gorilla/mux"
+)
+
+func scrape(m *metrics.Metrics) string {
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	return w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{POST_ID}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+
+	handler := Metrics(router, router, m)
+
+	// Route template is used as the label
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/post/42", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/post/43", nil))
+
+	// Unknown path
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nope", nil))
+
+	body := scrape(m)
+
+	for _, want := range []string{
+		`redditclone_http_request_duration_seconds_count{method="GET",route="/api/post/{POST_ID}",status="404"} 2`,
+		`redditclone_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in metrics output", want)
+		}
+	}
+}
+
+func TestCountLogins(t *testing.T) {
+	m := metrics.New()
+	status := http.StatusOK
+
+	handler := CountLogins(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}, m)
+
+	// OK
+	handler(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/login", nil))
+
+	// OK, v2 answers with 201
+	status = http.StatusCreated
+	handler(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/v2/sessions", nil))
+
+	// Bad credentials
+	status = http.StatusUnauthorized
+	handler(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/login", nil))
+	handler(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/login", nil))
+
+	body := scrape(m)
+
+	if !strings.Contains(body, "redditclone_logins_total 2") || !strings.Contains(body, "redditclone_login_failures_total 2") {
+		t.Errorf("unexpected login counters:\n%s", body)
+	}
+}
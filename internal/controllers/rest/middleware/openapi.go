@@ -0,0 +1,240 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/akrovv/redditclone/internal/controllers/rest"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gorilla/mux"
+)
+
+const specMismatch = "request doesn't match the API spec"
+
+// OpenAPI checks API requests against the spec before they reach the
+// handlers. The operation is found by the name of the matched mux route, so
+// the spec and the router can't disagree about which route was hit. Routes
+// outside the spec, like the frontend pages, pass as is.
+//
+// With validateResponses set, responses are checked too. A mismatch is only
+// logged, the client still gets the answer.
+func OpenAPI(next http.Handler, router *mux.Router, spec *openapi3.T, l logger.Logger, validateResponses bool) http.Handler {
+	operations := specRoutes(spec)
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var match mux.RouteMatch
+
+		if !router.Match(r, &match) || match.Route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		route, ok := operations[match.Route.GetName()]
+
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: match.Vars,
+			Route:      route,
+			Options:    options,
+		}
+
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			logger.FromContext(r.Context(), l).Infof("%s: %v", specMismatch, err)
+			status, resp := specErrorResponse(err)
+			rest.WriteErrorResponse(w, resp, status)
+			return
+		}
+
+		if !validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bw := &bufferedWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(bw, r)
+
+		err := openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 bw.status,
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(bw.body.Bytes())),
+			Options:                options,
+		})
+
+		if err != nil {
+			logger.FromContext(r.Context(), l).Warnf("response doesn't match the API spec: %v", err)
+		}
+
+		bw.flush()
+	})
+}
+
+// specRoutes indexes the operations of the spec by operationId.
+func specRoutes(spec *openapi3.T) map[string]*routers.Route {
+	operations := make(map[string]*routers.Route)
+
+	for path, item := range spec.Paths.Map() {
+		for method, operation := range item.Operations() {
+			operations[operation.OperationID] = &routers.Route{
+				Spec:      spec,
+				Path:      path,
+				PathItem:  item,
+				Method:    method,
+				Operation: operation,
+			}
+		}
+	}
+
+	return operations
+}
+
+// specErrorResponse turns the validation errors into field errors. Bad
+// parameters and unreadable bodies are answered with 400, a body that only
+// breaks the schema with 422, like the other field errors of the API.
+func specErrorResponse(err error) (int, *rest.ErrorResponse) {
+	resp := &rest.ErrorResponse{Message: specMismatch}
+	status := http.StatusUnprocessableEntity
+
+	for _, e := range unwrapMulti(err) {
+		reqErr := &openapi3filter.RequestError{}
+
+		if !errors.As(e, &reqErr) {
+			status = http.StatusBadRequest
+			resp.Errors = append(resp.Errors, rest.FieldError{Param: "request", Msg: e.Error()})
+			continue
+		}
+
+		if reqErr.Parameter != nil {
+			status = http.StatusBadRequest
+			resp.Errors = append(resp.Errors, rest.FieldError{
+				Location: reqErr.Parameter.In,
+				Param:    reqErr.Parameter.Name,
+				Msg:      requestErrorReason(reqErr),
+			})
+			continue
+		}
+
+		schemaErrs := schemaErrors(reqErr.Err)
+
+		if len(schemaErrs) == 0 {
+			status = http.StatusBadRequest
+			resp.Errors = append(resp.Errors, rest.FieldError{Location: "body", Param: "body", Msg: requestErrorReason(reqErr)})
+			continue
+		}
+
+		for _, schemaErr := range schemaErrs {
+			param := strings.Join(schemaErr.JSONPointer(), ".")
+
+			if param == "" {
+				param = "body"
+			}
+
+			resp.Errors = append(resp.Errors, rest.FieldError{
+				Location: "body",
+				Param:    param,
+				Value:    scalar(schemaErr.Value),
+				Msg:      schemaErr.Reason,
+			})
+		}
+	}
+
+	return status, resp
+}
+
+func requestErrorReason(err *openapi3filter.RequestError) string {
+	if err.Reason != "" {
+		return err.Reason
+	}
+
+	if err.Err != nil {
+		return err.Err.Error()
+	}
+
+	return "is invalid"
+}
+
+// unwrapMulti flattens nested MultiErrors. It doesn't look inside other
+// errors, a RequestError keeps its schema errors.
+func unwrapMulti(err error) []error {
+	multi, ok := err.(openapi3.MultiError)
+
+	if !ok {
+		return []error{err}
+	}
+
+	var errs []error
+
+	for _, e := range multi {
+		errs = append(errs, unwrapMulti(e)...)
+	}
+
+	return errs
+}
+
+func schemaErrors(err error) []*openapi3.SchemaError {
+	if err == nil {
+		return nil
+	}
+
+	var schemaErrs []*openapi3.SchemaError
+
+	for _, e := range unwrapMulti(err) {
+		schemaErr := &openapi3.SchemaError{}
+
+		if errors.As(e, &schemaErr) {
+			schemaErrs = append(schemaErrs, schemaErr)
+		}
+	}
+
+	return schemaErrs
+}
+
+// scalar keeps the invalid value only when it is short enough to be echoed
+// back, objects and arrays are left out.
+func scalar(v any) any {
+	switch v.(type) {
+	case string, float64, bool:
+		return v
+	default:
+		return nil
+	}
+}
+
+// bufferedWriter holds the response back until it is validated or its
+// cookies are set.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+
+	// The answer can't be changed anymore, the write error is for the client
+	// to notice.
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
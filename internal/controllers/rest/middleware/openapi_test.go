@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/akrovv/redditclone/api"
+	"github.com/akrovv/redditclone/internal/controllers/rest"
+	"github.com/gorilla/mux"
+)
+
+func TestOpenAPI(t *testing.T) {
+	spec, err := api.Load(context.Background())
+
+	if err != nil {
+		t.Errorf("can't load spec: %s", err)
+		return
+	}
+
+	var (
+		called bool
+		answer = `{"id":"1","type":"text","title":"akro","author":{"username":"akro","id":"1"},"category":"music","score":1,"views":1,"created":"2024-02-17T11:39:35Z"}`
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Header().Set("Content-type", "application/json")
+		_, _ = w.Write([]byte(answer))
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/posts", handler).Methods("POST").Name("createPost")
+	router.HandleFunc("/api/mod/log", handler).Methods("GET").Name("getModLog")
+	router.HandleFunc("/a/{CATEGORY_NAME}", handler)
+
+	l := newRecordLogger()
+	mw := OpenAPI(router, router, spec, l, true)
+
+	serve := func(method, path, body, contentType string) *httptest.ResponseRecorder {
+		called = false
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+
+		if contentType != "" {
+			req.Header.Set("Content-type", contentType)
+		}
+
+		mw.ServeHTTP(w, req)
+		return w
+	}
+
+	// OK
+	w := serve("POST", "/api/posts", `{"type":"text","title":"akro","category":"music","text":"my text"}`, "application/json")
+
+	if w.Code != http.StatusOK || !called || w.Body.String() != answer {
+		t.Errorf("expected the handler answer, got: %d %s", w.Code, w.Body.String())
+		return
+	}
+
+	if len(*l.lines) != 0 {
+		t.Errorf("expected no spec mismatch, got: %v", *l.lines)
+		return
+	}
+
+	// Body doesn't match the schema
+	w = serve("POST", "/api/posts", `{"type":"image","title":"akro"}`, "application/json")
+
+	if w.Code != http.StatusUnprocessableEntity || called {
+		t.Errorf("expected 422, got: %d", w.Code)
+		return
+	}
+
+	resp := &rest.ErrorResponse{}
+
+	if err = json.Unmarshal(w.Body.Bytes(), resp); err != nil {
+		t.Errorf("can't unmarshal errors: %s", err)
+		return
+	}
+
+	params := map[string]bool{}
+
+	for _, fieldErr := range resp.Errors {
+		params[fieldErr.Param] = true
+	}
+
+	if !params["type"] || !params["category"] {
+		t.Errorf("expected type and category errors, got: %s", w.Body.String())
+		return
+	}
+
+	// Pass Content-type
+	w = serve("POST", "/api/posts", `{"type":"text","title":"akro","category":"music"}`, "")
+
+	if w.Code != http.StatusBadRequest || called {
+		t.Errorf("expected 400, got: %d", w.Code)
+		return
+	}
+
+	// Bad query parameter
+	w = serve("GET", "/api/mod/log?page=first", "", "")
+
+	if w.Code != http.StatusBadRequest || called || !strings.Contains(w.Body.String(), `"param":"page"`) {
+		t.Errorf("expected 400 for page, got: %d %s", w.Code, w.Body.String())
+		return
+	}
+
+	// Routes outside the spec pass as is
+	w = serve("GET", "/a/music", "", "")
+
+	if w.Code != http.StatusOK || !called {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Response doesn't match the spec, the client still gets it
+	answer = `{"id":1}`
+	w = serve("GET", "/api/mod/log", "", "")
+
+	if w.Code != http.StatusOK || w.Body.String() != answer {
+		t.Errorf("expected the handler answer, got: %d %s", w.Code, w.Body.String())
+		return
+	}
+
+	if len(*l.lines) == 0 || !strings.HasPrefix((*l.lines)[len(*l.lines)-1], "warn response doesn't match the API spec") {
+		t.Errorf("expected response mismatch warning, got: %v", *l.lines)
+		return
+	}
+}
@@ -3,12 +3,13 @@ package middleware
 import (
 	"net/http"
 
+	"github.com/akrovv/redditclone/internal/controllers/rest"
 	"github.com/akrovv/redditclone/internal/domain"
 	"github.com/akrovv/redditclone/pkg/logger"
 	"github.com/casbin/casbin"
 )
 
-func Permissions(next http.Handler, logger logger.Logger, e *casbin.Enforcer) http.Handler {
+func Permissions(next http.Handler, l logger.Logger, e *casbin.Enforcer) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		role := "anonymous"
 		ctx := r.Context()
@@ -17,21 +18,29 @@ func Permissions(next http.Handler, logger logger.Logger, e *casbin.Enforcer) ht
 		sessCtx := ctx.Value(sess)
 
 		if sessCtx != nil {
-			role = "member"
+			role = domain.RoleMember
+
+			if s, ok := sessCtx.(*domain.Session); ok && s.User != nil {
+				switch s.User.Role {
+				case domain.RoleModerator, domain.RoleAdmin:
+					role = s.User.Role
+				}
+			}
 		}
 
 		res, err := e.EnforceSafe(role, r.URL.Path, r.Method)
 
 		if err != nil {
-			http.Error(w, "problem with request", http.StatusInternalServerError)
+			logger.FromContext(ctx, l).Errorf("can't enforce policy: %v", err)
+			rest.WriteError(w, "problem with request", http.StatusInternalServerError)
 			return
 		}
 
-		logger.Infof("path=%s role=%s access=%v", r.URL.Path, role, res)
+		logger.FromContext(ctx, l).Debugf("path=%s role=%s access=%v", r.URL.Path, role, res)
 		if res {
 			next.ServeHTTP(w, r)
 		} else {
-			http.Error(w, "forbidden", http.StatusForbidden)
+			rest.WriteError(w, "forbidden", http.StatusForbidden)
 			return
 		}
 	})
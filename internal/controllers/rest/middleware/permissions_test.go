@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/casbin/casbin"
+)
+
+func TestPermissions(t *testing.T) {
+	e, err := casbin.NewEnforcerSafe("../../../../basic_model.conf", "../../../../basic_policy.csv")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	handler := Permissions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), newRecordLogger(), e)
+
+	for _, tc := range []struct {
+		name   string
+		user   *domain.User
+		path   string
+		status int
+	}{
+		{"anonymous reads", nil, "/api/posts/", http.StatusOK},
+		{"anonymous on mod route", nil, "/api/mod/reports", http.StatusForbidden},
+		{"member on mod route", &domain.User{Role: domain.RoleMember}, "/api/mod/reports", http.StatusForbidden},
+		{"moderator on mod route", &domain.User{Role: domain.RoleModerator}, "/api/mod/reports", http.StatusOK},
+		{"admin on mod route", &domain.User{Role: domain.RoleAdmin}, "/api/mod/reports", http.StatusOK},
+		{"unknown role is a member", &domain.User{Role: "root"}, "/api/mod/reports", http.StatusForbidden},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+
+		if tc.user != nil {
+			var sess domain.SessionContextKey = "session"
+			r = r.WithContext(context.WithValue(r.Context(), sess, &domain.Session{User: tc.user}))
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tc.status {
+			t.Errorf("%s: expected status %d, got %d", tc.name, tc.status, w.Code)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	maxRequestIDLength = 128
+)
+
+type requestIDKey struct{}
+
+// RequestID keeps the X-Request-ID set by the gateway, or generates one, and
+// echoes it back in the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID rejects IDs that would break or flood the log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// SecurityPolicy are the headers that keep the pages from being framed,
+// from loading what they don't need and from leaking the URL to other
+// sites. An empty field sends no header.
+type SecurityPolicy struct {
+	ContentSecurityPolicy string
+	FrameOptions          string
+	ReferrerPolicy        string
+}
+
+// SecurityHeaders sends the headers of policy with every answer, the pages
+// of the frontend and the API alike.
+func SecurityHeaders(next http.Handler, policy SecurityPolicy) http.Handler {
+	headers := map[string]string{
+		"Content-Security-Policy": policy.ContentSecurityPolicy,
+		"X-Frame-Options":         policy.FrameOptions,
+		"Referrer-Policy":         policy.ReferrerPolicy,
+		"X-Content-Type-Options":  "nosniff",
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for name, value := range headers {
+			if value != "" {
+				w.Header().Set(name, value)
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+var inlineScript = regexp.MustCompile(`(?s)<script>(.*?)</script>`)
+
+// AllowInlineScripts adds the hashes of the inline scripts of page to the
+// script-src of policy, so the page runs them without 'unsafe-inline'. A
+// policy without script-src is returned as it is.
+func AllowInlineScripts(policy string, page []byte) string {
+	var hashes []string
+
+	for _, m := range inlineScript.FindAllSubmatch(page, -1) {
+		sum := sha256.Sum256(m[1])
+		hashes = append(hashes, "'sha256-"+base64.StdEncoding.EncodeToString(sum[:])+"'")
+	}
+
+	if len(hashes) == 0 {
+		return policy
+	}
+
+	directives := make([]string, 0, strings.Count(policy, ";")+1)
+
+	for _, directive := range strings.Split(policy, ";") {
+		fields := strings.Fields(directive)
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		if fields[0] == "script-src" {
+			fields = append(fields, hashes...)
+		}
+
+		directives = append(directives, strings.Join(fields, " "))
+	}
+
+	return strings.Join(directives, "; ")
+}
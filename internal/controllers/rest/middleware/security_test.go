@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	handler := SecurityHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), SecurityPolicy{
+		ContentSecurityPolicy: "default-src 'self'",
+		FrameOptions:          "DENY",
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	for name, expected := range map[string]string{
+		"Content-Security-Policy": "default-src 'self'",
+		"X-Frame-Options":         "DENY",
+		"X-Content-Type-Options":  "nosniff",
+		"Referrer-Policy":         "",
+	} {
+		if got := w.Header().Get(name); got != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, got)
+		}
+	}
+}
+
+func TestAllowInlineScripts(t *testing.T) {
+	page := []byte(`<html><script>alert(1)</script><script src="/static/js/main.js"></script><script>
+	run()
+</script></html>`)
+
+	// The hashes of both inline scripts, the one with src is a file
+	expected := "default-src 'self'; script-src 'self' 'sha256-bhHHL3z2vDgxUt0W3dWQOrprscmda2Y5pLsLg4GF+pI=' " +
+		"'sha256-F0jdW1MXzVtlNJTR/VwmwsztVsx4bS/3j8dJ+bD4beg='; frame-ancestors 'none'"
+
+	if got := AllowInlineScripts("default-src 'self'; script-src 'self'; frame-ancestors 'none';", page); got != expected {
+		t.Errorf("expected: %s, got: %s", expected, got)
+		return
+	}
+
+	// Nothing to add
+	if got := AllowInlineScripts("default-src 'self'", []byte(`<script src="/a.js"></script>`)); got != "default-src 'self'" {
+		t.Errorf("expected the policy unchanged, got: %s", got)
+	}
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Tracing starts the server span of a request, continuing the trace from an
+// incoming W3C traceparent header. It has to wrap the rest of the chain so
+// the other middlewares and the handlers log with the trace ID.
+func Tracing(next http.Handler, router *mux.Router) http.Handler {
+	return otelhttp.NewHandler(next, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + routeTemplate(router, r)
+		}),
+	)
+}
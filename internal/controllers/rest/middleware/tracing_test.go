@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/tracing"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider("redditclone", sdktrace.WithSyncer(exporter))
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var handlerSpan trace.SpanContext
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{POST_ID}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+	}).Methods("GET")
+
+	handler := Tracing(router, router)
+
+	// Trace continues from the gateway
+	req := httptest.NewRequest("GET", "/api/post/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+
+	if len(spans) != 1 {
+		t.Errorf("expected 1 span, got: %d", len(spans))
+		return
+	}
+
+	if spans[0].Name != "GET /api/post/{POST_ID}" {
+		t.Errorf("unexpected span name: %s", spans[0].Name)
+		return
+	}
+
+	if traceID := spans[0].SpanContext.TraceID().String(); traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected trace from traceparent, got: %s", traceID)
+		return
+	}
+
+	if spans[0].Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("expected remote parent, got: %s", spans[0].Parent.SpanID())
+		return
+	}
+
+	if handlerSpan.SpanID() != spans[0].SpanContext.SpanID() {
+		t.Errorf("handler doesn't see the request span")
+		return
+	}
+
+	// New trace without traceparent
+	exporter.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/post/42", nil))
+
+	spans = exporter.GetSpans()
+
+	if len(spans) != 1 || spans[0].Parent.IsValid() {
+		t.Errorf("expected 1 root span, got: %v", spans)
+	}
+}
@@ -0,0 +1,340 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	jsontransfer "github.com/akrovv/redditclone/pkg/jsonTransfer"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+type moderationHandler struct {
+	logger        logger.Logger
+	reportService ReportService
+	modLogService ModLogService
+	postService   PostService
+}
+
+func NewModerationHandler(logger logger.Logger, reportService ReportService, modLogService ModLogService, postService PostService) *moderationHandler {
+	return &moderationHandler{logger: logger, reportService: reportService, modLogService: modLogService, postService: postService}
+}
+
+func (h moderationHandler) log(r *http.Request) logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
+func (h moderationHandler) Report(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Content-type") != contentType {
+		h.log(r).Infof("not found application/json header")
+		WriteError(w, "not found application/json header", http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
+		return
+	}
+
+	vars := mux.Vars(r)
+	pi, in := vars["POST_ID"]
+
+	if !in {
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
+		return
+	}
+
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	formReport := &struct {
+		Reason string `json:"reason"`
+	}{}
+
+	err = json.Unmarshal(data, formReport)
+
+	if err != nil {
+		h.log(r).Infof("can't unmarshall json data to report: %v", err)
+		WriteError(w, "can't unmarshall json", http.StatusInternalServerError)
+		return
+	}
+
+	if formReport.Reason == "" {
+		h.log(r).Info("empty report reason")
+		WriteError(w, "reason is required", http.StatusBadRequest)
+		return
+	}
+
+	reportDto := &service.ReportContent{
+		User:      user,
+		PostID:    pi,
+		CommentID: vars["COMMENT_ID"],
+		Reason:    formReport.Reason,
+	}
+
+	report, err := h.reportService.Report(r.Context(), reportDto)
+
+	if err != nil {
+		h.log(r).Infof("can't save report: %v", err)
+		writeDomainError(w, err, "can't save report")
+		return
+	}
+
+	reportJSON, err := jsontransfer.GetJSON(report)
+
+	if err != nil {
+		h.log(r).Infof("can't marshall report to json: %v", err)
+		WriteError(w, "can't marshall report to json", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	_, err = w.Write(reportJSON)
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h moderationHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	cn, in := vars["CATEGORY_NAME"]
+
+	if !in {
+		h.log(r).Info("category not found")
+		WriteError(w, "category not found", http.StatusNotFound)
+		return
+	}
+
+	reports, err := h.reportService.Queue(r.Context(), &service.GetQueue{Category: cn})
+
+	if err != nil {
+		h.log(r).Infof("can't get reports: %v", err)
+		writeDomainError(w, err, "can't get reports")
+		return
+	}
+
+	reportsJSON, err := jsontransfer.GetJSON(reports)
+
+	if err != nil {
+		h.log(r).Infof("can't marshall reports to json: %v", err)
+		WriteError(w, "can't marshall reports to json", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	_, err = w.Write(reportsJSON)
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h moderationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.reportService.Approve)
+}
+
+func (h moderationHandler) Remove(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.reportService.Remove)
+}
+
+func (h moderationHandler) resolve(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, dto *service.ResolveReport) error) {
+	vars := mux.Vars(r)
+
+	ri, in := vars["REPORT_ID"]
+
+	if !in {
+		h.log(r).Info("report not found")
+		WriteError(w, "report not found", http.StatusNotFound)
+		return
+	}
+
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	err = action(r.Context(), &service.ResolveReport{User: user, ReportID: ri, Reason: r.URL.Query().Get("reason")})
+
+	if err != nil {
+		h.log(r).Infof("can't resolve report: %v", err)
+		writeDomainError(w, err, "can't resolve report")
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	_, err = w.Write([]byte("{\"message\":\"success\"}"))
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h moderationHandler) Lock(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Lock(r.Context(), &service.LockPost{User: user, PostID: postID, Locked: true, Reason: reason})
+	})
+}
+
+func (h moderationHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Lock(r.Context(), &service.LockPost{User: user, PostID: postID, Locked: false, Reason: reason})
+	})
+}
+
+func (h moderationHandler) Sticky(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Sticky(r.Context(), &service.StickyPost{User: user, PostID: postID, Stickied: true, Reason: reason})
+	})
+}
+
+func (h moderationHandler) Unsticky(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Sticky(r.Context(), &service.StickyPost{User: user, PostID: postID, Stickied: false, Reason: reason})
+	})
+}
+
+func (h moderationHandler) moderatePost(w http.ResponseWriter, r *http.Request, action func(user *domain.User, postID, reason string) error) {
+	vars := mux.Vars(r)
+
+	pi, in := vars["POST_ID"]
+
+	if !in {
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
+		return
+	}
+
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	err = action(user, pi, r.URL.Query().Get("reason"))
+
+	if err != nil {
+		h.log(r).Infof("can't moderate post: %v", err)
+		writeDomainError(w, err, "can't moderate post")
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	_, err = w.Write([]byte("{\"message\":\"success\"}"))
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h moderationHandler) ModLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, err := queryInt(query.Get("page"))
+
+	if err != nil {
+		h.log(r).Infof("bad page: %v", err)
+		WriteError(w, "bad page", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := queryInt(query.Get("limit"))
+
+	if err != nil {
+		h.log(r).Infof("bad limit: %v", err)
+		WriteError(w, "bad limit", http.StatusBadRequest)
+		return
+	}
+
+	getModLogDto := &service.GetModLog{
+		Category:  mux.Vars(r)["CATEGORY_NAME"],
+		Action:    query.Get("action"),
+		Moderator: query.Get("moderator"),
+		TargetID:  query.Get("target"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	entries, err := h.modLogService.Get(r.Context(), getModLogDto)
+
+	if err != nil {
+		h.log(r).Infof("can't get mod log: %v", err)
+		writeDomainError(w, err, "can't get mod log")
+		return
+	}
+
+	entriesJSON, err := jsontransfer.GetJSON(entries)
+
+	if err != nil {
+		h.log(r).Infof("can't marshall mod log to json: %v", err)
+		WriteError(w, "can't marshall mod log to json", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	_, err = w.Write(entriesJSON)
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
+		return
+	}
+}
+
+func queryInt(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,488 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/internal/service/mocks"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rSrv := mocks.NewMockReportService(ctrl)
+	mSrv := mocks.NewMockModLogService(ctrl)
+	pSrv := mocks.NewMockPostService(ctrl)
+	moderationHandler := NewModerationHandler(logger.NewLogger(), rSrv, mSrv, pSrv)
+
+	var sess domain.SessionContextKey = "session"
+	user, _ := getTestData()
+	ctx := context.WithValue(context.TODO(), sess, &domain.Session{User: user})
+
+	body := `{"reason": "spam"}`
+	vars := map[string]string{"POST_ID": "1", "COMMENT_ID": "2"}
+	reportDto := &service.ReportContent{User: user, PostID: "1", CommentID: "2", Reason: "spam"}
+
+	// OK
+	w, req := getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/{COMMENT_ID}/report", body)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Report(gomock.Any(), reportDto).Return(&domain.Report{}, nil)
+
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Pass Content-type
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/report", body)
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got: %d", w.Code)
+		return
+	}
+
+	// ReadAll returns error
+	req = httptest.NewRequest("POST", "/api/post/{POST_ID}/report", &BadReader{})
+	req.Header.Add("Content-type", "application/json")
+	w = httptest.NewRecorder()
+
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Pass POST_ID
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/report", body)
+	req.Header.Add("Content-type", "application/json")
+
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "POST", "/api/post/{POST_ID}/report", body)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Empty reason
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/report", `{"reason": ""}`)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got: %d", w.Code)
+		return
+	}
+
+	// Bad Unmarshall
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/report", `{"reason": {`)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Method's Report returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/report", body)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Report(gomock.Any(), reportDto).Return(nil, errors.New("some error"))
+
+	moderationHandler.Report(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Writter returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/report", body)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Report(gomock.Any(), reportDto).Return(&domain.Report{}, nil)
+
+	moderationHandler.Report(&BadResponseWriter{ResponseWriter: w}, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rSrv := mocks.NewMockReportService(ctrl)
+	mSrv := mocks.NewMockModLogService(ctrl)
+	pSrv := mocks.NewMockPostService(ctrl)
+	moderationHandler := NewModerationHandler(logger.NewLogger(), rSrv, mSrv, pSrv)
+	ctx := context.TODO()
+
+	queueDto := &service.GetQueue{Category: "music"}
+	vars := map[string]string{"CATEGORY_NAME": "music"}
+
+	// OK
+	w, req := getRequestRecorder(ctx, false, "GET", "/api/mod/{CATEGORY_NAME}/reports", "")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Queue(gomock.Any(), queueDto).Return([]*domain.Report{}, nil)
+
+	moderationHandler.Queue(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Pass CATEGORY_NAME
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/{CATEGORY_NAME}/reports", "")
+	moderationHandler.Queue(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Method's Queue returns error
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/{CATEGORY_NAME}/reports", "")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Queue(gomock.Any(), queueDto).Return(nil, errors.New("some error"))
+
+	moderationHandler.Queue(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Writter returns error
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/{CATEGORY_NAME}/reports", "")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Queue(gomock.Any(), queueDto).Return([]*domain.Report{}, nil)
+
+	moderationHandler.Queue(&BadResponseWriter{ResponseWriter: w}, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestResolveReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rSrv := mocks.NewMockReportService(ctrl)
+	mSrv := mocks.NewMockModLogService(ctrl)
+	pSrv := mocks.NewMockPostService(ctrl)
+	moderationHandler := NewModerationHandler(logger.NewLogger(), rSrv, mSrv, pSrv)
+
+	var sess domain.SessionContextKey = "session"
+	user, _ := getTestData()
+	ctx := context.WithValue(context.TODO(), sess, &domain.Session{User: user})
+
+	resolveDto := &service.ResolveReport{User: user, ReportID: "1"}
+	vars := map[string]string{"REPORT_ID": "1"}
+
+	// OK approve
+	w, req := getRequestRecorder(ctx, true, "POST", "/api/mod/reports/{REPORT_ID}/approve", "")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Approve(gomock.Any(), resolveDto).Return(nil)
+
+	moderationHandler.Approve(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// OK remove
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/reports/{REPORT_ID}/remove", "")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Remove(gomock.Any(), resolveDto).Return(nil)
+
+	moderationHandler.Remove(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Pass REPORT_ID
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/reports/{REPORT_ID}/remove", "")
+	moderationHandler.Remove(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "POST", "/api/mod/reports/{REPORT_ID}/remove", "")
+	req = mux.SetURLVars(req, vars)
+
+	moderationHandler.Remove(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Method's Remove returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/reports/{REPORT_ID}/remove", "")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Remove(gomock.Any(), resolveDto).Return(errors.New("some error"))
+
+	moderationHandler.Remove(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Writter returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/reports/{REPORT_ID}/approve", "")
+	req = mux.SetURLVars(req, vars)
+
+	rSrv.EXPECT().Approve(gomock.Any(), resolveDto).Return(nil)
+
+	moderationHandler.Approve(&BadResponseWriter{ResponseWriter: w}, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestModLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rSrv := mocks.NewMockReportService(ctrl)
+	mSrv := mocks.NewMockModLogService(ctrl)
+	pSrv := mocks.NewMockPostService(ctrl)
+	moderationHandler := NewModerationHandler(logger.NewLogger(), rSrv, mSrv, pSrv)
+	ctx := context.TODO()
+
+	vars := map[string]string{"CATEGORY_NAME": "music"}
+	modLogDto := &service.GetModLog{Category: "music", Action: "delete_post", Page: 2, Limit: 10}
+
+	// OK per category
+	w, req := getRequestRecorder(ctx, false, "GET", "/api/mod/{CATEGORY_NAME}/log?action=delete_post&page=2&limit=10", "")
+	req = mux.SetURLVars(req, vars)
+
+	mSrv.EXPECT().Get(gomock.Any(), modLogDto).Return([]*domain.ModLogEntry{}, nil)
+
+	moderationHandler.ModLog(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// OK site-wide
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/log?moderator=akro", "")
+
+	mSrv.EXPECT().Get(gomock.Any(), &service.GetModLog{Moderator: "akro"}).Return([]*domain.ModLogEntry{}, nil)
+
+	moderationHandler.ModLog(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Bad page
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/log?page=first", "")
+	moderationHandler.ModLog(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got: %d", w.Code)
+		return
+	}
+
+	// Bad limit
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/log?limit=all", "")
+	moderationHandler.ModLog(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got: %d", w.Code)
+		return
+	}
+
+	// Method's Get returns error
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/log", "")
+
+	mSrv.EXPECT().Get(gomock.Any(), &service.GetModLog{}).Return(nil, errors.New("some error"))
+
+	moderationHandler.ModLog(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Writter returns error
+	w, req = getRequestRecorder(ctx, false, "GET", "/api/mod/log", "")
+
+	mSrv.EXPECT().Get(gomock.Any(), &service.GetModLog{}).Return([]*domain.ModLogEntry{}, nil)
+
+	moderationHandler.ModLog(&BadResponseWriter{ResponseWriter: w}, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestModeratePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	rSrv := mocks.NewMockReportService(ctrl)
+	mSrv := mocks.NewMockModLogService(ctrl)
+	pSrv := mocks.NewMockPostService(ctrl)
+	moderationHandler := NewModerationHandler(logger.NewLogger(), rSrv, mSrv, pSrv)
+
+	var sess domain.SessionContextKey = "session"
+	user, _ := getTestData()
+	ctx := context.WithValue(context.TODO(), sess, &domain.Session{User: user})
+	vars := map[string]string{"POST_ID": "1"}
+
+	// OK lock
+	w, req := getRequestRecorder(ctx, true, "POST", "/api/mod/post/{POST_ID}/lock?reason=flamewar", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Lock(gomock.Any(), &service.LockPost{User: user, PostID: "1", Locked: true, Reason: "flamewar"}).Return(nil)
+
+	moderationHandler.Lock(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// OK unlock
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/post/{POST_ID}/unlock", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Lock(gomock.Any(), &service.LockPost{User: user, PostID: "1"}).Return(nil)
+
+	moderationHandler.Unlock(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// OK unsticky
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/post/{POST_ID}/unsticky", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Sticky(gomock.Any(), &service.StickyPost{User: user, PostID: "1"}).Return(nil)
+
+	moderationHandler.Unsticky(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Sticky limit reached
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/post/{POST_ID}/sticky", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Sticky(gomock.Any(), &service.StickyPost{User: user, PostID: "1", Stickied: true}).Return(domain.ErrStickyLimit)
+
+	moderationHandler.Sticky(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got: %d", w.Code)
+		return
+	}
+
+	// Pass POST_ID
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/post/{POST_ID}/lock", "")
+	moderationHandler.Lock(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "POST", "/api/mod/post/{POST_ID}/lock", "")
+	req = mux.SetURLVars(req, vars)
+	moderationHandler.Lock(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Method's Lock returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/post/{POST_ID}/lock", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Lock(gomock.Any(), &service.LockPost{User: user, PostID: "1", Locked: true}).Return(errors.New("some error"))
+
+	moderationHandler.Lock(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Writter returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/mod/post/{POST_ID}/lock", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Lock(gomock.Any(), &service.LockPost{User: user, PostID: "1", Locked: true}).Return(nil)
+
+	moderationHandler.Lock(&BadResponseWriter{ResponseWriter: w}, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
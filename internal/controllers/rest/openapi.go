@@ -0,0 +1,39 @@
+package rest
+
+import (
+	"net/http"
+
+	jsontransfer "github.com/akrovv/redditclone/pkg/jsonTransfer"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type openAPIHandler struct {
+	logger logger.Logger
+	spec   []byte
+}
+
+// NewOpenAPIHandler renders the spec to JSON once, it doesn't change while
+// the server runs.
+func NewOpenAPIHandler(logger logger.Logger, spec *openapi3.T) (*openAPIHandler, error) {
+	data, err := jsontransfer.GetJSON(spec)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &openAPIHandler{logger: logger, spec: data}, nil
+}
+
+func (h openAPIHandler) log(r *http.Request) logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
+func (h openAPIHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-type", contentType)
+	_, err := w.Write(h.spec)
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+	}
+}
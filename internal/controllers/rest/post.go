@@ -26,6 +26,11 @@ func NewPostHandler(logger logger.Logger, postService PostService, commentServic
 	return &postHandler{logger: logger, postService: postService, commentService: commentService, sessionService: sessionService}
 }
 
+// log returns the request-scoped logger set up by middleware.Logger.
+func (h postHandler) log(r *http.Request) logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
 const contentType = "application/json"
 
 func (h postHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
@@ -34,42 +39,57 @@ func (h postHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	pi, inPost := vars["POST_ID"]
 
 	if !inPost {
-		h.logger.Info("post not found")
-		http.Error(w, "post not found", http.StatusNotFound)
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
 		return
 	}
 
 	ci, inComment := vars["COMMENT_ID"]
 
 	if !inComment {
-		h.logger.Info("post not found")
-		http.Error(w, "comment not found", http.StatusNotFound)
+		h.log(r).Info("post not found")
+		WriteError(w, "comment not found", http.StatusNotFound)
 		return
 	}
 
-	deleteCommentDto := &service.DeleteComment{PostID: pi, CommentID: ci}
-	err := h.commentService.Delete(deleteCommentDto)
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
 
 	if err != nil {
-		h.logger.Infof("can't delete post: %w", err)
-		http.Error(w, "can't delete post", http.StatusInternalServerError)
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	deleteCommentDto := &service.DeleteComment{User: user, PostID: pi, CommentID: ci, Reason: r.URL.Query().Get("reason")}
+	err = h.commentService.Delete(r.Context(), deleteCommentDto)
+
+	if err != nil {
+		h.log(r).Infof("can't delete comment: %v", err)
+		writeDomainError(w, err, "can't delete comment")
 		return
 	}
 
 	getOnePostDto := &service.GetOnePost{PostID: pi}
-	postWithNoComment, err := h.postService.GetOne(getOnePostDto)
+	postWithNoComment, err := h.postService.GetOne(r.Context(), getOnePostDto)
 
 	if err != nil {
-		h.logger.Infof("can't get post: %w", err)
-		http.Error(w, "can't get post", http.StatusInternalServerError)
+		h.log(r).Infof("can't get post: %v", err)
+		writeDomainError(w, err, "can't get post")
 		return
 	}
 
 	postJSON, err := jsontransfer.GetJSON(postWithNoComment)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall post to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -77,8 +97,8 @@ func (h postHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
@@ -86,8 +106,8 @@ func (h postHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 func (h postHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 	jsonHeader := r.Header.Get("Content-type")
 	if jsonHeader != contentType {
-		h.logger.Infof("not found application/json header")
-		http.Error(w, "not found application/json header", http.StatusBadRequest)
+		h.log(r).Infof("not found application/json header")
+		WriteError(w, "not found application/json header", http.StatusBadRequest)
 		return
 	}
 
@@ -95,8 +115,8 @@ func (h postHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	if err != nil {
-		h.logger.Infof("can't read form: %w", err)
-		http.Error(w, "can't read form", http.StatusInternalServerError)
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
 		return
 	}
 
@@ -104,24 +124,24 @@ func (h postHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 	pi, in := vars["POST_ID"]
 
 	if !in {
-		h.logger.Info("post not found")
-		http.Error(w, "post not found", http.StatusNotFound)
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
 		return
 	}
 
 	ctx := r.Context()
 	sessCtx := ctx.Value(domain.SessionContextKey("session"))
 	if sessCtx == nil {
-		h.logger.Info("can't find session")
-		http.Error(w, "can't find session", http.StatusInternalServerError)
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusInternalServerError)
 		return
 	}
 
 	user, err := getUserFromSession(sessCtx)
 
 	if err != nil {
-		h.logger.Infof("can't convert to session: %w", err)
-		http.Error(w, "can't convert to session", http.StatusInternalServerError)
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
 		return
 	}
 
@@ -132,8 +152,9 @@ func (h postHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(data, formComment)
 
 	if err != nil {
-		h.logger.Infof("can't unmarshall json data to comment: %w", err)
-		http.Error(w, "can't unmarshall json", http.StatusInternalServerError)
+		h.log(r).Infof("can't unmarshall json data to comment: %v", err)
+		WriteError(w, "can't unmarshall json", http.StatusInternalServerError)
+		return
 	}
 
 	addCommentDto := &service.AddComment{
@@ -142,28 +163,28 @@ func (h postHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 		PostID: pi,
 	}
 
-	err = h.commentService.Add(addCommentDto)
+	err = h.commentService.Add(r.Context(), addCommentDto)
 
 	if err != nil {
-		h.logger.Infof("can't add comment: %w", err)
-		http.Error(w, "can't add comment", http.StatusInternalServerError)
+		h.log(r).Infof("can't add comment: %v", err)
+		writeDomainError(w, err, "can't add comment")
 		return
 	}
 
 	getOnePostDto := &service.GetOnePost{PostID: pi}
-	postWithComments, err := h.postService.GetOne(getOnePostDto)
+	postWithComments, err := h.postService.GetOne(r.Context(), getOnePostDto)
 
 	if err != nil {
-		h.logger.Infof("can't get post: %w", err)
-		http.Error(w, "can't get post", http.StatusInternalServerError)
+		h.log(r).Infof("can't get post: %v", err)
+		writeDomainError(w, err, "can't get post")
 		return
 	}
 
 	postJSON, err := jsontransfer.GetJSON(postWithComments)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall post to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -171,8 +192,8 @@ func (h postHandler) AddComment(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
@@ -185,24 +206,24 @@ func (h postHandler) PostVote(w http.ResponseWriter, r *http.Request) {
 	pi, in := vars["POST_ID"]
 
 	if !in {
-		h.logger.Info("post not found")
-		http.Error(w, "post not found", http.StatusNotFound)
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
 		return
 	}
 
 	ctx := r.Context()
 	sessCtx := ctx.Value(domain.SessionContextKey("session"))
 	if sessCtx == nil {
-		h.logger.Info("can't find session")
-		http.Error(w, "can't find session", http.StatusNotFound)
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
 		return
 	}
 
 	user, err := getUserFromSession(sessCtx)
 
 	if err != nil {
-		h.logger.Infof("can't convert to session: %w", err)
-		http.Error(w, "can't convert to session", http.StatusInternalServerError)
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
 		return
 	}
 
@@ -218,28 +239,28 @@ func (h postHandler) PostVote(w http.ResponseWriter, r *http.Request) {
 		Inc:      inc,
 		AuthorID: user.ID,
 	}
-	err = h.postService.UpdateMetrics(updatePostDto)
+	err = h.postService.UpdateMetrics(r.Context(), updatePostDto)
 
 	if err != nil {
-		h.logger.Infof("can't inc vote: %w", err)
-		http.Error(w, "can't inc vote", http.StatusInternalServerError)
+		h.log(r).Infof("can't inc vote: %v", err)
+		writeDomainError(w, err, "can't inc vote")
 		return
 	}
 
 	getOnePostDto := &service.GetOnePost{PostID: pi}
-	post, err := h.postService.GetOne(getOnePostDto)
+	post, err := h.postService.GetOne(r.Context(), getOnePostDto)
 
 	if err != nil {
-		h.logger.Infof("can't get post: %w", err)
-		http.Error(w, "can't get post", http.StatusInternalServerError)
+		h.log(r).Infof("can't get post: %v", err)
+		writeDomainError(w, err, "can't get post")
 		return
 	}
 
 	postJSON, err := jsontransfer.GetJSON(post)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall post to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -247,8 +268,8 @@ func (h postHandler) PostVote(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
@@ -259,34 +280,34 @@ func (h postHandler) PostDetail(w http.ResponseWriter, r *http.Request) {
 	pi, in := vars["POST_ID"]
 
 	if !in {
-		h.logger.Info("post not found")
-		http.Error(w, "post not found", http.StatusNotFound)
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
 		return
 	}
 
 	getOnePostDto := &service.GetOnePost{PostID: pi}
-	post, err := h.postService.GetOne(getOnePostDto)
+	post, err := h.postService.GetOne(r.Context(), getOnePostDto)
 
 	if err != nil {
-		h.logger.Infof("can't get post: %w", err)
-		http.Error(w, "can't get post", http.StatusInternalServerError)
+		h.log(r).Infof("can't get post: %v", err)
+		writeDomainError(w, err, "can't get post")
 		return
 	}
 
 	incViewsDto := &service.IncrViewsPost{PostID: pi}
-	err = h.postService.IncrViews(incViewsDto)
+	err = h.postService.IncrViews(r.Context(), incViewsDto)
 
 	if err != nil {
-		h.logger.Infof("can't inc views: %w", err)
-		http.Error(w, "can't inc views", http.StatusInternalServerError)
+		h.log(r).Infof("can't inc views: %v", err)
+		writeDomainError(w, err, "can't inc views")
 		return
 	}
 
 	postJSON, err := jsontransfer.GetJSON(post)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall post to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -294,8 +315,8 @@ func (h postHandler) PostDetail(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
@@ -306,25 +327,25 @@ func (h postHandler) ShowPostsByFilter(w http.ResponseWriter, r *http.Request) {
 	cn, in := vars["CATEGORY_NAME"]
 
 	if !in {
-		h.logger.Info("category not found")
-		http.Error(w, "category not found", http.StatusNotFound)
+		h.log(r).Info("category not found")
+		WriteError(w, "category not found", http.StatusNotFound)
 		return
 	}
 
 	getByDto := &service.GetByPost{Category: "category", Data: cn, SortField: "score"}
-	posts, err := h.postService.GetBy(getByDto)
+	posts, err := h.postService.GetBy(r.Context(), getByDto)
 
 	if err != nil {
-		h.logger.Infof("can't get posts: %w", err)
-		http.Error(w, "can't get posts", http.StatusInternalServerError)
+		h.log(r).Infof("can't get posts: %v", err)
+		writeDomainError(w, err, "can't get posts")
 		return
 	}
 
 	postsJSON, err := jsontransfer.GetJSON(posts)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall post to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -332,26 +353,26 @@ func (h postHandler) ShowPostsByFilter(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postsJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
 
 func (h postHandler) ShowPosts(w http.ResponseWriter, r *http.Request) {
-	posts, err := h.postService.Get()
+	posts, err := h.postService.Get(r.Context())
 
 	if err != nil {
-		h.logger.Infof("can't get posts: %w", err)
-		http.Error(w, "can't get posts", http.StatusInternalServerError)
+		h.log(r).Infof("can't get posts: %v", err)
+		writeDomainError(w, err, "can't get posts")
 		return
 	}
 
 	postsJSON, err := jsontransfer.GetJSON(posts)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall post to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -359,8 +380,8 @@ func (h postHandler) ShowPosts(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postsJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
@@ -371,25 +392,25 @@ func (h postHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	ul, in := vars["USER_LOGIN"]
 
 	if !in {
-		h.logger.Info("user_login not found")
-		http.Error(w, "user_login not found", http.StatusNotFound)
+		h.log(r).Info("user_login not found")
+		WriteError(w, "user_login not found", http.StatusNotFound)
 		return
 	}
 
 	getByDto := &service.GetByPost{Category: "author.username", Data: ul, SortField: "created"}
-	posts, err := h.postService.GetBy(getByDto)
+	posts, err := h.postService.GetBy(r.Context(), getByDto)
 
 	if err != nil {
-		h.logger.Infof("can't get user's posts: %w", err)
-		http.Error(w, "can't get user's posts", http.StatusInternalServerError)
+		h.log(r).Infof("can't get user's posts: %v", err)
+		writeDomainError(w, err, "can't get user's posts")
 		return
 	}
 
 	postsJSON, err := jsontransfer.GetJSON(posts)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall posts to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall posts to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -397,8 +418,8 @@ func (h postHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postsJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
@@ -409,17 +430,32 @@ func (h postHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	pi, in := vars["POST_ID"]
 
 	if !in {
-		h.logger.Info("post not found")
-		http.Error(w, "post not found", http.StatusNotFound)
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
 		return
 	}
 
-	deletePostDto := &service.DeletePost{PostID: pi}
-	err := h.postService.Delete(deletePostDto)
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	deletePostDto := &service.DeletePost{User: user, PostID: pi, Reason: r.URL.Query().Get("reason")}
+	err = h.postService.Delete(r.Context(), deletePostDto)
 
 	if err != nil {
-		h.logger.Infof("can't delete post: %w", err)
-		http.Error(w, "can't delete post", http.StatusInternalServerError)
+		h.log(r).Infof("can't delete post: %v", err)
+		writeDomainError(w, err, "can't delete post")
 		return
 	}
 
@@ -427,16 +463,16 @@ func (h postHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write([]byte("{\"message\":\"success\"}"))
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
 
 func (h postHandler) AddPost(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get("Content-type") != contentType {
-		h.logger.Infof("not found application/json header")
-		http.Error(w, "not found application/json header", http.StatusBadRequest)
+		h.log(r).Infof("not found application/json header")
+		WriteError(w, "not found application/json header", http.StatusBadRequest)
 		return
 	}
 
@@ -444,60 +480,163 @@ func (h postHandler) AddPost(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
 	if err != nil {
-		h.logger.Infof("can't read form: %w", err)
-		http.Error(w, "can't read form", http.StatusInternalServerError)
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
 		return
 	}
 
 	ctx := r.Context()
 	sessCtx := ctx.Value(domain.SessionContextKey("session"))
 	if sessCtx == nil {
-		h.logger.Info("can't find session")
-		http.Error(w, "can't find session", http.StatusNotFound)
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
 		return
 	}
 
 	user, err := getUserFromSession(sessCtx)
 
 	if err != nil {
-		h.logger.Infof("can't convert to session: %w", err)
-		http.Error(w, "can't convert to session", http.StatusInternalServerError)
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
 		return
 	}
 
-	post := &domain.Post{}
-	err = json.Unmarshal(data, post)
+	postDto := &service.SavePost{}
+	err = json.Unmarshal(data, postDto)
 
 	if err != nil {
-		h.logger.Infof("can't unmarshall json data to post: %w", err)
-		http.Error(w, "can't unmarshall json", http.StatusInternalServerError)
+		h.log(r).Infof("can't unmarshall json data to post: %v", err)
+		WriteError(w, "can't unmarshall json", http.StatusInternalServerError)
 		return
 	}
 
-	post.Author = &domain.Profile{Username: user.Username, ID: user.ID}
-	post.Score = 1
-	post.Views = 1
-	post.UpvotePercentage = 100
-	post.Comments = make([]*domain.Comment, 0)
-	post.Votes = make([]*domain.Vote, 1)
-	post.Votes[0] = &domain.Vote{
-		User: user.ID,
-		Vote: 1,
+	postDto.User = user
+
+	post, err := h.postService.Save(r.Context(), postDto)
+
+	if err != nil {
+		h.log(r).Infof("can't save post in repo: %v", err)
+		writeDomainError(w, err, "can't save post in repo")
+		return
+	}
+
+	postJSON, err := jsontransfer.GetJSON(post)
+
+	if err != nil {
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Add("Content-type", contentType)
+	_, err = w.Write(postJSON)
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (h postHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	pi, in := vars["POST_ID"]
+
+	if !in {
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
+		return
 	}
 
-	post, err = h.postService.Save(post)
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	restorePostDto := &service.RestorePost{User: user, PostID: pi}
+	err = h.postService.Restore(r.Context(), restorePostDto)
+
+	if err != nil {
+		h.log(r).Infof("can't restore post: %v", err)
+		writeDomainError(w, err, "can't restore post")
+		return
+	}
+
+	h.writePost(w, r, pi)
+}
+
+func (h postHandler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	pi, inPost := vars["POST_ID"]
+
+	if !inPost {
+		h.log(r).Info("post not found")
+		WriteError(w, "post not found", http.StatusNotFound)
+		return
+	}
+
+	ci, inComment := vars["COMMENT_ID"]
+
+	if !inComment {
+		h.log(r).Info("comment not found")
+		WriteError(w, "comment not found", http.StatusNotFound)
+		return
+	}
+
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "can't find session", http.StatusNotFound)
+		return
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return
+	}
+
+	restoreCommentDto := &service.RestoreComment{User: user, PostID: pi, CommentID: ci}
+	err = h.commentService.Restore(r.Context(), restoreCommentDto)
+
+	if err != nil {
+		h.log(r).Infof("can't restore comment: %v", err)
+		writeDomainError(w, err, "can't restore comment")
+		return
+	}
+
+	h.writePost(w, r, pi)
+}
+
+func (h postHandler) writePost(w http.ResponseWriter, r *http.Request, postID string) {
+	getOnePostDto := &service.GetOnePost{PostID: postID}
+	post, err := h.postService.GetOne(r.Context(), getOnePostDto)
 
 	if err != nil {
-		h.logger.Infof("can't save post in repo: %w", err)
-		http.Error(w, "can't save post in repo", http.StatusInternalServerError)
+		h.log(r).Infof("can't get post: %v", err)
+		writeDomainError(w, err, "can't get post")
 		return
 	}
 
 	postJSON, err := jsontransfer.GetJSON(post)
 
 	if err != nil {
-		h.logger.Infof("can't marshall post to json: %w", err)
-		http.Error(w, "can't marshall post to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall post to json: %v", err)
+		WriteError(w, "can't marshall post to json", http.StatusInternalServerError)
 		return
 	}
 
@@ -505,8 +644,8 @@ func (h postHandler) AddPost(w http.ResponseWriter, r *http.Request) {
 	_, err = w.Write(postJSON)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
@@ -2,6 +2,7 @@ package rest
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -72,6 +73,7 @@ func TestAdd(t *testing.T) {
 	postHandler := NewPostHandler(logger.NewLogger(), pSrv, cSrv, sSrv)
 	var sess domain.SessionContextKey = "session"
 	user, post := getTestData()
+	postDto := &service.SavePost{User: user, Type: "text", Title: "akro", Category: "music", Text: "my text in post"}
 
 	ctx := context.TODO()
 	ctx = context.WithValue(ctx,
@@ -91,7 +93,7 @@ func TestAdd(t *testing.T) {
 	// OK
 	w, req := getRequestRecorder(ctx, true, "POST", "/api/posts", body)
 	req.Header.Add("Content-type", "application/json")
-	pSrv.EXPECT().Save(post).Return(post, nil)
+	pSrv.EXPECT().Save(gomock.Any(), postDto).Return(post, nil)
 
 	postHandler.AddPost(w, req)
 
@@ -182,7 +184,7 @@ func TestAdd(t *testing.T) {
 	}`)
 	req.Header.Add("Content-type", "application/json")
 
-	pSrv.EXPECT().Save(post).Return(nil, errors.New("some error"))
+	pSrv.EXPECT().Save(gomock.Any(), postDto).Return(nil, errors.New("some error"))
 
 	postHandler.AddPost(w, req)
 
@@ -191,6 +193,49 @@ func TestAdd(t *testing.T) {
 		return
 	}
 
+	// Fields the client can't set are dropped
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/posts", `
+	{
+		"category": "music",
+		"text": "my text in post",
+		"title": "akro",
+		"type": "text",
+		"score": 100,
+		"views": 100,
+		"author": {"username": "admin", "id": "1"}
+	}`)
+	req.Header.Add("Content-type", "application/json")
+
+	pSrv.EXPECT().Save(gomock.Any(), postDto).Return(post, nil)
+
+	postHandler.AddPost(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Method's Save returns validation error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/posts", body)
+	req.Header.Add("Content-type", "application/json")
+
+	validationErr := &domain.ValidationError{Errors: []domain.FieldError{{Location: "body", Param: "title", Msg: "is required"}}}
+	pSrv.EXPECT().Save(gomock.Any(), postDto).Return(nil, validationErr)
+
+	postHandler.AddPost(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got: %d", w.Code)
+		return
+	}
+
+	errResp := &ErrorResponse{}
+
+	if err := json.Unmarshal(w.Body.Bytes(), errResp); err != nil || len(errResp.Errors) != 1 || errResp.Errors[0].Param != "title" {
+		t.Errorf("expected field errors, got: %s", w.Body.String())
+		return
+	}
+
 	// Writter returns error
 	_, req = getRequestRecorder(ctx, true, "POST", "/api/posts", `
 	{
@@ -203,7 +248,7 @@ func TestAdd(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	pSrv.EXPECT().Save(post).Return(post, nil)
+	pSrv.EXPECT().Save(gomock.Any(), postDto).Return(post, nil)
 
 	postHandler.AddPost(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -233,14 +278,14 @@ func TestDelete(t *testing.T) {
 			User: user,
 		})
 
-	deletePostDto := &service.DeletePost{PostID: "1"}
+	deletePostDto := &service.DeletePost{User: user, PostID: "1"}
 	vars := map[string]string{"POST_ID": "1"}
 
 	// OK
 	w, req := getRequestRecorder(ctx, true, "DELETE", "/api/post/{POST_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().Delete(deletePostDto).Return(nil)
+	pSrv.EXPECT().Delete(gomock.Any(), deletePostDto).Return(nil)
 
 	postHandler.DeletePost(w, req)
 
@@ -258,11 +303,34 @@ func TestDelete(t *testing.T) {
 		return
 	}
 
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "DELETE", "/api/post/{POST_ID}", "")
+	req = mux.SetURLVars(req, vars)
+	postHandler.DeletePost(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Not the author
+	w, req = getRequestRecorder(ctx, true, "DELETE", "/api/post/{POST_ID}", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Delete(gomock.Any(), deletePostDto).Return(domain.ErrForbidden)
+
+	postHandler.DeletePost(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got: %d", w.Code)
+		return
+	}
+
 	// Method's Delete returns error
 	w, req = getRequestRecorder(ctx, true, "DELETE", "/api/post/{POST_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().Delete(deletePostDto).Return(errors.New("some error"))
+	pSrv.EXPECT().Delete(gomock.Any(), deletePostDto).Return(errors.New("some error"))
 
 	postHandler.DeletePost(w, req)
 
@@ -276,7 +344,7 @@ func TestDelete(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	pSrv.EXPECT().Delete(deletePostDto).Return(nil)
+	pSrv.EXPECT().Delete(gomock.Any(), deletePostDto).Return(nil)
 
 	postHandler.DeletePost(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -304,7 +372,7 @@ func TestGetUserPosts(t *testing.T) {
 	w, req := getRequestRecorder(ctx, false, "GET", "/u/{USER_LOGIN}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().GetBy(getByDto).Return([]*domain.Post{}, nil)
+	pSrv.EXPECT().GetBy(gomock.Any(), getByDto).Return([]*domain.Post{}, nil)
 
 	postHandler.GetUserPosts(w, req)
 
@@ -326,7 +394,7 @@ func TestGetUserPosts(t *testing.T) {
 	w, req = getRequestRecorder(ctx, false, "GET", "/u/{USER_LOGIN}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().GetBy(getByDto).Return(nil, errors.New("some error"))
+	pSrv.EXPECT().GetBy(gomock.Any(), getByDto).Return(nil, errors.New("some error"))
 
 	postHandler.GetUserPosts(w, req)
 
@@ -340,7 +408,7 @@ func TestGetUserPosts(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	pSrv.EXPECT().GetBy(getByDto).Return([]*domain.Post{}, nil)
+	pSrv.EXPECT().GetBy(gomock.Any(), getByDto).Return([]*domain.Post{}, nil)
 
 	postHandler.GetUserPosts(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -364,7 +432,7 @@ func TestShowPosts(t *testing.T) {
 	// OK
 	w, req := getRequestRecorder(ctx, false, "GET", "/api/posts/", "")
 
-	pSrv.EXPECT().Get().Return([]*domain.Post{}, nil)
+	pSrv.EXPECT().Get(gomock.Any()).Return([]*domain.Post{}, nil)
 
 	postHandler.ShowPosts(w, req)
 
@@ -376,7 +444,7 @@ func TestShowPosts(t *testing.T) {
 	// Method's Get returns error
 	w, req = getRequestRecorder(ctx, false, "GET", "/api/posts/", "")
 
-	pSrv.EXPECT().Get().Return(nil, errors.New("some error"))
+	pSrv.EXPECT().Get(gomock.Any()).Return(nil, errors.New("some error"))
 
 	postHandler.ShowPosts(w, req)
 
@@ -389,7 +457,7 @@ func TestShowPosts(t *testing.T) {
 	w, req = getRequestRecorder(ctx, false, "GET", "/api/posts/", "")
 	req.Header.Add("Content-type", "application/json")
 
-	pSrv.EXPECT().Get().Return([]*domain.Post{}, nil)
+	pSrv.EXPECT().Get(gomock.Any()).Return([]*domain.Post{}, nil)
 
 	postHandler.ShowPosts(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -417,7 +485,7 @@ func TestShowPostsByFilter(t *testing.T) {
 	w, req := getRequestRecorder(ctx, false, "GET", "/api/posts/{CATEGORY_NAME}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().GetBy(getByDto).Return([]*domain.Post{}, nil)
+	pSrv.EXPECT().GetBy(gomock.Any(), getByDto).Return([]*domain.Post{}, nil)
 
 	postHandler.ShowPostsByFilter(w, req)
 
@@ -439,7 +507,7 @@ func TestShowPostsByFilter(t *testing.T) {
 	w, req = getRequestRecorder(ctx, false, "GET", "/api/posts/{CATEGORY_NAME}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().GetBy(getByDto).Return(nil, errors.New("some error"))
+	pSrv.EXPECT().GetBy(gomock.Any(), getByDto).Return(nil, errors.New("some error"))
 
 	postHandler.ShowPostsByFilter(w, req)
 
@@ -453,7 +521,7 @@ func TestShowPostsByFilter(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	pSrv.EXPECT().GetBy(getByDto).Return([]*domain.Post{}, nil)
+	pSrv.EXPECT().GetBy(gomock.Any(), getByDto).Return([]*domain.Post{}, nil)
 
 	postHandler.ShowPostsByFilter(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -482,8 +550,8 @@ func TestPostDetail(t *testing.T) {
 	w, req := getRequestRecorder(ctx, false, "GET", "/api/post/{POST_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
-	pSrv.EXPECT().IncrViews(incViewsDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
+	pSrv.EXPECT().IncrViews(gomock.Any(), incViewsDto).Return(nil)
 
 	postHandler.PostDetail(w, req)
 
@@ -505,7 +573,7 @@ func TestPostDetail(t *testing.T) {
 	w, req = getRequestRecorder(ctx, false, "GET", "/api/post/{POST_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(nil, errors.New("some error"))
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(nil, errors.New("some error"))
 
 	postHandler.PostDetail(w, req)
 
@@ -518,8 +586,8 @@ func TestPostDetail(t *testing.T) {
 	w, req = getRequestRecorder(ctx, false, "GET", "/api/post/{POST_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
-	pSrv.EXPECT().IncrViews(incViewsDto).Return(errors.New("some error"))
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
+	pSrv.EXPECT().IncrViews(gomock.Any(), incViewsDto).Return(errors.New("some error"))
 
 	postHandler.PostDetail(w, req)
 
@@ -533,8 +601,8 @@ func TestPostDetail(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
-	pSrv.EXPECT().IncrViews(incViewsDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
+	pSrv.EXPECT().IncrViews(gomock.Any(), incViewsDto).Return(nil)
 
 	postHandler.PostDetail(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -576,8 +644,8 @@ func TestPostVote(t *testing.T) {
 	w, req := getRequestRecorder(ctx, true, "GET", "/api/post/{POST_ID}/upvote", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().UpdateMetrics(updatePostDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), updatePostDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
 
 	postHandler.PostVote(w, req)
 
@@ -591,8 +659,8 @@ func TestPostVote(t *testing.T) {
 	w, req = getRequestRecorder(ctx, true, "GET", "/api/post/{POST_ID}/downvote", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().UpdateMetrics(updatePostDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), updatePostDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
 
 	postHandler.PostVote(w, req)
 
@@ -642,11 +710,24 @@ func TestPostVote(t *testing.T) {
 		return
 	}
 
+	// Post is locked
+	w, req = getRequestRecorder(ctx, true, "GET", "/api/post/{POST_ID}/downvote", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), updatePostDto).Return(domain.ErrPostLocked)
+
+	postHandler.PostVote(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got: %d", w.Code)
+		return
+	}
+
 	// Method's UpdateMetrics returns error
 	w, req = getRequestRecorder(ctx, true, "GET", "/api/post/{POST_ID}/downvote", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().UpdateMetrics(updatePostDto).Return(errors.New("some error"))
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), updatePostDto).Return(errors.New("some error"))
 
 	postHandler.PostVote(w, req)
 
@@ -659,8 +740,8 @@ func TestPostVote(t *testing.T) {
 	w, req = getRequestRecorder(ctx, true, "GET", "/api/post/{POST_ID}/downvote", "")
 	req = mux.SetURLVars(req, vars)
 
-	pSrv.EXPECT().UpdateMetrics(updatePostDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(nil, errors.New("some error"))
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), updatePostDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(nil, errors.New("some error"))
 
 	postHandler.PostVote(w, req)
 
@@ -674,8 +755,8 @@ func TestPostVote(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	pSrv.EXPECT().UpdateMetrics(updatePostDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), updatePostDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
 
 	postHandler.PostVote(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -720,8 +801,8 @@ func TestAddComment(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	req = mux.SetURLVars(req, vars)
 
-	cSrv.EXPECT().Add(addCommentDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
+	cSrv.EXPECT().Add(gomock.Any(), addCommentDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
 
 	postHandler.AddComment(w, req)
 
@@ -796,12 +877,26 @@ func TestAddComment(t *testing.T) {
 		return
 	}
 
+	// Post is archived
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}", `{"comment": "my comment!"}`)
+	req = mux.SetURLVars(req, vars)
+	req.Header.Add("Content-type", "application/json")
+
+	cSrv.EXPECT().Add(gomock.Any(), addCommentDto).Return(domain.ErrPostArchived)
+
+	postHandler.AddComment(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got: %d", w.Code)
+		return
+	}
+
 	// Method's Add returns error
 	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}", `{"comment": "my comment!"}`)
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	cSrv.EXPECT().Add(addCommentDto).Return(errors.New("some error"))
+	cSrv.EXPECT().Add(gomock.Any(), addCommentDto).Return(errors.New("some error"))
 
 	postHandler.AddComment(w, req)
 
@@ -815,8 +910,8 @@ func TestAddComment(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	cSrv.EXPECT().Add(addCommentDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(nil, errors.New("some error"))
+	cSrv.EXPECT().Add(gomock.Any(), addCommentDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(nil, errors.New("some error"))
 
 	postHandler.AddComment(w, req)
 
@@ -830,8 +925,8 @@ func TestAddComment(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	cSrv.EXPECT().Add(addCommentDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
+	cSrv.EXPECT().Add(gomock.Any(), addCommentDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
 
 	postHandler.AddComment(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -860,7 +955,7 @@ func TestPostDelComment(t *testing.T) {
 			User: user,
 		})
 
-	deleteCommentDto := &service.DeleteComment{PostID: "1", CommentID: "1"}
+	deleteCommentDto := &service.DeleteComment{User: user, PostID: "1", CommentID: "1"}
 	getOnePostDto := &service.GetOnePost{PostID: "1"}
 	vars := map[string]string{"POST_ID": "1", "COMMENT_ID": "1"}
 
@@ -868,8 +963,8 @@ func TestPostDelComment(t *testing.T) {
 	w, req := getRequestRecorder(ctx, true, "DELETE", "/api/post/{POST_ID}/{COMMENT_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	cSrv.EXPECT().Delete(deleteCommentDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
+	cSrv.EXPECT().Delete(gomock.Any(), deleteCommentDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
 
 	postHandler.DeleteComment(w, req)
 
@@ -898,11 +993,21 @@ func TestPostDelComment(t *testing.T) {
 		return
 	}
 
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "DELETE", "/api/post/{POST_ID}/{COMMENT_ID}", "")
+	req = mux.SetURLVars(req, vars)
+	postHandler.DeleteComment(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
 	// Method's Delete returns error
 	w, req = getRequestRecorder(ctx, true, "DELETE", "/api/post/{POST_ID}/{COMMENT_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	cSrv.EXPECT().Delete(deleteCommentDto).Return(errors.New("some error"))
+	cSrv.EXPECT().Delete(gomock.Any(), deleteCommentDto).Return(errors.New("some error"))
 
 	postHandler.DeleteComment(w, req)
 
@@ -915,8 +1020,8 @@ func TestPostDelComment(t *testing.T) {
 	w, req = getRequestRecorder(ctx, true, "DELETE", "/api/post/{POST_ID}/{COMMENT_ID}", "")
 	req = mux.SetURLVars(req, vars)
 
-	cSrv.EXPECT().Delete(deleteCommentDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(nil, errors.New("some error"))
+	cSrv.EXPECT().Delete(gomock.Any(), deleteCommentDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(nil, errors.New("some error"))
 
 	postHandler.DeleteComment(w, req)
 
@@ -930,8 +1035,8 @@ func TestPostDelComment(t *testing.T) {
 	req = mux.SetURLVars(req, vars)
 	req.Header.Add("Content-type", "application/json")
 
-	cSrv.EXPECT().Delete(deleteCommentDto).Return(nil)
-	pSrv.EXPECT().GetOne(getOnePostDto).Return(&domain.Post{}, nil)
+	cSrv.EXPECT().Delete(gomock.Any(), deleteCommentDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
 
 	postHandler.DeleteComment(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -940,3 +1045,188 @@ func TestPostDelComment(t *testing.T) {
 		return
 	}
 }
+
+func TestRestorePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pSrv := mocks.NewMockPostService(ctrl)
+	cSrv := mocks.NewMockCommentService(ctrl)
+	sSrv := mocks.NewMockSessionService(ctrl)
+
+	postHandler := NewPostHandler(logger.NewLogger(), pSrv, cSrv, sSrv)
+
+	var sess domain.SessionContextKey = "session"
+	user, _ := getTestData()
+	ctx := context.WithValue(context.TODO(), sess, &domain.Session{User: user})
+
+	restorePostDto := &service.RestorePost{User: user, PostID: "1"}
+	getOnePostDto := &service.GetOnePost{PostID: "1"}
+	vars := map[string]string{"POST_ID": "1"}
+
+	// OK
+	w, req := getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Restore(gomock.Any(), restorePostDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
+
+	postHandler.RestorePost(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Pass POST_ID
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/restore", "")
+	postHandler.RestorePost(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "POST", "/api/post/{POST_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+	postHandler.RestorePost(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Not the author
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Restore(gomock.Any(), restorePostDto).Return(domain.ErrForbidden)
+
+	postHandler.RestorePost(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got: %d", w.Code)
+		return
+	}
+
+	// Restore window has expired
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Restore(gomock.Any(), restorePostDto).Return(domain.ErrRestoreWindowClosed)
+
+	postHandler.RestorePost(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got: %d", w.Code)
+		return
+	}
+
+	// Method's GetOne returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Restore(gomock.Any(), restorePostDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(nil, errors.New("some error"))
+
+	postHandler.RestorePost(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+
+	// Writter returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	pSrv.EXPECT().Restore(gomock.Any(), restorePostDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
+
+	postHandler.RestorePost(&BadResponseWriter{ResponseWriter: w}, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestRestoreComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pSrv := mocks.NewMockPostService(ctrl)
+	cSrv := mocks.NewMockCommentService(ctrl)
+	sSrv := mocks.NewMockSessionService(ctrl)
+
+	postHandler := NewPostHandler(logger.NewLogger(), pSrv, cSrv, sSrv)
+
+	var sess domain.SessionContextKey = "session"
+	user, _ := getTestData()
+	ctx := context.WithValue(context.TODO(), sess, &domain.Session{User: user})
+
+	restoreCommentDto := &service.RestoreComment{User: user, PostID: "1", CommentID: "2"}
+	getOnePostDto := &service.GetOnePost{PostID: "1"}
+	vars := map[string]string{"POST_ID": "1", "COMMENT_ID": "2"}
+
+	// OK
+	w, req := getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/{COMMENT_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	cSrv.EXPECT().Restore(gomock.Any(), restoreCommentDto).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), getOnePostDto).Return(&domain.Post{}, nil)
+
+	postHandler.RestoreComment(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Pass COMMENT_ID
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/{COMMENT_ID}/restore", "")
+	req = mux.SetURLVars(req, map[string]string{"POST_ID": "1"})
+	postHandler.RestoreComment(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "POST", "/api/post/{POST_ID}/{COMMENT_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+	postHandler.RestoreComment(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+
+	// Not the author
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/{COMMENT_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	cSrv.EXPECT().Restore(gomock.Any(), restoreCommentDto).Return(domain.ErrForbidden)
+
+	postHandler.RestoreComment(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got: %d", w.Code)
+		return
+	}
+
+	// Method's Restore returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/post/{POST_ID}/{COMMENT_ID}/restore", "")
+	req = mux.SetURLVars(req, vars)
+
+	cSrv.EXPECT().Restore(gomock.Any(), restoreCommentDto).Return(errors.New("some error"))
+
+	postHandler.RestoreComment(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"github.com/akrovv/redditclone/pkg/logger"
 )
 
+// IndexPage is the page of the React app, every page of the frontend.
+const IndexPage = "front/html/index.html"
+
 type RootHandler struct {
 	logger logger.Logger
 }
@@ -14,7 +17,11 @@ func NewRootHandler(logger logger.Logger) *RootHandler {
 	return &RootHandler{logger: logger}
 }
 
+func (h RootHandler) log(r *http.Request) logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
 func (h RootHandler) Main(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, "front/html/index.html")
-	h.logger.Info("sent file with static")
+	http.ServeFile(w, r, IndexPage)
+	h.log(r).Info("sent file with static")
 }
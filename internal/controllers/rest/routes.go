@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// Route binds an operation of the OpenAPI spec to its handler. Name is the
+// operationId, so the spec, the router and the validation middleware all
+// refer to the route the same way.
+type Route struct {
+	Name    string
+	Method  string
+	Path    string
+	Handler http.HandlerFunc
+}
+
+type Routes []Route
+
+// Handlers are the handlers the API routes are served by.
+type Handlers struct {
+	Users      *userHandler
+	Posts      *postHandler
+	Moderation *moderationHandler
+	Automod    *automodHandler
+	OpenAPI    *openAPIHandler
+	V2         *v2Handler
+}
+
+// APIRoutes is the route table of the API. Every route must be described in
+// api/openapi.yaml under the same path, method and operationId.
+//
+// The routes under /api without a version are v1, middleware.LegacyAPI also
+// serves them under /api/v1. v2 shares the services and the automod handlers
+// with v1.
+func APIRoutes(h *Handlers) Routes {
+	return Routes{
+		{"getOpenAPI", http.MethodGet, "/api/openapi.json", h.OpenAPI.Spec},
+
+		// User
+		{"register", http.MethodPost, "/api/register", h.Users.Register},
+		{"login", http.MethodPost, "/api/login", h.Users.Login},
+
+		// Posts
+		{"listPosts", http.MethodGet, "/api/posts/", h.Posts.ShowPosts},
+		{"createPost", http.MethodPost, "/api/posts", h.Posts.AddPost},
+		{"listCategoryPosts", http.MethodGet, "/api/posts/{CATEGORY_NAME}", h.Posts.ShowPostsByFilter},
+		{"getPost", http.MethodGet, "/api/post/{POST_ID}", h.Posts.PostDetail},
+		{"createComment", http.MethodPost, "/api/post/{POST_ID}", h.Posts.AddComment},
+		{"deleteComment", http.MethodDelete, "/api/post/{POST_ID}/{COMMENT_ID}", h.Posts.DeleteComment},
+		{"upvotePost", http.MethodGet, "/api/post/{POST_ID}/upvote", h.Posts.PostVote},
+		{"downvotePost", http.MethodGet, "/api/post/{POST_ID}/downvote", h.Posts.PostVote},
+		{"unvotePost", http.MethodGet, "/api/post/{POST_ID}/unvote", h.Posts.PostVote},
+		{"deletePost", http.MethodDelete, "/api/post/{POST_ID}", h.Posts.DeletePost},
+		{"restorePost", http.MethodPost, "/api/post/{POST_ID}/restore", h.Posts.RestorePost},
+		{"restoreComment", http.MethodPost, "/api/post/{POST_ID}/{COMMENT_ID}/restore", h.Posts.RestoreComment},
+		{"listUserPosts", http.MethodGet, "/api/user/{USER_LOGIN}", h.Posts.GetUserPosts},
+
+		// Moderation
+		{"reportPost", http.MethodPost, "/api/post/{POST_ID}/report", h.Moderation.Report},
+		{"reportComment", http.MethodPost, "/api/post/{POST_ID}/{COMMENT_ID}/report", h.Moderation.Report},
+		{"listReports", http.MethodGet, "/api/mod/{CATEGORY_NAME}/reports", h.Moderation.Queue},
+		{"approveReport", http.MethodPost, "/api/mod/reports/{REPORT_ID}/approve", h.Moderation.Approve},
+		{"removeReported", http.MethodPost, "/api/mod/reports/{REPORT_ID}/remove", h.Moderation.Remove},
+		{"lockPost", http.MethodPost, "/api/mod/post/{POST_ID}/lock", h.Moderation.Lock},
+		{"unlockPost", http.MethodPost, "/api/mod/post/{POST_ID}/unlock", h.Moderation.Unlock},
+		{"stickyPost", http.MethodPost, "/api/mod/post/{POST_ID}/sticky", h.Moderation.Sticky},
+		{"unstickyPost", http.MethodPost, "/api/mod/post/{POST_ID}/unsticky", h.Moderation.Unsticky},
+		{"getModLog", http.MethodGet, "/api/mod/log", h.Moderation.ModLog},
+		{"getCategoryModLog", http.MethodGet, "/api/mod/{CATEGORY_NAME}/log", h.Moderation.ModLog},
+
+		// Automod
+		{"validateAutomod", http.MethodPost, "/api/mod/automod/validate", h.Automod.Validate},
+		{"getAutomod", http.MethodGet, "/api/mod/{CATEGORY_NAME}/automod", h.Automod.GetRules},
+		{"saveAutomod", http.MethodPut, "/api/mod/{CATEGORY_NAME}/automod", h.Automod.SaveRules},
+		{"dryRunAutomod", http.MethodPost, "/api/mod/{CATEGORY_NAME}/automod/dryrun", h.Automod.DryRun},
+
+		// v2 User
+		{"v2CreateUser", http.MethodPost, "/api/v2/users", h.V2.CreateUser},
+		{"v2CreateSession", http.MethodPost, "/api/v2/sessions", h.V2.CreateSession},
+		{"v2ListUserPosts", http.MethodGet, "/api/v2/users/{USER_LOGIN}/posts", h.V2.ListUserPosts},
+
+		// v2 Posts
+		{"v2ListPosts", http.MethodGet, "/api/v2/posts", h.V2.ListPosts},
+		{"v2CreatePost", http.MethodPost, "/api/v2/posts", h.V2.CreatePost},
+		{"v2GetPost", http.MethodGet, "/api/v2/posts/{POST_ID}", h.V2.GetPost},
+		{"v2DeletePost", http.MethodDelete, "/api/v2/posts/{POST_ID}", h.V2.DeletePost},
+		{"v2RestorePost", http.MethodPost, "/api/v2/posts/{POST_ID}/restore", h.V2.RestorePost},
+		{"v2Vote", http.MethodPost, "/api/v2/posts/{POST_ID}/votes", h.V2.Vote},
+		{"v2Unvote", http.MethodDelete, "/api/v2/posts/{POST_ID}/votes", h.V2.Unvote},
+		{"v2CreateComment", http.MethodPost, "/api/v2/posts/{POST_ID}/comments", h.V2.CreateComment},
+		{"v2DeleteComment", http.MethodDelete, "/api/v2/posts/{POST_ID}/comments/{COMMENT_ID}", h.V2.DeleteComment},
+		{"v2RestoreComment", http.MethodPost, "/api/v2/posts/{POST_ID}/comments/{COMMENT_ID}/restore", h.V2.RestoreComment},
+
+		// v2 Moderation
+		{"v2ReportPost", http.MethodPost, "/api/v2/posts/{POST_ID}/reports", h.V2.CreateReport},
+		{"v2ReportComment", http.MethodPost, "/api/v2/posts/{POST_ID}/comments/{COMMENT_ID}/reports", h.V2.CreateReport},
+		{"v2ListReports", http.MethodGet, "/api/v2/mod/{CATEGORY_NAME}/reports", h.V2.ListReports},
+		{"v2ApproveReport", http.MethodPost, "/api/v2/mod/reports/{REPORT_ID}/approve", h.V2.ApproveReport},
+		{"v2RemoveReported", http.MethodPost, "/api/v2/mod/reports/{REPORT_ID}/remove", h.V2.RemoveReported},
+		{"v2LockPost", http.MethodPut, "/api/v2/mod/posts/{POST_ID}/lock", h.V2.LockPost},
+		{"v2UnlockPost", http.MethodDelete, "/api/v2/mod/posts/{POST_ID}/lock", h.V2.UnlockPost},
+		{"v2StickyPost", http.MethodPut, "/api/v2/mod/posts/{POST_ID}/sticky", h.V2.StickyPost},
+		{"v2UnstickyPost", http.MethodDelete, "/api/v2/mod/posts/{POST_ID}/sticky", h.V2.UnstickyPost},
+		{"v2GetModLog", http.MethodGet, "/api/v2/mod/log", h.V2.ModLog},
+		{"v2GetCategoryModLog", http.MethodGet, "/api/v2/mod/{CATEGORY_NAME}/log", h.V2.ModLog},
+
+		// v2 Automod
+		{"v2ValidateAutomod", http.MethodPost, "/api/v2/mod/automod/validate", h.Automod.Validate},
+		{"v2GetAutomod", http.MethodGet, "/api/v2/mod/{CATEGORY_NAME}/automod", h.Automod.GetRules},
+		{"v2SaveAutomod", http.MethodPut, "/api/v2/mod/{CATEGORY_NAME}/automod", h.Automod.SaveRules},
+		{"v2DryRunAutomod", http.MethodPost, "/api/v2/mod/{CATEGORY_NAME}/automod/dryrun", h.Automod.DryRun},
+	}
+}
+
+// Wrap puts a middleware around the handler of the named route.
+func (rs Routes) Wrap(name string, wrap func(http.HandlerFunc) http.HandlerFunc) {
+	for i := range rs {
+		if rs[i].Name == name {
+			rs[i].Handler = wrap(rs[i].Handler)
+		}
+	}
+}
+
+// NewRouter serves the frontend: the static files, the pages the React app
+// routes itself, and the API.
+func NewRouter(root *RootHandler, routes Routes) *mux.Router {
+	router := mux.NewRouter()
+
+	// Static
+	staticHandler := http.StripPrefix("/static/", http.FileServer(http.Dir("front")))
+	router.PathPrefix("/static").Handler(staticHandler)
+	router.Handle("/static/", staticHandler)
+
+	// Pages
+	router.HandleFunc("/", root.Main)
+	router.HandleFunc("/signup", root.Main)
+	router.HandleFunc("/login", root.Main)
+	router.HandleFunc("/u/{USER_LOGIN}", root.Main)
+	router.HandleFunc("/createpost", root.Main)
+	router.HandleFunc("/a/{CATEGORY_NAME}", root.Main)
+	router.HandleFunc("/a/{CATEGORY_NAME}/{POST_ID}", root.Main)
+
+	// API
+	routes.Register(router)
+
+	return router
+}
+
+// Register adds the routes to the router in the table order, the first
+// matching route wins.
+func (rs Routes) Register(router *mux.Router) {
+	for _, route := range rs {
+		router.HandleFunc(route.Path, route.Handler).Methods(route.Method).Name(route.Name)
+	}
+}
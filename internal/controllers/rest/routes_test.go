@@ -0,0 +1,117 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/akrovv/redditclone/api"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/gorilla/mux"
+)
+
+func testRoutes(t *testing.T) Routes {
+	spec, err := api.Load(context.Background())
+
+	if err != nil {
+		t.Fatalf("can't load spec: %s", err)
+	}
+
+	openAPIHandler, err := NewOpenAPIHandler(logger.NewNop(), spec)
+
+	if err != nil {
+		t.Fatalf("can't create openapi handler: %s", err)
+	}
+
+	return APIRoutes(&Handlers{
+		Users:      NewUserHandler(logger.NewNop(), nil, nil),
+		Posts:      NewPostHandler(logger.NewNop(), nil, nil, nil),
+		Moderation: NewModerationHandler(logger.NewNop(), nil, nil, nil),
+		Automod:    NewAutomodHandler(logger.NewNop(), nil),
+		OpenAPI:    openAPIHandler,
+		V2:         NewV2Handler(logger.NewNop(), nil, nil, nil, nil, nil, nil),
+	})
+}
+
+// TestRoutesInSpec walks the router main serves and checks that every API
+// route is described in the spec under its operationId, and that the spec
+// has no operation without a route.
+func TestRoutesInSpec(t *testing.T) {
+	spec, err := api.Load(context.Background())
+
+	if err != nil {
+		t.Errorf("can't load spec: %s", err)
+		return
+	}
+
+	router := NewRouter(NewRootHandler(logger.NewNop()), testRoutes(t))
+	seen := make(map[string]bool)
+
+	err = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+
+		if err != nil || !strings.HasPrefix(path, "/api/") {
+			return nil
+		}
+
+		methods, err := route.GetMethods()
+
+		if err != nil {
+			t.Errorf("route %s has no methods", path)
+			return nil
+		}
+
+		item := spec.Paths.Value(path)
+
+		if item == nil {
+			t.Errorf("route %s is not in the spec", path)
+			return nil
+		}
+
+		for _, method := range methods {
+			op := item.GetOperation(method)
+
+			if op == nil {
+				t.Errorf("route %s %s is not in the spec", method, path)
+				continue
+			}
+
+			if op.OperationID != route.GetName() {
+				t.Errorf("route %s %s is named %q, the spec calls it %q", method, path, route.GetName(), op.OperationID)
+			}
+
+			seen[method+" "+path] = true
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Errorf("can't walk router: %s", err)
+		return
+	}
+
+	for path, item := range spec.Paths.Map() {
+		for method := range item.Operations() {
+			if !seen[method+" "+path] {
+				t.Errorf("spec operation %s %s has no route", method, path)
+			}
+		}
+	}
+}
+
+func TestRoutesWrap(t *testing.T) {
+	routes := testRoutes(t)
+	wrapped := false
+
+	routes.Wrap("login", func(next http.HandlerFunc) http.HandlerFunc {
+		wrapped = true
+		return next
+	})
+
+	if !wrapped {
+		t.Error("expected login route to be wrapped")
+		return
+	}
+}
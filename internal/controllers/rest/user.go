@@ -2,10 +2,12 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 
 	"io"
 	"net/http"
 
+	"github.com/akrovv/redditclone/internal/domain"
 	"github.com/akrovv/redditclone/internal/service"
 	jsontransfer "github.com/akrovv/redditclone/pkg/jsonTransfer"
 	"github.com/akrovv/redditclone/pkg/logger"
@@ -21,18 +23,22 @@ func NewUserHandler(logger logger.Logger, userService UserService, sessionServic
 	return &userHandler{logger: logger, userService: userService, sessionService: sessionService}
 }
 
+func (h userHandler) log(r *http.Request) logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
 func (h userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get("Content-type") != "application/json" {
-		h.logger.Infof("not found application/json header")
-		http.Error(w, "not found application/json header", http.StatusBadRequest)
+		h.log(r).Infof("not found application/json header")
+		WriteError(w, "not found application/json header", http.StatusBadRequest)
 		return
 	}
 
 	data, err := io.ReadAll(r.Body)
 
 	if err != nil {
-		h.logger.Infof("can't read form: %w", err)
-		http.Error(w, "can't read form", http.StatusInternalServerError)
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
 		return
 	}
 	defer r.Body.Close()
@@ -41,29 +47,36 @@ func (h userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(data, getUserDto)
 
 	if err != nil {
-		h.logger.Infof("can't unmarshall json to getUser: %w", err)
-		http.Error(w, "can't unmarshall json", http.StatusInternalServerError)
+		h.log(r).Infof("can't unmarshall json to getUser: %v", err)
+		WriteError(w, "can't unmarshall json", http.StatusInternalServerError)
 		return
 	}
 
-	user, err := h.userService.Get(getUserDto)
+	user, err := h.userService.Get(r.Context(), getUserDto)
+
+	if errors.Is(err, domain.ErrNotFound) {
+		h.log(r).Infof("bad login or password for %s", getUserDto.Username)
+		WriteError(w, "bad login or password", http.StatusUnauthorized)
+		return
+	}
 
 	if err != nil {
-		h.logger.Infof("can't get user: %w", err)
-		http.Error(w, "can't get user", http.StatusInternalServerError)
+		h.log(r).Infof("can't get user: %v", err)
+		writeDomainError(w, err, "can't get user")
 		return
 	}
 
 	sessionDto := &service.CreateSession{
 		ID:       user.ID,
 		Username: user.Username,
+		Role:     user.Role,
 	}
 
-	sess, err := h.sessionService.Create(sessionDto)
+	sess, err := h.sessionService.Create(r.Context(), sessionDto)
 
 	if err != nil {
-		h.logger.Infof("can't create session: %w", err)
-		http.Error(w, "can't create session", http.StatusInternalServerError)
+		h.log(r).Infof("can't create session: %v", err)
+		writeDomainError(w, err, "can't create session")
 		return
 	}
 
@@ -74,34 +87,34 @@ func (h userHandler) Login(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		h.logger.Infof("can't marshall token to json: %w", err)
-		http.Error(w, "can't marhsall token to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall token to json: %v", err)
+		WriteError(w, "can't marhsall token to json", http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.Infof("[%s] %s created session for user  with session's id [%s]", r.Method, r.URL.Path, sess.ID)
+	h.log(r).Infof("[%s] %s created session for user  with session's id [%s]", r.Method, r.URL.Path, sess.ID)
 	w.Header().Add("Content-type", "application/json")
 
 	_, err = w.Write(token)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
 
 func (h userHandler) Register(w http.ResponseWriter, r *http.Request) {
 	if r.Header.Get("Content-type") != "application/json" {
-		h.logger.Infof("not found application/json header")
-		http.Error(w, "not found application/json header", http.StatusBadRequest)
+		h.log(r).Infof("not found application/json header")
+		WriteError(w, "not found application/json header", http.StatusBadRequest)
 		return
 	}
 
 	data, err := io.ReadAll(r.Body)
 	if err != nil {
-		h.logger.Infof("can't read form: %w", err)
-		http.Error(w, "can't read form", http.StatusInternalServerError)
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
 		return
 	}
 	defer r.Body.Close()
@@ -110,44 +123,45 @@ func (h userHandler) Register(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(data, userSaveDto)
 
 	if err != nil {
-		h.logger.Infof("can't unmarshall json to getUser: %w", err)
-		http.Error(w, "can't unmarshall json", http.StatusInternalServerError)
+		h.log(r).Infof("can't unmarshall json to getUser: %v", err)
+		WriteError(w, "can't unmarshall json", http.StatusInternalServerError)
 		return
 	}
 
 	userGetDto := &service.GetUser{Username: userSaveDto.Username}
-	user, err := h.userService.Get(userGetDto)
+	user, err := h.userService.Get(r.Context(), userGetDto)
 
-	if err != nil && err.Error() != "sql: no rows in result set" {
-		h.logger.Infof("can't checked user: %w", err)
-		http.Error(w, "can't checked user", http.StatusInternalServerError)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		h.log(r).Infof("can't checked user: %v", err)
+		WriteError(w, "can't checked user", http.StatusInternalServerError)
 		return
 	}
 
 	if user != nil {
-		h.logger.Info("user already exists")
-		http.Error(w, "user already exists", http.StatusBadRequest)
+		h.log(r).Info("user already exists")
+		WriteError(w, "user already exists", http.StatusConflict)
 		return
 	}
 
-	user, err = h.userService.Save(userSaveDto)
+	user, err = h.userService.Save(r.Context(), userSaveDto)
 
 	if err != nil {
-		h.logger.Infof("can't save user in repo: %w", err)
-		http.Error(w, "can't save user in repo", http.StatusInternalServerError)
+		h.log(r).Infof("can't save user in repo: %v", err)
+		writeDomainError(w, err, "can't save user in repo")
 		return
 	}
 
 	sessionDto := &service.CreateSession{
 		ID:       user.ID,
 		Username: user.Username,
+		Role:     user.Role,
 	}
 
-	sess, err := h.sessionService.Create(sessionDto)
+	sess, err := h.sessionService.Create(r.Context(), sessionDto)
 
 	if err != nil {
-		h.logger.Infof("can't create session: %w", err)
-		http.Error(w, "can't create session", http.StatusInternalServerError)
+		h.log(r).Infof("can't create session: %v", err)
+		writeDomainError(w, err, "can't create session")
 		return
 	}
 
@@ -158,18 +172,18 @@ func (h userHandler) Register(w http.ResponseWriter, r *http.Request) {
 	})
 
 	if err != nil {
-		h.logger.Infof("can't marshall token to json: %w", err)
-		http.Error(w, "can't marhsall token to json", http.StatusInternalServerError)
+		h.log(r).Infof("can't marshall token to json: %v", err)
+		WriteError(w, "can't marhsall token to json", http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.Infof("[%s] %s created session for user with session's id [%s]", r.Method, r.URL.Path, sess.ID)
+	h.log(r).Infof("[%s] %s created session for user with session's id [%s]", r.Method, r.URL.Path, sess.ID)
 	w.Header().Add("Content-type", "application/json")
 	_, err = w.Write(token)
 
 	if err != nil {
-		h.logger.Infof("server can't write: %w", err)
-		http.Error(w, "server can't write", http.StatusInternalServerError)
+		h.log(r).Infof("server can't write: %v", err)
+		WriteError(w, "server can't write", http.StatusInternalServerError)
 		return
 	}
 }
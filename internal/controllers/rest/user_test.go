@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -42,9 +43,9 @@ func TestRegister(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Correct answer
-	us.EXPECT().Get(userGetDto).Return(nil, nil)
-	us.EXPECT().Save(userSaveDto).Return(user, nil)
-	ss.EXPECT().Create(sessionCreateDto).Return(session, nil)
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, nil)
+	us.EXPECT().Save(gomock.Any(), userSaveDto).Return(user, nil)
+	ss.EXPECT().Create(gomock.Any(), sessionCreateDto).Return(session, nil)
 
 	userHandler.Register(w, req)
 
@@ -81,7 +82,7 @@ func TestRegister(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	us.EXPECT().Get(userGetDto).Return(nil, errors.New("some error"))
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, errors.New("some error"))
 
 	userHandler.Register(w, req)
 
@@ -94,11 +95,48 @@ func TestRegister(t *testing.T) {
 	req = httptest.NewRequest("POST", "/api/register", strings.NewReader(body))
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
-	us.EXPECT().Get(userGetDto).Return(user, nil)
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(user, nil)
 	userHandler.Register(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("expected 500, got: %d", w.Code)
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got: %d", w.Code)
+		return
+	}
+
+	// Bad user, registered concurrently
+	req = httptest.NewRequest("POST", "/api/register", strings.NewReader(body))
+	req.Header.Add("Content-type", "application/json")
+	w = httptest.NewRecorder()
+
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, domain.NotFound("user not found"))
+	us.EXPECT().Save(gomock.Any(), userSaveDto).Return(nil, domain.AlreadyExists("user already exists"))
+
+	userHandler.Register(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got: %d", w.Code)
+		return
+	}
+
+	errResp := &ErrorResponse{}
+
+	if err := json.Unmarshal(w.Body.Bytes(), errResp); err != nil || errResp.Message != "user already exists" {
+		t.Errorf("expected error envelope, got: %s", w.Body.String())
+		return
+	}
+
+	// Bad user, invalid username or password
+	req = httptest.NewRequest("POST", "/api/register", strings.NewReader(body))
+	req.Header.Add("Content-type", "application/json")
+	w = httptest.NewRecorder()
+
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, domain.NotFound("user not found"))
+	us.EXPECT().Save(gomock.Any(), userSaveDto).Return(nil, &domain.ValidationError{Errors: []domain.FieldError{{Param: "password", Msg: "is required"}}})
+
+	userHandler.Register(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got: %d", w.Code)
 		return
 	}
 
@@ -107,8 +145,8 @@ func TestRegister(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	us.EXPECT().Get(userGetDto).Return(nil, nil)
-	us.EXPECT().Save(userSaveDto).Return(nil, errors.New("some error"))
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, nil)
+	us.EXPECT().Save(gomock.Any(), userSaveDto).Return(nil, errors.New("some error"))
 
 	userHandler.Register(w, req)
 
@@ -122,9 +160,9 @@ func TestRegister(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	us.EXPECT().Get(userGetDto).Return(nil, nil)
-	us.EXPECT().Save(userSaveDto).Return(user, nil)
-	ss.EXPECT().Create(sessionCreateDto).Return(nil, errors.New("some error"))
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, nil)
+	us.EXPECT().Save(gomock.Any(), userSaveDto).Return(user, nil)
+	ss.EXPECT().Create(gomock.Any(), sessionCreateDto).Return(nil, errors.New("some error"))
 
 	userHandler.Register(w, req)
 
@@ -151,9 +189,9 @@ func TestRegister(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	us.EXPECT().Get(userGetDto).Return(nil, nil)
-	us.EXPECT().Save(userSaveDto).Return(user, nil)
-	ss.EXPECT().Create(sessionCreateDto).Return(session, nil)
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, nil)
+	us.EXPECT().Save(gomock.Any(), userSaveDto).Return(user, nil)
+	ss.EXPECT().Create(gomock.Any(), sessionCreateDto).Return(session, nil)
 
 	userHandler.Register(&BadResponseWriter{ResponseWriter: w}, req)
 
@@ -189,8 +227,8 @@ func TestLogin(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Correct answer
-	us.EXPECT().Get(userGetDto).Return(user, nil)
-	ss.EXPECT().Create(sessionCreateDto).Return(session, nil)
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(user, nil)
+	ss.EXPECT().Create(gomock.Any(), sessionCreateDto).Return(session, nil)
 
 	userHandler.Login(w, req)
 
@@ -227,7 +265,7 @@ func TestLogin(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	us.EXPECT().Get(userGetDto).Return(nil, errors.New("some error"))
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, errors.New("some error"))
 
 	userHandler.Login(w, req)
 
@@ -236,13 +274,27 @@ func TestLogin(t *testing.T) {
 		return
 	}
 
+	// Bad login or password
+	req = httptest.NewRequest("POST", "/api/login", strings.NewReader(body))
+	req.Header.Add("Content-type", "application/json")
+	w = httptest.NewRecorder()
+
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(nil, domain.NotFound("user not found"))
+
+	userHandler.Login(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got: %d", w.Code)
+		return
+	}
+
 	// Bad session Create
 	req = httptest.NewRequest("POST", "/api/login", strings.NewReader(body))
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	us.EXPECT().Get(userGetDto).Return(user, nil)
-	ss.EXPECT().Create(sessionCreateDto).Return(nil, errors.New("some error"))
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(user, nil)
+	ss.EXPECT().Create(gomock.Any(), sessionCreateDto).Return(nil, errors.New("some error"))
 
 	userHandler.Login(w, req)
 
@@ -269,8 +321,8 @@ func TestLogin(t *testing.T) {
 	req.Header.Add("Content-type", "application/json")
 	w = httptest.NewRecorder()
 
-	us.EXPECT().Get(userGetDto).Return(user, nil)
-	ss.EXPECT().Create(sessionCreateDto).Return(session, nil)
+	us.EXPECT().Get(gomock.Any(), userGetDto).Return(user, nil)
+	ss.EXPECT().Create(gomock.Any(), sessionCreateDto).Return(session, nil)
 
 	userHandler.Login(&BadResponseWriter{ResponseWriter: w}, req)
 
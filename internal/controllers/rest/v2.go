@@ -0,0 +1,178 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	jsontransfer "github.com/akrovv/redditclone/pkg/jsonTransfer"
+	"github.com/akrovv/redditclone/pkg/logger"
+)
+
+// v2Handler serves /api/v2. It shares the services with the v1 handlers and
+// differs only in the HTTP shape: RESTful verbs, 201/204 statuses, 401 for a
+// missing session and pagination envelopes for lists.
+type v2Handler struct {
+	logger         logger.Logger
+	userService    UserService
+	sessionService SessionService
+	postService    PostService
+	commentService CommentService
+	reportService  ReportService
+	modLogService  ModLogService
+}
+
+func NewV2Handler(logger logger.Logger, userService UserService, sessionService SessionService, postService PostService,
+	commentService CommentService, reportService ReportService, modLogService ModLogService) *v2Handler {
+	return &v2Handler{
+		logger:         logger,
+		userService:    userService,
+		sessionService: sessionService,
+		postService:    postService,
+		commentService: commentService,
+		reportService:  reportService,
+		modLogService:  modLogService,
+	}
+}
+
+func (h v2Handler) log(r *http.Request) logger.Logger {
+	return logger.FromContext(r.Context(), h.logger)
+}
+
+const (
+	defaultPageLimit = 25
+	maxPageLimit     = 100
+)
+
+// Page is the envelope of every v2 list. Total is left out when the storage
+// pages the list itself and doesn't count it. Next is the link to the next
+// page, if there may be one.
+type Page[T any] struct {
+	Items []T    `json:"items"`
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Total *int   `json:"total,omitempty"`
+	Next  string `json:"next,omitempty"`
+}
+
+// pageParams reads page and limit from the query. Page starts at 1, the
+// limit is capped at maxPageLimit.
+func pageParams(query url.Values) (page, limit int, err error) {
+	page, limit = 1, defaultPageLimit
+
+	if v := query.Get("page"); v != "" {
+		if page, err = strconv.Atoi(v); err != nil || page < 1 {
+			return 0, 0, domain.Validation("page must be a positive number")
+		}
+	}
+
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 1 {
+			return 0, 0, domain.Validation("limit must be a positive number")
+		}
+	}
+
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	return page, limit, nil
+}
+
+// paginate cuts one page out of a whole list.
+func paginate[T any](r *http.Request, items []T, page, limit int) *Page[T] {
+	total := len(items)
+	from := min((page-1)*limit, total)
+	to := min(from+limit, total)
+
+	p := &Page[T]{Items: append(make([]T, 0, to-from), items[from:to]...), Page: page, Limit: limit, Total: &total}
+
+	if to < total {
+		p.Next = nextPage(r, page)
+	}
+
+	return p
+}
+
+// nextPage links to the page after the current one, keeping the filters.
+func nextPage(r *http.Request, page int) string {
+	query := r.URL.Query()
+	query.Set("page", strconv.Itoa(page+1))
+
+	return r.URL.Path + "?" + query.Encode()
+}
+
+// user returns the user of the session. v2 answers 401 without one, v1
+// answered 404.
+func (h v2Handler) user(w http.ResponseWriter, r *http.Request) (*domain.User, bool) {
+	sessCtx := r.Context().Value(domain.SessionContextKey("session"))
+
+	if sessCtx == nil {
+		h.log(r).Info("can't find session")
+		WriteError(w, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	user, err := getUserFromSession(sessCtx)
+
+	if err != nil {
+		h.log(r).Infof("can't convert to session: %v", err)
+		WriteError(w, "can't convert to session", http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return user, true
+}
+
+// decode reads a JSON body into v. A body that isn't JSON is the client's
+// fault, so it is answered with 400, not 500 like in v1.
+func (h v2Handler) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Header.Get("Content-type") != contentType {
+		h.log(r).Infof("not found application/json header")
+		WriteError(w, "not found application/json header", http.StatusBadRequest)
+		return false
+	}
+
+	data, err := io.ReadAll(r.Body)
+	defer r.Body.Close()
+
+	if err != nil {
+		h.log(r).Infof("can't read form: %v", err)
+		WriteError(w, "can't read form", http.StatusInternalServerError)
+		return false
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		h.log(r).Infof("can't unmarshall json: %v", err)
+		WriteError(w, "invalid json", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
+
+func (h v2Handler) writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	data, err := jsontransfer.GetJSON(v)
+
+	if err != nil {
+		h.log(r).Infof("can't marshall to json: %v", err)
+		WriteError(w, "can't marshall to json", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-type", contentType)
+	w.WriteHeader(status)
+	_, err = w.Write(data)
+
+	if err != nil {
+		h.log(r).Infof("server can't write: %v", err)
+	}
+}
+
+func (h v2Handler) writeError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	h.log(r).Infof("%s: %v", fallback, err)
+	writeDomainError(w, err, fallback)
+}
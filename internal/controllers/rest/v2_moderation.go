@@ -0,0 +1,176 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// CreateReport reports the post or, under /comments/{COMMENT_ID}, the comment.
+func (h v2Handler) CreateReport(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &struct {
+		Reason string `json:"reason"`
+	}{}
+
+	if !h.decode(w, r, form) {
+		return
+	}
+
+	if strings.TrimSpace(form.Reason) == "" {
+		h.writeError(w, r, &domain.ValidationError{Errors: []domain.FieldError{
+			{Location: "body", Param: "reason", Msg: "is required"},
+		}}, "empty report reason")
+		return
+	}
+
+	vars := mux.Vars(r)
+	reportDto := &service.ReportContent{
+		User:      user,
+		PostID:    vars["POST_ID"],
+		CommentID: vars["COMMENT_ID"],
+		Reason:    form.Reason,
+	}
+
+	report, err := h.reportService.Report(r.Context(), reportDto)
+
+	if err != nil {
+		h.writeError(w, r, err, "can't save report")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusCreated, report)
+}
+
+func (h v2Handler) ListReports(w http.ResponseWriter, r *http.Request) {
+	page, limit, err := pageParams(r.URL.Query())
+
+	if err != nil {
+		h.writeError(w, r, err, "bad page")
+		return
+	}
+
+	reports, err := h.reportService.Queue(r.Context(), &service.GetQueue{Category: mux.Vars(r)["CATEGORY_NAME"]})
+
+	if err != nil {
+		h.writeError(w, r, err, "can't get reports")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, paginate(r, reports, page, limit))
+}
+
+func (h v2Handler) ApproveReport(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.reportService.Approve)
+}
+
+func (h v2Handler) RemoveReported(w http.ResponseWriter, r *http.Request) {
+	h.resolve(w, r, h.reportService.Remove)
+}
+
+func (h v2Handler) resolve(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, dto *service.ResolveReport) error) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	err := action(r.Context(), &service.ResolveReport{User: user, ReportID: mux.Vars(r)["REPORT_ID"], Reason: r.URL.Query().Get("reason")})
+
+	if err != nil {
+		h.writeError(w, r, err, "can't resolve report")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// LockPost and UnlockPost are PUT and DELETE on the lock of the post, the
+// same goes for the sticky.
+func (h v2Handler) LockPost(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Lock(r.Context(), &service.LockPost{User: user, PostID: postID, Locked: true, Reason: reason})
+	})
+}
+
+func (h v2Handler) UnlockPost(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Lock(r.Context(), &service.LockPost{User: user, PostID: postID, Locked: false, Reason: reason})
+	})
+}
+
+func (h v2Handler) StickyPost(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Sticky(r.Context(), &service.StickyPost{User: user, PostID: postID, Stickied: true, Reason: reason})
+	})
+}
+
+func (h v2Handler) UnstickyPost(w http.ResponseWriter, r *http.Request) {
+	h.moderatePost(w, r, func(user *domain.User, postID, reason string) error {
+		return h.postService.Sticky(r.Context(), &service.StickyPost{User: user, PostID: postID, Stickied: false, Reason: reason})
+	})
+}
+
+func (h v2Handler) moderatePost(w http.ResponseWriter, r *http.Request, action func(user *domain.User, postID, reason string) error) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	if err := action(user, mux.Vars(r)["POST_ID"], r.URL.Query().Get("reason")); err != nil {
+		h.writeError(w, r, err, "can't moderate post")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ModLog is paged by the storage, so the envelope has no total and links to
+// the next page whenever the current one is full.
+func (h v2Handler) ModLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	page, limit, err := pageParams(query)
+
+	if err != nil {
+		h.writeError(w, r, err, "bad page")
+		return
+	}
+
+	getModLogDto := &service.GetModLog{
+		Category:  mux.Vars(r)["CATEGORY_NAME"],
+		Action:    query.Get("action"),
+		Moderator: query.Get("moderator"),
+		TargetID:  query.Get("target"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	entries, err := h.modLogService.Get(r.Context(), getModLogDto)
+
+	if err != nil {
+		h.writeError(w, r, err, "can't get mod log")
+		return
+	}
+
+	p := &Page[*domain.ModLogEntry]{Items: entries, Page: page, Limit: limit}
+
+	if p.Items == nil {
+		p.Items = []*domain.ModLogEntry{}
+	}
+
+	if len(entries) == limit {
+		p.Next = nextPage(r, page)
+	}
+
+	h.writeJSON(w, r, http.StatusOK, p)
+}
@@ -0,0 +1,260 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// ListPosts lists all posts or, with ?category=, the posts of a category.
+func (h v2Handler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	page, limit, err := pageParams(r.URL.Query())
+
+	if err != nil {
+		h.writeError(w, r, err, "bad page")
+		return
+	}
+
+	var posts []*domain.Post
+
+	if category := r.URL.Query().Get("category"); category != "" {
+		posts, err = h.postService.GetBy(r.Context(), &service.GetByPost{Category: "category", Data: category, SortField: "score"})
+	} else {
+		posts, err = h.postService.Get(r.Context())
+	}
+
+	if err != nil {
+		h.writeError(w, r, err, "can't get posts")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, paginate(r, posts, page, limit))
+}
+
+func (h v2Handler) ListUserPosts(w http.ResponseWriter, r *http.Request) {
+	page, limit, err := pageParams(r.URL.Query())
+
+	if err != nil {
+		h.writeError(w, r, err, "bad page")
+		return
+	}
+
+	getByDto := &service.GetByPost{Category: "author.username", Data: mux.Vars(r)["USER_LOGIN"], SortField: "created"}
+	posts, err := h.postService.GetBy(r.Context(), getByDto)
+
+	if err != nil {
+		h.writeError(w, r, err, "can't get user's posts")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, paginate(r, posts, page, limit))
+}
+
+// CreatePost answers with 201 and the link to the new post.
+func (h v2Handler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	postDto := &service.SavePost{}
+
+	if !h.decode(w, r, postDto) {
+		return
+	}
+
+	postDto.User = user
+	post, err := h.postService.Save(r.Context(), postDto)
+
+	if err != nil {
+		h.writeError(w, r, err, "can't save post in repo")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v2/posts/"+post.ID)
+	h.writeJSON(w, r, http.StatusCreated, post)
+}
+
+func (h v2Handler) GetPost(w http.ResponseWriter, r *http.Request) {
+	pi := mux.Vars(r)["POST_ID"]
+	post, err := h.postService.GetOne(r.Context(), &service.GetOnePost{PostID: pi})
+
+	if err != nil {
+		h.writeError(w, r, err, "can't get post")
+		return
+	}
+
+	if err = h.postService.IncrViews(r.Context(), &service.IncrViewsPost{PostID: pi}); err != nil {
+		h.writeError(w, r, err, "can't inc views")
+		return
+	}
+
+	h.writeJSON(w, r, http.StatusOK, post)
+}
+
+func (h v2Handler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	deletePostDto := &service.DeletePost{User: user, PostID: mux.Vars(r)["POST_ID"], Reason: r.URL.Query().Get("reason")}
+
+	if err := h.postService.Delete(r.Context(), deletePostDto); err != nil {
+		h.writeError(w, r, err, "can't delete post")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h v2Handler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	pi := mux.Vars(r)["POST_ID"]
+
+	if err := h.postService.Restore(r.Context(), &service.RestorePost{User: user, PostID: pi}); err != nil {
+		h.writeError(w, r, err, "can't restore post")
+		return
+	}
+
+	h.writePost(w, r, http.StatusOK, pi)
+}
+
+// Vote takes {"vote": 1} or {"vote": -1} and answers with the post, so the
+// client gets the new score.
+func (h v2Handler) Vote(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &struct {
+		Vote int8 `json:"vote"`
+	}{}
+
+	if !h.decode(w, r, form) {
+		return
+	}
+
+	if form.Vote != 1 && form.Vote != -1 {
+		h.writeError(w, r, &domain.ValidationError{Errors: []domain.FieldError{
+			{Location: "body", Param: "vote", Value: form.Vote, Msg: "must be 1 or -1"},
+		}}, "bad vote")
+		return
+	}
+
+	h.vote(w, r, user, form.Vote)
+}
+
+func (h v2Handler) Unvote(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	h.vote(w, r, user, 0)
+}
+
+func (h v2Handler) vote(w http.ResponseWriter, r *http.Request, user *domain.User, inc int8) {
+	pi := mux.Vars(r)["POST_ID"]
+	err := h.postService.UpdateMetrics(r.Context(), &service.UpdateMetricsPost{PostID: pi, Inc: inc, AuthorID: user.ID})
+
+	if err != nil {
+		h.writeError(w, r, err, "can't inc vote")
+		return
+	}
+
+	if inc == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.writePost(w, r, http.StatusOK, pi)
+}
+
+// CreateComment answers with 201 and the post, the service doesn't return
+// the comment itself.
+func (h v2Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &struct {
+		Comment string `json:"comment"`
+	}{}
+
+	if !h.decode(w, r, form) {
+		return
+	}
+
+	pi := mux.Vars(r)["POST_ID"]
+	err := h.commentService.Add(r.Context(), &service.AddComment{User: user, Body: form.Comment, PostID: pi})
+
+	if err != nil {
+		h.writeError(w, r, err, "can't add comment")
+		return
+	}
+
+	w.Header().Set("Location", "/api/v2/posts/"+pi)
+	h.writePost(w, r, http.StatusCreated, pi)
+}
+
+func (h v2Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	deleteCommentDto := &service.DeleteComment{User: user, PostID: vars["POST_ID"], CommentID: vars["COMMENT_ID"], Reason: r.URL.Query().Get("reason")}
+
+	if err := h.commentService.Delete(r.Context(), deleteCommentDto); err != nil {
+		h.writeError(w, r, err, "can't delete comment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h v2Handler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := h.user(w, r)
+
+	if !ok {
+		return
+	}
+
+	vars := mux.Vars(r)
+	restoreCommentDto := &service.RestoreComment{User: user, PostID: vars["POST_ID"], CommentID: vars["COMMENT_ID"]}
+
+	if err := h.commentService.Restore(r.Context(), restoreCommentDto); err != nil {
+		h.writeError(w, r, err, "can't restore comment")
+		return
+	}
+
+	h.writePost(w, r, http.StatusOK, vars["POST_ID"])
+}
+
+func (h v2Handler) writePost(w http.ResponseWriter, r *http.Request, status int, postID string) {
+	post, err := h.postService.GetOne(r.Context(), &service.GetOnePost{PostID: postID})
+
+	if err != nil {
+		h.writeError(w, r, err, "can't get post")
+		return
+	}
+
+	h.writeJSON(w, r, status, post)
+}
@@ -0,0 +1,361 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/internal/service/mocks"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+)
+
+func TestPageParams(t *testing.T) {
+	// Defaults
+	page, limit, err := pageParams(url.Values{})
+
+	if err != nil || page != 1 || limit != defaultPageLimit {
+		t.Errorf("expected 1 and %d, got: %d, %d, %v", defaultPageLimit, page, limit, err)
+		return
+	}
+
+	// Limit is capped
+	_, limit, err = pageParams(url.Values{"page": {"2"}, "limit": {"1000"}})
+
+	if err != nil || limit != maxPageLimit {
+		t.Errorf("expected %d, got: %d, %v", maxPageLimit, limit, err)
+		return
+	}
+
+	// Bad page
+	for _, query := range []url.Values{{"page": {"0"}}, {"page": {"a"}}, {"limit": {"-1"}}} {
+		if _, _, err = pageParams(query); !errors.Is(err, domain.ErrValidation) {
+			t.Errorf("expected validation error for %v, got: %v", query, err)
+			return
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+	_, req := getRequestRecorder(context.TODO(), false, "GET", "/api/v2/posts?category=music&limit=2", "")
+
+	// First page
+	p := paginate(req, items, 1, 2)
+
+	if len(p.Items) != 2 || *p.Total != 5 || p.Next != "/api/v2/posts?category=music&limit=2&page=2" {
+		t.Errorf("unexpected first page: %+v", p)
+		return
+	}
+
+	// Last page
+	p = paginate(req, items, 3, 2)
+
+	if len(p.Items) != 1 || p.Items[0] != 5 || p.Next != "" {
+		t.Errorf("unexpected last page: %+v", p)
+		return
+	}
+
+	// Past the end
+	p = paginate(req, items, 10, 2)
+
+	if p.Items == nil || len(p.Items) != 0 {
+		t.Errorf("expected empty items, got: %+v", p.Items)
+		return
+	}
+}
+
+func TestV2CreatePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pSrv := mocks.NewMockPostService(ctrl)
+	h := NewV2Handler(logger.NewNop(), nil, nil, pSrv, nil, nil, nil)
+
+	user, post := getTestData()
+	post.ID = "1"
+	ctx := context.WithValue(context.TODO(), domain.SessionContextKey("session"), &domain.Session{User: user})
+	body := `{"category": "music", "text": "my text in post", "title": "akro", "type": "text"}`
+
+	// OK
+	w, req := getRequestRecorder(ctx, true, "POST", "/api/v2/posts", body)
+	req.Header.Add("Content-type", "application/json")
+	pSrv.EXPECT().Save(gomock.Any(), &service.SavePost{User: user, Type: "text", Title: "akro", Category: "music", Text: "my text in post"}).Return(post, nil)
+
+	h.CreatePost(w, req)
+
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/api/v2/posts/1" {
+		t.Errorf("expected 201 with location, got: %d %q", w.Code, w.Header().Get("Location"))
+		return
+	}
+
+	// Pass context with session
+	w, req = getRequestRecorder(ctx, false, "POST", "/api/v2/posts", body)
+	req.Header.Add("Content-type", "application/json")
+
+	h.CreatePost(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got: %d", w.Code)
+		return
+	}
+
+	// Bad json
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/v2/posts", "{")
+	req.Header.Add("Content-type", "application/json")
+
+	h.CreatePost(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got: %d", w.Code)
+		return
+	}
+
+	// Method's Save returns error
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/v2/posts", body)
+	req.Header.Add("Content-type", "application/json")
+	pSrv.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil, &domain.ValidationError{})
+
+	h.CreatePost(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got: %d", w.Code)
+		return
+	}
+}
+
+func TestV2ListPosts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pSrv := mocks.NewMockPostService(ctrl)
+	h := NewV2Handler(logger.NewNop(), nil, nil, pSrv, nil, nil, nil)
+	_, post := getTestData()
+
+	// OK, category
+	w, req := getRequestRecorder(context.TODO(), false, "GET", "/api/v2/posts?category=music&limit=1", "")
+	pSrv.EXPECT().GetBy(gomock.Any(), &service.GetByPost{Category: "category", Data: "music", SortField: "score"}).Return([]*domain.Post{post, post}, nil)
+
+	h.ListPosts(w, req)
+
+	page := &Page[*domain.Post]{}
+
+	if err := json.Unmarshal(w.Body.Bytes(), page); err != nil || w.Code != http.StatusOK {
+		t.Errorf("expected 200 with page, got: %d %v", w.Code, err)
+		return
+	}
+
+	if len(page.Items) != 1 || *page.Total != 2 || page.Next == "" {
+		t.Errorf("unexpected page: %+v", page)
+		return
+	}
+
+	// OK, all
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/api/v2/posts", "")
+	pSrv.EXPECT().Get(gomock.Any()).Return(nil, nil)
+
+	h.ListPosts(w, req)
+
+	if w.Code != http.StatusOK || !json.Valid(w.Body.Bytes()) {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Bad page
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/api/v2/posts?page=0", "")
+
+	h.ListPosts(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got: %d", w.Code)
+		return
+	}
+
+	// Method's Get returns error
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/api/v2/posts", "")
+	pSrv.EXPECT().Get(gomock.Any()).Return(nil, errors.New("error"))
+
+	h.ListPosts(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got: %d", w.Code)
+		return
+	}
+}
+
+func TestV2Vote(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pSrv := mocks.NewMockPostService(ctrl)
+	h := NewV2Handler(logger.NewNop(), nil, nil, pSrv, nil, nil, nil)
+
+	user, post := getTestData()
+	ctx := context.WithValue(context.TODO(), domain.SessionContextKey("session"), &domain.Session{User: user})
+	vars := map[string]string{"POST_ID": "1"}
+
+	// OK
+	w, req := getRequestRecorder(ctx, true, "POST", "/api/v2/posts/1/votes", `{"vote": -1}`)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), &service.UpdateMetricsPost{PostID: "1", Inc: -1, AuthorID: user.ID}).Return(nil)
+	pSrv.EXPECT().GetOne(gomock.Any(), &service.GetOnePost{PostID: "1"}).Return(post, nil)
+
+	h.Vote(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	// Bad vote
+	w, req = getRequestRecorder(ctx, true, "POST", "/api/v2/posts/1/votes", `{"vote": 2}`)
+	req.Header.Add("Content-type", "application/json")
+	req = mux.SetURLVars(req, vars)
+
+	h.Vote(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got: %d", w.Code)
+		return
+	}
+
+	// Unvote
+	w, req = getRequestRecorder(ctx, true, "DELETE", "/api/v2/posts/1/votes", "")
+	req = mux.SetURLVars(req, vars)
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), &service.UpdateMetricsPost{PostID: "1", Inc: 0, AuthorID: user.ID}).Return(nil)
+
+	h.Unvote(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got: %d", w.Code)
+		return
+	}
+
+	// Method's UpdateMetrics returns error
+	w, req = getRequestRecorder(ctx, true, "DELETE", "/api/v2/posts/1/votes", "")
+	req = mux.SetURLVars(req, vars)
+	pSrv.EXPECT().UpdateMetrics(gomock.Any(), gomock.Any()).Return(domain.NotFound("post not found"))
+
+	h.Unvote(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got: %d", w.Code)
+		return
+	}
+}
+
+func TestV2CreateSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uSrv := mocks.NewMockUserService(ctrl)
+	sSrv := mocks.NewMockSessionService(ctrl)
+	h := NewV2Handler(logger.NewNop(), uSrv, sSrv, nil, nil, nil, nil)
+	user, _ := getTestData()
+	body := `{"username": "akro", "password": "akroakroakro"}`
+
+	// OK
+	w, req := getRequestRecorder(context.TODO(), false, "POST", "/api/v2/sessions", body)
+	req.Header.Add("Content-type", "application/json")
+	uSrv.EXPECT().Get(gomock.Any(), &service.GetUser{Username: "akro", Password: "akroakroakro"}).Return(user, nil)
+	sSrv.EXPECT().Create(gomock.Any(), gomock.Any()).Return(&domain.Session{ID: "token"}, nil)
+
+	h.CreateSession(w, req)
+
+	if w.Code != http.StatusCreated || w.Body.String() != `{"token":"token"}` {
+		t.Errorf("expected 201 with token, got: %d %s", w.Code, w.Body.String())
+		return
+	}
+
+	// Bad login or password
+	w, req = getRequestRecorder(context.TODO(), false, "POST", "/api/v2/sessions", body)
+	req.Header.Add("Content-type", "application/json")
+	uSrv.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, domain.ErrNotFound)
+
+	h.CreateSession(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got: %d", w.Code)
+		return
+	}
+}
+
+func TestV2ModLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mSrv := mocks.NewMockModLogService(ctrl)
+	h := NewV2Handler(logger.NewNop(), nil, nil, nil, nil, nil, mSrv)
+
+	// Full page links to the next one
+	w, req := getRequestRecorder(context.TODO(), false, "GET", "/api/v2/mod/music/log?limit=1", "")
+	req = mux.SetURLVars(req, map[string]string{"CATEGORY_NAME": "music"})
+	mSrv.EXPECT().Get(gomock.Any(), &service.GetModLog{Category: "music", Page: 1, Limit: 1}).Return([]*domain.ModLogEntry{{ID: "1"}}, nil)
+
+	h.ModLog(w, req)
+
+	page := &Page[*domain.ModLogEntry]{}
+
+	if err := json.Unmarshal(w.Body.Bytes(), page); err != nil || w.Code != http.StatusOK {
+		t.Errorf("expected 200 with page, got: %d %v", w.Code, err)
+		return
+	}
+
+	if page.Total != nil || page.Next != "/api/v2/mod/music/log?limit=1&page=2" {
+		t.Errorf("unexpected page: %+v", page)
+		return
+	}
+
+	// Empty page
+	w, req = getRequestRecorder(context.TODO(), false, "GET", "/api/v2/mod/log", "")
+	mSrv.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	h.ModLog(w, req)
+
+	if w.Body.String() != `{"items":[],"page":1,"limit":25}` {
+		t.Errorf("unexpected empty page: %s", w.Body.String())
+		return
+	}
+}
+
+func TestV2ModeratePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pSrv := mocks.NewMockPostService(ctrl)
+	h := NewV2Handler(logger.NewNop(), nil, nil, pSrv, nil, nil, nil)
+
+	user, _ := getTestData()
+	ctx := context.WithValue(context.TODO(), domain.SessionContextKey("session"), &domain.Session{User: user})
+
+	// OK
+	w, req := getRequestRecorder(ctx, true, "PUT", "/api/v2/mod/posts/1/lock?reason=spam", "")
+	req = mux.SetURLVars(req, map[string]string{"POST_ID": "1"})
+	pSrv.EXPECT().Lock(gomock.Any(), &service.LockPost{User: user, PostID: "1", Locked: true, Reason: "spam"}).Return(nil)
+
+	h.LockPost(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("expected 204, got: %d", w.Code)
+		return
+	}
+
+	// Method's Sticky returns error
+	w, req = getRequestRecorder(ctx, true, "DELETE", "/api/v2/mod/posts/1/sticky", "")
+	req = mux.SetURLVars(req, map[string]string{"POST_ID": "1"})
+	pSrv.EXPECT().Sticky(gomock.Any(), gomock.Any()).Return(domain.Conflict("too many stickied posts"))
+
+	h.UnstickyPost(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("expected 409, got: %d", w.Code)
+		return
+	}
+}
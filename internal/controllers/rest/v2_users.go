@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+)
+
+type tokenResponse struct {
+	Token string `json:"token"`
+}
+
+// CreateUser registers the user and logs them in.
+func (h v2Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	userSaveDto := &service.SaveUser{}
+
+	if !h.decode(w, r, userSaveDto) {
+		return
+	}
+
+	user, err := h.userService.Get(r.Context(), &service.GetUser{Username: userSaveDto.Username})
+
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		h.writeError(w, r, err, "can't checked user")
+		return
+	}
+
+	if user != nil {
+		h.log(r).Info("user already exists")
+		WriteError(w, "user already exists", http.StatusConflict)
+		return
+	}
+
+	user, err = h.userService.Save(r.Context(), userSaveDto)
+
+	if err != nil {
+		h.writeError(w, r, err, "can't save user in repo")
+		return
+	}
+
+	h.createSession(w, r, user)
+}
+
+// CreateSession logs the user in.
+func (h v2Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
+	getUserDto := &service.GetUser{}
+
+	if !h.decode(w, r, getUserDto) {
+		return
+	}
+
+	user, err := h.userService.Get(r.Context(), getUserDto)
+
+	if errors.Is(err, domain.ErrNotFound) {
+		h.log(r).Infof("bad login or password for %s", getUserDto.Username)
+		WriteError(w, "bad login or password", http.StatusUnauthorized)
+		return
+	}
+
+	if err != nil {
+		h.writeError(w, r, err, "can't get user")
+		return
+	}
+
+	h.createSession(w, r, user)
+}
+
+func (h v2Handler) createSession(w http.ResponseWriter, r *http.Request, user *domain.User) {
+	sessionDto := &service.CreateSession{
+		ID:       user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+	}
+
+	sess, err := h.sessionService.Create(r.Context(), sessionDto)
+
+	if err != nil {
+		h.writeError(w, r, err, "can't create session")
+		return
+	}
+
+	h.log(r).Infof("[%s] %s created session for user with session's id [%s]", r.Method, r.URL.Path, sess.ID)
+	h.writeJSON(w, r, http.StatusCreated, &tokenResponse{Token: sess.ID})
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "time"
+
+// AutomodRules is the rule set of a category as it was uploaded, so
+// moderators get back the same YAML or JSON they wrote.
+type AutomodRules struct {
+	Category  string    `json:"category" bson:"category"`
+	Source    string    `json:"source" bson:"source"`
+	Updated   time.Time `json:"updated" bson:"updated"`
+	UpdatedBy *Profile  `json:"updatedBy" bson:"updatedBy"`
+}
@@ -3,8 +3,11 @@ package domain
 import "time"
 
 type Comment struct {
-	Created time.Time `json:"created"`
-	Author  *Profile  `json:"author"`
-	Body    string    `json:"body"`
-	ID      string    `json:"id"`
+	Created   time.Time  `json:"created"`
+	Author    *Profile   `json:"author"`
+	Body      string     `json:"body"`
+	ID        string     `json:"id"`
+	Removed   bool       `json:"removed,omitempty"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy *Profile   `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
 }
@@ -1 +1,88 @@
 package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kinds of domain errors. Storages and services wrap them, so the handlers
+// can tell them apart with errors.Is and map them to HTTP statuses.
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrForbidden     = errors.New("forbidden")
+	ErrValidation    = errors.New("validation failed")
+	ErrConflict      = errors.New("conflict")
+)
+
+var (
+	ErrRestoreWindowClosed = &Error{Kind: ErrConflict, Msg: "restore window has expired"}
+	ErrPostLocked          = &Error{Kind: ErrForbidden, Msg: "post is locked"}
+	ErrPostArchived        = &Error{Kind: ErrForbidden, Msg: "post is archived"}
+	ErrStickyLimit         = &Error{Kind: ErrConflict, Msg: "category already has the maximum number of stickied posts"}
+	ErrAutomodRejected     = &Error{Kind: ErrForbidden, Msg: "rejected by automod"}
+)
+
+// Error is a domain error with its own message. errors.Is matches it both
+// by identity and by its kind.
+type Error struct {
+	Kind error
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func NotFound(format string, args ...any) error {
+	return &Error{Kind: ErrNotFound, Msg: fmt.Sprintf(format, args...)}
+}
+
+func AlreadyExists(format string, args ...any) error {
+	return &Error{Kind: ErrAlreadyExists, Msg: fmt.Sprintf(format, args...)}
+}
+
+func Forbidden(format string, args ...any) error {
+	return &Error{Kind: ErrForbidden, Msg: fmt.Sprintf(format, args...)}
+}
+
+func Validation(format string, args ...any) error {
+	return &Error{Kind: ErrValidation, Msg: fmt.Sprintf(format, args...)}
+}
+
+func Conflict(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Msg: fmt.Sprintf(format, args...)}
+}
+
+// FieldError points at one invalid field of the client input.
+type FieldError struct {
+	Location string `json:"location,omitempty"`
+	Param    string `json:"param"`
+	Value    any    `json:"value,omitempty"`
+	Msg      string `json:"msg"`
+}
+
+// ValidationError lists every invalid field of the input at once. It is of
+// the ErrValidation kind.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+
+	for _, fieldErr := range e.Errors {
+		msgs = append(msgs, fieldErr.Param+": "+fieldErr.Msg)
+	}
+
+	return "invalid input: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ModActionDeletePost     = "delete_post"
+	ModActionDeleteComment  = "delete_comment"
+	ModActionRestorePost    = "restore_post"
+	ModActionRestoreComment = "restore_comment"
+	ModActionRemovePost     = "remove_post"
+	ModActionRemoveComment  = "remove_comment"
+	ModActionApproveReport  = "approve_report"
+	ModActionLockPost       = "lock_post"
+	ModActionUnlockPost     = "unlock_post"
+	ModActionStickyPost     = "sticky_post"
+	ModActionUnstickyPost   = "unsticky_post"
+	ModActionUpdateAutomod  = "update_automod"
+	ModActionBanUser        = "ban_user"
+	ModActionUnbanUser      = "unban_user"
+)
+
+// ModLogEntry is an append-only record of a privileged action. Before and
+// After hold JSON snapshots of the target so the entry stays meaningful even
+// when the target itself is gone.
+type ModLogEntry struct {
+	ID         string          `json:"id" bson:"id"`
+	Actor      *Profile        `json:"actor" bson:"actor"`
+	Action     string          `json:"action" bson:"action"`
+	TargetType string          `json:"targetType" bson:"targetType"`
+	TargetID   string          `json:"targetId" bson:"targetId"`
+	Category   string          `json:"category,omitempty" bson:"category"`
+	Reason     string          `json:"reason,omitempty" bson:"reason"`
+	Created    time.Time       `json:"created" bson:"created"`
+	Before     json.RawMessage `json:"before,omitempty" bson:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty" bson:"after,omitempty"`
+}
+
+type ModLogFilter struct {
+	Category string
+	Action   string
+	Actor    string
+	TargetID string
+	Offset   int
+	Limit    int
+}
@@ -16,8 +16,23 @@ type Post struct {
 	Created          time.Time  `json:"created"`
 	UpvotePercentage uint       `json:"upvotePercentage"`
 	ID               string     `json:"id"`
+	Removed          bool       `json:"removed,omitempty"`
+	Locked           bool       `json:"locked,omitempty"`
+	Stickied         bool       `json:"stickied,omitempty"`
+	Archived         bool       `json:"archived,omitempty"`
+	Flair            string     `json:"flair,omitempty"`
+	DeletedAt        *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy        *Profile   `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
 }
 
+const (
+	PostTypeLink = "link"
+	PostTypeText = "text"
+)
+
+// Categories are the categories the frontend offers.
+var Categories = []string{"music", "funny", "videos", "programming", "news", "fashion"}
+
 type Vote struct {
 	User string `json:"user"`
 	Vote int    `json:"vote"`
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+
+	ReportStatusOpen     = "open"
+	ReportStatusApproved = "approved"
+	ReportStatusRemoved  = "removed"
+)
+
+type Report struct {
+	ID         string     `json:"id" bson:"id"`
+	TargetType string     `json:"targetType" bson:"targetType"`
+	PostID     string     `json:"postId" bson:"postId"`
+	CommentID  string     `json:"commentId,omitempty" bson:"commentId"`
+	Category   string     `json:"category" bson:"category"`
+	Reason     string     `json:"reason" bson:"reason"`
+	Reporter   *Profile   `json:"reporter" bson:"reporter"`
+	Status     string     `json:"status" bson:"status"`
+	Created    time.Time  `json:"created" bson:"created"`
+	ResolvedBy *Profile   `json:"resolvedBy,omitempty" bson:"resolvedBy,omitempty"`
+	Resolved   *time.Time `json:"resolved,omitempty" bson:"resolved,omitempty"`
+}
@@ -1,8 +1,17 @@
 package domain
 
+import "time"
+
 type Session struct {
 	ID   string
 	User *User
 }
 
+// SessionInfo is a live session as the admin tools list it.
+type SessionInfo struct {
+	ID      string    `json:"id"`
+	User    *User     `json:"user"`
+	Expires time.Time `json:"expires"`
+}
+
 type SessionContextKey string
@@ -1,12 +1,39 @@
 package domain
 
+import "time"
+
 type User struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	ID       string `json:"id"`
+	Role     string `json:"role,omitempty"`
 }
 
+const (
+	RoleMember    = "member"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type Profile struct {
 	Username string `json:"username"`
 	ID       string `json:"id"`
 }
+
+// UserRecord is a user as the storage keeps it, for moving users between
+// storages. The password is only there as its hash.
+type UserRecord struct {
+	Username string        `json:"username"`
+	ID       string        `json:"id"`
+	Role     string        `json:"role"`
+	Active   bool          `json:"active"`
+	Created  time.Time     `json:"created"`
+	Password *PasswordHash `json:"password,omitempty"`
+}
+
+// PasswordHash is a password hash in the scheme of the storage that made
+// it. Another storage can only take it over if it uses the same scheme.
+type PasswordHash struct {
+	Scheme string `json:"scheme"`
+	Hash   string `json:"hash"`
+}
@@ -0,0 +1,127 @@
+// Package metrics collects Prometheus metrics for HTTP traffic, storage calls,
+// connection pools and business events.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "redditclone"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration    *prometheus.HistogramVec
+	storageDuration *prometheus.HistogramVec
+	storageErrors   *prometheus.CounterVec
+
+	postsCreated prometheus.Counter
+	votes        *prometheus.CounterVec
+	comments     prometheus.Counter
+	logins       prometheus.Counter
+	failedLogins prometheus.Counter
+}
+
+// New builds the metrics on their own registry, so tests can create as many
+// instances as they need.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route template, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		storageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_operation_duration_seconds",
+			Help:      "Storage call latency by storage and method.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"storage", "method"}),
+		storageErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "storage_operation_errors_total",
+			Help:      "Failed storage calls by storage and method.",
+		}, []string{"storage", "method"}),
+		postsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "posts_created_total",
+			Help:      "Posts created.",
+		}),
+		votes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "votes_total",
+			Help:      "Votes cast by kind: upvote, downvote or unvote.",
+		}, []string{"vote"}),
+		comments: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "comments_created_total",
+			Help:      "Comments created.",
+		}),
+		logins: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Successful logins.",
+		}),
+		failedLogins: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "login_failures_total",
+			Help:      "Failed logins.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.storageDuration,
+		m.storageErrors,
+		m.postsCreated,
+		m.votes,
+		m.comments,
+		m.logins,
+		m.failedLogins,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Register adds an extra collector, e.g. connection pool stats.
+func (m *Metrics) Register(c prometheus.Collector) error {
+	return m.registry.Register(c)
+}
+
+func (m *Metrics) ObserveHTTP(route, method string, status int, duration time.Duration) {
+	m.httpDuration.WithLabelValues(route, method, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveLogin(ok bool) {
+	if ok {
+		m.logins.Inc()
+		return
+	}
+
+	m.failedLogins.Inc()
+}
+
+// observeStorage is deferred by the storage decorators with the named error
+// result of the call.
+func (m *Metrics) observeStorage(storage, method string, start time.Time, err *error) {
+	m.storageDuration.WithLabelValues(storage, method).Observe(time.Since(start).Seconds())
+
+	if *err != nil {
+		m.storageErrors.WithLabelValues(storage, method).Inc()
+	}
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// stubPostStorage fails every call once err is set.
+type stubPostStorage struct {
+	service.PostStorage
+	err error
+}
+
+func (s *stubPostStorage) Save(ctx context.Context, post *domain.Post) (*domain.Post, error) {
+	return post, s.err
+}
+
+func (s *stubPostStorage) UpdateMetrics(ctx context.Context, postID string, inc int8, authorID string) error {
+	return s.err
+}
+
+type stubCommentStorage struct {
+	service.CommentStorage
+	err error
+}
+
+func (s *stubCommentStorage) Add(ctx context.Context, author *domain.Profile, body, postID string) (*domain.Comment, error) {
+	return &domain.Comment{Body: body}, s.err
+}
+
+func TestPostStorage(t *testing.T) {
+	m := New()
+	stub := &stubPostStorage{}
+	posts := NewPostStorage(stub, m)
+
+	// OK
+	if _, err := posts.Save(context.TODO(), &domain.Post{}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	for _, inc := range []int8{1, 1, -1, 0} {
+		if err := posts.UpdateMetrics(context.TODO(), "1", inc, "2"); err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+	}
+
+	if got := testutil.ToFloat64(m.postsCreated); got != 1 {
+		t.Errorf("expected 1 post created, got: %v", got)
+		return
+	}
+
+	if got := testutil.ToFloat64(m.votes.WithLabelValues("upvote")); got != 2 {
+		t.Errorf("expected 2 upvotes, got: %v", got)
+		return
+	}
+
+	if got := testutil.ToFloat64(m.votes.WithLabelValues("unvote")); got != 1 {
+		t.Errorf("expected 1 unvote, got: %v", got)
+		return
+	}
+
+	// Storage returns error
+	stub.err = errors.New("some error")
+
+	if _, err := posts.Save(context.TODO(), &domain.Post{}); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if got := testutil.ToFloat64(m.postsCreated); got != 1 {
+		t.Errorf("failed save must not be counted, got: %v", got)
+		return
+	}
+
+	if got := testutil.ToFloat64(m.storageErrors.WithLabelValues("posts", "Save")); got != 1 {
+		t.Errorf("expected 1 storage error, got: %v", got)
+		return
+	}
+
+	if got := testutil.CollectAndCount(m.storageDuration); got != 2 {
+		t.Errorf("expected latency for 2 methods, got: %d", got)
+	}
+}
+
+func TestCommentStorage(t *testing.T) {
+	m := New()
+	stub := &stubCommentStorage{}
+	comments := NewCommentStorage(stub, m)
+
+	// OK
+	if _, err := comments.Add(context.TODO(), &domain.Profile{}, "body", "1"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// Storage returns error
+	stub.err = errors.New("some error")
+
+	if _, err := comments.Add(context.TODO(), &domain.Profile{}, "body", "1"); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if got := testutil.ToFloat64(m.comments); got != 1 {
+		t.Errorf("expected 1 comment created, got: %v", got)
+		return
+	}
+
+	if got := testutil.ToFloat64(m.storageErrors.WithLabelValues("comments", "Add")); got != 1 {
+		t.Errorf("expected 1 storage error, got: %v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.ObserveHTTP("/api/post/{POST_ID}", "GET", http.StatusOK, 10*time.Millisecond)
+	m.ObserveLogin(true)
+	m.ObserveLogin(false)
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got: %d", w.Code)
+		return
+	}
+
+	body := w.Body.String()
+
+	for _, want := range []string{
+		`redditclone_http_request_duration_seconds_count{method="GET",route="/api/post/{POST_ID}",status="200"} 1`,
+		"redditclone_logins_total 1",
+		"redditclone_login_failures_total 1",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %q in metrics output", want)
+		}
+	}
+}
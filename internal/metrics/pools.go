@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// RegisterPostgresPool exposes the database/sql pool stats of db.
+func (m *Metrics) RegisterPostgresPool(db *sql.DB, name string) error {
+	return m.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterRedisPool exposes the pool stats of a redis client.
+func (m *Metrics) RegisterRedisPool(client *redis.Client) error {
+	stats := map[string]func(s *redis.PoolStats) float64{
+		"hits":        func(s *redis.PoolStats) float64 { return float64(s.Hits) },
+		"misses":      func(s *redis.PoolStats) float64 { return float64(s.Misses) },
+		"timeouts":    func(s *redis.PoolStats) float64 { return float64(s.Timeouts) },
+		"total_conns": func(s *redis.PoolStats) float64 { return float64(s.TotalConns) },
+		"idle_conns":  func(s *redis.PoolStats) float64 { return float64(s.IdleConns) },
+		"stale_conns": func(s *redis.PoolStats) float64 { return float64(s.StaleConns) },
+	}
+
+	for name, stat := range stats {
+		stat := stat
+		gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "redis_pool",
+			Name:      name,
+			Help:      "Redis connection pool " + name + ".",
+		}, func() float64 {
+			return stat(client.PoolStats())
+		})
+
+		if err := m.Register(gauge); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MongoPoolMonitor tracks the Mongo connection pool. It has to be set on the
+// client options before connecting.
+func (m *Metrics) MongoPoolMonitor() *event.PoolMonitor {
+	open := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "mongo_pool",
+		Name:      "open_conns",
+		Help:      "Open Mongo connections.",
+	})
+	inUse := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "mongo_pool",
+		Name:      "in_use_conns",
+		Help:      "Mongo connections checked out of the pool.",
+	})
+	waitFailed := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "mongo_pool",
+		Name:      "checkout_failures_total",
+		Help:      "Failed attempts to get a Mongo connection from the pool.",
+	})
+
+	m.registry.MustRegister(open, inUse, waitFailed)
+
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				open.Inc()
+			case event.ConnectionClosed:
+				open.Dec()
+			case event.GetSucceeded:
+				inUse.Inc()
+			case event.ConnectionReturned:
+				inUse.Dec()
+			case event.GetFailed:
+				waitFailed.Inc()
+			}
+		},
+	}
+}
@@ -0,0 +1,358 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+)
+
+// The decorators below wrap the service storages: every call is timed and
+// failures are counted per storage method, successful writes also bump the
+// business counters.
+
+type postStorage struct {
+	next    service.PostStorage
+	metrics *Metrics
+}
+
+func NewPostStorage(next service.PostStorage, m *Metrics) service.PostStorage {
+	return &postStorage{next: next, metrics: m}
+}
+
+func (s *postStorage) observe(method string, start time.Time, err *error) {
+	s.metrics.observeStorage("posts", method, start, err)
+}
+
+func (s *postStorage) Save(ctx context.Context, post *domain.Post) (_ *domain.Post, err error) {
+	defer s.observe("Save", time.Now(), &err)
+
+	saved, err := s.next.Save(ctx, post)
+
+	if err == nil {
+		s.metrics.postsCreated.Inc()
+	}
+
+	return saved, err
+}
+
+func (s *postStorage) GetOne(ctx context.Context, id string) (_ *domain.Post, err error) {
+	defer s.observe("GetOne", time.Now(), &err)
+	return s.next.GetOne(ctx, id)
+}
+
+func (s *postStorage) Get(ctx context.Context) (_ []*domain.Post, err error) {
+	defer s.observe("Get", time.Now(), &err)
+	return s.next.Get(ctx)
+}
+
+func (s *postStorage) GetBy(ctx context.Context, category, data, sortField string) (_ []*domain.Post, err error) {
+	defer s.observe("GetBy", time.Now(), &err)
+	return s.next.GetBy(ctx, category, data, sortField)
+}
+
+func (s *postStorage) UpdateMetrics(ctx context.Context, postID string, inc int8, authorID string) (err error) {
+	defer s.observe("UpdateMetrics", time.Now(), &err)
+
+	if err = s.next.UpdateMetrics(ctx, postID, inc, authorID); err != nil {
+		return err
+	}
+
+	vote := "unvote"
+
+	switch {
+	case inc > 0:
+		vote = "upvote"
+	case inc < 0:
+		vote = "downvote"
+	}
+
+	s.metrics.votes.WithLabelValues(vote).Inc()
+
+	return nil
+}
+
+func (s *postStorage) IncrViews(ctx context.Context, postID string) (err error) {
+	defer s.observe("IncrViews", time.Now(), &err)
+	return s.next.IncrViews(ctx, postID)
+}
+
+func (s *postStorage) Delete(ctx context.Context, postID string, deletedBy *domain.Profile) (err error) {
+	defer s.observe("Delete", time.Now(), &err)
+	return s.next.Delete(ctx, postID, deletedBy)
+}
+
+func (s *postStorage) SetRemoved(ctx context.Context, postID string, removed bool) (err error) {
+	defer s.observe("SetRemoved", time.Now(), &err)
+	return s.next.SetRemoved(ctx, postID, removed)
+}
+
+func (s *postStorage) GetDeleted(ctx context.Context, id string) (_ *domain.Post, err error) {
+	defer s.observe("GetDeleted", time.Now(), &err)
+	return s.next.GetDeleted(ctx, id)
+}
+
+func (s *postStorage) Restore(ctx context.Context, postID string) (err error) {
+	defer s.observe("Restore", time.Now(), &err)
+	return s.next.Restore(ctx, postID)
+}
+
+func (s *postStorage) Purge(ctx context.Context, deletedBefore time.Time) (_ int64, err error) {
+	defer s.observe("Purge", time.Now(), &err)
+	return s.next.Purge(ctx, deletedBefore)
+}
+
+func (s *postStorage) SetLocked(ctx context.Context, postID string, locked bool) (err error) {
+	defer s.observe("SetLocked", time.Now(), &err)
+	return s.next.SetLocked(ctx, postID, locked)
+}
+
+func (s *postStorage) SetStickied(ctx context.Context, postID string, stickied bool) (err error) {
+	defer s.observe("SetStickied", time.Now(), &err)
+	return s.next.SetStickied(ctx, postID, stickied)
+}
+
+func (s *postStorage) GetStickied(ctx context.Context, category string) (_ []*domain.Post, err error) {
+	defer s.observe("GetStickied", time.Now(), &err)
+	return s.next.GetStickied(ctx, category)
+}
+
+func (s *postStorage) Archive(ctx context.Context, createdBefore time.Time) (_ int64, err error) {
+	defer s.observe("Archive", time.Now(), &err)
+	return s.next.Archive(ctx, createdBefore)
+}
+
+func (s *postStorage) RecomputeScores(ctx context.Context, postID string) (_ int64, err error) {
+	defer s.observe("RecomputeScores", time.Now(), &err)
+	return s.next.RecomputeScores(ctx, postID)
+}
+
+func (s *postStorage) Walk(ctx context.Context, fn func(post *domain.Post) error) (err error) {
+	defer s.observe("Walk", time.Now(), &err)
+	return s.next.Walk(ctx, fn)
+}
+
+func (s *postStorage) Import(ctx context.Context, post *domain.Post) (err error) {
+	defer s.observe("Import", time.Now(), &err)
+	return s.next.Import(ctx, post)
+}
+
+type userStorage struct {
+	next    service.UserStorage
+	metrics *Metrics
+}
+
+func NewUserStorage(next service.UserStorage, m *Metrics) service.UserStorage {
+	return &userStorage{next: next, metrics: m}
+}
+
+func (s *userStorage) observe(method string, start time.Time, err *error) {
+	s.metrics.observeStorage("users", method, start, err)
+}
+
+func (s *userStorage) Get(ctx context.Context, username, password string) (_ *domain.User, err error) {
+	defer s.observe("Get", time.Now(), &err)
+	return s.next.Get(ctx, username, password)
+}
+
+func (s *userStorage) Save(ctx context.Context, username, password string) (_ *domain.User, err error) {
+	defer s.observe("Save", time.Now(), &err)
+	return s.next.Save(ctx, username, password)
+}
+
+func (s *userStorage) GetCreated(ctx context.Context, username string) (_ time.Time, err error) {
+	defer s.observe("GetCreated", time.Now(), &err)
+	return s.next.GetCreated(ctx, username)
+}
+
+func (s *userStorage) SetPassword(ctx context.Context, username, password string) (err error) {
+	defer s.observe("SetPassword", time.Now(), &err)
+	return s.next.SetPassword(ctx, username, password)
+}
+
+func (s *userStorage) SetActive(ctx context.Context, username string, active bool) (err error) {
+	defer s.observe("SetActive", time.Now(), &err)
+	return s.next.SetActive(ctx, username, active)
+}
+
+func (s *userStorage) Walk(ctx context.Context, fn func(user *domain.UserRecord) error) (err error) {
+	defer s.observe("Walk", time.Now(), &err)
+	return s.next.Walk(ctx, fn)
+}
+
+func (s *userStorage) Import(ctx context.Context, user *domain.UserRecord) (_ string, _ bool, err error) {
+	defer s.observe("Import", time.Now(), &err)
+	return s.next.Import(ctx, user)
+}
+
+func (s *userStorage) PasswordScheme() string {
+	return s.next.PasswordScheme()
+}
+
+type sessionStorage struct {
+	next    service.SessionStorage
+	metrics *Metrics
+}
+
+func NewSessionStorage(next service.SessionStorage, m *Metrics) service.SessionStorage {
+	return &sessionStorage{next: next, metrics: m}
+}
+
+func (s *sessionStorage) observe(method string, start time.Time, err *error) {
+	s.metrics.observeStorage("sessions", method, start, err)
+}
+
+func (s *sessionStorage) Create(ctx context.Context, ID, username, role string) (_ *domain.Session, err error) {
+	defer s.observe("Create", time.Now(), &err)
+	return s.next.Create(ctx, ID, username, role)
+}
+
+func (s *sessionStorage) Get(ctx context.Context, key string) (_ *domain.User, err error) {
+	defer s.observe("Get", time.Now(), &err)
+	return s.next.Get(ctx, key)
+}
+
+func (s *sessionStorage) List(ctx context.Context, username string) (_ []*domain.SessionInfo, err error) {
+	defer s.observe("List", time.Now(), &err)
+	return s.next.List(ctx, username)
+}
+
+func (s *sessionStorage) Delete(ctx context.Context, key string) (err error) {
+	defer s.observe("Delete", time.Now(), &err)
+	return s.next.Delete(ctx, key)
+}
+
+type commentStorage struct {
+	next    service.CommentStorage
+	metrics *Metrics
+}
+
+func NewCommentStorage(next service.CommentStorage, m *Metrics) service.CommentStorage {
+	return &commentStorage{next: next, metrics: m}
+}
+
+func (s *commentStorage) observe(method string, start time.Time, err *error) {
+	s.metrics.observeStorage("comments", method, start, err)
+}
+
+func (s *commentStorage) Add(ctx context.Context, author *domain.Profile, body, postID string) (_ *domain.Comment, err error) {
+	defer s.observe("Add", time.Now(), &err)
+
+	comment, err := s.next.Add(ctx, author, body, postID)
+
+	if err == nil {
+		s.metrics.comments.Inc()
+	}
+
+	return comment, err
+}
+
+func (s *commentStorage) Delete(ctx context.Context, postID, commentID string, deletedBy *domain.Profile) (err error) {
+	defer s.observe("Delete", time.Now(), &err)
+	return s.next.Delete(ctx, postID, commentID, deletedBy)
+}
+
+func (s *commentStorage) SetRemoved(ctx context.Context, postID, commentID string, removed bool) (err error) {
+	defer s.observe("SetRemoved", time.Now(), &err)
+	return s.next.SetRemoved(ctx, postID, commentID, removed)
+}
+
+func (s *commentStorage) GetDeleted(ctx context.Context, postID, commentID string) (_ *domain.Comment, err error) {
+	defer s.observe("GetDeleted", time.Now(), &err)
+	return s.next.GetDeleted(ctx, postID, commentID)
+}
+
+func (s *commentStorage) Restore(ctx context.Context, postID, commentID string) (err error) {
+	defer s.observe("Restore", time.Now(), &err)
+	return s.next.Restore(ctx, postID, commentID)
+}
+
+func (s *commentStorage) Purge(ctx context.Context, deletedBefore time.Time) (_ int64, err error) {
+	defer s.observe("Purge", time.Now(), &err)
+	return s.next.Purge(ctx, deletedBefore)
+}
+
+func (s *commentStorage) Import(ctx context.Context, postID string, comment *domain.Comment) (err error) {
+	defer s.observe("Import", time.Now(), &err)
+	return s.next.Import(ctx, postID, comment)
+}
+
+type reportStorage struct {
+	next    service.ReportStorage
+	metrics *Metrics
+}
+
+func NewReportStorage(next service.ReportStorage, m *Metrics) service.ReportStorage {
+	return &reportStorage{next: next, metrics: m}
+}
+
+func (s *reportStorage) observe(method string, start time.Time, err *error) {
+	s.metrics.observeStorage("reports", method, start, err)
+}
+
+func (s *reportStorage) Save(ctx context.Context, report *domain.Report) (_ *domain.Report, err error) {
+	defer s.observe("Save", time.Now(), &err)
+	return s.next.Save(ctx, report)
+}
+
+func (s *reportStorage) GetOne(ctx context.Context, id string) (_ *domain.Report, err error) {
+	defer s.observe("GetOne", time.Now(), &err)
+	return s.next.GetOne(ctx, id)
+}
+
+func (s *reportStorage) GetOpen(ctx context.Context, category string) (_ []*domain.Report, err error) {
+	defer s.observe("GetOpen", time.Now(), &err)
+	return s.next.GetOpen(ctx, category)
+}
+
+func (s *reportStorage) Resolve(ctx context.Context, postID, commentID, status string, moderator *domain.Profile) (err error) {
+	defer s.observe("Resolve", time.Now(), &err)
+	return s.next.Resolve(ctx, postID, commentID, status, moderator)
+}
+
+type modLogStorage struct {
+	next    service.ModLogStorage
+	metrics *Metrics
+}
+
+func NewModLogStorage(next service.ModLogStorage, m *Metrics) service.ModLogStorage {
+	return &modLogStorage{next: next, metrics: m}
+}
+
+func (s *modLogStorage) observe(method string, start time.Time, err *error) {
+	s.metrics.observeStorage("modlog", method, start, err)
+}
+
+func (s *modLogStorage) Save(ctx context.Context, entry *domain.ModLogEntry) (err error) {
+	defer s.observe("Save", time.Now(), &err)
+	return s.next.Save(ctx, entry)
+}
+
+func (s *modLogStorage) Get(ctx context.Context, filter *domain.ModLogFilter) (_ []*domain.ModLogEntry, err error) {
+	defer s.observe("Get", time.Now(), &err)
+	return s.next.Get(ctx, filter)
+}
+
+type automodStorage struct {
+	next    service.AutomodStorage
+	metrics *Metrics
+}
+
+func NewAutomodStorage(next service.AutomodStorage, m *Metrics) service.AutomodStorage {
+	return &automodStorage{next: next, metrics: m}
+}
+
+func (s *automodStorage) observe(method string, start time.Time, err *error) {
+	s.metrics.observeStorage("automod", method, start, err)
+}
+
+func (s *automodStorage) Get(ctx context.Context, category string) (_ *domain.AutomodRules, err error) {
+	defer s.observe("Get", time.Now(), &err)
+	return s.next.Get(ctx, category)
+}
+
+func (s *automodStorage) Save(ctx context.Context, rules *domain.AutomodRules) (_ *domain.AutomodRules, err error) {
+	defer s.observe("Save", time.Now(), &err)
+	return s.next.Save(ctx, rules)
+}
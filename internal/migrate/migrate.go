@@ -0,0 +1,169 @@
+// Package migrate applies versioned schema migrations to the databases. A
+// Runner keeps track of the applied versions in the database itself and
+// takes a lock there first, so replicas that start at the same time don't
+// apply a migration twice.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Migration is one version of a schema.
+type Migration struct {
+	Version int
+	Name    string
+}
+
+func (m Migration) String() string {
+	return fmt.Sprintf("%04d_%s", m.Version, m.Name)
+}
+
+// Status is a migration and whether it is applied.
+type Status struct {
+	Migration
+	Applied bool
+}
+
+// driver is what a database needs for the runner.
+type driver interface {
+	// lock waits until no other runner works on the database, unlock
+	// releases it.
+	lock(ctx context.Context) (unlock func(), err error)
+	// prepare creates what keeps the applied versions, the caller holds
+	// the lock.
+	prepare(ctx context.Context) error
+	applied(ctx context.Context) (map[int]bool, error)
+	up(ctx context.Context, version int) error
+	down(ctx context.Context, version int) error
+	// reindex rebuilds the indexes the applied migrations created.
+	reindex(ctx context.Context, applied map[int]bool) error
+}
+
+type Runner struct {
+	name       string
+	migrations []Migration
+	driver     driver
+}
+
+func newRunner(name string, migrations []Migration, d driver) *Runner {
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return &Runner{name: name, migrations: migrations, driver: d}
+}
+
+// Name is the database the runner migrates, e.g. postgres.
+func (r *Runner) Name() string {
+	return r.name
+}
+
+// Up applies every migration that isn't applied yet, in the order of the
+// versions, and returns them.
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	var done []Migration
+
+	err := r.locked(ctx, func(applied map[int]bool) error {
+		for _, m := range r.migrations {
+			if applied[m.Version] {
+				continue
+			}
+
+			if err := r.driver.up(ctx, m.Version); err != nil {
+				return fmt.Errorf("%s: %s: %w", r.name, m, err)
+			}
+
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Down reverts the last steps applied migrations and returns them.
+func (r *Runner) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+
+	err := r.locked(ctx, func(applied map[int]bool) error {
+		for i := len(r.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := r.migrations[i]
+
+			if !applied[m.Version] {
+				continue
+			}
+
+			if err := r.driver.down(ctx, m.Version); err != nil {
+				return fmt.Errorf("%s: %s: %w", r.name, m, err)
+			}
+
+			done = append(done, m)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	statuses := make([]Status, 0, len(r.migrations))
+
+	err := r.locked(ctx, func(applied map[int]bool) error {
+		for _, m := range r.migrations {
+			statuses = append(statuses, Status{Migration: m, Applied: applied[m.Version]})
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Reindex rebuilds the indexes of the database, e.g. after a bulk import.
+func (r *Runner) Reindex(ctx context.Context) error {
+	return r.locked(ctx, func(applied map[int]bool) error {
+		if err := r.driver.reindex(ctx, applied); err != nil {
+			return fmt.Errorf("%s: can't reindex: %w", r.name, err)
+		}
+
+		return nil
+	})
+}
+
+// locked runs fn under the lock with the versions applied so far. A version
+// the runner doesn't know means the database is newer than the binary, it
+// is not touched then.
+func (r *Runner) locked(ctx context.Context, fn func(applied map[int]bool) error) error {
+	unlock, err := r.driver.lock(ctx)
+
+	if err != nil {
+		return fmt.Errorf("%s: can't take the migration lock: %w", r.name, err)
+	}
+	defer unlock()
+
+	if err = r.driver.prepare(ctx); err != nil {
+		return fmt.Errorf("%s: %w", r.name, err)
+	}
+
+	applied, err := r.driver.applied(ctx)
+
+	if err != nil {
+		return fmt.Errorf("%s: can't read applied migrations: %w", r.name, err)
+	}
+
+	known := make(map[int]bool, len(r.migrations))
+
+	for _, m := range r.migrations {
+		known[m.Version] = true
+	}
+
+	for version := range applied {
+		if !known[version] {
+			return fmt.Errorf("%s: migration %04d is applied but unknown to this build", r.name, version)
+		}
+	}
+
+	return fn(applied)
+}
@@ -0,0 +1,194 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fakeDriver struct {
+	state  map[int]bool
+	calls  []string
+	failUp int
+	locked bool
+}
+
+func (d *fakeDriver) lock(ctx context.Context) (func(), error) {
+	d.locked = true
+	return func() { d.locked = false }, nil
+}
+
+func (d *fakeDriver) prepare(ctx context.Context) error {
+	return nil
+}
+
+func (d *fakeDriver) applied(ctx context.Context) (map[int]bool, error) {
+	applied := make(map[int]bool, len(d.state))
+
+	for version := range d.state {
+		applied[version] = true
+	}
+
+	return applied, nil
+}
+
+func (d *fakeDriver) up(ctx context.Context, version int) error {
+	if !d.locked {
+		return errors.New("up without the lock")
+	}
+
+	if version == d.failUp {
+		return errors.New("some error")
+	}
+
+	d.calls = append(d.calls, "up")
+	d.state[version] = true
+
+	return nil
+}
+
+func (d *fakeDriver) reindex(ctx context.Context, applied map[int]bool) error {
+	d.calls = append(d.calls, "reindex")
+
+	return nil
+}
+
+func (d *fakeDriver) down(ctx context.Context, version int) error {
+	d.calls = append(d.calls, "down")
+	delete(d.state, version)
+
+	return nil
+}
+
+func newTestRunner(d *fakeDriver) *Runner {
+	return newRunner("fake", []Migration{{Version: 3, Name: "third"}, {Version: 1, Name: "first"}, {Version: 2, Name: "second"}}, d)
+}
+
+func versions(migrations []Migration) []int {
+	v := []int{}
+
+	for _, m := range migrations {
+		v = append(v, m.Version)
+	}
+
+	return v
+}
+
+func TestRunnerUp(t *testing.T) {
+	ctx := context.Background()
+	d := &fakeDriver{state: map[int]bool{1: true}}
+	r := newTestRunner(d)
+
+	// OK, only the pending migrations in order
+	done, err := r.Up(ctx)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if !reflect.DeepEqual([]int{2, 3}, versions(done)) {
+		t.Errorf("expected [2 3], got: %v", versions(done))
+		return
+	}
+
+	if d.locked {
+		t.Error("expected the lock to be released")
+		return
+	}
+
+	// Nothing to do
+	done, err = r.Up(ctx)
+
+	if err != nil || len(done) != 0 {
+		t.Errorf("expected nothing to apply, got: %v, %v", done, err)
+		return
+	}
+
+	// Migration fails, the ones before it stay applied
+	d = &fakeDriver{state: map[int]bool{}, failUp: 2}
+	done, err = newTestRunner(d).Up(ctx)
+
+	if err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if !reflect.DeepEqual([]int{1}, versions(done)) || d.state[3] {
+		t.Errorf("expected only 1 applied, got: %v", versions(done))
+		return
+	}
+
+	// Database is newer than the build
+	d = &fakeDriver{state: map[int]bool{4: true}}
+
+	if _, err = newTestRunner(d).Up(ctx); err == nil || len(d.calls) != 0 {
+		t.Errorf("expected error and no migrations, got: %v, %v", err, d.calls)
+		return
+	}
+}
+
+func TestRunnerDown(t *testing.T) {
+	ctx := context.Background()
+	d := &fakeDriver{state: map[int]bool{1: true, 2: true}}
+	r := newTestRunner(d)
+
+	// OK, the last applied first
+	done, err := r.Down(ctx, 1)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if !reflect.DeepEqual([]int{2}, versions(done)) || !d.state[1] {
+		t.Errorf("expected [2], got: %v", versions(done))
+		return
+	}
+
+	// More steps than applied
+	done, err = r.Down(ctx, 5)
+
+	if err != nil || !reflect.DeepEqual([]int{1}, versions(done)) {
+		t.Errorf("expected [1], got: %v, %v", versions(done), err)
+		return
+	}
+
+	statuses, err := r.Status(ctx)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	for _, s := range statuses {
+		if s.Applied {
+			t.Errorf("expected nothing applied, got: %v", statuses)
+			return
+		}
+	}
+}
+
+func TestRunnerReindex(t *testing.T) {
+	ctx := context.Background()
+	d := &fakeDriver{state: map[int]bool{1: true}}
+
+	// OK
+	if err := newTestRunner(d).Reindex(ctx); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if !reflect.DeepEqual([]string{"reindex"}, d.calls) || d.locked {
+		t.Errorf("expected one reindex under the lock, got: %v", d.calls)
+		return
+	}
+
+	// Database is newer than the build
+	d = &fakeDriver{state: map[int]bool{4: true}}
+
+	if err := newTestRunner(d).Reindex(ctx); err == nil || len(d.calls) != 0 {
+		t.Errorf("expected error and no reindex, got: %v, %v", err, d.calls)
+	}
+}
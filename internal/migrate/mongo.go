@@ -0,0 +1,217 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	mongoLockID = "migrate"
+	// mongoLockTTL is how long a lock lives if its runner dies before
+	// releasing it. Mongo has no session locks like Postgres.
+	mongoLockTTL   = 10 * time.Minute
+	mongoLockRetry = time.Second
+)
+
+type mongoMigration struct {
+	Migration
+	up, down func(ctx context.Context, db *mongo.Database) error
+	// reindex rebuilds what up created, nil if it created no indexes.
+	reindex func(ctx context.Context, db *mongo.Database) error
+}
+
+// mongoMigrations are the migrations of the post database. Mongo creates
+// collections on the first write, so there are only indexes to migrate.
+var mongoMigrations = []mongoMigration{
+	{Migration{Version: 1, Name: "posts_indexes"}, createIndexes("posts", postsIndexes), dropIndexes("posts", postsIndexes),
+		rebuildIndexes("posts", postsIndexes)},
+}
+
+// postsIndexes cover the lookups by id and the filters and sorts of the
+// listings.
+var postsIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetName("id").SetUnique(true)},
+	{Keys: bson.D{{Key: "category", Value: 1}}, Options: options.Index().SetName("category")},
+	{Keys: bson.D{{Key: "author.username", Value: 1}}, Options: options.Index().SetName("author.username")},
+	{Keys: bson.D{{Key: "score", Value: -1}}, Options: options.Index().SetName("score")},
+	{Keys: bson.D{{Key: "created", Value: -1}}, Options: options.Index().SetName("created")},
+}
+
+type mongoDriver struct {
+	db         *mongo.Database
+	migrations map[int]mongoMigration
+	owner      string
+	now        func() time.Time
+}
+
+// NewMongo migrates the database db. The applied versions are kept in the
+// migrations collection, the lock in migrations_lock.
+func NewMongo(db *mongo.Database) *Runner {
+	d := &mongoDriver{
+		db:         db,
+		migrations: make(map[int]mongoMigration, len(mongoMigrations)),
+		owner:      primitive.NewObjectID().Hex(),
+		now:        time.Now,
+	}
+	migrations := make([]Migration, 0, len(mongoMigrations))
+
+	for _, m := range mongoMigrations {
+		d.migrations[m.Version] = m
+		migrations = append(migrations, m.Migration)
+	}
+
+	return newRunner("mongo", migrations, d)
+}
+
+// lock takes the lock document if there is none or it has expired. While
+// another runner holds it, the upsert fails on the duplicate _id and lock
+// tries again.
+func (d *mongoDriver) lock(ctx context.Context) (func(), error) {
+	locks := d.db.Collection("migrations_lock")
+
+	for {
+		now := d.now()
+		_, err := locks.UpdateOne(ctx,
+			bson.M{"_id": mongoLockID, "expires": bson.M{"$lt": now}},
+			bson.M{"$set": bson.M{"owner": d.owner, "expires": now.Add(mongoLockTTL)}},
+			options.Update().SetUpsert(true))
+
+		if err == nil {
+			break
+		}
+
+		if !mongo.IsDuplicateKeyError(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(mongoLockRetry):
+		}
+	}
+
+	return func() {
+		if _, err := locks.DeleteOne(context.Background(), bson.M{"_id": mongoLockID, "owner": d.owner}); err != nil {
+			log.Println("mongo migration unlock:", err)
+		}
+	}, nil
+}
+
+func (d *mongoDriver) prepare(ctx context.Context) error {
+	return nil
+}
+
+func (d *mongoDriver) applied(ctx context.Context) (map[int]bool, error) {
+	c, err := d.db.Collection("migrations").Find(ctx, bson.M{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []struct {
+		Version int `bson:"_id"`
+	}
+
+	if err = c.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]bool, len(docs))
+
+	for _, doc := range docs {
+		applied[doc.Version] = true
+	}
+
+	return applied, nil
+}
+
+// up runs the migration and records it. Mongo can't do both in one
+// transaction without a replica set, so the migrations are written to be
+// safe to run again.
+func (d *mongoDriver) up(ctx context.Context, version int) error {
+	m := d.migrations[version]
+
+	if err := m.up(ctx, d.db); err != nil {
+		return err
+	}
+
+	_, err := d.db.Collection("migrations").InsertOne(ctx, bson.M{"_id": version, "name": m.Name, "applied": d.now()})
+
+	return err
+}
+
+func (d *mongoDriver) down(ctx context.Context, version int) error {
+	if err := d.migrations[version].down(ctx, d.db); err != nil {
+		return err
+	}
+
+	_, err := d.db.Collection("migrations").DeleteOne(ctx, bson.M{"_id": version})
+
+	return err
+}
+
+func (d *mongoDriver) reindex(ctx context.Context, applied map[int]bool) error {
+	for version := range applied {
+		if m := d.migrations[version]; m.reindex != nil {
+			if err := m.reindex(ctx, d.db); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// createIndexes creates the indexes of a collection, indexes that already
+// exist with the same keys are left as they are.
+func createIndexes(collection string, indexes []mongo.IndexModel) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, indexes)
+		return err
+	}
+}
+
+func dropIndexes(collection string, indexes []mongo.IndexModel) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		for _, index := range indexes {
+			if _, err := db.Collection(collection).Indexes().DropOne(ctx, *index.Options.Name); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// rebuildIndexes drops and creates the indexes one by one, so the collection
+// is never left without all of them at once. An index that is missing is
+// only created.
+func rebuildIndexes(collection string, indexes []mongo.IndexModel) func(ctx context.Context, db *mongo.Database) error {
+	return func(ctx context.Context, db *mongo.Database) error {
+		view := db.Collection(collection).Indexes()
+
+		for _, index := range indexes {
+			_, err := view.DropOne(ctx, *index.Options.Name)
+
+			var cmdErr mongo.CommandError
+
+			if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Name == "IndexNotFound") {
+				return err
+			}
+
+			if _, err = view.CreateOne(ctx, index); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestMongoUp(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("Up", func(mt *mtest.T) {
+		r := NewMongo(mt.Client.Database("post"))
+
+		// OK
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+			mtest.CreateCursorResponse(0, "post.migrations", mtest.FirstBatch),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+			mtest.CreateSuccessResponse(),
+		)
+
+		done, err := r.Up(ctx)
+
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+
+		if len(done) != 1 || done[0].String() != "0001_posts_indexes" {
+			t.Errorf("expected 0001_posts_indexes, got: %v", done)
+			return
+		}
+
+		// Already applied
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}},
+			mtest.CreateCursorResponse(0, "post.migrations", mtest.FirstBatch, bson.D{{Key: "_id", Value: 1}}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		done, err = r.Up(ctx)
+
+		if err != nil || len(done) != 0 {
+			t.Errorf("expected nothing to apply, got: %v, %v", done, err)
+			return
+		}
+	})
+
+	mt.Run("Locked", func(mt *mtest.T) {
+		r := NewMongo(mt.Client.Database("post"))
+
+		// Another runner holds the lock until the context is over
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "duplicate key"}))
+
+		ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+
+		if _, err := r.Up(ctx); err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+
+		// Lock fails
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+
+		if _, err := r.Up(context.Background()); err == nil {
+			t.Errorf("expected error, got nil")
+			return
+		}
+	})
+}
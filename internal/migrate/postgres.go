@@ -0,0 +1,207 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"strconv"
+
+	"github.com/lib/pq"
+)
+
+// postgresLockKey is the key of the advisory lock the runners of all the
+// replicas take.
+const postgresLockKey = 7_204_117
+
+var sqlFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type sqlMigration struct {
+	name, up, down string
+}
+
+type postgres struct {
+	db         *sql.DB
+	migrations map[int]*sqlMigration
+}
+
+// NewPostgres reads the migrations of fsys, pairs of NNNN_name.up.sql and
+// NNNN_name.down.sql, and applies them to db. The applied versions are kept
+// in the schema_migrations table.
+func NewPostgres(db *sql.DB, fsys fs.FS) (*Runner, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := &postgres{db: db, migrations: make(map[int]*sqlMigration)}
+
+	for _, file := range files {
+		match := sqlFileName.FindStringSubmatch(file)
+
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %s", file)
+		}
+
+		version, _ := strconv.Atoi(match[1])
+
+		m, ok := p.migrations[version]
+
+		if !ok {
+			m = &sqlMigration{name: match[2]}
+			p.migrations[version] = m
+		} else if m.name != match[2] {
+			return nil, fmt.Errorf("migration %04d is both %s and %s", version, m.name, match[2])
+		}
+
+		body, err := fs.ReadFile(fsys, file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] == "up" {
+			m.up = string(body)
+		} else {
+			m.down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(p.migrations))
+
+	for version, m := range p.migrations {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both up and down files", version, m.name)
+		}
+
+		migrations = append(migrations, Migration{Version: version, Name: m.name})
+	}
+
+	return newRunner("postgres", migrations, p), nil
+}
+
+// lock takes a session advisory lock, Postgres releases it on its own if
+// the process dies. The lock holds one connection of the pool until unlock.
+func (p *postgres) lock(ctx context.Context) (func(), error) {
+	conn, err := p.db.Conn(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", postgresLockKey); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", postgresLockKey); err != nil {
+			log.Println("postgres migration unlock:", err)
+		}
+
+		_ = conn.Close()
+	}, nil
+}
+
+func (p *postgres) prepare(ctx context.Context) error {
+	_, err := p.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version integer PRIMARY KEY,
+		name varchar(255) NOT NULL,
+		applied timestamptz NOT NULL DEFAULT now()
+	)`)
+
+	return err
+}
+
+func (p *postgres) applied(ctx context.Context) (map[int]bool, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+
+	for rows.Next() {
+		var version int
+
+		if err = rows.Scan(&version); err != nil {
+			return nil, err
+		}
+
+		applied[version] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// up runs the migration and records it in one transaction, a failed
+// migration leaves nothing behind.
+func (p *postgres) up(ctx context.Context, version int) error {
+	return p.inTx(ctx, p.migrations[version].up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", version, p.migrations[version].name)
+}
+
+func (p *postgres) down(ctx context.Context, version int) error {
+	return p.inTx(ctx, p.migrations[version].down, "DELETE FROM schema_migrations WHERE version = $1", version)
+}
+
+// reindex rebuilds the indexes of every table of the schema, the migrations
+// don't track which tables they created.
+func (p *postgres) reindex(ctx context.Context, applied map[int]bool) error {
+	rows, err := p.db.QueryContext(ctx, "SELECT tablename FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename")
+
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var tables []string
+
+	for rows.Next() {
+		var table string
+
+		if err = rows.Scan(&table); err != nil {
+			return err
+		}
+
+		tables = append(tables, table)
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, table := range tables {
+		if _, err = p.db.ExecContext(ctx, "REINDEX TABLE "+pq.QuoteIdentifier(table)); err != nil {
+			return fmt.Errorf("%s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *postgres) inTx(ctx context.Context, migration, record string, args ...any) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, migration); err == nil {
+		_, err = tx.ExecContext(ctx, record, args...)
+	}
+
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w, rollback: %v", err, rbErr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
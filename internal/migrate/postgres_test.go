@@ -0,0 +1,125 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/akrovv/redditclone/deploy/migrations"
+	"gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+func TestNewPostgres(t *testing.T) {
+	// OK, the migrations of the repository
+	r, err := NewPostgres(nil, migrations.FS)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if len(r.migrations) == 0 || r.migrations[0].String() != "0001_users" {
+		t.Errorf("expected 0001_users first, got: %v", r.migrations)
+		return
+	}
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"Pass a bad file name", fstest.MapFS{"users.sql": {}}},
+		{"Pass no down file", fstest.MapFS{"0001_users.up.sql": {Data: []byte("CREATE TABLE users ()")}}},
+		{"Pass two names for a version", fstest.MapFS{
+			"0001_users.up.sql":   {Data: []byte("CREATE TABLE users ()")},
+			"0001_posts.down.sql": {Data: []byte("DROP TABLE posts")},
+		}},
+	}
+
+	for _, tt := range tests {
+		if _, err = NewPostgres(nil, tt.fsys); err == nil {
+			t.Errorf("%s: expected error, got nil", tt.name)
+			return
+		}
+	}
+}
+
+func TestPostgresUp(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("can't create mock: %s", err)
+		return
+	}
+	defer db.Close()
+
+	r, err := NewPostgres(db, fstest.MapFS{
+		"0001_users.up.sql":   {Data: []byte("CREATE TABLE users ()")},
+		"0001_users.down.sql": {Data: []byte("DROP TABLE users")},
+		"0002_posts.up.sql":   {Data: []byte("CREATE TABLE posts ()")},
+		"0002_posts.down.sql": {Data: []byte("DROP TABLE posts")},
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+		return
+	}
+
+	ctx := context.Background()
+
+	// OK
+	mock.ExpectExec("SELECT pg_advisory_lock").WithArgs(postgresLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE posts").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(2, "posts").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WithArgs(postgresLockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	done, err := r.Up(ctx)
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if len(done) != 1 || done[0].Version != 2 {
+		t.Errorf("expected 0002_posts, got: %v", done)
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// Migration fails, it is rolled back
+	mock.ExpectExec("SELECT pg_advisory_lock").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec("CREATE TABLE posts").WillReturnError(errors.New("some error"))
+	mock.ExpectRollback()
+	mock.ExpectExec("SELECT pg_advisory_unlock").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err = r.Up(ctx); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// Lock can't be taken
+	mock.ExpectExec("SELECT pg_advisory_lock").WillReturnError(errors.New("some error"))
+
+	if _, err = r.Up(ctx); err == nil {
+		t.Errorf("expected error, got nil")
+		return
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,374 @@
+// Package seed fills the storages with made up but plausible users, posts,
+// comments and votes for demos and load tests. A few users and categories
+// get most of the posts, a few posts get most of the votes and comments, and
+// the posts are spread over the past months, so the rankings have something
+// to sort.
+//
+// The same seed gives the same data: the IDs and the timestamps come from
+// it, not from the storages, and a second run with it adds nothing.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type Options struct {
+	Seed  int64
+	Users int
+	Posts int
+	// Comments is the mean number of comments of a post, popular posts get
+	// more and the rest fewer.
+	Comments int
+	// Months is how far back the posts go.
+	Months int
+	// Password is the password of every user, so any of them can log in.
+	Password string
+	// Now is the moment the posts lead up to. The runs of the same seed
+	// match only with the same Now.
+	Now time.Time
+}
+
+// Result counts what a run created and what was there already.
+type Result struct {
+	Users        int `json:"users"`
+	UsersSkipped int `json:"usersSkipped"`
+	Posts        int `json:"posts"`
+	PostsSkipped int `json:"postsSkipped"`
+	Comments     int `json:"comments"`
+	Votes        int `json:"votes"`
+}
+
+// Run writes the users with UserStorage.Import, the posts with their votes
+// with PostStorage.Import and then their comments with CommentStorage.Import,
+// which keep the IDs and timestamps. CommentStorage.Add would stamp the
+// comments with the current time and a random ID.
+func Run(ctx context.Context, users service.UserStorage, posts service.PostStorage, comments service.CommentStorage,
+	opts Options) (*Result, error) {
+	if opts.Users < 1 || opts.Posts < 0 || opts.Comments < 0 || opts.Months < 1 {
+		return nil, errors.New("seed needs at least one user and one month, and no negative counts")
+	}
+
+	g := &seeder{
+		rand:  rand.New(rand.NewSource(opts.Seed)),
+		opts:  opts,
+		users: make([]*domain.Profile, 0, opts.Users),
+	}
+	result := &Result{}
+
+	for i := 0; i < opts.Users; i++ {
+		profile, created, err := g.saveUser(ctx, users, g.username(i))
+
+		if err != nil {
+			return result, err
+		}
+
+		if created {
+			result.Users++
+		} else {
+			result.UsersSkipped++
+		}
+
+		g.users = append(g.users, profile)
+	}
+
+	g.authors = g.zipf(len(g.users))
+	g.categories = g.zipf(len(domain.Categories))
+
+	for i := 0; i < opts.Posts; i++ {
+		post := g.post(i)
+		postComments := post.Comments
+		post.Comments = []*domain.Comment{}
+		err := posts.Import(ctx, post)
+
+		switch {
+		case errors.Is(err, domain.ErrAlreadyExists):
+			result.PostsSkipped++
+		case err != nil:
+			return result, err
+		default:
+			result.Posts++
+			result.Votes += len(post.Votes)
+		}
+
+		// The comments of a skipped post are imported too, a run that
+		// stopped between a post and its comments is finished this way
+		n, err := importComments(ctx, comments, post.ID, postComments)
+		result.Comments += n
+
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// importComments imports the comments of the post in order and counts the
+// ones it added.
+func importComments(ctx context.Context, comments service.CommentStorage, postID string, list []*domain.Comment) (int, error) {
+	added := 0
+
+	for _, comment := range list {
+		err := comments.Import(ctx, postID, comment)
+
+		if errors.Is(err, domain.ErrAlreadyExists) {
+			continue
+		}
+
+		if err != nil {
+			return added, err
+		}
+
+		added++
+	}
+
+	return added, nil
+}
+
+// saveUser creates the user, or finds the ID of the one a previous run
+// created: Import of a taken username changes nothing and returns its ID.
+// The users register at the start of the seeded months, before their posts.
+func (g *seeder) saveUser(ctx context.Context, users service.UserStorage, username string) (*domain.Profile, bool, error) {
+	id, created, err := users.Import(ctx, &domain.UserRecord{
+		Username: username,
+		Role:     domain.RoleMember,
+		Active:   true,
+		Created:  g.opts.Now.AddDate(0, -g.opts.Months, 0),
+	})
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Import leaves a new user without a usable password
+	if created {
+		if err = users.SetPassword(ctx, username, g.opts.Password); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return &domain.Profile{Username: username, ID: id}, created, nil
+}
+
+type seeder struct {
+	rand *rand.Rand
+	opts Options
+
+	users      []*domain.Profile
+	authors    *rand.Zipf
+	categories *rand.Zipf
+}
+
+// zipf picks indexes below n, the small ones much more often.
+func (g *seeder) zipf(n int) *rand.Zipf {
+	return rand.NewZipf(g.rand, 1.1, 2, uint64(n-1))
+}
+
+func (g *seeder) username(i int) string {
+	return fmt.Sprintf("%s_%s_%d", adjectives[i%len(adjectives)], nouns[(i/len(adjectives))%len(nouns)], i)
+}
+
+func (g *seeder) post(i int) *domain.Post {
+	author := g.users[g.authors.Uint64()]
+	span := g.opts.Now.Sub(g.opts.Now.AddDate(0, -g.opts.Months, 0))
+	created := g.opts.Now.Add(-time.Duration(g.rand.Int63n(int64(span)))).Truncate(time.Millisecond)
+	title := g.sentence(3, 10)
+
+	post := &domain.Post{
+		ID:       generator.GenerateIDFrom(fmt.Sprintf("seed-%d-post-%d", g.opts.Seed, i)),
+		Type:     domain.PostTypeText,
+		Title:    title,
+		Category: domain.Categories[g.categories.Uint64()],
+		Author:   &domain.Profile{Username: author.Username, ID: author.ID},
+		Created:  created,
+		Votes:    g.votes(author),
+		Comments: []*domain.Comment{},
+	}
+
+	if g.rand.Intn(3) == 0 {
+		post.Type = domain.PostTypeLink
+		post.URL = "https://example.com/" + slug(title)
+	} else {
+		post.Text = g.paragraph()
+	}
+
+	post.Score, post.UpvotePercentage = score(post.Votes)
+	post.Views = uint(len(post.Votes)*(5+g.rand.Intn(15)) + g.rand.Intn(20))
+	post.Comments = g.comments(post, i)
+
+	return post
+}
+
+// votes starts with the upvote of the author, like a new post. How many
+// more a post gets follows a log-normal distribution: most get a handful, a
+// few get most of the users. The share of upvotes varies around 80%.
+func (g *seeder) votes(author *domain.Profile) []*domain.Vote {
+	votes := []*domain.Vote{{User: author.ID, Vote: 1}}
+	n := min(int(math.Exp(1+1.4*g.rand.NormFloat64())), len(g.users)-1)
+	upvotes := math.Max(0.05, math.Min(0.98, 0.8+0.15*g.rand.NormFloat64()))
+
+	for _, voter := range g.sample(n, author) {
+		vote := -1
+
+		if g.rand.Float64() < upvotes {
+			vote = 1
+		}
+
+		votes = append(votes, &domain.Vote{User: voter.ID, Vote: vote})
+	}
+
+	return votes
+}
+
+// sample picks n distinct users other than the author.
+func (g *seeder) sample(n int, author *domain.Profile) []*domain.Profile {
+	picked := make([]*domain.Profile, 0, n)
+
+	for _, i := range g.rand.Perm(len(g.users)) {
+		if len(picked) == n {
+			break
+		}
+
+		if g.users[i].ID != author.ID {
+			picked = append(picked, g.users[i])
+		}
+	}
+
+	return picked
+}
+
+// comments come in after the post, most of them within the first hours, and
+// the more votes a post has, the more comments.
+func (g *seeder) comments(post *domain.Post, i int) []*domain.Comment {
+	mean := float64(g.opts.Comments) * (0.3 + 0.35*math.Log1p(float64(len(post.Votes))))
+	n := min(g.poisson(mean), 200)
+	comments := make([]*domain.Comment, 0, n)
+
+	for j := 0; j < n; j++ {
+		author := g.users[g.authors.Uint64()]
+		delay := time.Duration(g.rand.ExpFloat64() * float64(8*time.Hour))
+		created := post.Created.Add(delay)
+
+		if created.After(g.opts.Now) {
+			created = g.opts.Now
+		}
+
+		comments = append(comments, &domain.Comment{
+			ID:      generator.GenerateIDFrom(fmt.Sprintf("seed-%d-post-%d-comment-%d", g.opts.Seed, i, j)),
+			Author:  &domain.Profile{Username: author.Username, ID: author.ID},
+			Body:    g.sentence(4, 25),
+			Created: created.Truncate(time.Millisecond),
+		})
+	}
+
+	sort.SliceStable(comments, func(a, b int) bool { return comments[a].Created.Before(comments[b].Created) })
+
+	return comments
+}
+
+// poisson draws with Knuth's method, fine for the small means here.
+func (g *seeder) poisson(mean float64) int {
+	limit, k, p := math.Exp(-mean), 0, 1.0
+
+	for {
+		p *= g.rand.Float64()
+
+		if p <= limit {
+			return k
+		}
+
+		k++
+	}
+}
+
+func (g *seeder) sentence(minWords, maxWords int) string {
+	n := minWords + g.rand.Intn(maxWords-minWords+1)
+	b := make([]byte, 0, n*8)
+
+	for i := 0; i < n; i++ {
+		word := words[g.rand.Intn(len(words))]
+
+		if i == 0 {
+			b = append(b, word[0]-'a'+'A')
+			b = append(b, word[1:]...)
+			continue
+		}
+
+		b = append(b, ' ')
+		b = append(b, word...)
+	}
+
+	return string(b)
+}
+
+func (g *seeder) paragraph() string {
+	n := 1 + g.rand.Intn(4)
+	text := ""
+
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			text += " "
+		}
+
+		text += g.sentence(6, 18) + "."
+	}
+
+	return text
+}
+
+// score counts like the storages do after a vote.
+func score(votes []*domain.Vote) (int, uint) {
+	score, positive := 0, 0
+
+	for _, vote := range votes {
+		score += vote.Vote
+
+		if vote.Vote > 0 {
+			positive++
+		}
+	}
+
+	if len(votes) == 0 {
+		return score, 0
+	}
+
+	return score, uint((float32(positive) / float32(len(votes))) * 100.0)
+}
+
+func slug(title string) string {
+	b := make([]byte, 0, len(title))
+
+	for i := 0; i < len(title); i++ {
+		switch c := title[i]; {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			b = append(b, c)
+		case c >= 'A' && c <= 'Z':
+			b = append(b, c-'A'+'a')
+		case c == ' ':
+			b = append(b, '-')
+		}
+	}
+
+	return string(b)
+}
+
+var (
+	adjectives = []string{"quiet", "brave", "lazy", "sharp", "happy", "grumpy", "swift", "clever", "sleepy", "bold",
+		"calm", "eager", "fuzzy", "jolly", "noisy", "proud", "shy", "witty"}
+	nouns = []string{"fox", "owl", "cat", "otter", "panda", "raven", "wolf", "badger", "gecko", "lynx", "moose", "heron"}
+	words = []string{"the", "a", "new", "old", "music", "video", "code", "news", "today", "really", "best", "worst",
+		"why", "how", "every", "nobody", "finally", "album", "guitar", "release", "bug", "compiler", "server", "deploy",
+		"outfit", "jacket", "joke", "cat", "dog", "city", "weather", "update", "review", "question", "answer", "week",
+		"thought", "works", "breaks", "loves", "hates", "found", "made", "about", "with", "without", "after", "before",
+		"again", "this", "that", "my", "your", "their", "first", "last", "great", "strange", "simple", "tiny", "huge"}
+)
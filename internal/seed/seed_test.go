@@ -0,0 +1,144 @@
+package seed
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/adapters/memory"
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+var now = time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+
+func walk(t *testing.T, db *memory.DB) []*domain.Post {
+	t.Helper()
+
+	var all []*domain.Post
+
+	err := memory.NewPostStorage(db).Walk(context.Background(), func(post *domain.Post) error {
+		all = append(all, post)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return all
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	opts := Options{Seed: 42, Users: 30, Posts: 60, Comments: 3, Months: 2, Password: "password", Now: now}
+	db := memory.New()
+	users, posts, comments := memory.NewUserStorage(db), memory.NewPostStorage(db), memory.NewCommentStorage(db)
+
+	result, err := Run(ctx, users, posts, comments, opts)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if result.Users != 30 || result.Posts != 60 || result.Comments == 0 || result.Votes < 60 {
+		t.Errorf("unexpected result: %+v", *result)
+		return
+	}
+
+	if _, err = users.Get(ctx, "quiet_fox_0", "password"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// The users register before the first post
+	if created, err := users.GetCreated(ctx, "quiet_fox_0"); err != nil || !created.Equal(now.AddDate(0, -2, 0)) {
+		t.Errorf("unexpected registration %s, error: %v", created, err)
+		return
+	}
+
+	// OK, the posts are spread over the months, the votes are of distinct
+	// users and the comments come after their post
+	seeded := walk(t, db)
+	stored := 0
+
+	for _, post := range seeded {
+		stored += len(post.Comments)
+
+		if post.Created.After(now) || post.Created.Before(now.AddDate(0, -2, 0)) {
+			t.Errorf("post %s created at %s", post.ID, post.Created)
+			return
+		}
+
+		voters := map[string]bool{}
+
+		for _, vote := range post.Votes {
+			if voters[vote.User] {
+				t.Errorf("post %s has two votes of %s", post.ID, vote.User)
+				return
+			}
+
+			voters[vote.User] = true
+		}
+
+		for _, comment := range post.Comments {
+			if comment.Created.Before(post.Created) || comment.Created.After(now) {
+				t.Errorf("comment %s created at %s, the post at %s", comment.ID, comment.Created, post.Created)
+				return
+			}
+		}
+	}
+
+	if stored != result.Comments {
+		t.Errorf("expected %d stored comments, got: %d", result.Comments, stored)
+		return
+	}
+
+	// The scores are what the storage counts
+	if changed, err := posts.RecomputeScores(ctx, ""); err != nil || changed != 0 {
+		t.Errorf("expected no changes, got: %d, %v", changed, err)
+		return
+	}
+
+	// Another run adds nothing
+	result, err = Run(ctx, users, posts, comments, opts)
+
+	if err != nil || *result != (Result{UsersSkipped: 30, PostsSkipped: 60}) {
+		t.Errorf("expected everything skipped, got: %+v, %v", result, err)
+		return
+	}
+
+	// The same seed gives the same data elsewhere
+	other := memory.New()
+
+	if _, err = Run(ctx, memory.NewUserStorage(other), memory.NewPostStorage(other), memory.NewCommentStorage(other), opts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if got := walk(t, other); !reflect.DeepEqual(seeded, got) {
+		t.Errorf("expected the same posts for the same seed")
+		return
+	}
+
+	// Another seed gives other data
+	opts.Seed = 7
+	result, err = Run(ctx, users, posts, comments, opts)
+
+	if err != nil || result.Posts != 60 || result.UsersSkipped != 30 {
+		t.Errorf("unexpected result: %+v, %v", result, err)
+	}
+}
+
+func TestRunOptions(t *testing.T) {
+	db := memory.New()
+
+	for _, opts := range []Options{
+		{Users: 0, Months: 1},
+		{Users: 1, Months: 0},
+		{Users: 1, Months: 1, Posts: -1},
+	} {
+		if _, err := Run(context.Background(), memory.NewUserStorage(db), memory.NewPostStorage(db), memory.NewCommentStorage(db), opts); err == nil {
+			t.Errorf("%+v: expected error, got nil", opts)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package service
+
+import "github.com/akrovv/redditclone/internal/domain"
+
+func isAuthor(user *domain.User, author *domain.Profile) bool {
+	return author != nil && user.ID == author.ID
+}
+
+// canDelete allows authors to delete their own content and moderators or
+// admins to delete anyone's.
+func canDelete(user *domain.User, author *domain.Profile) bool {
+	return isAuthor(user, author) || user.Role == domain.RoleModerator || user.Role == domain.RoleAdmin
+}
+
+// canRestore is narrower than canDelete: only the author or an admin can
+// bring deleted content back.
+func canRestore(user *domain.User, author *domain.Profile) bool {
+	return isAuthor(user, author) || user.Role == domain.RoleAdmin
+}
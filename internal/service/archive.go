@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/archive"
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/generator"
+)
+
+type archiveService struct {
+	users UserStorage
+	posts PostStorage
+}
+
+func NewArchiveService(users UserStorage, posts PostStorage) *archiveService {
+	return &archiveService{users: users, posts: posts}
+}
+
+// Export streams every user and every post with its comments and votes,
+// deleted and removed content too, so nothing restorable is lost.
+func (s archiveService) Export(ctx context.Context, dto *ExportArchive) (*archive.Counts, error) {
+	ctx, span := tracer.Start(ctx, "archiveService.Export")
+	defer span.End()
+
+	w, err := archive.NewWriter(dto.Writer, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.users.Walk(ctx, w.WriteUser); err != nil {
+		return nil, err
+	}
+
+	if err = s.posts.Walk(ctx, w.WritePost); err != nil {
+		return nil, err
+	}
+
+	return w.Close()
+}
+
+// Import restores an archive. Running it again changes nothing: users whose
+// username is taken and posts that are there already are skipped. The users
+// get the IDs of this storage, and the posts, comments and votes refer to
+// them by those.
+func (s archiveService) Import(ctx context.Context, dto *ImportArchive) (*ImportResult, error) {
+	ctx, span := tracer.Start(ctx, "archiveService.Import")
+	defer span.End()
+
+	r, err := archive.NewReader(dto.Reader)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	// userIDs maps the user IDs of the archive to the ones here.
+	userIDs := map[string]string{}
+
+	for {
+		user, post, err := r.Next()
+
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+
+		if err != nil {
+			return result, err
+		}
+
+		if user != nil {
+			err = s.importUser(ctx, user, userIDs, result)
+		} else {
+			err = s.importPost(ctx, post, userIDs, result)
+		}
+
+		if err != nil {
+			return result, err
+		}
+	}
+}
+
+func (s archiveService) importUser(ctx context.Context, user *domain.UserRecord, userIDs map[string]string, result *ImportResult) error {
+	id, created, err := s.users.Import(ctx, user)
+
+	if err != nil {
+		return err
+	}
+
+	userIDs[user.ID] = id
+
+	switch {
+	case !created:
+		result.UsersSkipped++
+	case user.Password == nil || user.Password.Scheme != s.users.PasswordScheme():
+		result.Users++
+		result.PasswordResets++
+	default:
+		result.Users++
+	}
+
+	return nil
+}
+
+// importPost keeps the IDs of the post and its comments unless a different
+// post has the ID here. Then both get IDs derived from theirs, the same on
+// every import, so a second import finds the post under the derived ID.
+func (s archiveService) importPost(ctx context.Context, post *domain.Post, userIDs map[string]string, result *ImportResult) error {
+	remapUsers(post, userIDs)
+
+	err := s.posts.Import(ctx, post)
+
+	if err == nil {
+		result.Posts++
+		return nil
+	}
+
+	if !errors.Is(err, domain.ErrAlreadyExists) {
+		return err
+	}
+
+	existing, err := s.findPost(ctx, post.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if samePost(existing, post) {
+		result.PostsSkipped++
+		return nil
+	}
+
+	post.ID = generator.GenerateIDFrom(post.ID)
+
+	for _, comment := range post.Comments {
+		comment.ID = generator.GenerateIDFrom(comment.ID)
+	}
+
+	err = s.posts.Import(ctx, post)
+
+	switch {
+	case errors.Is(err, domain.ErrAlreadyExists):
+		result.PostsSkipped++
+	case err != nil:
+		return err
+	default:
+		result.Posts++
+		result.PostsRemapped++
+	}
+
+	return nil
+}
+
+// findPost looks for the post whether it is deleted or not.
+func (s archiveService) findPost(ctx context.Context, id string) (*domain.Post, error) {
+	post, err := s.posts.GetOne(ctx, id)
+
+	if errors.Is(err, domain.ErrNotFound) {
+		return s.posts.GetDeleted(ctx, id)
+	}
+
+	return post, err
+}
+
+// samePost tells whether the post here is the one of the archive, imported
+// before. The fields compared don't change after a post is created. Mongo
+// keeps the time in milliseconds, so the time is compared in those.
+func samePost(existing, imported *domain.Post) bool {
+	return existing.Created.Truncate(time.Millisecond).Equal(imported.Created.Truncate(time.Millisecond)) && existing.Title == imported.Title &&
+		existing.Author != nil && imported.Author != nil && existing.Author.Username == imported.Author.Username
+}
+
+// remapUsers replaces the user IDs of the archive with the ones here. IDs of
+// users that are not in the archive stay as they are.
+func remapUsers(post *domain.Post, userIDs map[string]string) {
+	remap := func(profile *domain.Profile) {
+		if profile == nil {
+			return
+		}
+
+		if id, ok := userIDs[profile.ID]; ok {
+			profile.ID = id
+		}
+	}
+
+	remap(post.Author)
+	remap(post.DeletedBy)
+
+	for _, vote := range post.Votes {
+		if id, ok := userIDs[vote.User]; ok {
+			vote.User = id
+		}
+	}
+
+	for _, comment := range post.Comments {
+		remap(comment.Author)
+		remap(comment.DeletedBy)
+	}
+}
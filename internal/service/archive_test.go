@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/adapters/memory"
+	"github.com/akrovv/redditclone/internal/archive"
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func newArchiveService(db *memory.DB) *archiveService {
+	return NewArchiveService(memory.NewUserStorage(db), memory.NewPostStorage(db))
+}
+
+func walkPosts(t *testing.T, posts PostStorage) []*domain.Post {
+	t.Helper()
+
+	var all []*domain.Post
+
+	err := posts.Walk(context.Background(), func(post *domain.Post) error {
+		all = append(all, post)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return all
+}
+
+func TestArchive(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC)
+	source := memory.NewWithClock(func() time.Time { return now })
+	users, posts := memory.NewUserStorage(source), memory.NewPostStorage(source)
+
+	akro, err := users.Save(ctx, "akro", "password")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	author := &domain.Profile{Username: akro.Username, ID: akro.ID}
+
+	var saved []*domain.Post
+
+	for _, title := range []string{"first", "second"} {
+		post, err := posts.Save(ctx, &domain.Post{Type: domain.PostTypeText, Title: title, Text: "text", Category: "music", Author: author,
+			Votes: []*domain.Vote{}, Comments: []*domain.Comment{}})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		saved = append(saved, post)
+	}
+
+	if err = posts.UpdateMetrics(ctx, saved[0].ID, 1, akro.ID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err = memory.NewCommentStorage(source).Add(ctx, author, "body", saved[0].ID); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err = posts.Delete(ctx, saved[1].ID, author); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	counts, err := newArchiveService(source).Export(ctx, &ExportArchive{Writer: buf})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if *counts != (archive.Counts{Users: 1, Posts: 2, Comments: 1, Votes: 1}) {
+		t.Errorf("unexpected counts: %+v", *counts)
+		return
+	}
+
+	// OK, everything arrives and the password still works
+	target := memory.New()
+	result, err := newArchiveService(target).Import(ctx, &ImportArchive{Reader: bytes.NewReader(buf.Bytes())})
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if *result != (ImportResult{Users: 1, Posts: 2}) {
+		t.Errorf("unexpected result: %+v", *result)
+		return
+	}
+
+	if _, err = memory.NewUserStorage(target).Get(ctx, "akro", "password"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if got, expected := walkPosts(t, memory.NewPostStorage(target)), walkPosts(t, posts); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected: %v, got: %v", expected, got)
+		return
+	}
+
+	// Import again, nothing changes
+	result, err = newArchiveService(target).Import(ctx, &ImportArchive{Reader: bytes.NewReader(buf.Bytes())})
+
+	if err != nil || *result != (ImportResult{UsersSkipped: 1, PostsSkipped: 2}) {
+		t.Errorf("expected everything skipped, got: %+v, %v", result, err)
+		return
+	}
+
+	// Another post has the ID, the imported one gets another ID, the same on
+	// the second import
+	other := memory.New()
+	err = memory.NewPostStorage(other).Import(ctx, &domain.Post{ID: saved[0].ID, Title: "other", Author: &domain.Profile{Username: "other"}})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, expected := range []ImportResult{{Users: 1, Posts: 2, PostsRemapped: 1}, {UsersSkipped: 1, PostsSkipped: 2}} {
+		result, err = newArchiveService(other).Import(ctx, &ImportArchive{Reader: bytes.NewReader(buf.Bytes())})
+
+		if err != nil || *result != expected {
+			t.Errorf("expected: %+v, got: %+v, %v", expected, result, err)
+			return
+		}
+	}
+
+	if n := len(walkPosts(t, memory.NewPostStorage(other))); n != 3 {
+		t.Errorf("expected 3 posts, got: %d", n)
+		return
+	}
+}
+
+func TestArchiveImportPasswordScheme(t *testing.T) {
+	ctx := context.Background()
+	buf := &bytes.Buffer{}
+	w, err := archive.NewWriter(buf, time.Now())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = w.WriteUser(&domain.UserRecord{Username: "akro", ID: "1", Role: domain.RoleMember, Active: true,
+		Password: &domain.PasswordHash{Scheme: "argon2id", Hash: "hash"}})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if _, err = w.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The hash of another storage is not taken over
+	db := memory.New()
+	result, err := newArchiveService(db).Import(ctx, &ImportArchive{Reader: buf})
+
+	if err != nil || *result != (ImportResult{Users: 1, PasswordResets: 1}) {
+		t.Errorf("expected a password reset, got: %+v, %v", result, err)
+		return
+	}
+
+	if _, err = memory.NewUserStorage(db).Get(ctx, "akro", "hash"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected not found, got: %v", err)
+	}
+}
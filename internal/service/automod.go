@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/automod"
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// automodUser is the actor recorded for everything the rules engine does.
+var automodUser = &domain.User{Username: "automod", ID: "automod"}
+
+type automodService struct {
+	storage AutomodStorage
+	posts   PostStorage
+	users   UserStorage
+	reports ReportStorage
+	modLog  ModLogStorage
+}
+
+func NewAutomodService(storage AutomodStorage, posts PostStorage, users UserStorage, reports ReportStorage, modLog ModLogStorage) *automodService {
+	return &automodService{storage: storage, posts: posts, users: users, reports: reports, modLog: modLog}
+}
+
+func (s automodService) Get(ctx context.Context, dto *GetAutomod) (*domain.AutomodRules, error) {
+	ctx, span := tracer.Start(ctx, "automodService.Get")
+	defer span.End()
+
+	rules, err := s.storage.Get(ctx, dto.Category)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		return nil, domain.NotFound("automod rules not found")
+	}
+
+	return rules, nil
+}
+
+func (s automodService) Validate(ctx context.Context, dto *ValidateAutomod) (*automod.RuleSet, error) {
+	ctx, span := tracer.Start(ctx, "automodService.Validate")
+	defer span.End()
+
+	return automod.Parse([]byte(dto.Source))
+}
+
+// Save replaces the rule set of a category. Invalid rule sets are never
+// stored.
+func (s automodService) Save(ctx context.Context, dto *SaveAutomod) (*domain.AutomodRules, error) {
+	ctx, span := tracer.Start(ctx, "automodService.Save")
+	defer span.End()
+
+	_, err := automod.Parse([]byte(dto.Source))
+
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := s.storage.Get(ctx, dto.Category)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.storage.Save(ctx, &domain.AutomodRules{
+		Category:  dto.Category,
+		Source:    dto.Source,
+		UpdatedBy: &domain.Profile{Username: dto.User.Username, ID: dto.User.ID},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	record := &modLogRecord{
+		actor:      dto.User,
+		action:     domain.ModActionUpdateAutomod,
+		targetType: "automod",
+		targetID:   dto.Category,
+		category:   dto.Category,
+		after:      rules,
+	}
+
+	if before != nil {
+		record.before = before
+	}
+
+	writeModLog(ctx, s.modLog, record)
+
+	return rules, nil
+}
+
+// DryRun evaluates a rule set against the posts of a category and their
+// comments without changing anything. An empty source means the stored
+// rules.
+func (s automodService) DryRun(ctx context.Context, dto *DryRunAutomod) ([]*automod.Result, error) {
+	ctx, span := tracer.Start(ctx, "automodService.DryRun")
+	defer span.End()
+
+	source := dto.Source
+
+	if source == "" {
+		stored, err := s.Get(ctx, &GetAutomod{Category: dto.Category})
+
+		if err != nil {
+			return nil, err
+		}
+
+		source = stored.Source
+	}
+
+	set, err := automod.Parse([]byte(source))
+
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.posts.GetBy(ctx, "category", dto.Category, "created")
+
+	if err != nil {
+		return nil, err
+	}
+
+	authors := make(map[string]*automod.Author)
+	results := []*automod.Result{}
+
+	for _, post := range posts {
+		author, err := s.cachedAuthor(ctx, set, authors, post.Author)
+
+		if err != nil {
+			return nil, err
+		}
+
+		matches := set.Evaluate(&automod.Content{
+			Target: automod.TargetPost,
+			Title:  post.Title,
+			Body:   post.Text,
+			URL:    post.URL,
+			Author: author,
+		})
+
+		if len(matches) != 0 {
+			results = append(results, &automod.Result{PostID: post.ID, Title: post.Title, Matches: matches})
+		}
+
+		for _, comment := range post.Comments {
+			author, err := s.cachedAuthor(ctx, set, authors, comment.Author)
+
+			if err != nil {
+				return nil, err
+			}
+
+			matches := set.Evaluate(&automod.Content{
+				Target: automod.TargetComment,
+				Body:   comment.Body,
+				Author: author,
+			})
+
+			if len(matches) != 0 {
+				results = append(results, &automod.Result{PostID: post.ID, CommentID: comment.ID, Matches: matches})
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// check evaluates the stored rules of a category. A nil service or a
+// category without rules lets everything through.
+func (s *automodService) check(ctx context.Context, category string, content *automod.Content, username string) ([]*automod.Match, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	stored, err := s.storage.Get(ctx, category)
+
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	set, err := automod.Parse([]byte(stored.Source))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if set.NeedsAuthor() {
+		if content.Author, err = s.author(ctx, username); err != nil {
+			return nil, err
+		}
+	}
+
+	matches := set.Evaluate(content)
+
+	if match := automod.First(matches, automod.ActionReject); match != nil {
+		message := match.Message
+
+		if message == "" {
+			message = match.Rule
+		}
+
+		return nil, fmt.Errorf("%w: %s", domain.ErrAutomodRejected, message)
+	}
+
+	return matches, nil
+}
+
+// apply records the remove and queue matches once the content is stored.
+// comment is nil for posts.
+func (s *automodService) apply(ctx context.Context, post *domain.Post, comment *domain.Comment, matches []*automod.Match) error {
+	if s == nil {
+		return nil
+	}
+
+	report := &domain.Report{
+		TargetType: domain.ReportTargetPost,
+		PostID:     post.ID,
+		Category:   post.Category,
+		Reporter:   &domain.Profile{Username: automodUser.Username, ID: automodUser.ID},
+	}
+
+	record := &modLogRecord{
+		actor:      automodUser,
+		action:     domain.ModActionRemovePost,
+		targetType: domain.ReportTargetPost,
+		targetID:   post.ID,
+		category:   post.Category,
+		after:      post,
+	}
+
+	if comment != nil {
+		report.TargetType, report.CommentID = domain.ReportTargetComment, comment.ID
+		record.action, record.targetType, record.targetID = domain.ModActionRemoveComment, domain.ReportTargetComment, comment.ID
+		record.after = comment
+	}
+
+	if match := automod.First(matches, automod.ActionRemove); match != nil {
+		record.reason = matchReason(match)
+
+		writeModLog(ctx, s.modLog, record)
+	}
+
+	if match := automod.First(matches, automod.ActionQueue); match != nil {
+		report.Reason = matchReason(match)
+
+		if _, err := s.reports.Save(ctx, report); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s automodService) author(ctx context.Context, username string) (*automod.Author, error) {
+	created, err := s.users.GetCreated(ctx, username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.posts.GetBy(ctx, "author.username", username, "created")
+
+	if err != nil {
+		return nil, err
+	}
+
+	author := &automod.Author{AccountAge: time.Since(created)}
+
+	for _, post := range posts {
+		author.Karma += post.Score
+	}
+
+	return author, nil
+}
+
+// cachedAuthor loads the stats of every author once per dry run. An author
+// the users storage doesn't know, e.g. a deleted account, has no stats, the
+// account rules don't match their content.
+func (s automodService) cachedAuthor(ctx context.Context, set *automod.RuleSet, authors map[string]*automod.Author, profile *domain.Profile) (*automod.Author, error) {
+	if !set.NeedsAuthor() || profile == nil {
+		return nil, nil
+	}
+
+	if author, ok := authors[profile.Username]; ok {
+		return author, nil
+	}
+
+	author, err := s.author(ctx, profile.Username)
+
+	if errors.Is(err, domain.ErrNotFound) {
+		author, err = nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	authors[profile.Username] = author
+	return author, nil
+}
+
+func matchReason(match *automod.Match) string {
+	if match.Message != "" {
+		return "automod: " + match.Message
+	}
+
+	return "automod: " + match.Rule
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/adapters/memory"
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestDryRunAccountRules(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	posts, comments, users := memory.NewPostStorage(db), memory.NewCommentStorage(db), memory.NewUserStorage(db)
+	modLog := memory.NewModLogStorage(db)
+	s := NewAutomodService(memory.NewAutomodStorage(db), posts, users, memory.NewReportStorage(db), modLog)
+	commentService := NewCommentService(comments, posts, modLog, ContentPolicy{RestoreWindow: time.Hour}, s)
+
+	newcomer, err := users.Save(ctx, "newcomer", "password")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	post, err := posts.Save(ctx, &domain.Post{Type: domain.PostTypeText, Title: "title", Text: "text", Category: "music",
+		Author: &domain.Profile{Username: newcomer.Username, ID: newcomer.ID}, Votes: []*domain.Vote{}, Comments: []*domain.Comment{}})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The ghost has no account, e.g. it was deleted since
+	ghost := &domain.User{Username: "ghost", ID: "ghost-id", Role: domain.RoleMember}
+
+	for _, user := range []*domain.User{newcomer, ghost} {
+		if err = commentService.Add(ctx, &AddComment{User: user, Body: "first!", PostID: post.ID}); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	results, err := s.DryRun(ctx, &DryRunAutomod{Category: "music", Source: `
+rules:
+  - name: new-accounts
+    target: comment
+    account_age_below: 24h
+    action: queue
+`})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Only the comment of the known new account matches
+	stored, err := posts.GetOne(ctx, post.ID)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(results) != 1 || results[0].CommentID != stored.Comments[0].ID || stored.Comments[0].Author.Username != "newcomer" {
+		t.Errorf("expected the comment of newcomer, got: %+v", results)
+	}
+}
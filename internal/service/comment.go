@@ -1,20 +1,163 @@
 package service
 
-import "github.com/akrovv/redditclone/internal/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/automod"
+	"github.com/akrovv/redditclone/internal/domain"
+)
 
 type commentService struct {
 	storage CommentStorage
+	posts   PostStorage
+	modLog  ModLogStorage
+	policy  ContentPolicy
+	automod *automodService
+}
+
+func NewCommentService(storage CommentStorage, posts PostStorage, modLog ModLogStorage, policy ContentPolicy, automod *automodService) *commentService {
+	return &commentService{storage: storage, posts: posts, modLog: modLog, policy: policy, automod: automod}
 }
 
-func NewCommentService(storage CommentStorage) *commentService {
-	return &commentService{storage: storage}
+func (s commentService) Add(ctx context.Context, dto *AddComment) error {
+	ctx, span := tracer.Start(ctx, "commentService.Add")
+	defer span.End()
+
+	if err := dto.Validate(); err != nil {
+		return err
+	}
+
+	post, err := s.posts.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	if err = s.policy.checkWritable(post); err != nil {
+		return err
+	}
+
+	content := &automod.Content{Target: automod.TargetComment, Body: dto.Body}
+	matches, err := s.automod.check(ctx, post.Category, content, dto.User.Username)
+
+	if err != nil {
+		return err
+	}
+
+	author := &domain.Profile{Username: dto.User.Username, ID: dto.User.ID}
+	comment, err := s.storage.Add(ctx, author, dto.Body, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	if automod.First(matches, automod.ActionRemove) != nil {
+		if err = s.storage.SetRemoved(ctx, dto.PostID, comment.ID, true); err != nil {
+			return err
+		}
+
+		comment.Removed = true
+	}
+
+	return s.automod.apply(ctx, post, comment, matches)
+}
+
+func (s commentService) Delete(ctx context.Context, dto *DeleteComment) error {
+	ctx, span := tracer.Start(ctx, "commentService.Delete")
+	defer span.End()
+
+	post, err := s.posts.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	comment := findComment(post, dto.CommentID)
+
+	if comment == nil {
+		return domain.NotFound("comment not found")
+	}
+
+	if !canDelete(dto.User, comment.Author) {
+		return domain.ErrForbidden
+	}
+
+	deletedBy := &domain.Profile{Username: dto.User.Username, ID: dto.User.ID}
+	err = s.storage.Delete(ctx, dto.PostID, dto.CommentID, deletedBy)
+
+	if err != nil {
+		return err
+	}
+
+	deletedAt := time.Now()
+	deleted := *comment
+	deleted.DeletedAt, deleted.DeletedBy = &deletedAt, deletedBy
+
+	writeModeratorLog(ctx, s.modLog, comment.Author, &modLogRecord{
+		actor:      dto.User,
+		action:     domain.ModActionDeleteComment,
+		targetType: domain.ReportTargetComment,
+		targetID:   comment.ID,
+		category:   post.Category,
+		reason:     dto.Reason,
+		before:     comment,
+		after:      &deleted,
+	})
+
+	return nil
 }
 
-func (s commentService) Add(dto *AddComment) error {
-	author := &domain.Profile{Username: dto.User.Password, ID: dto.User.ID}
-	return s.storage.Add(author, dto.Body, dto.PostID)
+func (s commentService) Restore(ctx context.Context, dto *RestoreComment) error {
+	ctx, span := tracer.Start(ctx, "commentService.Restore")
+	defer span.End()
+
+	post, err := s.posts.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.storage.GetDeleted(ctx, dto.PostID, dto.CommentID)
+
+	if err != nil {
+		return err
+	}
+
+	if !canRestore(dto.User, comment.Author) {
+		return domain.ErrForbidden
+	}
+
+	if time.Since(*comment.DeletedAt) > s.policy.RestoreWindow {
+		return domain.ErrRestoreWindowClosed
+	}
+
+	err = s.storage.Restore(ctx, dto.PostID, dto.CommentID)
+
+	if err != nil {
+		return err
+	}
+
+	restored := *comment
+	restored.DeletedAt, restored.DeletedBy = nil, nil
+
+	writeModeratorLog(ctx, s.modLog, comment.Author, &modLogRecord{
+		actor:      dto.User,
+		action:     domain.ModActionRestoreComment,
+		targetType: domain.ReportTargetComment,
+		targetID:   comment.ID,
+		category:   post.Category,
+		before:     comment,
+		after:      &restored,
+	})
+
+	return nil
 }
 
-func (s commentService) Delete(dto *DeleteComment) error {
-	return s.storage.Delete(dto.PostID, dto.CommentID)
+// Purge hard-deletes comments whose restore window has passed.
+func (s commentService) Purge(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "commentService.Purge")
+	defer span.End()
+
+	return s.storage.Purge(ctx, time.Now().Add(-s.policy.RestoreWindow))
 }
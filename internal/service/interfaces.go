@@ -1,30 +1,90 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/akrovv/redditclone/internal/domain"
 )
 
 type PostStorage interface {
-	Save(post *domain.Post) (*domain.Post, error)
-	GetOne(id string) (*domain.Post, error)
-	Get() ([]*domain.Post, error)
-	GetBy(category, data, sortField string) ([]*domain.Post, error)
-	UpdateMetrics(postID string, inc int8, authorID string) error
-	IncrViews(postID string) error
-	Delete(postID string) error
+	Save(ctx context.Context, post *domain.Post) (*domain.Post, error)
+	GetOne(ctx context.Context, id string) (*domain.Post, error)
+	Get(ctx context.Context) ([]*domain.Post, error)
+	GetBy(ctx context.Context, category, data, sortField string) ([]*domain.Post, error)
+	UpdateMetrics(ctx context.Context, postID string, inc int8, authorID string) error
+	IncrViews(ctx context.Context, postID string) error
+	Delete(ctx context.Context, postID string, deletedBy *domain.Profile) error
+	SetRemoved(ctx context.Context, postID string, removed bool) error
+	GetDeleted(ctx context.Context, id string) (*domain.Post, error)
+	Restore(ctx context.Context, postID string) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	SetLocked(ctx context.Context, postID string, locked bool) error
+	SetStickied(ctx context.Context, postID string, stickied bool) error
+	GetStickied(ctx context.Context, category string) ([]*domain.Post, error)
+	Archive(ctx context.Context, createdBefore time.Time) (int64, error)
+	// RecomputeScores counts the score and the upvote percentage of the
+	// post from its votes anew, of every post if postID is empty, and
+	// reports how many posts changed.
+	RecomputeScores(ctx context.Context, postID string) (int64, error)
+	// Walk calls fn with every post in the order they were saved, removed
+	// and deleted ones too, with all of their votes and comments.
+	Walk(ctx context.Context, fn func(post *domain.Post) error) error
+	// Import saves the post as it is, with its ID, votes and comments. It
+	// returns an already exists error if there is a post with the ID.
+	Import(ctx context.Context, post *domain.Post) error
 }
 
 type UserStorage interface {
-	Get(username, password string) (*domain.User, error)
-	Save(username, password string) (*domain.User, error)
+	Get(ctx context.Context, username, password string) (*domain.User, error)
+	Save(ctx context.Context, username, password string) (*domain.User, error)
+	GetCreated(ctx context.Context, username string) (time.Time, error)
+	SetPassword(ctx context.Context, username, password string) error
+	SetActive(ctx context.Context, username string, active bool) error
+	// Walk calls fn with every user in the order they registered.
+	Walk(ctx context.Context, fn func(user *domain.UserRecord) error) error
+	// Import saves the user unless the username is taken and returns the ID
+	// the user has in this storage. A password hash of another scheme is
+	// not taken over, the password has to be reset then.
+	Import(ctx context.Context, user *domain.UserRecord) (id string, created bool, err error)
+	// PasswordScheme names the password hashes Walk returns and Import
+	// takes over.
+	PasswordScheme() string
 }
 
 type SessionStorage interface {
-	Create(ID, username string) (*domain.Session, error)
-	Get(key string) (*domain.User, error)
+	Create(ctx context.Context, ID, username, role string) (*domain.Session, error)
+	Get(ctx context.Context, key string) (*domain.User, error)
+	List(ctx context.Context, username string) ([]*domain.SessionInfo, error)
+	Delete(ctx context.Context, key string) error
 }
 
 type CommentStorage interface {
-	Add(author *domain.Profile, body, postID string) error
-	Delete(postID, commentID string) error
+	Add(ctx context.Context, author *domain.Profile, body, postID string) (*domain.Comment, error)
+	Delete(ctx context.Context, postID, commentID string, deletedBy *domain.Profile) error
+	SetRemoved(ctx context.Context, postID, commentID string, removed bool) error
+	GetDeleted(ctx context.Context, postID, commentID string) (*domain.Comment, error)
+	Restore(ctx context.Context, postID, commentID string) error
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// Import adds the comment to the end of the post as it is, with its ID
+	// and timestamps. It returns a not found error if there is no post with
+	// postID and an already exists error if there is a comment with the ID.
+	Import(ctx context.Context, postID string, comment *domain.Comment) error
+}
+
+type ReportStorage interface {
+	Save(ctx context.Context, report *domain.Report) (*domain.Report, error)
+	GetOne(ctx context.Context, id string) (*domain.Report, error)
+	GetOpen(ctx context.Context, category string) ([]*domain.Report, error)
+	Resolve(ctx context.Context, postID, commentID, status string, moderator *domain.Profile) error
+}
+
+type ModLogStorage interface {
+	Save(ctx context.Context, entry *domain.ModLogEntry) error
+	Get(ctx context.Context, filter *domain.ModLogFilter) ([]*domain.ModLogEntry, error)
+}
+
+type AutomodStorage interface {
+	Get(ctx context.Context, category string) (*domain.AutomodRules, error)
+	Save(ctx context.Context, rules *domain.AutomodRules) (*domain.AutomodRules, error)
 }
@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interfaces.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	automod "github.com/akrovv/redditclone/internal/automod"
+	domain "github.com/akrovv/redditclone/internal/domain"
+	service "github.com/akrovv/redditclone/internal/service"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAutomodService is a mock of AutomodService interface.
+type MockAutomodService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAutomodServiceMockRecorder
+}
+
+// MockAutomodServiceMockRecorder is the mock recorder for MockAutomodService.
+type MockAutomodServiceMockRecorder struct {
+	mock *MockAutomodService
+}
+
+// NewMockAutomodService creates a new mock instance.
+func NewMockAutomodService(ctrl *gomock.Controller) *MockAutomodService {
+	mock := &MockAutomodService{ctrl: ctrl}
+	mock.recorder = &MockAutomodServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAutomodService) EXPECT() *MockAutomodServiceMockRecorder {
+	return m.recorder
+}
+
+// DryRun mocks base method.
+func (m *MockAutomodService) DryRun(ctx context.Context, dto *service.DryRunAutomod) ([]*automod.Result, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRun", ctx, dto)
+	ret0, _ := ret[0].([]*automod.Result)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DryRun indicates an expected call of DryRun.
+func (mr *MockAutomodServiceMockRecorder) DryRun(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRun", reflect.TypeOf((*MockAutomodService)(nil).DryRun), ctx, dto)
+}
+
+// Get mocks base method.
+func (m *MockAutomodService) Get(ctx context.Context, dto *service.GetAutomod) (*domain.AutomodRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, dto)
+	ret0, _ := ret[0].(*domain.AutomodRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAutomodServiceMockRecorder) Get(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAutomodService)(nil).Get), ctx, dto)
+}
+
+// Save mocks base method.
+func (m *MockAutomodService) Save(ctx context.Context, dto *service.SaveAutomod) (*domain.AutomodRules, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, dto)
+	ret0, _ := ret[0].(*domain.AutomodRules)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockAutomodServiceMockRecorder) Save(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockAutomodService)(nil).Save), ctx, dto)
+}
+
+// Validate mocks base method.
+func (m *MockAutomodService) Validate(ctx context.Context, dto *service.ValidateAutomod) (*automod.RuleSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", ctx, dto)
+	ret0, _ := ret[0].(*automod.RuleSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockAutomodServiceMockRecorder) Validate(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockAutomodService)(nil).Validate), ctx, dto)
+}
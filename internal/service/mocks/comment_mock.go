@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	service "github.com/akrovv/redditclone/internal/service"
@@ -35,29 +36,43 @@ func (m *MockCommentService) EXPECT() *MockCommentServiceMockRecorder {
 }
 
 // Add mocks base method.
-func (m *MockCommentService) Add(dto *service.AddComment) error {
+func (m *MockCommentService) Add(ctx context.Context, dto *service.AddComment) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Add", dto)
+	ret := m.ctrl.Call(m, "Add", ctx, dto)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Add indicates an expected call of Add.
-func (mr *MockCommentServiceMockRecorder) Add(dto interface{}) *gomock.Call {
+func (mr *MockCommentServiceMockRecorder) Add(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockCommentService)(nil).Add), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockCommentService)(nil).Add), ctx, dto)
 }
 
 // Delete mocks base method.
-func (m *MockCommentService) Delete(dto *service.DeleteComment) error {
+func (m *MockCommentService) Delete(ctx context.Context, dto *service.DeleteComment) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", dto)
+	ret := m.ctrl.Call(m, "Delete", ctx, dto)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockCommentServiceMockRecorder) Delete(dto interface{}) *gomock.Call {
+func (mr *MockCommentServiceMockRecorder) Delete(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentService)(nil).Delete), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCommentService)(nil).Delete), ctx, dto)
+}
+
+// Restore mocks base method.
+func (m *MockCommentService) Restore(ctx context.Context, dto *service.RestoreComment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockCommentServiceMockRecorder) Restore(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockCommentService)(nil).Restore), ctx, dto)
 }
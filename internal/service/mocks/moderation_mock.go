@@ -0,0 +1,133 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interfaces.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	domain "github.com/akrovv/redditclone/internal/domain"
+	service "github.com/akrovv/redditclone/internal/service"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReportService is a mock of ReportService interface.
+type MockReportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportServiceMockRecorder
+}
+
+// MockReportServiceMockRecorder is the mock recorder for MockReportService.
+type MockReportServiceMockRecorder struct {
+	mock *MockReportService
+}
+
+// NewMockReportService creates a new mock instance.
+func NewMockReportService(ctrl *gomock.Controller) *MockReportService {
+	mock := &MockReportService{ctrl: ctrl}
+	mock.recorder = &MockReportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportService) EXPECT() *MockReportServiceMockRecorder {
+	return m.recorder
+}
+
+// Approve mocks base method.
+func (m *MockReportService) Approve(ctx context.Context, dto *service.ResolveReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Approve", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Approve indicates an expected call of Approve.
+func (mr *MockReportServiceMockRecorder) Approve(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Approve", reflect.TypeOf((*MockReportService)(nil).Approve), ctx, dto)
+}
+
+// Queue mocks base method.
+func (m *MockReportService) Queue(ctx context.Context, dto *service.GetQueue) ([]*domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queue", ctx, dto)
+	ret0, _ := ret[0].([]*domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queue indicates an expected call of Queue.
+func (mr *MockReportServiceMockRecorder) Queue(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockReportService)(nil).Queue), ctx, dto)
+}
+
+// Remove mocks base method.
+func (m *MockReportService) Remove(ctx context.Context, dto *service.ResolveReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockReportServiceMockRecorder) Remove(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockReportService)(nil).Remove), ctx, dto)
+}
+
+// Report mocks base method.
+func (m *MockReportService) Report(ctx context.Context, dto *service.ReportContent) (*domain.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", ctx, dto)
+	ret0, _ := ret[0].(*domain.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockReportServiceMockRecorder) Report(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockReportService)(nil).Report), ctx, dto)
+}
+
+// MockModLogService is a mock of ModLogService interface.
+type MockModLogService struct {
+	ctrl     *gomock.Controller
+	recorder *MockModLogServiceMockRecorder
+}
+
+// MockModLogServiceMockRecorder is the mock recorder for MockModLogService.
+type MockModLogServiceMockRecorder struct {
+	mock *MockModLogService
+}
+
+// NewMockModLogService creates a new mock instance.
+func NewMockModLogService(ctrl *gomock.Controller) *MockModLogService {
+	mock := &MockModLogService{ctrl: ctrl}
+	mock.recorder = &MockModLogServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModLogService) EXPECT() *MockModLogServiceMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockModLogService) Get(ctx context.Context, dto *service.GetModLog) ([]*domain.ModLogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, dto)
+	ret0, _ := ret[0].([]*domain.ModLogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockModLogServiceMockRecorder) Get(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockModLogService)(nil).Get), ctx, dto)
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	domain "github.com/akrovv/redditclone/internal/domain"
@@ -36,103 +37,145 @@ func (m *MockPostService) EXPECT() *MockPostServiceMockRecorder {
 }
 
 // Delete mocks base method.
-func (m *MockPostService) Delete(dto *service.DeletePost) error {
+func (m *MockPostService) Delete(ctx context.Context, dto *service.DeletePost) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", dto)
+	ret := m.ctrl.Call(m, "Delete", ctx, dto)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockPostServiceMockRecorder) Delete(dto interface{}) *gomock.Call {
+func (mr *MockPostServiceMockRecorder) Delete(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPostService)(nil).Delete), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPostService)(nil).Delete), ctx, dto)
 }
 
 // Get mocks base method.
-func (m *MockPostService) Get() ([]*domain.Post, error) {
+func (m *MockPostService) Get(ctx context.Context) ([]*domain.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get")
+	ret := m.ctrl.Call(m, "Get", ctx)
 	ret0, _ := ret[0].([]*domain.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockPostServiceMockRecorder) Get() *gomock.Call {
+func (mr *MockPostServiceMockRecorder) Get(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPostService)(nil).Get))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPostService)(nil).Get), ctx)
 }
 
 // GetBy mocks base method.
-func (m *MockPostService) GetBy(dto *service.GetByPost) ([]*domain.Post, error) {
+func (m *MockPostService) GetBy(ctx context.Context, dto *service.GetByPost) ([]*domain.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBy", dto)
+	ret := m.ctrl.Call(m, "GetBy", ctx, dto)
 	ret0, _ := ret[0].([]*domain.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetBy indicates an expected call of GetBy.
-func (mr *MockPostServiceMockRecorder) GetBy(dto interface{}) *gomock.Call {
+func (mr *MockPostServiceMockRecorder) GetBy(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBy", reflect.TypeOf((*MockPostService)(nil).GetBy), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBy", reflect.TypeOf((*MockPostService)(nil).GetBy), ctx, dto)
 }
 
 // GetOne mocks base method.
-func (m *MockPostService) GetOne(dto *service.GetOnePost) (*domain.Post, error) {
+func (m *MockPostService) GetOne(ctx context.Context, dto *service.GetOnePost) (*domain.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOne", dto)
+	ret := m.ctrl.Call(m, "GetOne", ctx, dto)
 	ret0, _ := ret[0].(*domain.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOne indicates an expected call of GetOne.
-func (mr *MockPostServiceMockRecorder) GetOne(dto interface{}) *gomock.Call {
+func (mr *MockPostServiceMockRecorder) GetOne(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOne", reflect.TypeOf((*MockPostService)(nil).GetOne), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOne", reflect.TypeOf((*MockPostService)(nil).GetOne), ctx, dto)
 }
 
 // IncrViews mocks base method.
-func (m *MockPostService) IncrViews(dto *service.IncrViewsPost) error {
+func (m *MockPostService) IncrViews(ctx context.Context, dto *service.IncrViewsPost) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IncrViews", dto)
+	ret := m.ctrl.Call(m, "IncrViews", ctx, dto)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // IncrViews indicates an expected call of IncrViews.
-func (mr *MockPostServiceMockRecorder) IncrViews(dto interface{}) *gomock.Call {
+func (mr *MockPostServiceMockRecorder) IncrViews(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrViews", reflect.TypeOf((*MockPostService)(nil).IncrViews), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrViews", reflect.TypeOf((*MockPostService)(nil).IncrViews), ctx, dto)
+}
+
+// Lock mocks base method.
+func (m *MockPostService) Lock(ctx context.Context, dto *service.LockPost) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockPostServiceMockRecorder) Lock(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockPostService)(nil).Lock), ctx, dto)
+}
+
+// Restore mocks base method.
+func (m *MockPostService) Restore(ctx context.Context, dto *service.RestorePost) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockPostServiceMockRecorder) Restore(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockPostService)(nil).Restore), ctx, dto)
 }
 
 // Save mocks base method.
-func (m *MockPostService) Save(post *domain.Post) (*domain.Post, error) {
+func (m *MockPostService) Save(ctx context.Context, dto *service.SavePost) (*domain.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", post)
+	ret := m.ctrl.Call(m, "Save", ctx, dto)
 	ret0, _ := ret[0].(*domain.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockPostServiceMockRecorder) Save(post interface{}) *gomock.Call {
+func (mr *MockPostServiceMockRecorder) Save(ctx, dto interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockPostService)(nil).Save), ctx, dto)
+}
+
+// Sticky mocks base method.
+func (m *MockPostService) Sticky(ctx context.Context, dto *service.StickyPost) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sticky", ctx, dto)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sticky indicates an expected call of Sticky.
+func (mr *MockPostServiceMockRecorder) Sticky(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockPostService)(nil).Save), post)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sticky", reflect.TypeOf((*MockPostService)(nil).Sticky), ctx, dto)
 }
 
 // UpdateMetrics mocks base method.
-func (m *MockPostService) UpdateMetrics(dto *service.UpdateMetricsPost) error {
+func (m *MockPostService) UpdateMetrics(ctx context.Context, dto *service.UpdateMetricsPost) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateMetrics", dto)
+	ret := m.ctrl.Call(m, "UpdateMetrics", ctx, dto)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateMetrics indicates an expected call of UpdateMetrics.
-func (mr *MockPostServiceMockRecorder) UpdateMetrics(dto interface{}) *gomock.Call {
+func (mr *MockPostServiceMockRecorder) UpdateMetrics(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetrics", reflect.TypeOf((*MockPostService)(nil).UpdateMetrics), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetrics", reflect.TypeOf((*MockPostService)(nil).UpdateMetrics), ctx, dto)
 }
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	domain "github.com/akrovv/redditclone/internal/domain"
@@ -36,33 +37,33 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 }
 
 // Get mocks base method.
-func (m *MockUserService) Get(dto *service.GetUser) (*domain.User, error) {
+func (m *MockUserService) Get(ctx context.Context, dto *service.GetUser) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", dto)
+	ret := m.ctrl.Call(m, "Get", ctx, dto)
 	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockUserServiceMockRecorder) Get(dto interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Get(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserService)(nil).Get), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserService)(nil).Get), ctx, dto)
 }
 
 // Save mocks base method.
-func (m *MockUserService) Save(dto *service.SaveUser) (*domain.User, error) {
+func (m *MockUserService) Save(ctx context.Context, dto *service.SaveUser) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Save", dto)
+	ret := m.ctrl.Call(m, "Save", ctx, dto)
 	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Save indicates an expected call of Save.
-func (mr *MockUserServiceMockRecorder) Save(dto interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) Save(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockUserService)(nil).Save), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockUserService)(nil).Save), ctx, dto)
 }
 
 // MockSessionService is a mock of SessionService interface.
@@ -89,31 +90,31 @@ func (m *MockSessionService) EXPECT() *MockSessionServiceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockSessionService) Create(dto *service.CreateSession) (*domain.Session, error) {
+func (m *MockSessionService) Create(ctx context.Context, dto *service.CreateSession) (*domain.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", dto)
+	ret := m.ctrl.Call(m, "Create", ctx, dto)
 	ret0, _ := ret[0].(*domain.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockSessionServiceMockRecorder) Create(dto interface{}) *gomock.Call {
+func (mr *MockSessionServiceMockRecorder) Create(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionService)(nil).Create), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionService)(nil).Create), ctx, dto)
 }
 
 // Get mocks base method.
-func (m *MockSessionService) Get(dto *service.GetSession) (*domain.User, error) {
+func (m *MockSessionService) Get(ctx context.Context, dto *service.GetSession) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", dto)
+	ret := m.ctrl.Call(m, "Get", ctx, dto)
 	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockSessionServiceMockRecorder) Get(dto interface{}) *gomock.Call {
+func (mr *MockSessionServiceMockRecorder) Get(ctx, dto interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionService)(nil).Get), dto)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSessionService)(nil).Get), ctx, dto)
 }
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	defaultModLogLimit = 25
+	maxModLogLimit     = 100
+)
+
+type modLogService struct {
+	storage ModLogStorage
+}
+
+func NewModLogService(storage ModLogStorage) *modLogService {
+	return &modLogService{storage: storage}
+}
+
+func (s modLogService) Get(ctx context.Context, dto *GetModLog) ([]*domain.ModLogEntry, error) {
+	ctx, span := tracer.Start(ctx, "modLogService.Get")
+	defer span.End()
+
+	limit := dto.Limit
+
+	if limit <= 0 {
+		limit = defaultModLogLimit
+	} else if limit > maxModLogLimit {
+		limit = maxModLogLimit
+	}
+
+	page := dto.Page
+
+	if page < 1 {
+		page = 1
+	}
+
+	filter := &domain.ModLogFilter{
+		Category: dto.Category,
+		Action:   dto.Action,
+		Actor:    dto.Moderator,
+		TargetID: dto.TargetID,
+		Offset:   (page - 1) * limit,
+		Limit:    limit,
+	}
+
+	return s.storage.Get(ctx, filter)
+}
+
+// modLogRecord describes a single privileged action before it is written to
+// the mod log.
+type modLogRecord struct {
+	actor      *domain.User
+	action     string
+	targetType string
+	targetID   string
+	category   string
+	reason     string
+	before     any
+	after      any
+}
+
+// writeModLog records an action that is already done. The stores can't
+// write the entry in one transaction with the action, so a failed entry
+// doesn't undo it or fail the request, which would invite a retry of
+// something that happened: the failure is logged and recorded on the span.
+func writeModLog(ctx context.Context, storage ModLogStorage, record *modLogRecord) {
+	if err := saveModLog(ctx, storage, record); err != nil {
+		trace.SpanFromContext(ctx).RecordError(err)
+		logger.FromContext(ctx, logger.NewNop()).Errorf("can't write mod log entry %s of %s %s: %v",
+			record.action, record.targetType, record.targetID, err)
+	}
+}
+
+// writeModeratorLog records the action only when the actor is not the
+// author, an author deleting or restoring their own content is no
+// moderation.
+func writeModeratorLog(ctx context.Context, storage ModLogStorage, author *domain.Profile, record *modLogRecord) {
+	if !isAuthor(record.actor, author) {
+		writeModLog(ctx, storage, record)
+	}
+}
+
+func saveModLog(ctx context.Context, storage ModLogStorage, record *modLogRecord) error {
+	entry := &domain.ModLogEntry{
+		Actor:      &domain.Profile{Username: record.actor.Username, ID: record.actor.ID},
+		Action:     record.action,
+		TargetType: record.targetType,
+		TargetID:   record.targetID,
+		Category:   record.category,
+		Reason:     record.reason,
+	}
+
+	var err error
+
+	if entry.Before, err = snapshot(record.before); err != nil {
+		return err
+	}
+
+	if entry.After, err = snapshot(record.after); err != nil {
+		return err
+	}
+
+	return storage.Save(ctx, entry)
+}
+
+func snapshot(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(v)
+}
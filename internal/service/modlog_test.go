@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/adapters/memory"
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// failingModLog loses every entry.
+type failingModLog struct {
+	ModLogStorage
+}
+
+func (failingModLog) Save(ctx context.Context, entry *domain.ModLogEntry) error {
+	return errors.New("mod log is down")
+}
+
+func TestModLogOfDelete(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	posts, modLog := memory.NewPostStorage(db), memory.NewModLogStorage(db)
+	policy := ContentPolicy{RestoreWindow: time.Hour}
+
+	author := &domain.User{Username: "akro", ID: "akro-id", Role: domain.RoleMember}
+	moderator := &domain.User{Username: "mod", ID: "mod-id", Role: domain.RoleModerator}
+
+	savePost := func() *domain.Post {
+		post, err := posts.Save(ctx, &domain.Post{Type: domain.PostTypeText, Title: "title", Text: "text", Category: "music",
+			Author: &domain.Profile{Username: author.Username, ID: author.ID}, Votes: []*domain.Vote{}, Comments: []*domain.Comment{}})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return post
+	}
+
+	own, moderated, unlogged := savePost(), savePost(), savePost()
+
+	for _, tc := range []struct {
+		name   string
+		modLog ModLogStorage
+		user   *domain.User
+		postID string
+	}{
+		{"author deletes and restores", modLog, author, own.ID},
+		{"moderator deletes", modLog, moderator, moderated.ID},
+		{"mod log is down", failingModLog{modLog}, moderator, unlogged.ID},
+	} {
+		s := NewPostService(posts, tc.modLog, policy, nil)
+
+		if err := s.Delete(ctx, &DeletePost{User: tc.user, PostID: tc.postID}); err != nil {
+			t.Errorf("%s: unexpected error: %s", tc.name, err)
+			continue
+		}
+
+		if _, err := posts.GetDeleted(ctx, tc.postID); err != nil {
+			t.Errorf("%s: expected the post to be deleted, got: %v", tc.name, err)
+		}
+	}
+
+	if err := NewPostService(posts, modLog, policy, nil).Restore(ctx, &RestorePost{User: author, PostID: own.ID}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	// Only the moderator is in the log
+	entries, err := modLog.Get(ctx, &domain.ModLogFilter{})
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(entries) != 1 || entries[0].Action != domain.ModActionDeletePost || entries[0].TargetID != moderated.ID {
+		t.Errorf("expected the delete of the moderator, got: %+v", entries)
+	}
+}
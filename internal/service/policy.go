@@ -0,0 +1,40 @@
+package service
+
+import (
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// maxStickiedPosts is how many posts a category can pin at once.
+const maxStickiedPosts = 2
+
+// ContentPolicy holds the time-based rules shared by the post and comment
+// services.
+type ContentPolicy struct {
+	RestoreWindow      time.Duration
+	ArchiveAfterMonths int
+}
+
+func (p ContentPolicy) archiveBefore() time.Time {
+	return time.Now().AddDate(0, -p.ArchiveAfterMonths, 0)
+}
+
+// isArchived does not rely on the archive job alone, so a post becomes
+// read-only as soon as it is old enough.
+func (p ContentPolicy) isArchived(post *domain.Post) bool {
+	return post.Archived || (p.ArchiveAfterMonths > 0 && post.Created.Before(p.archiveBefore()))
+}
+
+// checkWritable rejects new comments and votes on locked or archived posts.
+func (p ContentPolicy) checkWritable(post *domain.Post) error {
+	if post.Locked {
+		return domain.ErrPostLocked
+	}
+
+	if p.isArchived(post) {
+		return domain.ErrPostArchived
+	}
+
+	return nil
+}
@@ -1,39 +1,355 @@
 package service
 
-import "github.com/akrovv/redditclone/internal/domain"
+import (
+	"context"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/automod"
+	"github.com/akrovv/redditclone/internal/domain"
+)
 
 type postService struct {
 	storage PostStorage
+	modLog  ModLogStorage
+	policy  ContentPolicy
+	automod *automodService
+}
+
+func NewPostService(storage PostStorage, modLog ModLogStorage, policy ContentPolicy, automod *automodService) *postService {
+	return &postService{storage: storage, modLog: modLog, policy: policy, automod: automod}
+}
+
+// Save validates the post and runs the automod rules of the category before
+// storing it. Rejected posts are not stored, removed ones are stored hidden.
+func (s postService) Save(ctx context.Context, dto *SavePost) (*domain.Post, error) {
+	ctx, span := tracer.Start(ctx, "postService.Save")
+	defer span.End()
+
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	post := newPost(dto)
+
+	content := &automod.Content{
+		Target: automod.TargetPost,
+		Title:  post.Title,
+		Body:   post.Text,
+		URL:    post.URL,
+	}
+
+	matches, err := s.automod.check(ctx, post.Category, content, post.Author.Username)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if match := automod.First(matches, automod.ActionFlair); match != nil {
+		post.Flair = match.Flair
+	}
+
+	if automod.First(matches, automod.ActionRemove) != nil {
+		post.Removed = true
+	}
+
+	post, err = s.storage.Save(ctx, post)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return post, s.automod.apply(ctx, post, nil, matches)
+}
+
+// newPost fills in the fields a client can't set. The author upvotes the post
+// right away.
+func newPost(dto *SavePost) *domain.Post {
+	post := &domain.Post{
+		Type:             dto.Type,
+		Title:            dto.Title,
+		Category:         dto.Category,
+		Author:           &domain.Profile{Username: dto.User.Username, ID: dto.User.ID},
+		Score:            1,
+		Views:            1,
+		UpvotePercentage: 100,
+		Comments:         make([]*domain.Comment, 0),
+		Votes:            []*domain.Vote{{User: dto.User.ID, Vote: 1}},
+	}
+
+	if dto.Type == domain.PostTypeLink {
+		post.URL = dto.URL
+	} else {
+		post.Text = dto.Text
+	}
+
+	return post
+}
+
+func (s postService) Get(ctx context.Context) ([]*domain.Post, error) {
+	ctx, span := tracer.Start(ctx, "postService.Get")
+	defer span.End()
+
+	return s.storage.Get(ctx)
+}
+
+func (s postService) GetOne(ctx context.Context, dto *GetOnePost) (*domain.Post, error) {
+	ctx, span := tracer.Start(ctx, "postService.GetOne")
+	defer span.End()
+
+	return s.storage.GetOne(ctx, dto.PostID)
+}
+
+// GetBy puts the stickied posts of a category on top of its listing.
+func (s postService) GetBy(ctx context.Context, dto *GetByPost) ([]*domain.Post, error) {
+	ctx, span := tracer.Start(ctx, "postService.GetBy")
+	defer span.End()
+
+	posts, err := s.storage.GetBy(ctx, dto.Category, dto.Data, dto.SortField)
+
+	if err != nil || dto.Category != "category" {
+		return posts, err
+	}
+
+	stickied, err := s.storage.GetStickied(ctx, dto.Data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(stickied) == 0 {
+		return posts, nil
+	}
+
+	merged := make([]*domain.Post, 0, len(posts))
+	merged = append(merged, stickied...)
+
+	for _, post := range posts {
+		if !post.Stickied {
+			merged = append(merged, post)
+		}
+	}
+
+	return merged, nil
+}
+
+func (s postService) Delete(ctx context.Context, dto *DeletePost) error {
+	ctx, span := tracer.Start(ctx, "postService.Delete")
+	defer span.End()
+
+	post, err := s.storage.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	if !canDelete(dto.User, post.Author) {
+		return domain.ErrForbidden
+	}
+
+	deletedBy := &domain.Profile{Username: dto.User.Username, ID: dto.User.ID}
+	err = s.storage.Delete(ctx, dto.PostID, deletedBy)
+
+	if err != nil {
+		return err
+	}
+
+	deletedAt := time.Now()
+	deleted := *post
+	deleted.DeletedAt, deleted.DeletedBy = &deletedAt, deletedBy
+
+	writeModeratorLog(ctx, s.modLog, post.Author, &modLogRecord{
+		actor:      dto.User,
+		action:     domain.ModActionDeletePost,
+		targetType: domain.ReportTargetPost,
+		targetID:   post.ID,
+		category:   post.Category,
+		reason:     dto.Reason,
+		before:     post,
+		after:      &deleted,
+	})
+
+	return nil
 }
 
-func NewPostService(storage PostStorage) *postService {
-	return &postService{storage: storage}
+func (s postService) Restore(ctx context.Context, dto *RestorePost) error {
+	ctx, span := tracer.Start(ctx, "postService.Restore")
+	defer span.End()
+
+	post, err := s.storage.GetDeleted(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	if !canRestore(dto.User, post.Author) {
+		return domain.ErrForbidden
+	}
+
+	if time.Since(*post.DeletedAt) > s.policy.RestoreWindow {
+		return domain.ErrRestoreWindowClosed
+	}
+
+	err = s.storage.Restore(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	restored := *post
+	restored.DeletedAt, restored.DeletedBy = nil, nil
+
+	writeModeratorLog(ctx, s.modLog, post.Author, &modLogRecord{
+		actor:      dto.User,
+		action:     domain.ModActionRestorePost,
+		targetType: domain.ReportTargetPost,
+		targetID:   post.ID,
+		category:   post.Category,
+		before:     post,
+		after:      &restored,
+	})
+
+	return nil
 }
 
-func (s postService) Save(post *domain.Post) (*domain.Post, error) {
-	return s.storage.Save(post)
+// Purge hard-deletes posts whose restore window has passed.
+func (s postService) Purge(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "postService.Purge")
+	defer span.End()
+
+	return s.storage.Purge(ctx, time.Now().Add(-s.policy.RestoreWindow))
 }
 
-func (s postService) Get() ([]*domain.Post, error) {
-	return s.storage.Get()
+// RecomputeScores repairs scores that drifted from the votes, e.g. after a
+// manual fix in the database.
+func (s postService) RecomputeScores(ctx context.Context, dto *RecomputeScores) (int64, error) {
+	ctx, span := tracer.Start(ctx, "postService.RecomputeScores")
+	defer span.End()
+
+	return s.storage.RecomputeScores(ctx, dto.PostID)
 }
 
-func (s postService) GetOne(dto *GetOnePost) (*domain.Post, error) {
-	return s.storage.GetOne(dto.PostID)
+func (s postService) IncrViews(ctx context.Context, dto *IncrViewsPost) error {
+	ctx, span := tracer.Start(ctx, "postService.IncrViews")
+	defer span.End()
+
+	return s.storage.IncrViews(ctx, dto.PostID)
 }
 
-func (s postService) GetBy(dto *GetByPost) ([]*domain.Post, error) {
-	return s.storage.GetBy(dto.Category, dto.Data, dto.SortField)
+func (s postService) UpdateMetrics(ctx context.Context, dto *UpdateMetricsPost) error {
+	ctx, span := tracer.Start(ctx, "postService.UpdateMetrics")
+	defer span.End()
+
+	post, err := s.storage.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	if err = s.policy.checkWritable(post); err != nil {
+		return err
+	}
+
+	return s.storage.UpdateMetrics(ctx, dto.PostID, dto.Inc, dto.AuthorID)
 }
 
-func (s postService) Delete(dto *DeletePost) error {
-	return s.storage.Delete(dto.PostID)
+func (s postService) Lock(ctx context.Context, dto *LockPost) error {
+	ctx, span := tracer.Start(ctx, "postService.Lock")
+	defer span.End()
+
+	post, err := s.storage.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.storage.SetLocked(ctx, dto.PostID, dto.Locked)
+
+	if err != nil {
+		return err
+	}
+
+	action := domain.ModActionLockPost
+
+	if !dto.Locked {
+		action = domain.ModActionUnlockPost
+	}
+
+	changed := *post
+	changed.Locked = dto.Locked
+
+	writeModLog(ctx, s.modLog, &modLogRecord{
+		actor:      dto.User,
+		action:     action,
+		targetType: domain.ReportTargetPost,
+		targetID:   post.ID,
+		category:   post.Category,
+		reason:     dto.Reason,
+		before:     post,
+		after:      &changed,
+	})
+
+	return nil
 }
 
-func (s postService) IncrViews(dto *IncrViewsPost) error {
-	return s.storage.IncrViews(dto.PostID)
+func (s postService) Sticky(ctx context.Context, dto *StickyPost) error {
+	ctx, span := tracer.Start(ctx, "postService.Sticky")
+	defer span.End()
+
+	post, err := s.storage.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	if dto.Stickied && !post.Stickied {
+		stickied, err := s.storage.GetStickied(ctx, post.Category)
+
+		if err != nil {
+			return err
+		}
+
+		if len(stickied) >= maxStickiedPosts {
+			return domain.ErrStickyLimit
+		}
+	}
+
+	err = s.storage.SetStickied(ctx, dto.PostID, dto.Stickied)
+
+	if err != nil {
+		return err
+	}
+
+	action := domain.ModActionStickyPost
+
+	if !dto.Stickied {
+		action = domain.ModActionUnstickyPost
+	}
+
+	changed := *post
+	changed.Stickied = dto.Stickied
+
+	writeModLog(ctx, s.modLog, &modLogRecord{
+		actor:      dto.User,
+		action:     action,
+		targetType: domain.ReportTargetPost,
+		targetID:   post.ID,
+		category:   post.Category,
+		reason:     dto.Reason,
+		before:     post,
+		after:      &changed,
+	})
+
+	return nil
 }
 
-func (s postService) UpdateMetrics(dto *UpdateMetricsPost) error {
-	return s.storage.UpdateMetrics(dto.PostID, dto.Inc, dto.AuthorID)
+// Archive marks posts older than the configured age as read-only.
+func (s postService) Archive(ctx context.Context) (int64, error) {
+	ctx, span := tracer.Start(ctx, "postService.Archive")
+	defer span.End()
+
+	if s.policy.ArchiveAfterMonths <= 0 {
+		return 0, nil
+	}
+
+	return s.storage.Archive(ctx, s.policy.archiveBefore())
 }
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+type reportService struct {
+	storage  ReportStorage
+	posts    PostStorage
+	comments CommentStorage
+	modLog   ModLogStorage
+}
+
+func NewReportService(storage ReportStorage, posts PostStorage, comments CommentStorage, modLog ModLogStorage) *reportService {
+	return &reportService{storage: storage, posts: posts, comments: comments, modLog: modLog}
+}
+
+func (s reportService) Report(ctx context.Context, dto *ReportContent) (*domain.Report, error) {
+	ctx, span := tracer.Start(ctx, "reportService.Report")
+	defer span.End()
+
+	post, err := s.posts.GetOne(ctx, dto.PostID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Removed and deleted content is out of the queue for good, a report
+	// would bring it back.
+	if post.Removed || post.DeletedAt != nil {
+		return nil, domain.NotFound("post not found")
+	}
+
+	report := &domain.Report{
+		TargetType: domain.ReportTargetPost,
+		PostID:     post.ID,
+		Category:   post.Category,
+		Reason:     dto.Reason,
+		Reporter:   &domain.Profile{Username: dto.User.Username, ID: dto.User.ID},
+	}
+
+	if dto.CommentID != "" {
+		comment := findComment(post, dto.CommentID)
+
+		if comment == nil || comment.Removed || comment.DeletedAt != nil {
+			return nil, domain.NotFound("comment not found")
+		}
+
+		report.TargetType = domain.ReportTargetComment
+		report.CommentID = dto.CommentID
+	}
+
+	return s.storage.Save(ctx, report)
+}
+
+func (s reportService) Queue(ctx context.Context, dto *GetQueue) ([]*domain.Report, error) {
+	ctx, span := tracer.Start(ctx, "reportService.Queue")
+	defer span.End()
+
+	return s.storage.GetOpen(ctx, dto.Category)
+}
+
+func (s reportService) Approve(ctx context.Context, dto *ResolveReport) error {
+	ctx, span := tracer.Start(ctx, "reportService.Approve")
+	defer span.End()
+
+	report, err := s.storage.GetOne(ctx, dto.ReportID)
+
+	if err != nil {
+		return err
+	}
+
+	err = s.resolve(ctx, report, domain.ReportStatusApproved, dto.User)
+
+	if err != nil {
+		return err
+	}
+
+	writeModLog(ctx, s.modLog, &modLogRecord{
+		actor:      dto.User,
+		action:     domain.ModActionApproveReport,
+		targetType: report.TargetType,
+		targetID:   reportTargetID(report),
+		category:   report.Category,
+		reason:     dto.Reason,
+		before:     report,
+	})
+
+	return nil
+}
+
+func (s reportService) Remove(ctx context.Context, dto *ResolveReport) error {
+	ctx, span := tracer.Start(ctx, "reportService.Remove")
+	defer span.End()
+
+	report, err := s.storage.GetOne(ctx, dto.ReportID)
+
+	if err != nil {
+		return err
+	}
+
+	if report.Status != domain.ReportStatusOpen {
+		return domain.Conflict("report already resolved")
+	}
+
+	post, err := s.posts.GetOne(ctx, report.PostID)
+
+	if err != nil {
+		return err
+	}
+
+	record := &modLogRecord{
+		actor:      dto.User,
+		targetType: report.TargetType,
+		targetID:   reportTargetID(report),
+		category:   report.Category,
+		reason:     dto.Reason,
+	}
+
+	if report.TargetType == domain.ReportTargetComment {
+		comment := findComment(post, report.CommentID)
+
+		if comment == nil {
+			return domain.NotFound("comment not found")
+		}
+
+		err = s.comments.SetRemoved(ctx, report.PostID, report.CommentID, true)
+		removed := *comment
+		removed.Removed = true
+
+		record.action = domain.ModActionRemoveComment
+		record.before, record.after = comment, &removed
+	} else {
+		err = s.posts.SetRemoved(ctx, report.PostID, true)
+		removed := *post
+		removed.Removed = true
+
+		record.action = domain.ModActionRemovePost
+		record.before, record.after = post, &removed
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = s.resolve(ctx, report, domain.ReportStatusRemoved, dto.User)
+
+	if err != nil {
+		return err
+	}
+
+	writeModLog(ctx, s.modLog, record)
+
+	return nil
+}
+
+func (s reportService) resolve(ctx context.Context, report *domain.Report, status string, moderator *domain.User) error {
+	if report.Status != domain.ReportStatusOpen {
+		return domain.Conflict("report already resolved")
+	}
+
+	profile := &domain.Profile{Username: moderator.Username, ID: moderator.ID}
+	return s.storage.Resolve(ctx, report.PostID, report.CommentID, status, profile)
+}
+
+func reportTargetID(report *domain.Report) string {
+	if report.TargetType == domain.ReportTargetComment {
+		return report.CommentID
+	}
+
+	return report.PostID
+}
+
+func findComment(post *domain.Post, commentID string) *domain.Comment {
+	for _, comment := range post.Comments {
+		if comment.ID == commentID {
+			return comment
+		}
+	}
+
+	return nil
+}
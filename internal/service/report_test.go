@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/adapters/memory"
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+func TestReportRemovedContent(t *testing.T) {
+	ctx := context.Background()
+	db := memory.New()
+	posts, comments, reports := memory.NewPostStorage(db), memory.NewCommentStorage(db), memory.NewReportStorage(db)
+	s := NewReportService(reports, posts, comments, memory.NewModLogStorage(db))
+
+	author := &domain.Profile{Username: "akro", ID: "akro-id"}
+	reporter := &domain.User{Username: "reporter", ID: "reporter-id", Role: domain.RoleMember}
+
+	savePost := func(title string) *domain.Post {
+		post, err := posts.Save(ctx, &domain.Post{Type: domain.PostTypeText, Title: title, Text: "text", Category: "music", Author: author,
+			Votes: []*domain.Vote{}, Comments: []*domain.Comment{}})
+
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return post
+	}
+
+	addComment := func(postID string) *domain.Comment {
+		comment, err := comments.Add(ctx, author, "body", postID)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return comment
+	}
+
+	post, removedPost, deletedPost := savePost("open"), savePost("removed"), savePost("deleted")
+	comment, removedComment, deletedComment := addComment(post.ID), addComment(post.ID), addComment(post.ID)
+
+	for _, err := range []error{
+		posts.SetRemoved(ctx, removedPost.ID, true),
+		posts.Delete(ctx, deletedPost.ID, author),
+		comments.SetRemoved(ctx, post.ID, removedComment.ID, true),
+		comments.Delete(ctx, post.ID, deletedComment.ID, author),
+	} {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		postID    string
+		commentID string
+		err       error
+	}{
+		{"open post", post.ID, "", nil},
+		{"open comment", post.ID, comment.ID, nil},
+		{"removed post", removedPost.ID, "", domain.ErrNotFound},
+		{"deleted post", deletedPost.ID, "", domain.ErrNotFound},
+		{"removed comment", post.ID, removedComment.ID, domain.ErrNotFound},
+		{"deleted comment", post.ID, deletedComment.ID, domain.ErrNotFound},
+	} {
+		_, err := s.Report(ctx, &ReportContent{User: reporter, PostID: tc.postID, CommentID: tc.commentID, Reason: "spam"})
+
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected %v, got: %v", tc.name, tc.err, err)
+		}
+	}
+
+	// Only the open content is in the queue
+	queue, err := s.Queue(ctx, &GetQueue{Category: "music"})
+
+	if err != nil || len(queue) != 2 {
+		t.Errorf("expected two reports, got: %v, err: %v", queue, err)
+	}
+}
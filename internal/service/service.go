@@ -1,10 +1,24 @@
 package service
 
 import (
+	"io"
+
 	"github.com/akrovv/redditclone/internal/domain"
 )
 
 // Post
+
+// SavePost holds the only post fields a client may set. The rest are filled
+// in by the service.
+type SavePost struct {
+	User     *domain.User `json:"-"`
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	URL      string       `json:"url"`
+	Category string       `json:"category"`
+	Text     string       `json:"text"`
+}
+
 type GetOnePost struct {
 	PostID string
 }
@@ -26,6 +40,33 @@ type IncrViewsPost struct {
 }
 
 type DeletePost struct {
+	User   *domain.User
+	PostID string
+	Reason string
+}
+
+type RestorePost struct {
+	User   *domain.User
+	PostID string
+}
+
+type LockPost struct {
+	User   *domain.User
+	PostID string
+	Locked bool
+	Reason string
+}
+
+type StickyPost struct {
+	User     *domain.User
+	PostID   string
+	Stickied bool
+	Reason   string
+}
+
+// RecomputeScores recounts the post with PostID, or every post if it is
+// empty.
+type RecomputeScores struct {
 	PostID string
 }
 
@@ -42,16 +83,42 @@ type SaveUser struct {
 	ID       string `json:"id"`
 }
 
+type ResetPassword struct {
+	Username string
+	Password string
+}
+
+// SetUserActive bans a user with Active false and unbans with true. User
+// is the admin the mod log records.
+type SetUserActive struct {
+	User     *domain.User
+	Username string
+	Active   bool
+}
+
 // Session
 type CreateSession struct {
 	ID       string
 	Username string
+	Role     string
 }
 
 type GetSession struct {
 	Key string
 }
 
+type ListSessions struct {
+	Username string
+}
+
+type RevokeSession struct {
+	Key string
+}
+
+type RevokeSessions struct {
+	Username string
+}
+
 // Comment
 type AddComment struct {
 	User   *domain.User
@@ -60,6 +127,83 @@ type AddComment struct {
 }
 
 type DeleteComment struct {
+	User      *domain.User
+	PostID    string
+	CommentID string
+	Reason    string
+}
+
+type RestoreComment struct {
+	User      *domain.User
+	PostID    string
+	CommentID string
+}
+
+// Report
+type ReportContent struct {
+	User      *domain.User
 	PostID    string
 	CommentID string
+	Reason    string
+}
+
+type GetQueue struct {
+	Category string
+}
+
+type ResolveReport struct {
+	User     *domain.User
+	ReportID string
+	Reason   string
+}
+
+// ModLog
+type GetModLog struct {
+	Category  string
+	Action    string
+	Moderator string
+	TargetID  string
+	Page      int
+	Limit     int
+}
+
+// Automod
+type GetAutomod struct {
+	Category string
+}
+
+type SaveAutomod struct {
+	User     *domain.User
+	Category string
+	Source   string
+}
+
+type ValidateAutomod struct {
+	Source string
+}
+
+type DryRunAutomod struct {
+	Category string
+	Source   string
+}
+
+// Archive
+type ExportArchive struct {
+	Writer io.Writer
+}
+
+type ImportArchive struct {
+	Reader io.Reader
+}
+
+// ImportResult counts what an import created and what was there already.
+// PasswordResets are the created users whose password hash this storage
+// can't take over, they log in after a reset only.
+type ImportResult struct {
+	Users          int `json:"users"`
+	UsersSkipped   int `json:"usersSkipped"`
+	PasswordResets int `json:"passwordResets"`
+	Posts          int `json:"posts"`
+	PostsSkipped   int `json:"postsSkipped"`
+	PostsRemapped  int `json:"postsRemapped"`
 }
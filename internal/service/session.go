@@ -1,6 +1,9 @@
 package service
 
 import (
+	"context"
+	"errors"
+
 	"github.com/akrovv/redditclone/internal/domain"
 )
 
@@ -12,10 +15,61 @@ func NewSessionService(storage SessionStorage) *sessionService {
 	return &sessionService{storage: storage}
 }
 
-func (s sessionService) Create(dto *CreateSession) (*domain.Session, error) {
-	return s.storage.Create(dto.ID, dto.Username)
+func (s sessionService) Create(ctx context.Context, dto *CreateSession) (*domain.Session, error) {
+	ctx, span := tracer.Start(ctx, "sessionService.Create")
+	defer span.End()
+
+	return s.storage.Create(ctx, dto.ID, dto.Username, dto.Role)
+}
+
+func (s sessionService) Get(ctx context.Context, dto *GetSession) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "sessionService.Get")
+	defer span.End()
+
+	return s.storage.Get(ctx, dto.Key)
+}
+
+func (s sessionService) List(ctx context.Context, dto *ListSessions) ([]*domain.SessionInfo, error) {
+	ctx, span := tracer.Start(ctx, "sessionService.List")
+	defer span.End()
+
+	return s.storage.List(ctx, dto.Username)
 }
 
-func (s sessionService) Get(dto *GetSession) (*domain.User, error) {
-	return s.storage.Get(dto.Key)
+func (s sessionService) Revoke(ctx context.Context, dto *RevokeSession) error {
+	ctx, span := tracer.Start(ctx, "sessionService.Revoke")
+	defer span.End()
+
+	return s.storage.Delete(ctx, dto.Key)
+}
+
+// RevokeAll logs the user out everywhere and reports how many sessions were
+// revoked. A session that expires in between is not an error.
+func (s sessionService) RevokeAll(ctx context.Context, dto *RevokeSessions) (int, error) {
+	ctx, span := tracer.Start(ctx, "sessionService.RevokeAll")
+	defer span.End()
+
+	sessions, err := s.storage.List(ctx, dto.Username)
+
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+
+	for _, session := range sessions {
+		err = s.storage.Delete(ctx, session.ID)
+
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return revoked, err
+		}
+
+		revoked++
+	}
+
+	return revoked, nil
 }
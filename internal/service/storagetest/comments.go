@@ -0,0 +1,209 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+)
+
+// TestCommentStorage checks a CommentStorage. The comments belong to posts,
+// so newStorages returns the post storage they share the data with.
+func TestCommentStorage(t *testing.T, newStorages func(t *testing.T, clock *Clock) (service.PostStorage, service.CommentStorage)) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, posts service.PostStorage, s service.CommentStorage, clock *Clock)
+	}{
+		{"Add", testCommentAdd},
+		{"NotFound", testCommentNotFound},
+		{"DeleteRestore", testCommentDeleteRestore},
+		{"SetRemoved", testCommentSetRemoved},
+		{"Purge", testCommentPurge},
+		{"Import", testCommentImport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock()
+			posts, comments := newStorages(t, clock)
+			tt.test(t, posts, comments, clock)
+		})
+	}
+}
+
+func addComment(t *testing.T, s service.CommentStorage, postID, body string) *domain.Comment {
+	t.Helper()
+
+	comment, err := s.Add(context.Background(), &domain.Profile{Username: "commenter", ID: "commenter-id"}, body, postID)
+	expectErr(t, "Add", err, nil)
+
+	return comment
+}
+
+func commentBodies(t *testing.T, posts service.PostStorage, postID string) []string {
+	t.Helper()
+
+	post, err := posts.GetOne(context.Background(), postID)
+	expectErr(t, "GetOne", err, nil)
+
+	bodies := make([]string, 0, len(post.Comments))
+
+	for _, comment := range post.Comments {
+		bodies = append(bodies, comment.Body)
+	}
+
+	return bodies
+}
+
+func testCommentAdd(t *testing.T, posts service.PostStorage, s service.CommentStorage, clock *Clock) {
+	post := savePost(t, posts, NewPost("title", "music", "akro"))
+	first := addComment(t, s, post.ID, "first")
+	clock.Advance(time.Second)
+	second := addComment(t, s, post.ID, "second")
+
+	if first.ID == "" || first.ID == second.ID || first.Author.Username != "commenter" || first.Created.IsZero() {
+		t.Fatalf("unexpected comments: %+v, %+v", first, second)
+	}
+
+	// The comments keep the order they were written in
+	if bodies := commentBodies(t, posts, post.ID); len(bodies) != 2 || bodies[0] != "first" || bodies[1] != "second" {
+		t.Fatalf("expected first,second, got: %v", bodies)
+	}
+}
+
+func testCommentNotFound(t *testing.T, posts service.PostStorage, s service.CommentStorage, clock *Clock) {
+	ctx := context.Background()
+	post := savePost(t, posts, NewPost("title", "music", "akro"))
+	comment := addComment(t, s, post.ID, "body")
+	author := &domain.Profile{Username: "akro", ID: "akro-id"}
+
+	_, err := s.Add(ctx, author, "body", "unknown")
+	expectErr(t, "Add to an unknown post", err, domain.ErrNotFound)
+
+	_, err = s.GetDeleted(ctx, post.ID, comment.ID)
+	expectErr(t, "GetDeleted of a comment that isn't deleted", err, domain.ErrNotFound)
+
+	expectErr(t, "Delete", s.Delete(ctx, post.ID, "unknown", author), domain.ErrNotFound)
+	expectErr(t, "Delete on an unknown post", s.Delete(ctx, "unknown", comment.ID, author), domain.ErrNotFound)
+	expectErr(t, "Restore", s.Restore(ctx, post.ID, "unknown"), domain.ErrNotFound)
+	expectErr(t, "SetRemoved", s.SetRemoved(ctx, post.ID, "unknown", true), domain.ErrNotFound)
+}
+
+func testCommentDeleteRestore(t *testing.T, posts service.PostStorage, s service.CommentStorage, clock *Clock) {
+	ctx := context.Background()
+	post := savePost(t, posts, NewPost("title", "music", "akro"))
+	kept := addComment(t, s, post.ID, "kept")
+	comment := addComment(t, s, post.ID, "deleted")
+	moderator := &domain.Profile{Username: "moderator", ID: "moderator-id"}
+
+	expectErr(t, "Restore of a comment that isn't deleted", s.Restore(ctx, post.ID, comment.ID), domain.ErrNotFound)
+	expectErr(t, "Delete", s.Delete(ctx, post.ID, comment.ID, moderator), nil)
+	expectErr(t, "second Delete", s.Delete(ctx, post.ID, comment.ID, moderator), domain.ErrNotFound)
+
+	if bodies := commentBodies(t, posts, post.ID); len(bodies) != 1 || bodies[0] != "kept" {
+		t.Fatalf("expected the deleted comment to be hidden, got: %v", bodies)
+	}
+
+	deleted, err := s.GetDeleted(ctx, post.ID, comment.ID)
+	expectErr(t, "GetDeleted", err, nil)
+
+	if deleted.DeletedAt == nil || deleted.DeletedBy == nil || deleted.DeletedBy.Username != "moderator" {
+		t.Fatalf("expected deletedAt and deletedBy, got: %v, %v", deleted.DeletedAt, deleted.DeletedBy)
+	}
+
+	expectErr(t, "Restore", s.Restore(ctx, post.ID, comment.ID), nil)
+	expectErr(t, "second Restore", s.Restore(ctx, post.ID, comment.ID), domain.ErrNotFound)
+
+	if bodies := commentBodies(t, posts, post.ID); len(bodies) != 2 {
+		t.Fatalf("expected the restored comment back, got: %v", bodies)
+	}
+
+	_, err = s.GetDeleted(ctx, post.ID, kept.ID)
+	expectErr(t, "GetDeleted of a kept comment", err, domain.ErrNotFound)
+}
+
+func testCommentSetRemoved(t *testing.T, posts service.PostStorage, s service.CommentStorage, clock *Clock) {
+	ctx := context.Background()
+	post := savePost(t, posts, NewPost("title", "music", "akro"))
+	comment := addComment(t, s, post.ID, "removed")
+
+	expectErr(t, "SetRemoved", s.SetRemoved(ctx, post.ID, comment.ID, true), nil)
+
+	if bodies := commentBodies(t, posts, post.ID); len(bodies) != 0 {
+		t.Fatalf("expected the removed comment to be hidden, got: %v", bodies)
+	}
+
+	expectErr(t, "SetRemoved", s.SetRemoved(ctx, post.ID, comment.ID, false), nil)
+
+	if bodies := commentBodies(t, posts, post.ID); len(bodies) != 1 {
+		t.Fatalf("expected the comment back, got: %v", bodies)
+	}
+}
+
+func testCommentPurge(t *testing.T, posts service.PostStorage, s service.CommentStorage, clock *Clock) {
+	ctx := context.Background()
+	author := &domain.Profile{Username: "akro", ID: "akro-id"}
+	first := savePost(t, posts, NewPost("first", "music", "akro"))
+	second := savePost(t, posts, NewPost("second", "music", "akro"))
+	untouched := savePost(t, posts, NewPost("untouched", "music", "akro"))
+
+	purged := []*domain.Comment{addComment(t, s, first.ID, "a"), addComment(t, s, first.ID, "b")}
+	other := addComment(t, s, second.ID, "c")
+	recent := addComment(t, s, untouched.ID, "d")
+
+	expectErr(t, "Delete", s.Delete(ctx, first.ID, purged[0].ID, author), nil)
+	expectErr(t, "Delete", s.Delete(ctx, first.ID, purged[1].ID, author), nil)
+	expectErr(t, "Delete", s.Delete(ctx, second.ID, other.ID, author), nil)
+	deletedBefore := clock.Now().Add(time.Second)
+	clock.Advance(time.Minute)
+	expectErr(t, "Delete", s.Delete(ctx, untouched.ID, recent.ID, author), nil)
+
+	n, err := s.Purge(ctx, deletedBefore)
+	expectErr(t, "Purge", err, nil)
+
+	if n != 2 {
+		t.Fatalf("expected 2 touched posts, got: %d", n)
+	}
+
+	_, err = s.GetDeleted(ctx, first.ID, purged[0].ID)
+	expectErr(t, "GetDeleted of a purged comment", err, domain.ErrNotFound)
+
+	_, err = s.GetDeleted(ctx, untouched.ID, recent.ID)
+	expectErr(t, "GetDeleted of a recently deleted comment", err, nil)
+}
+
+func testCommentImport(t *testing.T, posts service.PostStorage, s service.CommentStorage, clock *Clock) {
+	ctx := context.Background()
+	post := savePost(t, posts, NewPost("title", "music", "akro"))
+	added := addComment(t, s, post.ID, "added")
+	author := &domain.Profile{Username: "akro", ID: "akro-id"}
+	created := clock.Now().Add(-time.Hour)
+	deletedAt := clock.Now().Add(-time.Minute)
+
+	imported := &domain.Comment{ID: "imported-comment", Author: author, Body: "imported", Created: created}
+	expectErr(t, "Import", s.Import(ctx, post.ID, imported), nil)
+	expectErr(t, "Import of an existing ID", s.Import(ctx, post.ID, imported), domain.ErrAlreadyExists)
+	expectErr(t, "Import of an added comment", s.Import(ctx, post.ID, added), domain.ErrAlreadyExists)
+	expectErr(t, "Import to an unknown post", s.Import(ctx, "unknown", &domain.Comment{ID: "other", Author: author, Body: "body",
+		Created: created}), domain.ErrNotFound)
+
+	deleted := &domain.Comment{ID: "deleted-comment", Author: author, Body: "gone", Created: created, DeletedAt: &deletedAt, DeletedBy: author}
+	expectErr(t, "Import of a deleted comment", s.Import(ctx, post.ID, deleted), nil)
+
+	got, err := posts.GetOne(ctx, post.ID)
+	expectErr(t, "GetOne", err, nil)
+
+	// The comment keeps its ID and created and comes after the added one
+	if len(got.Comments) != 2 || got.Comments[1].ID != "imported-comment" || !got.Comments[1].Created.Equal(created) {
+		t.Fatalf("expected the added and the imported comment, got: %+v", got.Comments)
+	}
+
+	gone, err := s.GetDeleted(ctx, post.ID, deleted.ID)
+	expectErr(t, "GetDeleted of the imported deleted comment", err, nil)
+
+	if !gone.DeletedAt.Equal(deletedAt) || gone.DeletedBy == nil || gone.DeletedBy.Username != "akro" {
+		t.Fatalf("expected the deletion as it was imported, got: %v, %v", gone.DeletedAt, gone.DeletedBy)
+	}
+}
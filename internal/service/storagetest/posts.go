@@ -0,0 +1,472 @@
+package storagetest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+)
+
+// TestPostStorage checks a PostStorage. newStorage is called for every
+// check and must return an empty storage.
+func TestPostStorage(t *testing.T, newStorage func(t *testing.T, clock *Clock) service.PostStorage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s service.PostStorage, clock *Clock)
+	}{
+		{"SaveAndGetOne", testPostSaveAndGetOne},
+		{"NotFound", testPostNotFound},
+		{"GetOrder", testPostGetOrder},
+		{"GetBy", testPostGetBy},
+		{"GetStickied", testPostGetStickied},
+		{"Votes", testPostVotes},
+		{"IncrViews", testPostIncrViews},
+		{"DeleteRestore", testPostDeleteRestore},
+		{"Purge", testPostPurge},
+		{"Archive", testPostArchive},
+		{"RecomputeScores", testPostRecomputeScores},
+		{"WalkAndImport", testPostWalkAndImport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock()
+			tt.test(t, newStorage(t, clock), clock)
+		})
+	}
+}
+
+// NewPost returns a post the way the post service builds it: one upvote of
+// its author.
+func NewPost(title, category, username string) *domain.Post {
+	return &domain.Post{
+		Score:            1,
+		Views:            1,
+		Type:             domain.PostTypeText,
+		Title:            title,
+		Author:           &domain.Profile{Username: username, ID: username + "-id"},
+		Category:         category,
+		Text:             "text of " + title,
+		Votes:            []*domain.Vote{{User: username + "-id", Vote: 1}},
+		Comments:         []*domain.Comment{},
+		UpvotePercentage: 100,
+	}
+}
+
+func savePost(t *testing.T, s service.PostStorage, post *domain.Post) *domain.Post {
+	t.Helper()
+
+	saved, err := s.Save(context.Background(), post)
+	expectErr(t, "Save", err, nil)
+
+	return saved
+}
+
+func postTitles(posts []*domain.Post) string {
+	titles := make([]string, 0, len(posts))
+
+	for _, post := range posts {
+		titles = append(titles, post.Title)
+	}
+
+	return strings.Join(titles, ",")
+}
+
+func testPostSaveAndGetOne(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	saved := savePost(t, s, NewPost("first", "music", "akro"))
+
+	if saved.ID == "" || saved.Created.IsZero() {
+		t.Fatalf("expected id and created to be set, got: %q, %v", saved.ID, saved.Created)
+	}
+
+	other := savePost(t, s, NewPost("second", "music", "akro"))
+
+	if other.ID == saved.ID {
+		t.Fatalf("expected unique ids, got %q twice", saved.ID)
+	}
+
+	got, err := s.GetOne(ctx, saved.ID)
+	expectErr(t, "GetOne", err, nil)
+
+	if got.Title != "first" || got.Category != "music" || got.Author.Username != "akro" || got.Score != 1 ||
+		got.Views != 1 || got.UpvotePercentage != 100 || len(got.Votes) != 1 || !got.Created.Equal(saved.Created) {
+		t.Fatalf("stored post differs: %+v", got)
+	}
+}
+
+func testPostNotFound(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	saved := savePost(t, s, NewPost("title", "music", "akro"))
+
+	_, err := s.GetOne(ctx, "unknown")
+	expectErr(t, "GetOne", err, domain.ErrNotFound)
+
+	_, err = s.GetDeleted(ctx, saved.ID)
+	expectErr(t, "GetDeleted of a post that isn't deleted", err, domain.ErrNotFound)
+
+	expectErr(t, "IncrViews", s.IncrViews(ctx, "unknown"), domain.ErrNotFound)
+	expectErr(t, "UpdateMetrics", s.UpdateMetrics(ctx, "unknown", 1, "user"), domain.ErrNotFound)
+	expectErr(t, "Delete", s.Delete(ctx, "unknown", saved.Author), domain.ErrNotFound)
+	expectErr(t, "Restore", s.Restore(ctx, "unknown"), domain.ErrNotFound)
+	expectErr(t, "SetRemoved", s.SetRemoved(ctx, "unknown", true), domain.ErrNotFound)
+	expectErr(t, "SetLocked", s.SetLocked(ctx, "unknown", true), domain.ErrNotFound)
+	expectErr(t, "SetStickied", s.SetStickied(ctx, "unknown", true), domain.ErrNotFound)
+}
+
+// testPostGetOrder checks that Get sorts by score, descending, and leaves
+// out removed and deleted posts.
+func testPostGetOrder(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+
+	posts, err := s.Get(ctx)
+	expectErr(t, "Get", err, nil)
+
+	if posts == nil || len(posts) != 0 {
+		t.Fatalf("expected an empty list, got: %v", posts)
+	}
+
+	low := savePost(t, s, NewPost("low", "music", "akro"))
+	high := savePost(t, s, NewPost("high", "news", "akro"))
+	removed := savePost(t, s, NewPost("removed", "music", "akro"))
+	deleted := savePost(t, s, NewPost("deleted", "music", "akro"))
+
+	expectErr(t, "UpdateMetrics", s.UpdateMetrics(ctx, high.ID, 1, "voter"), nil)
+	expectErr(t, "UpdateMetrics", s.UpdateMetrics(ctx, low.ID, -1, "voter"), nil)
+	expectErr(t, "SetRemoved", s.SetRemoved(ctx, removed.ID, true), nil)
+	expectErr(t, "Delete", s.Delete(ctx, deleted.ID, deleted.Author), nil)
+
+	posts, err = s.Get(ctx)
+	expectErr(t, "Get", err, nil)
+
+	if titles := postTitles(posts); titles != "high,low" {
+		t.Fatalf("expected high,low, got: %s", titles)
+	}
+
+	// A removed post is still there by its id, for the moderators
+	_, err = s.GetOne(ctx, removed.ID)
+	expectErr(t, "GetOne of a removed post", err, nil)
+}
+
+func testPostGetBy(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+
+	for _, post := range []*domain.Post{
+		NewPost("old", "music", "akro"),
+		NewPost("other author", "music", "other"),
+		NewPost("other category", "news", "akro"),
+		NewPost("new", "music", "akro"),
+	} {
+		savePost(t, s, post)
+		clock.Advance(time.Minute)
+	}
+
+	posts, err := s.GetBy(ctx, "category", "music", "created")
+	expectErr(t, "GetBy", err, nil)
+
+	if titles := postTitles(posts); titles != "new,other author,old" {
+		t.Fatalf("expected music posts newest first, got: %s", titles)
+	}
+
+	posts, err = s.GetBy(ctx, "author.username", "akro", "created")
+	expectErr(t, "GetBy", err, nil)
+
+	if titles := postTitles(posts); titles != "new,other category,old" {
+		t.Fatalf("expected posts of akro newest first, got: %s", titles)
+	}
+
+	expectErr(t, "UpdateMetrics", s.UpdateMetrics(ctx, posts[2].ID, 1, "voter"), nil)
+
+	posts, err = s.GetBy(ctx, "author.username", "akro", "score")
+	expectErr(t, "GetBy", err, nil)
+
+	if len(posts) != 3 || posts[0].Title != "old" || posts[0].Score != 2 {
+		t.Fatalf("expected the upvoted post first, got: %s", postTitles(posts))
+	}
+
+	posts, err = s.GetBy(ctx, "category", "videos", "score")
+	expectErr(t, "GetBy", err, nil)
+
+	if posts == nil || len(posts) != 0 {
+		t.Fatalf("expected an empty list, got: %v", posts)
+	}
+}
+
+func testPostGetStickied(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	old := savePost(t, s, NewPost("old", "music", "akro"))
+	clock.Advance(time.Minute)
+	savePost(t, s, NewPost("plain", "music", "akro"))
+	clock.Advance(time.Minute)
+	recent := savePost(t, s, NewPost("new", "music", "akro"))
+	news := savePost(t, s, NewPost("news", "news", "akro"))
+
+	for _, post := range []*domain.Post{old, recent, news} {
+		expectErr(t, "SetStickied", s.SetStickied(ctx, post.ID, true), nil)
+	}
+
+	posts, err := s.GetStickied(ctx, "music")
+	expectErr(t, "GetStickied", err, nil)
+
+	if titles := postTitles(posts); titles != "new,old" {
+		t.Fatalf("expected new,old, got: %s", titles)
+	}
+
+	expectErr(t, "SetStickied", s.SetStickied(ctx, old.ID, false), nil)
+
+	posts, err = s.GetStickied(ctx, "music")
+	expectErr(t, "GetStickied", err, nil)
+
+	if titles := postTitles(posts); titles != "new" {
+		t.Fatalf("expected new, got: %s", titles)
+	}
+}
+
+// testPostVotes checks that a vote counts once per user, whatever the
+// client repeats.
+func testPostVotes(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	post := savePost(t, s, NewPost("title", "music", "akro"))
+
+	expectScore := func(what string, score int, percent uint, votes int) {
+		t.Helper()
+
+		got, err := s.GetOne(ctx, post.ID)
+		expectErr(t, "GetOne", err, nil)
+
+		if got.Score != score || got.UpvotePercentage != percent || len(got.Votes) != votes {
+			t.Fatalf("%s: expected score %d, %d%% and %d votes, got: %d, %d%% and %d votes",
+				what, score, percent, votes, got.Score, got.UpvotePercentage, len(got.Votes))
+		}
+	}
+
+	expectErr(t, "downvote", s.UpdateMetrics(ctx, post.ID, -1, "voter"), nil)
+	expectScore("downvote", 0, 50, 2)
+
+	expectErr(t, "same downvote", s.UpdateMetrics(ctx, post.ID, -1, "voter"), domain.ErrConflict)
+	expectScore("same downvote", 0, 50, 2)
+
+	expectErr(t, "upvote instead", s.UpdateMetrics(ctx, post.ID, 1, "voter"), nil)
+	expectScore("upvote instead", 2, 100, 2)
+
+	expectErr(t, "unvote", s.UpdateMetrics(ctx, post.ID, 0, "voter"), nil)
+	expectScore("unvote", 1, 100, 1)
+
+	expectErr(t, "unvote without a vote", s.UpdateMetrics(ctx, post.ID, 0, "voter"), domain.ErrConflict)
+	expectScore("unvote without a vote", 1, 100, 1)
+
+	expectErr(t, "author takes the vote back", s.UpdateMetrics(ctx, post.ID, 0, post.Author.ID), nil)
+	expectScore("author takes the vote back", 0, 0, 0)
+
+	expectErr(t, "unknown vote", s.UpdateMetrics(ctx, post.ID, 2, "voter"), domain.ErrValidation)
+}
+
+func testPostIncrViews(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	post := savePost(t, s, NewPost("title", "music", "akro"))
+
+	for i := 0; i < 3; i++ {
+		expectErr(t, "IncrViews", s.IncrViews(ctx, post.ID), nil)
+	}
+
+	got, err := s.GetOne(ctx, post.ID)
+	expectErr(t, "GetOne", err, nil)
+
+	if got.Views != 4 {
+		t.Fatalf("expected 4 views, got: %d", got.Views)
+	}
+}
+
+func testPostDeleteRestore(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	post := savePost(t, s, NewPost("title", "music", "akro"))
+	moderator := &domain.Profile{Username: "moderator", ID: "moderator-id"}
+
+	expectErr(t, "Restore of a post that isn't deleted", s.Restore(ctx, post.ID), domain.ErrNotFound)
+	expectErr(t, "Delete", s.Delete(ctx, post.ID, moderator), nil)
+	expectErr(t, "second Delete", s.Delete(ctx, post.ID, moderator), domain.ErrNotFound)
+
+	_, err := s.GetOne(ctx, post.ID)
+	expectErr(t, "GetOne of a deleted post", err, domain.ErrNotFound)
+
+	deleted, err := s.GetDeleted(ctx, post.ID)
+	expectErr(t, "GetDeleted", err, nil)
+
+	if deleted.DeletedAt == nil || deleted.DeletedBy == nil || deleted.DeletedBy.Username != "moderator" {
+		t.Fatalf("expected deletedAt and deletedBy, got: %v, %v", deleted.DeletedAt, deleted.DeletedBy)
+	}
+
+	expectErr(t, "Restore", s.Restore(ctx, post.ID), nil)
+	expectErr(t, "second Restore", s.Restore(ctx, post.ID), domain.ErrNotFound)
+
+	restored, err := s.GetOne(ctx, post.ID)
+	expectErr(t, "GetOne of a restored post", err, nil)
+
+	if restored.DeletedAt != nil || restored.DeletedBy != nil {
+		t.Fatalf("expected deletedAt and deletedBy to be cleared, got: %v, %v", restored.DeletedAt, restored.DeletedBy)
+	}
+}
+
+func testPostPurge(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	kept := savePost(t, s, NewPost("kept", "music", "akro"))
+	purged := savePost(t, s, NewPost("purged", "music", "akro"))
+	recent := savePost(t, s, NewPost("recent", "music", "akro"))
+
+	expectErr(t, "Delete", s.Delete(ctx, purged.ID, purged.Author), nil)
+	deletedBefore := clock.Now().Add(time.Second)
+	clock.Advance(time.Minute)
+	expectErr(t, "Delete", s.Delete(ctx, recent.ID, recent.Author), nil)
+
+	n, err := s.Purge(ctx, deletedBefore)
+	expectErr(t, "Purge", err, nil)
+
+	if n != 1 {
+		t.Fatalf("expected 1 purged post, got: %d", n)
+	}
+
+	_, err = s.GetDeleted(ctx, purged.ID)
+	expectErr(t, "GetDeleted of a purged post", err, domain.ErrNotFound)
+
+	_, err = s.GetDeleted(ctx, recent.ID)
+	expectErr(t, "GetDeleted of a recently deleted post", err, nil)
+
+	_, err = s.GetOne(ctx, kept.ID)
+	expectErr(t, "GetOne of a kept post", err, nil)
+}
+
+func testPostArchive(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+	old := savePost(t, s, NewPost("old", "music", "akro"))
+	clock.Advance(time.Hour)
+	createdBefore := clock.Now()
+	recent := savePost(t, s, NewPost("new", "music", "akro"))
+
+	n, err := s.Archive(ctx, createdBefore)
+	expectErr(t, "Archive", err, nil)
+
+	if n != 1 {
+		t.Fatalf("expected 1 archived post, got: %d", n)
+	}
+
+	n, err = s.Archive(ctx, createdBefore)
+	expectErr(t, "second Archive", err, nil)
+
+	if n != 0 {
+		t.Fatalf("expected archived posts to be skipped, got: %d", n)
+	}
+
+	got, _ := s.GetOne(ctx, old.ID)
+
+	if !got.Archived {
+		t.Fatalf("expected the old post to be archived")
+	}
+
+	got, _ = s.GetOne(ctx, recent.ID)
+
+	if got.Archived {
+		t.Fatalf("expected the new post not to be archived")
+	}
+}
+
+func testPostRecomputeScores(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+
+	// The stored score doesn't match the one vote of the author
+	wrong := NewPost("wrong", "music", "akro")
+	wrong.Score, wrong.UpvotePercentage = 5, 50
+	wrong = savePost(t, s, wrong)
+	right := savePost(t, s, NewPost("right", "music", "akro"))
+
+	_, err := s.RecomputeScores(ctx, "unknown")
+	expectErr(t, "RecomputeScores of an unknown post", err, domain.ErrNotFound)
+
+	n, err := s.RecomputeScores(ctx, right.ID)
+	expectErr(t, "RecomputeScores", err, nil)
+
+	if n != 0 {
+		t.Fatalf("expected the right post to stay, got %d changed", n)
+	}
+
+	n, err = s.RecomputeScores(ctx, "")
+	expectErr(t, "RecomputeScores of every post", err, nil)
+
+	if n != 1 {
+		t.Fatalf("expected 1 changed post, got: %d", n)
+	}
+
+	got, err := s.GetOne(ctx, wrong.ID)
+	expectErr(t, "GetOne", err, nil)
+
+	if got.Score != 1 || got.UpvotePercentage != 100 {
+		t.Fatalf("expected score 1 and 100%%, got: %d and %d%%", got.Score, got.UpvotePercentage)
+	}
+
+	n, err = s.RecomputeScores(ctx, wrong.ID)
+	expectErr(t, "second RecomputeScores", err, nil)
+
+	if n != 0 {
+		t.Fatalf("expected nothing to change, got: %d", n)
+	}
+}
+
+func testPostWalkAndImport(t *testing.T, s service.PostStorage, clock *Clock) {
+	ctx := context.Background()
+
+	first := savePost(t, s, NewPost("first", "music", "akro"))
+	clock.Advance(time.Minute)
+	second := savePost(t, s, NewPost("second", "music", "akro"))
+	expectErr(t, "Delete", s.Delete(ctx, second.ID, second.Author), nil)
+	expectErr(t, "SetRemoved", s.SetRemoved(ctx, first.ID, true), nil)
+
+	var posts []*domain.Post
+
+	err := s.Walk(ctx, func(post *domain.Post) error {
+		posts = append(posts, post)
+		return nil
+	})
+	expectErr(t, "Walk", err, nil)
+
+	if postTitles(posts) != "first,second" || !posts[0].Removed || posts[1].DeletedAt == nil || len(posts[0].Votes) != 1 {
+		t.Fatalf("expected the removed first and the deleted second, got: %s", postTitles(posts))
+	}
+
+	expectErr(t, "Import of an existing ID", s.Import(ctx, posts[0]), domain.ErrAlreadyExists)
+
+	deletedAt := clock.Now().Add(-time.Hour)
+	imported := NewPost("imported", "news", "other")
+	imported.ID = "imported-id"
+	imported.Created = clock.Now().Add(-2 * time.Hour)
+	imported.Locked = true
+	imported.Comments = []*domain.Comment{
+		{ID: "imported-comment", Author: &domain.Profile{Username: "akro", ID: "akro-id"}, Body: "body", Created: imported.Created},
+		{ID: "deleted-comment", Author: &domain.Profile{Username: "akro", ID: "akro-id"}, Body: "gone", Created: imported.Created,
+			DeletedAt: &deletedAt, DeletedBy: &domain.Profile{Username: "akro", ID: "akro-id"}},
+	}
+	expectErr(t, "Import", s.Import(ctx, imported), nil)
+
+	got, err := s.GetOne(ctx, "imported-id")
+	expectErr(t, "GetOne of the imported post", err, nil)
+
+	if got.Title != "imported" || !got.Locked || got.Score != 1 || len(got.Votes) != 1 || !got.Created.Equal(imported.Created) {
+		t.Fatalf("expected the post as it was imported, got: %+v", got)
+	}
+
+	if len(got.Comments) != 1 || got.Comments[0].ID != "imported-comment" {
+		t.Fatalf("expected the visible comment only, got: %+v", got.Comments)
+	}
+
+	posts = nil
+	err = s.Walk(ctx, func(post *domain.Post) error {
+		posts = append(posts, post)
+		return nil
+	})
+	expectErr(t, "Walk", err, nil)
+
+	if postTitles(posts) != "first,second,imported" || len(posts[2].Comments) != 2 {
+		t.Fatalf("expected the imported post last with both comments, got: %s", postTitles(posts))
+	}
+}
@@ -0,0 +1,124 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+)
+
+// TestSessionStorage checks a SessionStorage. ttl is how long the storage
+// keeps a session.
+func TestSessionStorage(t *testing.T, newStorage func(t *testing.T, clock *Clock) service.SessionStorage, ttl time.Duration) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s service.SessionStorage, clock *Clock, ttl time.Duration)
+	}{
+		{"CreateAndGet", testSessionCreateAndGet},
+		{"NotFound", testSessionNotFound},
+		{"Expiry", testSessionExpiry},
+		{"ListAndDelete", testSessionListAndDelete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock()
+			tt.test(t, newStorage(t, clock), clock, ttl)
+		})
+	}
+}
+
+func createSession(t *testing.T, s service.SessionStorage, username string) *domain.Session {
+	t.Helper()
+
+	session, err := s.Create(context.Background(), username+"-id", username, domain.RoleMember)
+	expectErr(t, "Create", err, nil)
+
+	return session
+}
+
+func testSessionCreateAndGet(t *testing.T, s service.SessionStorage, clock *Clock, ttl time.Duration) {
+	first := createSession(t, s, "akro")
+	second := createSession(t, s, "other")
+
+	if first.ID == "" || first.ID == second.ID {
+		t.Fatalf("expected unique session ids, got: %q and %q", first.ID, second.ID)
+	}
+
+	user, err := s.Get(context.Background(), first.ID)
+	expectErr(t, "Get", err, nil)
+
+	if user.ID != "akro-id" || user.Username != "akro" || user.Role != domain.RoleMember || user.Password != "" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
+
+func testSessionNotFound(t *testing.T, s service.SessionStorage, clock *Clock, ttl time.Duration) {
+	_, err := s.Get(context.Background(), "unknown")
+	expectErr(t, "Get", err, domain.ErrNotFound)
+}
+
+func testSessionExpiry(t *testing.T, s service.SessionStorage, clock *Clock, ttl time.Duration) {
+	ctx := context.Background()
+	session := createSession(t, s, "akro")
+
+	clock.Advance(ttl - time.Second)
+
+	_, err := s.Get(ctx, session.ID)
+	expectErr(t, "Get before the TTL", err, nil)
+
+	clock.Advance(time.Second)
+
+	_, err = s.Get(ctx, session.ID)
+	expectErr(t, "Get after the TTL", err, domain.ErrNotFound)
+
+	// A new login starts a new TTL
+	session = createSession(t, s, "akro")
+	clock.Advance(ttl / 2)
+
+	_, err = s.Get(ctx, session.ID)
+	expectErr(t, "Get of a new session", err, nil)
+}
+
+func testSessionListAndDelete(t *testing.T, s service.SessionStorage, clock *Clock, ttl time.Duration) {
+	ctx := context.Background()
+	session := createSession(t, s, "akro")
+	createSession(t, s, "other")
+
+	sessions, err := s.List(ctx, "akro")
+	expectErr(t, "List", err, nil)
+
+	if len(sessions) != 1 || sessions[0].ID != session.ID || sessions[0].User.Username != "akro" {
+		t.Fatalf("expected the session of akro, got: %v", sessions)
+	}
+
+	if expires := sessions[0].Expires.Sub(clock.Now()); expires <= 0 || expires > ttl {
+		t.Fatalf("expected the session to expire within %v, got: %v", ttl, expires)
+	}
+
+	expectErr(t, "Delete", s.Delete(ctx, session.ID), nil)
+	expectErr(t, "second Delete", s.Delete(ctx, session.ID), domain.ErrNotFound)
+
+	_, err = s.Get(ctx, session.ID)
+	expectErr(t, "Get of a deleted session", err, domain.ErrNotFound)
+
+	sessions, err = s.List(ctx, "akro")
+	expectErr(t, "List", err, nil)
+
+	if sessions == nil || len(sessions) != 0 {
+		t.Fatalf("expected an empty list, got: %v", sessions)
+	}
+
+	// Expired sessions aren't listed
+	createSession(t, s, "akro")
+	clock.Advance(ttl)
+
+	sessions, err = s.List(ctx, "akro")
+	expectErr(t, "List", err, nil)
+
+	if len(sessions) != 0 {
+		t.Fatalf("expected the expired session to be left out, got: %v", sessions)
+	}
+}
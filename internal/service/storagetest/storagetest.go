@@ -0,0 +1,59 @@
+// Package storagetest is the conformance suite of the storage interfaces of
+// the service package. Every implementation runs the same checks from its
+// own tests, so the adapters can't drift apart in ordering, not found
+// errors, vote handling or expiry:
+//
+//	func TestPostStorage(t *testing.T) {
+//		storagetest.TestPostStorage(t, func(t *testing.T, clock *storagetest.Clock) service.PostStorage {
+//			return NewPostStorage(NewWithClock(clock.Now))
+//		})
+//	}
+//
+// The implementation must take its time from the clock, the suite moves it
+// forward to check creation order, purging and TTLs without sleeping.
+package storagetest
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Clock is a fake time source. It starts at the real time, so the moments
+// the suite passes to the storages stay realistic.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewClock() *Clock {
+	return &Clock{now: time.Now().Truncate(time.Millisecond)}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// expectErr fails the test unless err is of the given kind, nil expects no
+// error.
+func expectErr(t *testing.T, what string, err, kind error) {
+	t.Helper()
+
+	switch {
+	case kind == nil && err != nil:
+		t.Fatalf("%s: unexpected error: %v", what, err)
+	case kind != nil && !errors.Is(err, kind):
+		t.Fatalf("%s: expected %v, got: %v", what, kind, err)
+	}
+}
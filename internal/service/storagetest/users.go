@@ -0,0 +1,210 @@
+package storagetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/service"
+)
+
+// TestUserStorage checks a UserStorage.
+func TestUserStorage(t *testing.T, newStorage func(t *testing.T, clock *Clock) service.UserStorage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s service.UserStorage, clock *Clock)
+	}{
+		{"SaveAndGet", testUserSaveAndGet},
+		{"NotFound", testUserNotFound},
+		{"AlreadyExists", testUserAlreadyExists},
+		{"GetCreated", testUserGetCreated},
+		{"SetPassword", testUserSetPassword},
+		{"SetActive", testUserSetActive},
+		{"WalkAndImport", testUserWalkAndImport},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewClock()
+			tt.test(t, newStorage(t, clock), clock)
+		})
+	}
+}
+
+func testUserSaveAndGet(t *testing.T, s service.UserStorage, clock *Clock) {
+	ctx := context.Background()
+
+	saved, err := s.Save(ctx, "akro", "password")
+	expectErr(t, "Save", err, nil)
+
+	if saved.ID == "" || saved.Username != "akro" || saved.Role != domain.RoleMember {
+		t.Fatalf("unexpected user: %+v", saved)
+	}
+
+	got, err := s.Get(ctx, "akro", "password")
+	expectErr(t, "Get", err, nil)
+
+	if got.ID != saved.ID || got.Username != "akro" || got.Role != domain.RoleMember {
+		t.Fatalf("expected %+v, got: %+v", saved, got)
+	}
+}
+
+func testUserNotFound(t *testing.T, s service.UserStorage, clock *Clock) {
+	ctx := context.Background()
+
+	_, err := s.Save(ctx, "akro", "password")
+	expectErr(t, "Save", err, nil)
+
+	_, err = s.Get(ctx, "akro", "wrong password")
+	expectErr(t, "Get with a wrong password", err, domain.ErrNotFound)
+
+	_, err = s.Get(ctx, "unknown", "password")
+	expectErr(t, "Get of an unknown user", err, domain.ErrNotFound)
+
+	_, err = s.GetCreated(ctx, "unknown")
+	expectErr(t, "GetCreated of an unknown user", err, domain.ErrNotFound)
+}
+
+func testUserAlreadyExists(t *testing.T, s service.UserStorage, clock *Clock) {
+	ctx := context.Background()
+
+	_, err := s.Save(ctx, "akro", "password")
+	expectErr(t, "Save", err, nil)
+
+	_, err = s.Save(ctx, "akro", "another password")
+	expectErr(t, "second Save", err, domain.ErrAlreadyExists)
+
+	// The first password still works
+	_, err = s.Get(ctx, "akro", "password")
+	expectErr(t, "Get", err, nil)
+}
+
+func testUserGetCreated(t *testing.T, s service.UserStorage, clock *Clock) {
+	ctx := context.Background()
+	registered := clock.Now()
+
+	_, err := s.Save(ctx, "akro", "password")
+	expectErr(t, "Save", err, nil)
+
+	clock.Advance(time.Hour)
+
+	created, err := s.GetCreated(ctx, "akro")
+	expectErr(t, "GetCreated", err, nil)
+
+	if created.Sub(registered).Abs() > time.Second {
+		t.Fatalf("expected the user to be created at %v, got: %v", registered, created)
+	}
+}
+
+func testUserSetPassword(t *testing.T, s service.UserStorage, clock *Clock) {
+	ctx := context.Background()
+
+	_, err := s.Save(ctx, "akro", "password")
+	expectErr(t, "Save", err, nil)
+
+	expectErr(t, "SetPassword", s.SetPassword(ctx, "akro", "new password"), nil)
+	expectErr(t, "SetPassword of an unknown user", s.SetPassword(ctx, "unknown", "password"), domain.ErrNotFound)
+
+	_, err = s.Get(ctx, "akro", "password")
+	expectErr(t, "Get with the old password", err, domain.ErrNotFound)
+
+	_, err = s.Get(ctx, "akro", "new password")
+	expectErr(t, "Get with the new password", err, nil)
+}
+
+func testUserSetActive(t *testing.T, s service.UserStorage, clock *Clock) {
+	ctx := context.Background()
+
+	_, err := s.Save(ctx, "akro", "password")
+	expectErr(t, "Save", err, nil)
+
+	expectErr(t, "ban", s.SetActive(ctx, "akro", false), nil)
+
+	_, err = s.Get(ctx, "akro", "password")
+	expectErr(t, "Get of a banned user", err, domain.ErrNotFound)
+
+	expectErr(t, "unban", s.SetActive(ctx, "akro", true), nil)
+
+	_, err = s.Get(ctx, "akro", "password")
+	expectErr(t, "Get of an unbanned user", err, nil)
+
+	expectErr(t, "SetActive of an unknown user", s.SetActive(ctx, "unknown", false), domain.ErrNotFound)
+}
+
+func testUserWalkAndImport(t *testing.T, s service.UserStorage, clock *Clock) {
+	ctx := context.Background()
+
+	_, err := s.Save(ctx, "first", "password")
+	expectErr(t, "Save", err, nil)
+	clock.Advance(time.Minute)
+	_, err = s.Save(ctx, "second", "password")
+	expectErr(t, "Save", err, nil)
+	expectErr(t, "ban", s.SetActive(ctx, "second", false), nil)
+
+	var records []*domain.UserRecord
+
+	err = s.Walk(ctx, func(user *domain.UserRecord) error {
+		records = append(records, user)
+		return nil
+	})
+	expectErr(t, "Walk", err, nil)
+
+	if len(records) != 2 || records[0].Username != "first" || records[1].Username != "second" || records[1].Active {
+		t.Fatalf("expected first and the banned second, got: %+v", records)
+	}
+
+	if records[0].Password == nil || records[0].Password.Scheme != s.PasswordScheme() {
+		t.Fatalf("expected a %s password hash, got: %+v", s.PasswordScheme(), records[0].Password)
+	}
+
+	// Taken username, the user stays as it is
+	id, created, err := s.Import(ctx, &domain.UserRecord{Username: "first", Role: domain.RoleAdmin, Active: true})
+	expectErr(t, "Import of a taken username", err, nil)
+
+	if created || id != records[0].ID {
+		t.Fatalf("expected the existing %s, got: %s, created %v", records[0].ID, id, created)
+	}
+
+	got, err := s.Get(ctx, "first", "password")
+	expectErr(t, "Get after the import", err, nil)
+
+	if got.Role != domain.RoleMember {
+		t.Fatalf("expected the role to stay, got: %s", got.Role)
+	}
+
+	// A new user with the hash of this storage logs in with the password
+	imported := *records[0]
+	imported.Username = "third"
+	imported.Role = domain.RoleModerator
+	imported.Created = clock.Now().Add(-time.Hour)
+
+	id, created, err = s.Import(ctx, &imported)
+	expectErr(t, "Import", err, nil)
+
+	if !created || id == "" {
+		t.Fatalf("expected the user to be created, got: %s, created %v", id, created)
+	}
+
+	got, err = s.Get(ctx, "third", "password")
+	expectErr(t, "Get of the imported user", err, nil)
+
+	if got.ID != id || got.Role != domain.RoleModerator {
+		t.Fatalf("expected %s, a moderator, got: %+v", id, got)
+	}
+
+	registered, err := s.GetCreated(ctx, "third")
+	expectErr(t, "GetCreated of the imported user", err, nil)
+
+	if !registered.Equal(imported.Created) {
+		t.Fatalf("expected the user to be created at %v, got: %v", imported.Created, registered)
+	}
+
+	// A hash of another scheme works for no password
+	_, _, err = s.Import(ctx, &domain.UserRecord{Username: "fourth", Role: domain.RoleMember, Active: true,
+		Password: &domain.PasswordHash{Scheme: "unknown", Hash: records[0].Password.Hash}})
+	expectErr(t, "Import with another scheme", err, nil)
+
+	_, err = s.Get(ctx, "fourth", "password")
+	expectErr(t, "Get with a password of another scheme", err, domain.ErrNotFound)
+}
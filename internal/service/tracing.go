@@ -0,0 +1,6 @@
+package service
+
+import "go.opentelemetry.io/otel"
+
+// tracer starts a child span of the request for every exported service call.
+var tracer = otel.Tracer("github.com/akrovv/redditclone/internal/service")
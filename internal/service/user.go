@@ -1,21 +1,71 @@
 package service
 
 import (
+	"context"
 	"github.com/akrovv/redditclone/internal/domain"
 )
 
 type userService struct {
 	storage UserStorage
+	modLog  ModLogStorage
 }
 
-func NewUserService(storage UserStorage) *userService {
-	return &userService{storage: storage}
+func NewUserService(storage UserStorage, modLog ModLogStorage) *userService {
+	return &userService{storage: storage, modLog: modLog}
 }
 
-func (s userService) Get(dto *GetUser) (*domain.User, error) {
-	return s.storage.Get(dto.Username, dto.Password)
+func (s userService) Get(ctx context.Context, dto *GetUser) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "userService.Get")
+	defer span.End()
+
+	return s.storage.Get(ctx, dto.Username, dto.Password)
+}
+
+func (s userService) Save(ctx context.Context, dto *SaveUser) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "userService.Save")
+	defer span.End()
+
+	if err := dto.Validate(); err != nil {
+		return nil, err
+	}
+
+	return s.storage.Save(ctx, dto.Username, dto.Password)
+}
+
+func (s userService) ResetPassword(ctx context.Context, dto *ResetPassword) error {
+	ctx, span := tracer.Start(ctx, "userService.ResetPassword")
+	defer span.End()
+
+	if err := dto.Validate(); err != nil {
+		return err
+	}
+
+	return s.storage.SetPassword(ctx, dto.Username, dto.Password)
 }
 
-func (s userService) Save(dto *SaveUser) (*domain.User, error) {
-	return s.storage.Save(dto.Username, dto.Password)
+// SetActive bans or unbans the user and records it in the mod log. A banned
+// user can't log in, the sessions already open are revoked by the session
+// service.
+func (s userService) SetActive(ctx context.Context, dto *SetUserActive) error {
+	ctx, span := tracer.Start(ctx, "userService.SetActive")
+	defer span.End()
+
+	if err := s.storage.SetActive(ctx, dto.Username, dto.Active); err != nil {
+		return err
+	}
+
+	action := domain.ModActionBanUser
+
+	if dto.Active {
+		action = domain.ModActionUnbanUser
+	}
+
+	writeModLog(ctx, s.modLog, &modLogRecord{
+		actor:      dto.User,
+		action:     action,
+		targetType: "user",
+		targetID:   dto.Username,
+	})
+
+	return nil
 }
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/akrovv/redditclone/internal/domain"
+	"github.com/akrovv/redditclone/internal/validation"
+)
+
+const (
+	maxTitleLength   = 300
+	maxTextLength    = 10000
+	maxURLLength     = 2048
+	maxCommentLength = 5000
+
+	minUsernameLength = 3
+	maxUsernameLength = 32
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Validate checks the lengths, the type and that a link post has a URL and a
+// text post has a text. The field the type doesn't use is ignored: the
+// frontend form sends both when the user switches the type.
+func (dto *SavePost) Validate() error {
+	v := validation.New()
+
+	v.Required("title", dto.Title)
+	v.Length("title", dto.Title, 1, maxTitleLength)
+	v.OneOf("category", dto.Category, domain.Categories...)
+	v.OneOf("type", dto.Type, domain.PostTypeLink, domain.PostTypeText)
+
+	switch dto.Type {
+	case domain.PostTypeLink:
+		v.Required("url", dto.URL)
+		v.Length("url", dto.URL, 1, maxURLLength)
+		v.URL("url", dto.URL)
+	case domain.PostTypeText:
+		v.Required("text", dto.Text)
+		v.Length("text", dto.Text, 1, maxTextLength)
+	}
+
+	return v.Err()
+}
+
+// Validate checks the length of the comment. The frontend sends it as
+// "comment".
+func (dto *AddComment) Validate() error {
+	v := validation.New()
+
+	v.Required("comment", dto.Body)
+	v.Length("comment", dto.Body, 1, maxCommentLength)
+
+	return v.Err()
+}
+
+// Validate checks the username and password rules of a new user.
+func (dto *SaveUser) Validate() error {
+	v := validation.New()
+
+	v.Required("username", dto.Username)
+	v.Length("username", dto.Username, minUsernameLength, maxUsernameLength)
+	v.Check(dto.Username == "" || usernamePattern.MatchString(dto.Username),
+		"username", dto.Username, "may contain only latin letters, digits, _ and -")
+
+	checkPassword(v, dto.Password)
+
+	return v.Err()
+}
+
+// Validate checks the new password by the rules of a new user.
+func (dto *ResetPassword) Validate() error {
+	v := validation.New()
+
+	v.Required("username", dto.Username)
+	checkPassword(v, dto.Password)
+
+	return v.Err()
+}
+
+func checkPassword(v *validation.Validator, password string) {
+	// The password is never echoed back.
+	v.Check(password != "", "password", nil, "is required")
+	v.Check(password == "" || len([]rune(password)) >= minPasswordLength,
+		"password", nil, fmt.Sprintf("must be at least %d characters long", minPasswordLength))
+	v.Check(len([]rune(password)) <= maxPasswordLength,
+		"password", nil, fmt.Sprintf("must be at most %d characters long", maxPasswordLength))
+}
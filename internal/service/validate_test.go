@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// params returns the names of the invalid fields.
+func params(err error) []string {
+	validationErr := &domain.ValidationError{}
+
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+
+	names := make([]string, 0, len(validationErr.Errors))
+
+	for _, fieldErr := range validationErr.Errors {
+		names = append(names, fieldErr.Param)
+	}
+
+	return names
+}
+
+func TestSavePostValidate(t *testing.T) {
+	// OK, text post
+	dto := &SavePost{Type: "text", Title: "akro", Category: "music", Text: "my text in post"}
+
+	if err := dto.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// OK, link post
+	dto = &SavePost{Type: "link", Title: "akro", Category: "news", URL: "https://example.com"}
+
+	if err := dto.Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// Link post with a bad URL, the text is ignored
+	dto = &SavePost{Type: "link", Title: "akro", Category: "news", URL: "javascript:alert(1)", Text: "text"}
+
+	if got := strings.Join(params(dto.Validate()), ","); got != "url" {
+		t.Errorf("expected url, got: %s", got)
+		return
+	}
+
+	// Text post without a text, the URL is ignored
+	dto = &SavePost{Type: "text", Title: "akro", Category: "news", URL: "https://example.com"}
+
+	if got := strings.Join(params(dto.Validate()), ","); got != "text" {
+		t.Errorf("expected text, got: %s", got)
+		return
+	}
+
+	// Unknown type and category, long title
+	dto = &SavePost{Type: "image", Title: strings.Repeat("a", maxTitleLength+1), Category: "cats"}
+
+	if got := strings.Join(params(dto.Validate()), ","); got != "title,category,type" {
+		t.Errorf("expected title,category,type, got: %s", got)
+		return
+	}
+}
+
+func TestAddCommentValidate(t *testing.T) {
+	// OK
+	if err := (&AddComment{Body: "nice"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// Empty comment
+	if err := (&AddComment{Body: " "}).Validate(); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected validation error, got: %v", err)
+		return
+	}
+
+	// Long comment
+	if err := (&AddComment{Body: strings.Repeat("a", maxCommentLength+1)}).Validate(); !errors.Is(err, domain.ErrValidation) {
+		t.Errorf("expected validation error, got: %v", err)
+		return
+	}
+}
+
+func TestSaveUserValidate(t *testing.T) {
+	// OK
+	if err := (&SaveUser{Username: "akro_1", Password: "akroakroakro"}).Validate(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	// Bad username and short password
+	err := (&SaveUser{Username: "ak ro", Password: "akro"}).Validate()
+
+	if got := strings.Join(params(err), ","); got != "username,password" {
+		t.Errorf("expected username,password, got: %s", got)
+		return
+	}
+
+	// The password isn't echoed back
+	validationErr := &domain.ValidationError{}
+
+	if errors.As(err, &validationErr) && validationErr.Errors[1].Value != nil {
+		t.Errorf("expected no password value, got: %v", validationErr.Errors[1].Value)
+		return
+	}
+
+	// Missing fields
+	err = (&SaveUser{}).Validate()
+
+	if got := strings.Join(params(err), ","); got != "username,password" {
+		t.Errorf("expected username,password, got: %s", got)
+		return
+	}
+}
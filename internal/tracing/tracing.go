@@ -0,0 +1,84 @@
+// Package tracing sets up OpenTelemetry: the tracer provider, the exporter
+// and W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
+type Config struct {
+	ServiceName string
+	Exporter    string
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint string
+	Insecure bool
+}
+
+// Setup installs the global tracer provider and propagator. The returned
+// function flushes the spans left in the batch and must be called on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		// The global provider stays a no-op, incoming trace context is
+		// still propagated to the outgoing calls.
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, expected one of: otlp, stdout, none", cfg.Exporter)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("can't create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	provider := NewProvider(cfg.ServiceName, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// NewProvider builds a tracer provider for the service. Tests pass
+// sdktrace.WithSyncer with an in-memory exporter.
+func NewProvider(serviceName string, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))
+
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, opts...)...)
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	// OK
+	shutdown, err := Setup(context.TODO(), Config{ServiceName: "redditclone", Exporter: ExporterNone})
+
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	if err = shutdown(context.TODO()); err != nil {
+		t.Errorf("unexpected error: %s", err)
+		return
+	}
+
+	carrier := propagation.MapCarrier{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	ctx := otel.GetTextMapPropagator().Extract(context.TODO(), carrier)
+
+	out := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, out)
+
+	if out["traceparent"] != carrier["traceparent"] {
+		t.Errorf("expected traceparent to be propagated, got: %v", out)
+		return
+	}
+
+	// Unknown exporter
+	if _, err = Setup(context.TODO(), Config{Exporter: "jaeger"}); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider("redditclone", sdktrace.WithSyncer(exporter))
+
+	_, span := provider.Tracer("test").Start(context.TODO(), "span")
+	span.End()
+
+	spans := exporter.GetSpans()
+
+	if len(spans) != 1 {
+		t.Errorf("expected 1 span, got: %d", len(spans))
+		return
+	}
+
+	if name, _ := spans[0].Resource.Set().Value("service.name"); name.AsString() != "redditclone" {
+		t.Errorf("expected service name redditclone, got: %s", name.AsString())
+	}
+}
@@ -0,0 +1,157 @@
+// Package validation checks client input. A Validator collects every invalid
+// field of a request, so the client gets all of them in one answer.
+package validation
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/akrovv/redditclone/internal/domain"
+)
+
+// LocationBody marks fields that came in the request body.
+const LocationBody = "body"
+
+type Validator struct {
+	errs []domain.FieldError
+}
+
+func New() *Validator {
+	return &Validator{}
+}
+
+// Add records an invalid field. Only the first problem of a field is kept.
+func (v *Validator) Add(param string, value any, msg string) {
+	for _, fieldErr := range v.errs {
+		if fieldErr.Param == param {
+			return
+		}
+	}
+
+	v.errs = append(v.errs, domain.FieldError{Location: LocationBody, Param: param, Value: value, Msg: msg})
+}
+
+// Check records the field as invalid unless ok is true.
+func (v *Validator) Check(ok bool, param string, value any, msg string) {
+	if !ok {
+		v.Add(param, value, msg)
+	}
+}
+
+// Required checks that the trimmed value isn't empty.
+func (v *Validator) Required(param, value string) {
+	v.Check(strings.TrimSpace(value) != "", param, value, "is required")
+}
+
+// Length checks the number of characters of the value. Empty values are
+// left to Required.
+func (v *Validator) Length(param, value string, min, max int) {
+	if value == "" {
+		return
+	}
+
+	n := utf8.RuneCountInString(value)
+
+	switch {
+	case n < min:
+		v.Add(param, value, fmt.Sprintf("must be at least %d characters long", min))
+	case n > max:
+		v.Add(param, value, fmt.Sprintf("must be at most %d characters long", max))
+	}
+}
+
+// OneOf checks that the value is one of the allowed ones.
+func (v *Validator) OneOf(param, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+
+	v.Add(param, value, "must be one of "+strings.Join(allowed, ", "))
+}
+
+// URL checks that the value is an absolute http or https URL with a valid
+// host and without credentials.
+func (v *Validator) URL(param, value string) {
+	if value == "" {
+		return
+	}
+
+	if msg := checkURL(value); msg != "" {
+		v.Add(param, value, msg)
+	}
+}
+
+// Err returns a *domain.ValidationError with the recorded fields or nil.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+
+	return &domain.ValidationError{Errors: v.errs}
+}
+
+func checkURL(value string) string {
+	u, err := url.Parse(value)
+
+	if err != nil {
+		return "must be a valid URL"
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "must be an http or https URL"
+	}
+
+	if u.User != nil {
+		return "must not contain credentials"
+	}
+
+	if !validHost(u.Hostname()) {
+		return "must have a valid host"
+	}
+
+	return ""
+}
+
+// validHost accepts IP addresses and domain names of at least two labels.
+func validHost(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+
+	if len(host) > 253 {
+		return false
+	}
+
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+
+	if len(labels) < 2 {
+		return false
+	}
+
+	for _, label := range labels {
+		if !validLabel(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func validLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+
+	for _, c := range label {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+
+	return true
+}
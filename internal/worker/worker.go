@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/akrovv/redditclone/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+var tracer = otel.Tracer("github.com/akrovv/redditclone/internal/worker")
+
+// Run calls job every interval until ctx is cancelled. A failing run is
+// logged and does not stop the loop. job gets ctx so a run in progress is
+// cancelled together with the loop.
+func Run(ctx context.Context, l logger.Logger, name string, interval time.Duration, job func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runOnce(ctx, l, name, job)
+		}
+	}
+}
+
+// runOnce traces every run as its own root span, so the storage calls of a
+// run and its log line share a trace ID.
+func runOnce(ctx context.Context, l logger.Logger, name string, job func(ctx context.Context) error) {
+	ctx, span := tracer.Start(ctx, "worker."+name)
+	defer span.End()
+
+	if err := job(ctx); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.WithTrace(ctx, l).Errorf("worker %s failed: %v", name, err)
+	}
+}
+
+// Group tracks running workers so shutdown can wait for the job in progress
+// to finish before the storages are closed.
+type Group struct {
+	wg sync.WaitGroup
+}
+
+// Go starts Run in its own goroutine.
+func (g *Group) Go(ctx context.Context, logger logger.Logger, name string, interval time.Duration, job func(ctx context.Context) error) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+		Run(ctx, logger, name, interval, job)
+	}()
+}
+
+// Wait blocks until every worker has stopped or ctx is done. It reports
+// whether all workers stopped in time.
+func (g *Group) Wait(ctx context.Context) bool {
+	done := make(chan struct{})
+
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,77 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/akrovv/redditclone/pkg/logger"
+)
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int32
+
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, logger.NewLogger(), "test", time.Millisecond, func(ctx context.Context) error {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return errors.New("some error")
+			}
+
+			return nil
+		})
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Error("expected Run to stop after cancel")
+		return
+	}
+
+	if atomic.LoadInt32(&calls) < 2 {
+		t.Errorf("expected job to keep running after an error, got %d calls", calls)
+	}
+}
+
+func TestGroupWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := &Group{}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+
+	g.Go(ctx, logger.NewLogger(), "test", time.Millisecond, func(ctx context.Context) error {
+		once.Do(func() { close(started) })
+		<-release
+		return nil
+	})
+
+	<-started
+	cancel()
+
+	// Job in progress
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer waitCancel()
+
+	if g.Wait(waitCtx) {
+		t.Error("expected Wait to time out while the job is running")
+		return
+	}
+
+	// Job finished
+	close(release)
+
+	if !g.Wait(context.Background()) {
+		t.Error("expected Wait to return after the job finished")
+	}
+}
@@ -0,0 +1,103 @@
+// Package certreload serves a TLS certificate from files and picks up the
+// renewed one without a restart of the server.
+package certreload
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	// stamp tells whether the files changed since the last load, a renewal
+	// replaces them or the symlinks to them.
+	stamp string
+}
+
+// New loads the certificate and fails if it doesn't, the server must not
+// start without one.
+func New(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetCertificate is the tls.Config callback, every handshake gets the
+// certificate loaded last.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// NotAfter is when the certificate in use expires.
+func (r *Reloader) NotAfter() time.Time {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert.Leaf.NotAfter
+}
+
+// Reload loads the files again if they changed and reports whether it did.
+// A pair that doesn't load, e.g. the certificate is renewed but the key is
+// not yet, keeps the old certificate in use until the next call.
+func (r *Reloader) Reload() (bool, error) {
+	stamp, err := r.stat()
+
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := stamp == r.stamp
+	r.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		return false, fmt.Errorf("can't load the certificate: %w", err)
+	}
+
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return false, fmt.Errorf("can't parse the certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert, r.stamp = &cert, stamp
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+func (r *Reloader) stat() (string, error) {
+	stamp := ""
+
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+
+		if err != nil {
+			return "", err
+		}
+
+		stamp += fmt.Sprintf("%d:%d;", info.ModTime().UnixNano(), info.Size())
+	}
+
+	return stamp, nil
+}
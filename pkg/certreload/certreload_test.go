@@ -0,0 +1,176 @@
+package certreload
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writePair writes a self-signed certificate for localhost and its key and
+// moves their modification time to at, a rotation within the same clock
+// tick must still be noticed.
+func writePair(t *testing.T, certFile, keyFile string, serial int64, at time.Time) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for name, block := range map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+	} {
+		if err = os.WriteFile(name, pem.EncodeToMemory(block), 0o600); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if err = os.Chtimes(name, at, at); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	cert, err := x509.ParseCertificate(der)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	return cert
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	now := time.Now()
+	first := writePair(t, certFile, keyFile, 1, now)
+
+	r, err := New(certFile, keyFile)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(first)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Proto", req.Proto)
+	}))
+	srv.EnableHTTP2 = true
+	srv.TLS = &tls.Config{GetCertificate: r.GetCertificate}
+	srv.StartTLS()
+	defer srv.Close()
+
+	get := func() (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{RootCAs: roots, ServerName: "localhost"},
+			ForceAttemptHTTP2: true,
+		}}
+		defer client.CloseIdleConnections()
+
+		resp, err := client.Get(srv.URL)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return resp, resp.Body.Close()
+	}
+
+	// OK, HTTP/2 with the first certificate
+	resp, err := get()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if resp.ProtoMajor != 2 || resp.TLS.PeerCertificates[0].SerialNumber.Int64() != 1 {
+		t.Errorf("expected HTTP/2 with serial 1, got: %s, %d", resp.Proto, resp.TLS.PeerCertificates[0].SerialNumber)
+		return
+	}
+
+	// Nothing changed
+	if reloaded, err := r.Reload(); reloaded || err != nil {
+		t.Errorf("expected no reload, got: %v, %v", reloaded, err)
+		return
+	}
+
+	// A broken pair keeps the old certificate
+	if err = os.WriteFile(keyFile, []byte("broken"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if reloaded, err := r.Reload(); reloaded || err == nil {
+		t.Errorf("expected error, got: %v, %v", reloaded, err)
+		return
+	}
+
+	if resp, err = get(); err != nil || resp.TLS.PeerCertificates[0].SerialNumber.Int64() != 1 {
+		t.Errorf("expected serial 1, got: %v", err)
+		return
+	}
+
+	// The renewed certificate is served without a restart
+	second := writePair(t, certFile, keyFile, 2, now.Add(time.Minute))
+	roots.AddCert(second)
+
+	if reloaded, err := r.Reload(); !reloaded || err != nil {
+		t.Errorf("expected reload, got: %v, %v", reloaded, err)
+		return
+	}
+
+	if !r.NotAfter().Equal(second.NotAfter) {
+		t.Errorf("expected: %s, got: %s", second.NotAfter, r.NotAfter())
+		return
+	}
+
+	if resp, err = get(); err != nil || resp.TLS.PeerCertificates[0].SerialNumber.Int64() != 2 {
+		t.Errorf("expected serial 2, got: %v", err)
+		return
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := New(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
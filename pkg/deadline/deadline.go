@@ -0,0 +1,16 @@
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+// Bound limits a single storage operation to timeout on top of whatever
+// deadline ctx already has. A zero timeout keeps only the caller's deadline.
+func Bound(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
@@ -21,3 +21,9 @@ func GenerateNewIDByMD(text string) string {
 	h.Write([]byte(text))
 	return hex.EncodeToString(h.Sum(nil))[:24]
 }
+
+// GenerateIDFrom derives a new ID from another one, the same one every time,
+// so a second import maps an ID the way the first one did.
+func GenerateIDFrom(id string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(id)).String()
+}
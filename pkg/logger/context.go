@@ -0,0 +1,20 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext stores the request-scoped logger in ctx.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request-scoped logger, or fallback when ctx has
+// none, e.g. outside of an HTTP request.
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+
+	return fallback
+}
@@ -4,6 +4,12 @@ type Logger interface {
 	Info(args ...interface{})
 	Infof(msg string, args ...interface{})
 	Debugf(msg string, args ...interface{})
+	Warn(args ...interface{})
+	Warnf(msg string, args ...interface{})
+	Error(args ...interface{})
+	Errorf(msg string, args ...interface{})
 	Fatalf(msg string, args ...interface{})
 	Panicf(msg string, args ...interface{})
+	// With returns a child logger that adds the key-value pairs to every line.
+	With(args ...interface{}) Logger
 }
@@ -8,18 +8,49 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+type zapLogger struct {
+	*zap.SugaredLogger
+}
+
+func (l zapLogger) With(args ...interface{}) Logger {
+	return zapLogger{l.SugaredLogger.With(args...)}
+}
+
+// NewLogger logs at the info level.
 func NewLogger() Logger {
+	l, err := New("info")
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return l
+}
+
+// New builds a logger writing lines at level and above: debug, info, warn
+// or error.
+func New(level string) (Logger, error) {
+	lvl, err := zapcore.ParseLevel(level)
+
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := zap.NewProductionConfig()
+	cfg.Level = zap.NewAtomicLevelAt(lvl)
 	cfg.EncoderConfig.TimeKey = "timestamp"
 	cfg.EncoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout(time.RFC1123)
 
 	logger, err := cfg.Build()
 
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	l := logger.Sugar()
+	return zapLogger{logger.Sugar()}, nil
+}
 
-	return l
+// NewNop discards everything, e.g. where no logger was set up.
+func NewNop() Logger {
+	return zapLogger{zap.NewNop().Sugar()}
 }
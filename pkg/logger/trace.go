@@ -0,0 +1,19 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTrace adds the trace and span IDs of the span in ctx to every line
+// written by l. l is returned as is when there is no span.
+func WithTrace(ctx context.Context, l Logger) Logger {
+	sc := trace.SpanContextFromContext(ctx)
+
+	if !sc.IsValid() {
+		return l
+	}
+
+	return l.With("trace_id", sc.TraceID().String(), "span_id", sc.SpanID().String())
+}